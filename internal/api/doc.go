@@ -0,0 +1,6 @@
+package api
+
+// Package api implements an optional localhost HTTP API for controlling a running
+// app: queueing URLs, listing and controlling tasks and playlists, and streaming
+// download events as Server-Sent Events. All endpoints require the token
+// configured in settings.
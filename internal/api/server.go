@@ -0,0 +1,700 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/download"
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/network"
+)
+
+// Server constants
+const (
+	ListenHost        = "127.0.0.1"
+	PathPrefix        = "/api/v1"
+	TokenHeader       = "X-Api-Token"
+	TokenQueryParam   = "token"
+	HeartbeatInterval = 15 * time.Second
+	ShutdownTimeout   = 3 * time.Second
+	MaxRequestBytes   = 64 << 10
+)
+
+// Task actions accepted by POST /tasks/{id}/{action}
+const (
+	ActionPause   = "pause"
+	ActionResume  = "resume"
+	ActionStop    = "stop"
+	ActionRestart = "restart"
+	ActionNext    = "next"   // moves the task to the top of the queue
+	ActionCancel  = "cancel" // playlists only
+	ActionDelete  = "delete" // partial downloads only
+)
+
+// DeletePartialParam asks DELETE /tasks/{id} to delete the unfinished download too
+const DeletePartialParam = "delete_partial"
+
+// Server exposes download.Downloader operations over HTTP on localhost
+type Server struct {
+	downloader download.Downloader
+	token      string
+	addURL     func(url string) error
+
+	mutex      sync.Mutex
+	httpServer *http.Server
+	listener   net.Listener
+	cancel     context.CancelFunc
+}
+
+// NewServer creates an API server; URLs are queued with downloader.AddTask unless
+// SetURLHandler installs a different handler
+func NewServer(downloader download.Downloader, token string) *Server {
+	s := &Server{
+		downloader: downloader,
+		token:      token,
+	}
+	s.addURL = func(url string) error {
+		_, err := downloader.AddTask(url)
+		return err
+	}
+	return s
+}
+
+// SetURLHandler replaces the handler used by POST /tasks, e.g. to route playlist URLs
+func (s *Server) SetURLHandler(handler func(url string) error) {
+	if handler != nil {
+		s.addURL = handler
+	}
+}
+
+// Handler returns the HTTP handler with all routes and token checks
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+PathPrefix+"/tasks", s.handleListTasks)
+	mux.HandleFunc("POST "+PathPrefix+"/tasks", s.handleAddTask)
+	mux.HandleFunc("GET "+PathPrefix+"/tasks/{id}", s.handleGetTask)
+	mux.HandleFunc("DELETE "+PathPrefix+"/tasks/{id}", s.handleRemoveTask)
+	mux.HandleFunc("POST "+PathPrefix+"/tasks/{id}/{action}", s.handleTaskAction)
+	mux.HandleFunc("PUT "+PathPrefix+"/tasks/{id}/proxy", s.handleSetTaskProxy)
+	mux.HandleFunc("PUT "+PathPrefix+"/tasks/{id}/schedule", s.handleScheduleTask)
+	mux.HandleFunc("PUT "+PathPrefix+"/tasks/{id}/position", s.handleMoveTask)
+	mux.HandleFunc("PUT "+PathPrefix+"/tasks/{id}/priority", s.handleSetTaskPriority)
+	mux.HandleFunc("GET "+PathPrefix+"/queue", s.handleListQueue)
+	mux.HandleFunc("GET "+PathPrefix+"/partials", s.handleListPartials)
+	mux.HandleFunc("POST "+PathPrefix+"/partials/{action}", s.handlePartialAction)
+	mux.HandleFunc("GET "+PathPrefix+"/playlists", s.handleListPlaylists)
+	mux.HandleFunc("GET "+PathPrefix+"/playlists/{id}", s.handleGetPlaylist)
+	mux.HandleFunc("POST "+PathPrefix+"/playlists/{id}/{action}", s.handlePlaylistAction)
+	mux.HandleFunc("PUT "+PathPrefix+"/playlists/{id}/schedule", s.handleSchedulePlaylist)
+	mux.HandleFunc("GET "+PathPrefix+"/events", s.handleEvents)
+	return s.withAuth(mux)
+}
+
+// Start listens on localhost at the given port and serves in background
+func (s *Server) Start(port int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.httpServer != nil {
+		return errors.New("api server already running")
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(ListenHost, fmt.Sprint(port)))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", port, err)
+	}
+
+	// Cancelled on Stop so long-lived event streams end before shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	s.httpServer = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	s.listener = listener
+	s.cancel = cancel
+
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("API server stopped: %v", err)
+		}
+	}(s.httpServer)
+
+	log.Printf("API server listening on %s", listener.Addr())
+	return nil
+}
+
+// Addr returns the listening address, or an empty string when stopped
+func (s *Server) Addr() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop shuts the server down; it is a no-op when not running
+func (s *Server) Stop() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.httpServer == nil {
+		return nil
+	}
+	s.cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	err := s.httpServer.Shutdown(ctx)
+
+	s.httpServer = nil
+	s.listener = nil
+	s.cancel = nil
+	return err
+}
+
+// withAuth allows CORS preflight and rejects requests without a valid token.
+// Requests naming another host are refused, so a page whose domain was rebound
+// to the loopback address cannot reach the API.
+func (s *Server) withAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !localHost(r.Host) {
+			writeError(w, http.StatusForbidden, errors.New("host not allowed"))
+			return
+		}
+
+		// Browser bookmarklets call the API cross-origin; the token still guards access
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+TokenHeader)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if !s.authorized(r) {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorized checks the token from the Authorization header or X-Api-Token.
+// ?token= is only accepted by GET /events, as EventSource cannot set headers
+// and query strings end up in browser history and logs.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return false
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.Header.Get(TokenHeader)
+	}
+	if token == "" && r.Method == http.MethodGet && r.URL.Path == PathPrefix+"/events" {
+		token = r.URL.Query().Get(TokenQueryParam)
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// localHost reports whether the Host header names the loopback interface
+func localHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.Trim(host, "[]")
+	return host == "localhost" || host == ListenHost || host == "::1"
+}
+
+// addTaskRequest is the body of POST /tasks
+type addTaskRequest struct {
+	URL string `json:"url"`
+}
+
+// setProxyRequest is the body of PUT /tasks/{id}/proxy
+type setProxyRequest struct {
+	Proxy string `json:"proxy"`
+}
+
+// scheduleRequest is the body of PUT /tasks/{id}/schedule and PUT /playlists/{id}/schedule;
+// a missing or null start_at starts as soon as a download slot is free
+type scheduleRequest struct {
+	StartAt *time.Time `json:"start_at"`
+}
+
+// startTime returns the requested start time, zero when none was given
+func (r scheduleRequest) startTime() time.Time {
+	if r.StartAt == nil {
+		return time.Time{}
+	}
+	return *r.StartAt
+}
+
+// moveRequest is the body of PUT /tasks/{id}/position; 0 is the top of the
+// queue and a negative index the bottom
+type moveRequest struct {
+	Index int `json:"index"`
+}
+
+// priorityRequest is the body of PUT /tasks/{id}/priority
+type priorityRequest struct {
+	Priority string `json:"priority"`
+}
+
+// partialRequest is the body of POST /partials/{action}
+type partialRequest struct {
+	Path string `json:"path"`
+}
+
+// taskJSON is the API representation of a download task
+type taskJSON struct {
+	ID         string    `json:"id"`
+	URL        string    `json:"url"`
+	Site       string    `json:"site,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	Status     string    `json:"status"`
+	Progress   float64   `json:"progress"`
+	Percent    int       `json:"percent"`
+	Speed      string    `json:"speed,omitempty"`
+	ETASec     int       `json:"eta_sec"`
+	Error      string    `json:"error,omitempty"`
+	OutputPath string    `json:"output_path,omitempty"`
+	FileSize   int64     `json:"file_size,omitempty"`
+	PlaylistID string    `json:"playlist_id,omitempty"`
+	Priority   string    `json:"priority"`
+	Proxy      string    `json:"proxy,omitempty"` // password masked
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+
+	// Live broadcasts
+	ScheduledStart time.Time `json:"scheduled_start,omitempty"`
+	RecordedSec    int       `json:"recorded_sec,omitempty"`
+
+	// Deferred start; scheduled_start doubles as the time a Scheduled task starts
+	StartAt time.Time `json:"start_at,omitempty"`
+
+	// RemovedSec is the length of the SponsorBlock segments cut out of the file
+	RemovedSec int `json:"removed_sec,omitempty"`
+}
+
+// eventJSON is the payload of a streamed event
+type eventJSON struct {
+	Type     download.EventType `json:"type"`
+	Task     *taskJSON          `json:"task,omitempty"`
+	Playlist *model.Playlist    `json:"playlist,omitempty"`
+}
+
+// newTaskJSON converts a task snapshot to its API representation
+func newTaskJSON(task *model.DownloadTask) *taskJSON {
+	return &taskJSON{
+		ID:         task.ID,
+		URL:        task.URL,
+		Site:       task.Site,
+		Title:      task.Title,
+		Status:     task.Status.String(),
+		Progress:   task.Progress,
+		Percent:    task.Percent,
+		Speed:      task.Speed,
+		ETASec:     task.ETASec,
+		Error:      task.LastError,
+		OutputPath: task.OutputPath,
+		FileSize:   task.FileSize,
+		PlaylistID: task.PlaylistID,
+		Priority:   task.Priority.String(),
+		Proxy:      redactProxy(task.Proxy),
+		StartedAt:  task.StartedAt,
+		FinishedAt: task.FinishedAt,
+
+		ScheduledStart: task.ScheduledStart,
+		RecordedSec:    int(task.Recorded.Seconds()),
+
+		StartAt: task.StartAt,
+
+		RemovedSec: int(task.RemovedDuration.Round(time.Second).Seconds()),
+	}
+}
+
+// handleListTasks returns all tasks
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	tasks := s.downloader.GetAllTasks()
+	result := make([]*taskJSON, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, newTaskJSON(task))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleAddTask queues a URL
+func (s *Server) handleAddTask(w http.ResponseWriter, r *http.Request) {
+	var req addTaskRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if req.URL == "" {
+		writeError(w, http.StatusBadRequest, errors.New("url is required"))
+		return
+	}
+
+	if err := s.addURL(req.URL); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"url": req.URL})
+}
+
+// handleGetTask returns a single task
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	task, ok := s.downloader.GetTask(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("task not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+// handleRemoveTask removes a task
+func (s *Server) handleRemoveTask(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.downloader.GetTask(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("task not found"))
+		return
+	}
+	deletePartial := r.URL.Query().Get(DeletePartialParam) == "true"
+	if err := s.downloader.RemoveTask(id, deletePartial); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleTaskAction pauses, resumes, stops, restarts or prioritizes a task
+func (s *Server) handleTaskAction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.downloader.GetTask(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("task not found"))
+		return
+	}
+
+	var err error
+	switch action := r.PathValue("action"); action {
+	case ActionPause:
+		err = s.downloader.PauseTask(id)
+	case ActionResume:
+		err = s.downloader.ResumeTask(id)
+	case ActionStop:
+		err = s.downloader.StopTask(id)
+	case ActionRestart:
+		err = s.downloader.RestartTask(id)
+	case ActionNext:
+		err = s.downloader.DownloadNext(id)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action: %s", action))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	task, _ := s.downloader.GetTask(id)
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+// handleSetTaskProxy overrides the proxy of a task; an empty proxy restores the global one
+func (s *Server) handleSetTaskProxy(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.downloader.GetTask(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("task not found"))
+		return
+	}
+
+	var req setProxyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := s.downloader.SetTaskProxy(id, req.Proxy); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	task, _ := s.downloader.GetTask(id)
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+// handleScheduleTask sets when a task starts
+func (s *Server) handleScheduleTask(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.downloader.GetTask(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("task not found"))
+		return
+	}
+
+	var req scheduleRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := s.downloader.ScheduleTask(id, req.startTime()); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	task, _ := s.downloader.GetTask(id)
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+// handleListQueue returns the queued and paused tasks in start order
+func (s *Server) handleListQueue(w http.ResponseWriter, r *http.Request) {
+	tasks := s.downloader.GetQueue()
+	result := make([]*taskJSON, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, newTaskJSON(task))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleListPartials lists the unfinished downloads no task owns
+func (s *Server) handleListPartials(w http.ResponseWriter, r *http.Request) {
+	partials := s.downloader.OrphanedPartials()
+	if partials == nil {
+		partials = []download.PartialFile{}
+	}
+	writeJSON(w, http.StatusOK, partials)
+}
+
+// handlePartialAction resumes or deletes an orphaned partial download
+func (s *Server) handlePartialAction(w http.ResponseWriter, r *http.Request) {
+	var req partialRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	switch action := r.PathValue("action"); action {
+	case ActionResume:
+		task, err := s.downloader.ResumePartial(req.Path)
+		if err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newTaskJSON(task))
+	case ActionDelete:
+		if err := s.downloader.DeletePartial(req.Path); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action: %s", action))
+	}
+}
+
+// handleMoveTask moves a queued task to another queue position
+func (s *Server) handleMoveTask(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.downloader.GetTask(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("task not found"))
+		return
+	}
+
+	var req moveRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := s.downloader.MoveTask(id, req.Index); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	task, _ := s.downloader.GetTask(id)
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+// handleSetTaskPriority changes the priority of a task
+func (s *Server) handleSetTaskPriority(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.downloader.GetTask(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("task not found"))
+		return
+	}
+
+	var req priorityRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	priority, err := model.ParseTaskPriority(req.Priority)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.downloader.SetTaskPriority(id, priority); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	task, _ := s.downloader.GetTask(id)
+	writeJSON(w, http.StatusOK, newTaskJSON(task))
+}
+
+// redactProxy hides the password of a proxy URL
+func redactProxy(proxyURL string) string {
+	if proxyURL == "" {
+		return ""
+	}
+	cfg, err := network.ParseProxyURL(proxyURL)
+	if err != nil {
+		return ""
+	}
+	return cfg.String()
+}
+
+// handleListPlaylists returns all playlists known to the download service
+func (s *Server) handleListPlaylists(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.downloader.GetAllPlaylists())
+}
+
+// handleGetPlaylist returns a single playlist
+func (s *Server) handleGetPlaylist(w http.ResponseWriter, r *http.Request) {
+	playlist, ok := s.downloader.GetPlaylist(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("playlist not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, playlist)
+}
+
+// handlePlaylistAction pauses, resumes or cancels a playlist
+func (s *Server) handlePlaylistAction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.downloader.GetPlaylist(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("playlist not found"))
+		return
+	}
+
+	var err error
+	switch action := r.PathValue("action"); action {
+	case ActionPause:
+		err = s.downloader.PausePlaylist(id)
+	case ActionResume:
+		err = s.downloader.ResumePlaylist(id)
+	case ActionCancel:
+		err = s.downloader.CancelPlaylist(id)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown action: %s", action))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	playlist, _ := s.downloader.GetPlaylist(id)
+	writeJSON(w, http.StatusOK, playlist)
+}
+
+// handleSchedulePlaylist sets when the unfinished videos of a playlist start
+func (s *Server) handleSchedulePlaylist(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.downloader.GetPlaylist(id); !ok {
+		writeError(w, http.StatusNotFound, errors.New("playlist not found"))
+		return
+	}
+
+	var req scheduleRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := s.downloader.SchedulePlaylist(id, req.startTime()); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	playlist, _ := s.downloader.GetPlaylist(id)
+	writeJSON(w, http.StatusOK, playlist)
+}
+
+// handleEvents streams download events as Server-Sent Events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	events, unsubscribe := s.downloader.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes one event in SSE format
+func writeEvent(w http.ResponseWriter, event download.Event) error {
+	payload := eventJSON{Type: event.Type, Playlist: event.Playlist}
+	if event.Type != download.EventPlaylistUpdated {
+		payload.Task = newTaskJSON(&event.Task)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Failed to write API response: %v", err)
+	}
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
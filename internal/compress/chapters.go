@@ -0,0 +1,265 @@
+package compress
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// Chapter constants
+const (
+	// ChapterMetadataHeader starts an ffmpeg metadata file
+	ChapterMetadataHeader = ";FFMETADATA1"
+	// ChapterTimebase is the unit of chapter START and END values: milliseconds
+	ChapterTimebase = "1/1000"
+	// ChapterMetadataSuffix names the temporary metadata file next to the media
+	ChapterMetadataSuffix = ".chapters.txt"
+	// ChapterTempInfix marks the temporary output while chapters are embedded
+	ChapterTempInfix = ".chapters"
+	// ChapterFilePerm is the permission of metadata files
+	ChapterFilePerm = 0644
+	// ChapterDirPerm is the permission of the directory split chapters are written to
+	ChapterDirPerm = 0755
+)
+
+// chapterContainers are the formats whose chapters ffmpeg can write
+var chapterContainers = map[string]bool{
+	".mp4": true,
+	".m4a": true,
+	".m4v": true,
+	".mov": true,
+	".mkv": true,
+	".mka": true,
+}
+
+// SupportsChapters reports whether chapters can be embedded in the file's container
+func SupportsChapters(path string) bool {
+	return chapterContainers[strings.ToLower(filepath.Ext(strings.TrimSuffix(path, engine.PartSuffix)))]
+}
+
+// FFmpegAvailable reports whether ffmpeg is in PATH
+func FFmpegAvailable() bool {
+	_, err := exec.LookPath(FFmpegCommand)
+	return err == nil
+}
+
+// ChapterMetadata renders chapters as an ffmpeg metadata file. A last chapter
+// without an end runs to the end of the file.
+func ChapterMetadata(chapters []model.Chapter) string {
+	var b strings.Builder
+	b.WriteString(ChapterMetadataHeader + "\n")
+	for _, chapter := range chapters {
+		b.WriteString("[CHAPTER]\n")
+		b.WriteString("TIMEBASE=" + ChapterTimebase + "\n")
+		fmt.Fprintf(&b, "START=%d\n", chapter.Start.Milliseconds())
+		if chapter.End > chapter.Start {
+			fmt.Fprintf(&b, "END=%d\n", chapter.End.Milliseconds())
+		}
+		b.WriteString("title=" + escapeMetadata(chapter.Title) + "\n")
+	}
+	return b.String()
+}
+
+// escapeMetadata escapes the characters special to ffmpeg metadata files
+func escapeMetadata(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch r {
+		case '=', ';', '#', '\\', '\n':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// BuildEmbedChaptersArgs builds the ffmpeg arguments that copy all streams of
+// inputPath to outputPath with the chapters of metadataPath
+func BuildEmbedChaptersArgs(inputPath, metadataPath, outputPath string) []string {
+	return append(append([]string{
+		"-y",
+		"-v", FFprobeLogLevel,
+		"-i", inputPath,
+		"-i", metadataPath,
+		"-map", "0",
+		"-map_metadata", "0",
+		"-map_chapters", "1",
+		"-c", "copy",
+	}, engine.MuxerArgs(outputPath)...), outputPath)
+}
+
+// BuildSplitArgs builds the ffmpeg arguments that copy one chapter of inputPath to outputPath
+func BuildSplitArgs(inputPath string, chapter model.Chapter, outputPath string) []string {
+	args := []string{"-y", "-v", FFprobeLogLevel, "-ss", formatSeconds(chapter.Start)}
+	if chapter.End > chapter.Start {
+		args = append(args, "-to", formatSeconds(chapter.End))
+	}
+	return append(args,
+		"-i", inputPath,
+		"-map", "0",
+		"-map_chapters", "-1",
+		"-c", "copy",
+		"-metadata", "title="+chapter.Title,
+		outputPath,
+	)
+}
+
+// formatSeconds formats a duration as seconds with millisecond precision
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// EmbedChapters writes chapters into the media file in place. The file is
+// rewritten through a temporary copy, so it is left untouched on failure.
+func EmbedChapters(ctx context.Context, inputPath string, chapters []model.Chapter) error {
+	if len(chapters) == 0 {
+		return nil
+	}
+	if !SupportsChapters(inputPath) {
+		return fmt.Errorf("chapters cannot be embedded in %s files", filepath.Ext(inputPath))
+	}
+
+	metadataPath := inputPath + ChapterMetadataSuffix
+	if err := os.WriteFile(metadataPath, []byte(ChapterMetadata(chapters)), ChapterFilePerm); err != nil {
+		return err
+	}
+	defer os.Remove(metadataPath)
+
+	tempPath := rewritePath(inputPath, ChapterTempInfix)
+	output, err := exec.CommandContext(ctx, FFmpegCommand, BuildEmbedChaptersArgs(inputPath, metadataPath, tempPath)...).CombinedOutput()
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return os.Rename(tempPath, inputPath)
+}
+
+// ChapterFileName names the file of a split chapter "NN - title.ext"
+func ChapterFileName(index, total int, title, ext string) string {
+	width := max(len(strconv.Itoa(total)), 2)
+	name := fmt.Sprintf("%0*d - %s", width, index+1, sanitizeChapterTitle(title))
+	return name + ext
+}
+
+// sanitizeChapterTitle removes characters that are invalid in file names
+func sanitizeChapterTitle(title string) string {
+	title = strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		if r < ' ' {
+			return -1
+		}
+		return r
+	}, title)
+	title = strings.Trim(strings.TrimSpace(title), ".")
+	if title == "" {
+		return "chapter"
+	}
+	return title
+}
+
+// ChaptersDir is the directory split chapters of inputPath are written to:
+// a folder named after the file, next to it
+func ChaptersDir(inputPath string) string {
+	return strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
+}
+
+// SplitByChapters cuts the media file into one file per chapter in
+// ChaptersDir. Streams are copied, so cuts snap to the nearest keyframes.
+func (s *Service) SplitByChapters(inputPath string, chapters []model.Chapter) (*model.CompressionTask, error) {
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("file has no chapters: %s", inputPath)
+	}
+
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	for _, task := range s.tasks {
+		if task.InputPath == inputPath && task.Status.IsActive() {
+			return nil, fmt.Errorf("processing already in progress for file: %s", inputPath)
+		}
+	}
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("input file does not exist: %s", inputPath)
+	}
+
+	task := &model.CompressionTask{
+		ID:         generateTaskID(),
+		InputPath:  inputPath,
+		OutputPath: ChaptersDir(inputPath),
+		Status:     model.TaskStatusPending,
+		StartedAt:  time.Now(),
+	}
+	s.tasks[task.ID] = task
+
+	go s.startSplit(task, chapters)
+
+	return task, nil
+}
+
+// startSplit runs ffmpeg once per chapter
+func (s *Service) startSplit(task *model.CompressionTask, chapters []model.Chapter) {
+	if err := os.MkdirAll(task.OutputPath, ChapterDirPerm); err != nil {
+		s.setTaskError(task, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.tasksMutex.Lock()
+	if task.Status == model.TaskStatusPending {
+		task.Status = model.TaskStatusDownloading
+	}
+	s.tasksMutex.Unlock()
+	s.notifyUpdate(task)
+
+	go s.watchStop(task, cancel)
+
+	ext := filepath.Ext(task.InputPath)
+	var splitErr error
+	for i, chapter := range chapters {
+		outputPath := filepath.Join(task.OutputPath, ChapterFileName(i, len(chapters), chapter.Title, ext))
+		output, err := exec.CommandContext(ctx, FFmpegCommand, BuildSplitArgs(task.InputPath, chapter, outputPath)...).CombinedOutput()
+		if err != nil {
+			// Remove the partial chapter; finished ones are kept
+			os.Remove(outputPath)
+			splitErr = fmt.Errorf("chapter %d: %w: %s", i+1, err, strings.TrimSpace(string(output)))
+			break
+		}
+
+		s.tasksMutex.Lock()
+		task.Progress = float64(i+1) / float64(len(chapters))
+		task.Percent = int(task.Progress * 100)
+		s.tasksMutex.Unlock()
+		s.notifyUpdate(task)
+	}
+
+	s.tasksMutex.Lock()
+	if ctx.Err() == context.Canceled {
+		task.Status = model.TaskStatusStopped
+	} else if splitErr != nil {
+		log.Printf("Splitting %s by chapters failed: %v", task.InputPath, splitErr)
+		task.Status = model.TaskStatusError
+		task.LastError = splitErr.Error()
+	} else {
+		task.Status = model.TaskStatusCompleted
+		task.Progress = 1.0
+		task.Percent = 100
+	}
+	task.FinishedAt = time.Now()
+	s.tasksMutex.Unlock()
+
+	s.notifyUpdate(task)
+}
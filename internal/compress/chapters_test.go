@@ -0,0 +1,124 @@
+package compress
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+func TestChapterMetadata(t *testing.T) {
+	chapters := []model.Chapter{
+		{Title: "Intro", Start: 0, End: 90 * time.Second},
+		{Title: "Q&A; a=b #1", Start: 90 * time.Second},
+	}
+	expected := `;FFMETADATA1
+[CHAPTER]
+TIMEBASE=1/1000
+START=0
+END=90000
+title=Intro
+[CHAPTER]
+TIMEBASE=1/1000
+START=90000
+title=Q&A\; a\=b \#1
+`
+	if result := ChapterMetadata(chapters); result != expected {
+		t.Errorf("unexpected metadata:\n%s\nwant\n%s", result, expected)
+	}
+}
+
+func TestSupportsChapters(t *testing.T) {
+	tests := map[string]bool{
+		"/videos/talk.mp4":      true,
+		"/videos/album.M4A":     true,
+		"/videos/film.mkv":      true,
+		"/videos/clip.webm":     false,
+		"/videos/live.ts":       false,
+		"/videos/talk.mp4.part": true,
+	}
+	for path, expected := range tests {
+		if result := SupportsChapters(path); result != expected {
+			t.Errorf("SupportsChapters(%s) = %v, expected %v", path, result, expected)
+		}
+	}
+}
+
+func TestChapterFileName(t *testing.T) {
+	tests := []struct {
+		index, total int
+		title        string
+		expected     string
+	}{
+		{0, 12, "Intro", "01 - Intro.mp4"},
+		{8, 12, "Part 1/2: Setup?", "09 - Part 1_2_ Setup_.mp4"},
+		{4, 120, "  ...  ", "005 - chapter.mp4"},
+	}
+	for _, test := range tests {
+		if result := ChapterFileName(test.index, test.total, test.title, ".mp4"); result != test.expected {
+			t.Errorf("ChapterFileName(%d, %d, %q) = %q, expected %q", test.index, test.total, test.title, result, test.expected)
+		}
+	}
+}
+
+func TestBuildSplitArgs(t *testing.T) {
+	chapter := model.Chapter{Title: "Lexing", Start: 150500 * time.Millisecond, End: 605 * time.Second}
+	args := BuildSplitArgs("/in.mp4", chapter, "/in/02 - Lexing.mp4")
+	expected := []string{
+		"-y", "-v", "error",
+		"-ss", "150.500",
+		"-to", "605.000",
+		"-i", "/in.mp4",
+		"-map", "0",
+		"-map_chapters", "-1",
+		"-c", "copy",
+		"-metadata", "title=Lexing",
+		"/in/02 - Lexing.mp4",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("unexpected args %q", args)
+	}
+
+	// The last chapter runs to the end of the file
+	last := BuildSplitArgs("/in.mp4", model.Chapter{Title: "End", Start: time.Minute}, "/out.mp4")
+	if strings.Contains(strings.Join(last, " "), "-to") {
+		t.Errorf("open-ended chapter must not have -to: %q", last)
+	}
+}
+
+func TestBuildEmbedChaptersArgs(t *testing.T) {
+	args := strings.Join(BuildEmbedChaptersArgs("/in.mp4", "/in.mp4.chapters.txt", "/in.chapters.mp4"), " ")
+	expected := "-y -v error -i /in.mp4 -i /in.mp4.chapters.txt -map 0 -map_metadata 0 -map_chapters 1 -c copy /in.chapters.mp4"
+	if args != expected {
+		t.Errorf("unexpected args %q", args)
+	}
+
+	// A partial file needs its container named explicitly
+	args = strings.Join(BuildEmbedChaptersArgs("/in.mp4.part", "/in.mp4.part.chapters.txt", "/in.chapters.mp4.part"), " ")
+	if !strings.HasSuffix(args, "-c copy -f mp4 /in.chapters.mp4.part") {
+		t.Errorf("unexpected args %q", args)
+	}
+	if temp := rewritePath("/in.mp4.part", ChapterTempInfix); temp != "/in.chapters.mp4.part" {
+		t.Errorf("unexpected temporary file %s", temp)
+	}
+}
+
+func TestSplitByChapters_Validation(t *testing.T) {
+	service := NewService()
+	chapters := []model.Chapter{{Title: "One"}, {Title: "Two", Start: time.Minute}}
+
+	if _, err := service.SplitByChapters("/path/to/nonexistent/file.mp4", chapters); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected 'does not exist' error, got: %v", err)
+	}
+	if _, err := service.SplitByChapters("/path/to/file.mp4", nil); err == nil || !strings.Contains(err.Error(), "no chapters") {
+		t.Errorf("Expected 'no chapters' error, got: %v", err)
+	}
+}
+
+func TestChaptersDir(t *testing.T) {
+	if dir := ChaptersDir("/videos/Lecture 1.mp4"); dir != "/videos/Lecture 1" {
+		t.Errorf("unexpected chapters dir %s", dir)
+	}
+}
@@ -0,0 +1,58 @@
+package compress
+
+import (
+	"context"
+	"log"
+
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/platform"
+)
+
+// SetCollisionPolicy sets what happens when a compressed file already exists
+func (s *Service) SetCollisionPolicy(policy platform.CollisionPolicy) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+	s.collision = policy
+}
+
+// resolveCollision applies the collision policy to the task's output path. It
+// returns true when the existing file is kept as the result; otherwise the
+// task's OutputPath is where ffmpeg may write.
+func (s *Service) resolveCollision(ctx context.Context, task *model.CompressionTask) bool {
+	s.tasksMutex.RLock()
+	policy := s.collision
+	outputPath := task.OutputPath
+	s.tasksMutex.RUnlock()
+
+	if !platform.FileExists(outputPath) {
+		return false
+	}
+	switch policy {
+	case platform.CollisionSkip:
+		return true
+	case platform.CollisionOverwrite:
+		return false // ffmpeg runs with -y
+	case platform.CollisionVerify:
+		if s.compressedFrom(ctx, task.InputPath, outputPath) {
+			return true
+		}
+	}
+
+	renamed := platform.NumberedPath(outputPath)
+	log.Printf("Compressed file %s exists, writing %s instead", outputPath, renamed)
+	s.tasksMutex.Lock()
+	task.OutputPath = renamed
+	s.tasksMutex.Unlock()
+	return false
+}
+
+// compressedFrom reports whether outputPath has the duration of inputPath; files
+// that cannot be probed do not match
+func (s *Service) compressedFrom(ctx context.Context, inputPath, outputPath string) bool {
+	input, err := s.probe(ctx, inputPath)
+	if err != nil {
+		return false
+	}
+	output, err := s.probe(ctx, outputPath)
+	return err == nil && SameDuration(input, output)
+}
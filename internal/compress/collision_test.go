@@ -0,0 +1,64 @@
+package compress
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/platform"
+)
+
+// collisionTask returns a task whose compressed output already exists
+func collisionTask(t *testing.T) *model.CompressionTask {
+	t.Helper()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "Intro.mp4")
+	output := generateOutputPath(input)
+	for _, path := range []string{input, output} {
+		if err := os.WriteFile(path, []byte("video"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &model.CompressionTask{InputPath: input, OutputPath: output}
+}
+
+func TestResolveCollision(t *testing.T) {
+	durations := map[string]time.Duration{}
+	service := NewService().(*Service)
+	service.probe = func(_ context.Context, path string) (time.Duration, error) {
+		return durations[filepath.Base(path)], nil
+	}
+
+	tests := []struct {
+		policy  platform.CollisionPolicy
+		output  time.Duration
+		keep    bool
+		renamed bool
+	}{
+		{policy: platform.CollisionSkip, keep: true},
+		{policy: platform.CollisionOverwrite},
+		{policy: platform.CollisionRename, renamed: true},
+		{policy: platform.CollisionVerify, output: 61 * time.Second, keep: true},
+		{policy: platform.CollisionVerify, output: 30 * time.Second, renamed: true},
+	}
+	for _, test := range tests {
+		task := collisionTask(t)
+		original := task.OutputPath
+		durations["Intro.mp4"] = 60 * time.Second
+		durations[filepath.Base(original)] = test.output
+
+		service.SetCollisionPolicy(test.policy)
+		if keep := service.resolveCollision(context.Background(), task); keep != test.keep {
+			t.Errorf("%s: expected keep=%v, got %v", test.policy, test.keep, keep)
+		}
+		if renamed := task.OutputPath != original; renamed != test.renamed {
+			t.Errorf("%s: unexpected output path %s", test.policy, task.OutputPath)
+		}
+		if test.renamed && filepath.Base(task.OutputPath) != "Intro-compressed_1.mp4" {
+			t.Errorf("%s: expected a numbered name, got %s", test.policy, task.OutputPath)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package compress
 
 import (
 	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/platform"
 )
 
 // Compressor defines the interface for the compression service.
@@ -10,4 +11,10 @@ type Compressor interface {
 	StartCompression(inputPath string) (*model.CompressionTask, error)
 	StopCompression(taskID string) error
 	GetTask(taskID string) (*model.CompressionTask, bool)
+
+	// SetCollisionPolicy sets what happens when a compressed file already exists
+	SetCollisionPolicy(policy platform.CollisionPolicy)
+
+	// SplitByChapters cuts a media file into one file per chapter
+	SplitByChapters(inputPath string, chapters []model.Chapter) (*model.CompressionTask, error)
 }
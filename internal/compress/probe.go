@@ -0,0 +1,93 @@
+package compress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DurationTolerance is how far the durations of two files may differ for them
+// to count as the same video
+const DurationTolerance = 2 * time.Second
+
+// FFprobeAvailable reports whether ffprobe is in PATH
+func FFprobeAvailable() bool {
+	_, err := exec.LookPath(FFprobeCommand)
+	return err == nil
+}
+
+// ProbeDuration reads the duration of a media file with ffprobe
+func ProbeDuration(ctx context.Context, path string) (time.Duration, error) {
+	cmd := exec.CommandContext(ctx, FFprobeCommand, "-v", FFprobeLogLevel, "-show_entries", FFprobeShowEntries, "-of", FFprobeOutputFormat, path)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to run ffprobe: %w", err)
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse duration: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// MediaInfo describes a media file as read by ffprobe
+type MediaInfo struct {
+	Duration time.Duration // zero when the container does not report one
+	HasVideo bool
+	HasAudio bool
+}
+
+// ProbeMedia reads the duration and stream types of a media file with ffprobe.
+// A file ffprobe cannot read or that has no streams is an error.
+func ProbeMedia(ctx context.Context, path string) (MediaInfo, error) {
+	cmd := exec.CommandContext(ctx, FFprobeCommand, "-v", FFprobeLogLevel, "-show_entries", FFprobeMediaEntries, "-of", "json", path)
+	output, err := cmd.Output()
+	if err != nil {
+		return MediaInfo{}, fmt.Errorf("failed to run ffprobe: %w", err)
+	}
+	return parseProbeOutput(output)
+}
+
+// parseProbeOutput reads the JSON written by ProbeMedia's ffprobe call
+func parseProbeOutput(output []byte) (MediaInfo, error) {
+	var probe struct {
+		Streams []struct {
+			CodecType string `json:"codec_type"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return MediaInfo{}, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	if len(probe.Streams) == 0 {
+		return MediaInfo{}, errors.New("no media streams found")
+	}
+
+	var info MediaInfo
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			info.HasVideo = true
+		case "audio":
+			info.HasAudio = true
+		}
+	}
+	if seconds, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	return info, nil
+}
+
+// SameDuration reports whether two durations are within DurationTolerance
+func SameDuration(a, b time.Duration) bool {
+	diff := a - b
+	return diff <= DurationTolerance && diff >= -DurationTolerance
+}
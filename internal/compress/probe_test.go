@@ -0,0 +1,34 @@
+package compress
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSameDuration(t *testing.T) {
+	if !SameDuration(time.Minute, time.Minute+DurationTolerance) {
+		t.Error("durations within the tolerance should match")
+	}
+	if SameDuration(time.Minute, time.Minute-DurationTolerance-time.Millisecond) {
+		t.Error("durations beyond the tolerance should not match")
+	}
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	info, err := parseProbeOutput([]byte(`{"streams":[{"codec_type":"video"},{"codec_type":"audio"}],"format":{"duration":"61.500000"}}`))
+	if err != nil {
+		t.Fatalf("parseProbeOutput: %v", err)
+	}
+	if !info.HasVideo || !info.HasAudio || info.Duration != 61500*time.Millisecond {
+		t.Errorf("unexpected media info %+v", info)
+	}
+
+	info, err = parseProbeOutput([]byte(`{"streams":[{"codec_type":"audio"}],"format":{}}`))
+	if err != nil || info.HasVideo || !info.HasAudio || info.Duration != 0 {
+		t.Errorf("unexpected media info %+v, %v", info, err)
+	}
+
+	if _, err := parseProbeOutput([]byte(`{"streams":[],"format":{"duration":"1.0"}}`)); err == nil {
+		t.Error("expected an error for a file without streams")
+	}
+}
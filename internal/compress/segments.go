@@ -0,0 +1,111 @@
+package compress
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+)
+
+// Segment removal constants
+const (
+	// CutListSuffix names the temporary concat list next to the media
+	CutListSuffix = ".cut.txt"
+	// CutTempInfix marks the temporary output while segments are removed
+	CutTempInfix = ".cut"
+)
+
+// TimeRange is a part of a media file; a zero End runs to the end of the file
+type TimeRange struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// KeepRanges returns the parts of a file that remain once the sorted,
+// non-overlapping ranges are removed
+func KeepRanges(remove []TimeRange, duration time.Duration) []TimeRange {
+	var keep []TimeRange
+	var position time.Duration
+	for _, r := range remove {
+		if r.Start > position {
+			keep = append(keep, TimeRange{Start: position, End: r.Start})
+		}
+		position = max(position, r.End)
+	}
+	if duration == 0 || position < duration {
+		keep = append(keep, TimeRange{Start: position, End: duration})
+	}
+	return keep
+}
+
+// CutList renders an ffmpeg concat list that plays the kept ranges of inputPath in order
+func CutList(inputPath string, keep []TimeRange) string {
+	quoted := "'" + strings.ReplaceAll(inputPath, "'", `'\''`) + "'"
+	var b strings.Builder
+	b.WriteString("ffconcat version 1.0\n")
+	for _, r := range keep {
+		b.WriteString("file " + quoted + "\n")
+		if r.Start > 0 {
+			b.WriteString("inpoint " + formatSeconds(r.Start) + "\n")
+		}
+		if r.End > r.Start {
+			b.WriteString("outpoint " + formatSeconds(r.End) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// BuildCutArgs builds the ffmpeg arguments that copy the streams listed in
+// listPath to outputPath
+func BuildCutArgs(listPath, outputPath string) []string {
+	return append(append([]string{
+		"-y",
+		"-v", FFprobeLogLevel,
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listPath,
+		"-map", "0",
+		"-c", "copy",
+	}, engine.MuxerArgs(outputPath)...), outputPath)
+}
+
+// rewritePath returns the temporary file an in-place rewrite of path goes
+// through: "name.cut.mp4" for "name.mp4". A partial file keeps its PartSuffix,
+// so the rewrite stays hidden from media scanners as well.
+func rewritePath(path, infix string) string {
+	media := strings.TrimSuffix(path, engine.PartSuffix)
+	ext := filepath.Ext(media)
+	return strings.TrimSuffix(media, ext) + infix + ext + strings.TrimPrefix(path, media)
+}
+
+// RemoveRanges cuts the ranges out of the media file in place. Streams are
+// copied, so cuts snap to the nearest keyframes. The file is rewritten through
+// a temporary copy, so it is left untouched on failure.
+func RemoveRanges(ctx context.Context, inputPath string, remove []TimeRange, duration time.Duration) error {
+	if len(remove) == 0 {
+		return nil
+	}
+	keep := KeepRanges(remove, duration)
+	if len(keep) == 0 {
+		return fmt.Errorf("nothing would remain of %s", filepath.Base(inputPath))
+	}
+
+	listPath := inputPath + CutListSuffix
+	if err := os.WriteFile(listPath, []byte(CutList(inputPath, keep)), ChapterFilePerm); err != nil {
+		return err
+	}
+	defer os.Remove(listPath)
+
+	tempPath := rewritePath(inputPath, CutTempInfix)
+	output, err := exec.CommandContext(ctx, FFmpegCommand, BuildCutArgs(listPath, tempPath)...).CombinedOutput()
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return os.Rename(tempPath, inputPath)
+}
@@ -0,0 +1,38 @@
+package compress
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeepRanges(t *testing.T) {
+	remove := []TimeRange{{Start: 0, End: 10 * time.Second}, {Start: time.Minute, End: 90 * time.Second}}
+	expected := []TimeRange{{Start: 10 * time.Second, End: time.Minute}, {Start: 90 * time.Second, End: 2 * time.Minute}}
+	if keep := KeepRanges(remove, 2*time.Minute); !reflect.DeepEqual(keep, expected) {
+		t.Errorf("unexpected ranges %+v", keep)
+	}
+
+	// A range running to the end leaves nothing after it
+	if keep := KeepRanges([]TimeRange{{Start: time.Minute, End: 2 * time.Minute}}, 2*time.Minute); len(keep) != 1 || keep[0].End != time.Minute {
+		t.Errorf("unexpected ranges %+v", keep)
+	}
+}
+
+func TestCutList(t *testing.T) {
+	keep := []TimeRange{{Start: 0, End: 30 * time.Second}, {Start: 45500 * time.Millisecond}}
+	expected := `ffconcat version 1.0
+file '/videos/it'\''s.mp4'
+outpoint 30.000
+file '/videos/it'\''s.mp4'
+inpoint 45.500
+`
+	if list := CutList("/videos/it's.mp4", keep); list != expected {
+		t.Errorf("unexpected list:\n%s", list)
+	}
+	args := strings.Join(BuildCutArgs("/in.mp4.cut.txt", "/in.cut.mp4"), " ")
+	if args != "-y -v error -f concat -safe 0 -i /in.mp4.cut.txt -map 0 -c copy /in.cut.mp4" {
+		t.Errorf("unexpected args %q", args)
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/platform"
 )
 
 // FFmpeg constants for compression settings
@@ -41,10 +42,14 @@ const (
 	FFprobeLogLevel     = "error"
 	FFprobeShowEntries  = "format=duration"
 	FFprobeOutputFormat = "csv=p=0"
+	FFprobeMediaEntries = "format=duration:stream=codec_type"
 	ProgressPipeTarget  = "pipe:2"
 	ProgressTimePrefix  = "out_time_us="
 	TaskIDPrefix        = "compress-"
 	OutputExtensionMP4  = ".mp4"
+
+	// StopPollInterval is how often running tasks check for a stop request
+	StopPollInterval = 100 * time.Millisecond
 )
 
 // Service handles video compression operations
@@ -52,12 +57,20 @@ type Service struct {
 	tasks      map[string]*model.CompressionTask
 	tasksMutex sync.RWMutex
 	onUpdate   func(*model.CompressionTask) // callback for UI updates
+
+	// collision decides what happens to an existing output file; guarded by tasksMutex
+	collision platform.CollisionPolicy
+
+	// probe reads media durations, ffprobe unless replaced in tests
+	probe func(ctx context.Context, path string) (time.Duration, error)
 }
 
 // NewService creates a new compression service
 func NewService() Compressor {
 	return &Service{
-		tasks: make(map[string]*model.CompressionTask),
+		tasks:     make(map[string]*model.CompressionTask),
+		collision: platform.CollisionVerify,
+		probe:     ProbeDuration,
 	}
 }
 
@@ -133,36 +146,33 @@ func (s *Service) startCompression(task *model.CompressionTask) {
 	s.tasksMutex.Unlock()
 	s.notifyUpdate(task)
 
+	// Create context for cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Monitor for stop requests
+	go s.watchStop(task, cancel)
+
+	// Keep an existing output file or pick another name for it
+	if s.resolveCollision(ctx, task) {
+		s.tasksMutex.Lock()
+		task.Status = model.TaskStatusCompleted
+		task.Progress = 1.0
+		task.Percent = 100
+		task.FinishedAt = time.Now()
+		s.tasksMutex.Unlock()
+		s.notifyUpdate(task)
+		return
+	}
+
 	// Get duration of input file for progress calculation
-	duration, err := s.getVideoDuration(task.InputPath)
+	duration, err := s.getVideoDuration(ctx, task.InputPath)
 	if err != nil {
 		log.Printf("Failed to get video duration for %s: %v", task.InputPath, err)
 		s.setTaskError(task, err)
 		return
 	}
 
-	// Create context for cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Monitor for stop requests
-	go func() {
-		for {
-			s.tasksMutex.RLock()
-			status := task.Status
-			s.tasksMutex.RUnlock()
-
-			if status == model.TaskStatusStopping {
-				cancel()
-				return
-			}
-			if status.IsFinished() {
-				return
-			}
-			time.Sleep(100 * time.Millisecond)
-		}
-	}()
-
 	// Update status to downloading
 	s.tasksMutex.Lock()
 	task.Status = model.TaskStatusDownloading
@@ -214,6 +224,24 @@ func (s *Service) startCompression(task *model.CompressionTask) {
 	s.notifyUpdate(task)
 }
 
+// watchStop cancels the task's ffmpeg run once a stop is requested
+func (s *Service) watchStop(task *model.CompressionTask, cancel context.CancelFunc) {
+	for {
+		s.tasksMutex.RLock()
+		status := task.Status
+		s.tasksMutex.RUnlock()
+
+		if status == model.TaskStatusStopping {
+			cancel()
+			return
+		}
+		if status.IsFinished() {
+			return
+		}
+		time.Sleep(StopPollInterval)
+	}
+}
+
 // GetTask returns a compression task by ID
 func (s *Service) GetTask(taskID string) (*model.CompressionTask, bool) {
 	s.tasksMutex.RLock()
@@ -239,21 +267,13 @@ func (s *Service) BuildFFmpegArgs(inputPath, outputPath string) []string {
 	}
 }
 
-// getVideoDuration gets the duration of a video file using ffprobe
-func (s *Service) getVideoDuration(filePath string) (float64, error) {
-	cmd := exec.Command(FFprobeCommand, "-v", FFprobeLogLevel, "-show_entries", FFprobeShowEntries, "-of", FFprobeOutputFormat, filePath)
-	output, err := cmd.Output()
+// getVideoDuration gets the duration of a video file in seconds
+func (s *Service) getVideoDuration(ctx context.Context, filePath string) (float64, error) {
+	duration, err := s.probe(ctx, filePath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to run ffprobe: %w", err)
+		return 0, err
 	}
-
-	durationStr := strings.TrimSpace(string(output))
-	duration, err := strconv.ParseFloat(durationStr, 64)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse duration: %w", err)
-	}
-
-	return duration, nil
+	return duration.Seconds(), nil
 }
 
 // monitorProgress monitors ffmpeg progress output
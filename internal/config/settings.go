@@ -1,8 +1,19 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+
 	"fyne.io/fyne/v2"
+	"github.com/ytget/yt-downloader/internal/download"
+	"github.com/ytget/yt-downloader/internal/network"
 	"github.com/ytget/yt-downloader/internal/platform"
+	"github.com/ytget/yt-downloader/internal/schedule"
+	"github.com/ytget/yt-downloader/internal/sponsorblock"
 )
 
 // Quality presets for downloads
@@ -18,25 +29,73 @@ const (
 const (
 	KeyDownloadDir        = "download_directory"
 	KeyMaxParallel        = "max_parallel_downloads"
+	KeyDownloadSegments   = "download_segments"
 	KeyQualityPreset      = "quality_preset"
 	KeyFilenameTemplate   = "filename_template"
 	KeyLanguage           = "app_language"
 	KeyAutoRevealComplete = "auto_reveal_on_complete"
+	KeyPlaylistSubfolder  = "playlist_subfolder"
+	KeyPlaylistWriteM3U   = "playlist_write_m3u"
+	KeyAPIEnabled         = "api_enabled"
+	KeyAPIPort            = "api_port"
+	KeyAPIToken           = "api_token"
+	KeyClipboardWatch     = "clipboard_watch"
+	KeyClipboardDismissed = "clipboard_dismissed_urls"
+	KeyProxyMode          = "proxy_mode"
+	KeyProxyHost          = "proxy_host"
+	KeyProxyPort          = "proxy_port"
+	KeyProxyUsername      = "proxy_username"
+	KeyProxyPassword      = "proxy_password"
+	KeyProxyRemember      = "proxy_remember_password"
+	KeySponsorBlockMode   = "sponsorblock_mode"
+	KeySponsorBlockCats   = "sponsorblock_categories"
+	KeySponsorBlockURL    = "sponsorblock_url"
+	KeyWindowEnabled      = "download_window_enabled"
+	KeyWindowStart        = "download_window_start"
+	KeyWindowEnd          = "download_window_end"
+	KeyDiskReserveMB      = "disk_reserve_mb"
+	KeyCollisionPolicy    = "collision_policy"
+	KeyTempDir            = "temp_directory"
+	KeyVerifyMode         = "verify_mode"
 )
 
 // Default values
 const (
 	DefaultMaxParallel        = 2
+	DefaultDownloadSegments   = 1
 	DefaultQualityPreset      = QualityMedium
 	DefaultFilenameTemplate   = "%(title)s.%(ext)s"
 	DefaultLanguage           = "system"
 	DefaultAutoRevealComplete = true
+	DefaultPlaylistSubfolder  = false
+	DefaultPlaylistWriteM3U   = false
+	DefaultAPIEnabled         = false
+	DefaultAPIPort            = 8765
+	DefaultClipboardWatch     = false
+	DefaultProxyMode          = network.ProxySystem
+	DefaultProxyRemember      = false
+	DefaultSponsorBlockMode   = sponsorblock.ModeOff
+	DefaultWindowEnabled      = false
+	DefaultWindowStart        = "01:00"
+	DefaultWindowEnd          = "07:00"
+	DefaultDiskReserveMB      = 500
+	DefaultCollisionPolicy    = platform.CollisionVerify
+	DefaultVerifyMode         = download.VerifyOff
 )
 
 // Validation limits
 const (
 	MinParallelDownloads = 1
 	MaxParallelDownloads = 10
+	MinDownloadSegments  = 1
+	MaxDownloadSegments  = download.MaxSegments
+	MinAPIPort           = 1024
+	MaxAPIPort           = 65535
+	APITokenBytes        = 24
+	MaxDismissedURLs     = 200
+	MaxDiskReserveMB     = 1 << 20 // 1 TB
+	// MaxTotalConnections bounds parallel downloads times segments per download
+	MaxTotalConnections = download.MaxConnections
 )
 
 // Fallback values
@@ -55,11 +114,21 @@ const (
 // Settings manages application configuration
 type Settings struct {
 	app fyne.App
+
+	// mutex guards proxyPassword, which keeps the proxy password for the
+	// session when the user did not opt in to storing it
+	mutex         sync.Mutex
+	proxyPassword string
 }
 
-// NewSettings creates a new settings manager
+// NewSettings creates a new settings manager. A proxy password stored
+// without the user's consent by an earlier version is removed.
 func NewSettings(app fyne.App) *Settings {
-	return &Settings{app: app}
+	s := &Settings{app: app}
+	if !s.GetProxyRememberPassword() {
+		app.Preferences().RemoveValue(KeyProxyPassword)
+	}
+	return s
 }
 
 // GetDownloadDirectory returns the configured download directory
@@ -88,6 +157,17 @@ func (s *Settings) SetDownloadDirectory(dir string) {
 	s.app.Preferences().SetString(KeyDownloadDir, dir)
 }
 
+// GetTempDirectory returns where unfinished downloads are written; empty keeps
+// them next to the finished files
+func (s *Settings) GetTempDirectory() string {
+	return strings.TrimSpace(s.app.Preferences().String(KeyTempDir))
+}
+
+// SetTempDirectory sets where unfinished downloads are written
+func (s *Settings) SetTempDirectory(dir string) {
+	s.app.Preferences().SetString(KeyTempDir, strings.TrimSpace(dir))
+}
+
 // ResetDownloadDirectory resets download directory to system default
 func (s *Settings) ResetDownloadDirectory() {
 	s.app.Preferences().RemoveValue(KeyDownloadDir)
@@ -114,6 +194,73 @@ func (s *Settings) SetMaxParallelDownloads(count int) {
 	s.app.Preferences().SetInt(KeyMaxParallel, count)
 }
 
+// GetDownloadSegments returns the number of connections per file download.
+// One disables segmented downloading; the value is lowered so that all
+// parallel downloads together stay within MaxTotalConnections.
+func (s *Settings) GetDownloadSegments() int {
+	segments := s.app.Preferences().IntWithFallback(KeyDownloadSegments, DefaultDownloadSegments)
+	return ClampDownloadSegments(segments, s.GetMaxParallelDownloads())
+}
+
+// SetDownloadSegments sets the number of connections per file download
+func (s *Settings) SetDownloadSegments(count int) {
+	count = max(MinDownloadSegments, min(count, MaxDownloadSegments))
+	s.app.Preferences().SetInt(KeyDownloadSegments, count)
+}
+
+// GetDiskReserveMB returns the free disk space in megabytes that downloads must
+// leave; zero disables the disk space checks
+func (s *Settings) GetDiskReserveMB() int {
+	reserve := s.app.Preferences().IntWithFallback(KeyDiskReserveMB, DefaultDiskReserveMB)
+	return max(0, min(reserve, MaxDiskReserveMB))
+}
+
+// SetDiskReserveMB sets the free disk space in megabytes that downloads must leave
+func (s *Settings) SetDiskReserveMB(reserve int) {
+	reserve = max(0, min(reserve, MaxDiskReserveMB))
+	s.app.Preferences().SetInt(KeyDiskReserveMB, reserve)
+}
+
+// GetCollisionPolicy returns what happens when an output file already exists
+func (s *Settings) GetCollisionPolicy() platform.CollisionPolicy {
+	policy := platform.CollisionPolicy(s.app.Preferences().StringWithFallback(KeyCollisionPolicy, string(DefaultCollisionPolicy)))
+	if !policy.IsValid() {
+		return DefaultCollisionPolicy
+	}
+	return policy
+}
+
+// SetCollisionPolicy sets what happens when an output file already exists
+func (s *Settings) SetCollisionPolicy(policy platform.CollisionPolicy) {
+	if !policy.IsValid() {
+		policy = DefaultCollisionPolicy
+	}
+	s.app.Preferences().SetString(KeyCollisionPolicy, string(policy))
+}
+
+// GetVerifyMode returns whether finished downloads are checked with ffprobe
+func (s *Settings) GetVerifyMode() download.VerifyMode {
+	mode := download.VerifyMode(s.app.Preferences().StringWithFallback(KeyVerifyMode, string(DefaultVerifyMode)))
+	if !mode.IsValid() {
+		return DefaultVerifyMode
+	}
+	return mode
+}
+
+// SetVerifyMode sets whether finished downloads are checked with ffprobe
+func (s *Settings) SetVerifyMode(mode download.VerifyMode) {
+	if !mode.IsValid() {
+		mode = DefaultVerifyMode
+	}
+	s.app.Preferences().SetString(KeyVerifyMode, string(mode))
+}
+
+// ClampDownloadSegments bounds segments per download to the allowed range and
+// to what MaxTotalConnections leaves for maxParallel downloads
+func ClampDownloadSegments(segments, maxParallel int) int {
+	return max(MinDownloadSegments, download.ClampSegments(segments, maxParallel))
+}
+
 // GetQualityPreset returns the configured quality preset
 func (s *Settings) GetQualityPreset() QualityPreset {
 	preset := s.app.Preferences().String(KeyQualityPreset)
@@ -177,6 +324,233 @@ func (s *Settings) SetAutoRevealOnComplete(autoReveal bool) {
 	s.app.Preferences().SetBool(KeyAutoRevealComplete, autoReveal)
 }
 
+// GetPlaylistSubfolder returns whether playlists download into their own numbered folder by default
+func (s *Settings) GetPlaylistSubfolder() bool {
+	return s.app.Preferences().BoolWithFallback(KeyPlaylistSubfolder, DefaultPlaylistSubfolder)
+}
+
+// SetPlaylistSubfolder sets whether playlists download into their own numbered folder by default
+func (s *Settings) SetPlaylistSubfolder(enabled bool) {
+	s.app.Preferences().SetBool(KeyPlaylistSubfolder, enabled)
+}
+
+// GetPlaylistWriteM3U returns whether an M3U file is generated for playlists by default
+func (s *Settings) GetPlaylistWriteM3U() bool {
+	return s.app.Preferences().BoolWithFallback(KeyPlaylistWriteM3U, DefaultPlaylistWriteM3U)
+}
+
+// SetPlaylistWriteM3U sets whether an M3U file is generated for playlists by default
+func (s *Settings) SetPlaylistWriteM3U(enabled bool) {
+	s.app.Preferences().SetBool(KeyPlaylistWriteM3U, enabled)
+}
+
+// GetAPIEnabled returns whether the local control API is enabled
+func (s *Settings) GetAPIEnabled() bool {
+	return s.app.Preferences().BoolWithFallback(KeyAPIEnabled, DefaultAPIEnabled)
+}
+
+// SetAPIEnabled sets whether the local control API is enabled
+func (s *Settings) SetAPIEnabled(enabled bool) {
+	s.app.Preferences().SetBool(KeyAPIEnabled, enabled)
+}
+
+// GetAPIPort returns the localhost port of the control API
+func (s *Settings) GetAPIPort() int {
+	port := s.app.Preferences().IntWithFallback(KeyAPIPort, DefaultAPIPort)
+	if port < MinAPIPort || port > MaxAPIPort {
+		return DefaultAPIPort
+	}
+	return port
+}
+
+// SetAPIPort sets the localhost port of the control API
+func (s *Settings) SetAPIPort(port int) {
+	if port < MinAPIPort || port > MaxAPIPort {
+		port = DefaultAPIPort
+	}
+	s.app.Preferences().SetInt(KeyAPIPort, port)
+}
+
+// GetAPIToken returns the control API token, generating one on first use
+func (s *Settings) GetAPIToken() string {
+	token := s.app.Preferences().String(KeyAPIToken)
+	if token == "" {
+		token = s.RegenerateAPIToken()
+	}
+	return token
+}
+
+// RegenerateAPIToken replaces the control API token with a new random one
+func (s *Settings) RegenerateAPIToken() string {
+	buf := make([]byte, APITokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		// Keep the current token rather than storing a predictable one
+		log.Printf("Failed to generate API token: %v", err)
+		return s.app.Preferences().String(KeyAPIToken)
+	}
+	token := hex.EncodeToString(buf)
+	s.app.Preferences().SetString(KeyAPIToken, token)
+	return token
+}
+
+// GetClipboardWatch returns whether copied links are offered for download
+func (s *Settings) GetClipboardWatch() bool {
+	return s.app.Preferences().BoolWithFallback(KeyClipboardWatch, DefaultClipboardWatch)
+}
+
+// SetClipboardWatch sets whether copied links are offered for download
+func (s *Settings) SetClipboardWatch(enabled bool) {
+	s.app.Preferences().SetBool(KeyClipboardWatch, enabled)
+}
+
+// IsClipboardURLDismissed reports whether the user declined to download the copied URL
+func (s *Settings) IsClipboardURLDismissed(url string) bool {
+	for _, dismissed := range s.app.Preferences().StringList(KeyClipboardDismissed) {
+		if dismissed == url {
+			return true
+		}
+	}
+	return false
+}
+
+// AddDismissedClipboardURL remembers a declined URL, keeping the newest MaxDismissedURLs
+func (s *Settings) AddDismissedClipboardURL(url string) {
+	if s.IsClipboardURLDismissed(url) {
+		return
+	}
+	dismissed := append(s.app.Preferences().StringList(KeyClipboardDismissed), url)
+	if len(dismissed) > MaxDismissedURLs {
+		dismissed = dismissed[len(dismissed)-MaxDismissedURLs:]
+	}
+	s.app.Preferences().SetStringList(KeyClipboardDismissed, dismissed)
+}
+
+// GetProxy returns the proxy configuration; an unknown mode falls back to the default
+func (s *Settings) GetProxy() network.ProxyConfig {
+	prefs := s.app.Preferences()
+	cfg := network.ProxyConfig{
+		Mode:     network.ProxyMode(prefs.StringWithFallback(KeyProxyMode, string(DefaultProxyMode))),
+		Host:     prefs.String(KeyProxyHost),
+		Port:     prefs.Int(KeyProxyPort),
+		Username: prefs.String(KeyProxyUsername),
+	}
+	if s.GetProxyRememberPassword() {
+		cfg.Password = prefs.String(KeyProxyPassword)
+	} else {
+		s.mutex.Lock()
+		cfg.Password = s.proxyPassword
+		s.mutex.Unlock()
+	}
+	if cfg.Mode != network.ProxyNone && cfg.Mode != network.ProxySystem && !cfg.Mode.NeedsServer() {
+		cfg.Mode = DefaultProxyMode
+	}
+	return cfg
+}
+
+// SetProxy stores the proxy configuration. The password is written to the
+// plaintext app preferences only when the user chose to remember it;
+// otherwise it lasts until the application exits.
+func (s *Settings) SetProxy(cfg network.ProxyConfig) {
+	prefs := s.app.Preferences()
+	prefs.SetString(KeyProxyMode, string(cfg.Mode))
+	prefs.SetString(KeyProxyHost, cfg.Host)
+	prefs.SetInt(KeyProxyPort, cfg.Port)
+	prefs.SetString(KeyProxyUsername, cfg.Username)
+	s.mutex.Lock()
+	s.proxyPassword = cfg.Password
+	s.mutex.Unlock()
+	if s.GetProxyRememberPassword() {
+		prefs.SetString(KeyProxyPassword, cfg.Password)
+	} else {
+		prefs.RemoveValue(KeyProxyPassword)
+	}
+}
+
+// GetProxyRememberPassword returns whether the proxy password is stored between sessions
+func (s *Settings) GetProxyRememberPassword() bool {
+	return s.app.Preferences().BoolWithFallback(KeyProxyRemember, DefaultProxyRemember)
+}
+
+// SetProxyRememberPassword sets whether the proxy password is stored between
+// sessions; turning it off removes the stored password but keeps it for this session
+func (s *Settings) SetProxyRememberPassword(remember bool) {
+	prefs := s.app.Preferences()
+	if remember == s.GetProxyRememberPassword() {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if remember {
+		prefs.SetString(KeyProxyPassword, s.proxyPassword)
+	} else {
+		s.proxyPassword = prefs.String(KeyProxyPassword)
+		prefs.RemoveValue(KeyProxyPassword)
+	}
+	prefs.SetBool(KeyProxyRemember, remember)
+}
+
+// GetSponsorBlock returns the SponsorBlock configuration; an unknown mode falls
+// back to the default and unknown categories are dropped
+func (s *Settings) GetSponsorBlock() sponsorblock.Config {
+	prefs := s.app.Preferences()
+	cfg := sponsorblock.Config{
+		Mode:    sponsorblock.Mode(prefs.StringWithFallback(KeySponsorBlockMode, string(DefaultSponsorBlockMode))),
+		BaseURL: prefs.String(KeySponsorBlockURL),
+	}
+	if !slices.Contains(sponsorblock.Modes, cfg.Mode) {
+		cfg.Mode = DefaultSponsorBlockMode
+	}
+	defaults := make([]string, 0, len(sponsorblock.DefaultCategories))
+	for _, category := range sponsorblock.DefaultCategories {
+		defaults = append(defaults, string(category))
+	}
+	for _, name := range prefs.StringListWithFallback(KeySponsorBlockCats, defaults) {
+		if category := sponsorblock.Category(name); category.IsValid() {
+			cfg.Categories = append(cfg.Categories, category)
+		}
+	}
+	return cfg
+}
+
+// SetSponsorBlock stores the SponsorBlock configuration
+func (s *Settings) SetSponsorBlock(cfg sponsorblock.Config) {
+	prefs := s.app.Preferences()
+	categories := make([]string, 0, len(cfg.Categories))
+	for _, category := range cfg.Categories {
+		categories = append(categories, string(category))
+	}
+	prefs.SetString(KeySponsorBlockMode, string(cfg.Mode))
+	prefs.SetStringList(KeySponsorBlockCats, categories)
+	prefs.SetString(KeySponsorBlockURL, strings.TrimSpace(cfg.BaseURL))
+}
+
+// GetDownloadWindow returns the daily window in which downloads may start; an
+// invalid bound falls back to its default
+func (s *Settings) GetDownloadWindow() schedule.Window {
+	prefs := s.app.Preferences()
+	start, err := schedule.ParseClock(prefs.StringWithFallback(KeyWindowStart, DefaultWindowStart))
+	if err != nil {
+		start, _ = schedule.ParseClock(DefaultWindowStart)
+	}
+	end, err := schedule.ParseClock(prefs.StringWithFallback(KeyWindowEnd, DefaultWindowEnd))
+	if err != nil {
+		end, _ = schedule.ParseClock(DefaultWindowEnd)
+	}
+	return schedule.Window{
+		Enabled: prefs.BoolWithFallback(KeyWindowEnabled, DefaultWindowEnabled),
+		Start:   start,
+		End:     end,
+	}
+}
+
+// SetDownloadWindow stores the daily download window
+func (s *Settings) SetDownloadWindow(window schedule.Window) {
+	prefs := s.app.Preferences()
+	prefs.SetBool(KeyWindowEnabled, window.Enabled)
+	prefs.SetString(KeyWindowStart, schedule.FormatClock(window.Start))
+	prefs.SetString(KeyWindowEnd, schedule.FormatClock(window.End))
+}
+
 // GetLanguageOptions returns available language options
 func (s *Settings) GetLanguageOptions() map[string]string {
 	return map[string]string{
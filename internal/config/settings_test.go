@@ -1,9 +1,18 @@
 package config
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"fyne.io/fyne/v2/test"
+
+	"github.com/ytget/yt-downloader/internal/download"
+	"github.com/ytget/yt-downloader/internal/network"
+	"github.com/ytget/yt-downloader/internal/platform"
+	"github.com/ytget/yt-downloader/internal/schedule"
+	"github.com/ytget/yt-downloader/internal/sponsorblock"
 )
 
 func TestNewSettings(t *testing.T) {
@@ -65,6 +74,124 @@ func TestMaxParallelDownloads(t *testing.T) {
 	}
 }
 
+func TestDiskReserve(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	if got := settings.GetDiskReserveMB(); got != DefaultDiskReserveMB {
+		t.Errorf("Expected default reserve %d, got %d", DefaultDiskReserveMB, got)
+	}
+
+	settings.SetDiskReserveMB(0) // Disables the checks
+	if got := settings.GetDiskReserveMB(); got != 0 {
+		t.Errorf("Expected reserve 0, got %d", got)
+	}
+
+	settings.SetDiskReserveMB(-5)
+	if got := settings.GetDiskReserveMB(); got != 0 {
+		t.Errorf("Expected negative reserve clamped to 0, got %d", got)
+	}
+
+	settings.SetDiskReserveMB(MaxDiskReserveMB + 1)
+	if got := settings.GetDiskReserveMB(); got != MaxDiskReserveMB {
+		t.Errorf("Expected reserve clamped to %d, got %d", MaxDiskReserveMB, got)
+	}
+}
+
+func TestCollisionPolicy(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	if got := settings.GetCollisionPolicy(); got != DefaultCollisionPolicy {
+		t.Errorf("Expected default policy %s, got %s", DefaultCollisionPolicy, got)
+	}
+
+	settings.SetCollisionPolicy(platform.CollisionRename)
+	if got := settings.GetCollisionPolicy(); got != platform.CollisionRename {
+		t.Errorf("Expected rename policy, got %s", got)
+	}
+
+	app.Preferences().SetString(KeyCollisionPolicy, "bogus")
+	if got := settings.GetCollisionPolicy(); got != DefaultCollisionPolicy {
+		t.Errorf("Expected unknown policy to fall back to %s, got %s", DefaultCollisionPolicy, got)
+	}
+}
+
+func TestVerifyMode(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	if got := settings.GetVerifyMode(); got != DefaultVerifyMode {
+		t.Errorf("Expected default mode %s, got %s", DefaultVerifyMode, got)
+	}
+
+	settings.SetVerifyMode(download.VerifyRetry)
+	if got := settings.GetVerifyMode(); got != download.VerifyRetry {
+		t.Errorf("Expected retry mode, got %s", got)
+	}
+
+	app.Preferences().SetString(KeyVerifyMode, "bogus")
+	if got := settings.GetVerifyMode(); got != DefaultVerifyMode {
+		t.Errorf("Expected unknown mode to fall back to %s, got %s", DefaultVerifyMode, got)
+	}
+}
+
+func TestTempDirectory(t *testing.T) {
+	settings := NewSettings(test.NewApp())
+
+	if got := settings.GetTempDirectory(); got != "" {
+		t.Errorf("Expected no temp directory by default, got %q", got)
+	}
+	settings.SetTempDirectory("  /mnt/scratch ")
+	if got := settings.GetTempDirectory(); got != "/mnt/scratch" {
+		t.Errorf("Expected /mnt/scratch, got %q", got)
+	}
+}
+
+func TestDownloadSegments(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	if got := settings.GetDownloadSegments(); got != DefaultDownloadSegments {
+		t.Errorf("Expected default segments %d, got %d", DefaultDownloadSegments, got)
+	}
+
+	settings.SetMaxParallelDownloads(2)
+	settings.SetDownloadSegments(4)
+	if got := settings.GetDownloadSegments(); got != 4 {
+		t.Errorf("Expected 4 segments, got %d", got)
+	}
+
+	settings.SetDownloadSegments(20) // Should be clamped to 8
+	if got := settings.GetDownloadSegments(); got != MaxDownloadSegments {
+		t.Errorf("Expected segments clamped to %d, got %d", MaxDownloadSegments, got)
+	}
+
+	// 5 parallel downloads leave 3 connections each within the total limit
+	settings.SetMaxParallelDownloads(5)
+	if got := settings.GetDownloadSegments(); got != 3 {
+		t.Errorf("Expected segments bounded to 3 by the parallel limit, got %d", got)
+	}
+}
+
+func TestClampDownloadSegments(t *testing.T) {
+	tests := []struct {
+		segments, parallel, want int
+	}{
+		{0, 2, 1},
+		{4, 2, 4},
+		{8, 1, 8},
+		{8, 3, 5},
+		{8, 10, 1},
+		{6, 0, 6},
+	}
+	for _, tt := range tests {
+		if got := ClampDownloadSegments(tt.segments, tt.parallel); got != tt.want {
+			t.Errorf("ClampDownloadSegments(%d, %d) = %d, want %d", tt.segments, tt.parallel, got, tt.want)
+		}
+	}
+}
+
 func TestQualityPreset(t *testing.T) {
 	app := test.NewApp()
 	settings := NewSettings(app)
@@ -165,3 +292,202 @@ func TestGetLanguageOptions(t *testing.T) {
 		t.Errorf("Expected %d language options, got %d", len(expectedLangs), len(options))
 	}
 }
+
+func TestPlaylistOutputOptions(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	if settings.GetPlaylistSubfolder() != DefaultPlaylistSubfolder {
+		t.Errorf("Expected default playlist subfolder %v", DefaultPlaylistSubfolder)
+	}
+	if settings.GetPlaylistWriteM3U() != DefaultPlaylistWriteM3U {
+		t.Errorf("Expected default playlist M3U %v", DefaultPlaylistWriteM3U)
+	}
+
+	settings.SetPlaylistSubfolder(true)
+	settings.SetPlaylistWriteM3U(true)
+
+	if !settings.GetPlaylistSubfolder() {
+		t.Error("Expected playlist subfolder to be enabled")
+	}
+	if !settings.GetPlaylistWriteM3U() {
+		t.Error("Expected playlist M3U to be enabled")
+	}
+}
+
+func TestAPISettings(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	if settings.GetAPIEnabled() != DefaultAPIEnabled {
+		t.Errorf("Expected default API enabled %v", DefaultAPIEnabled)
+	}
+	if settings.GetAPIPort() != DefaultAPIPort {
+		t.Errorf("Expected default API port %d, got %d", DefaultAPIPort, settings.GetAPIPort())
+	}
+
+	settings.SetAPIPort(9000)
+	if settings.GetAPIPort() != 9000 {
+		t.Errorf("Expected API port 9000, got %d", settings.GetAPIPort())
+	}
+	settings.SetAPIPort(80)
+	if settings.GetAPIPort() != DefaultAPIPort {
+		t.Errorf("Expected privileged port to fall back to %d, got %d", DefaultAPIPort, settings.GetAPIPort())
+	}
+
+	token := settings.GetAPIToken()
+	if len(token) != APITokenBytes*2 {
+		t.Errorf("Expected token of %d hex chars, got %q", APITokenBytes*2, token)
+	}
+	if settings.GetAPIToken() != token {
+		t.Error("Expected token to be stable between calls")
+	}
+	if settings.RegenerateAPIToken() == token {
+		t.Error("Expected regenerated token to differ")
+	}
+}
+
+func TestClipboardSettings(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	if settings.GetClipboardWatch() != DefaultClipboardWatch {
+		t.Errorf("Expected default clipboard watch %v", DefaultClipboardWatch)
+	}
+	settings.SetClipboardWatch(true)
+	if !settings.GetClipboardWatch() {
+		t.Error("Expected clipboard watch to be enabled")
+	}
+
+	url := "https://youtu.be/dQw4w9WgXcQ"
+	if settings.IsClipboardURLDismissed(url) {
+		t.Error("Expected URL not to be dismissed initially")
+	}
+	settings.AddDismissedClipboardURL(url)
+	settings.AddDismissedClipboardURL(url)
+	if !settings.IsClipboardURLDismissed(url) {
+		t.Error("Expected URL to be dismissed")
+	}
+	if got := len(app.Preferences().StringList(KeyClipboardDismissed)); got != 1 {
+		t.Errorf("Expected dismissed URL to be stored once, got %d entries", got)
+	}
+
+	for i := 0; i < MaxDismissedURLs; i++ {
+		settings.AddDismissedClipboardURL(fmt.Sprintf("https://youtu.be/video%05d", i))
+	}
+	if settings.IsClipboardURLDismissed(url) {
+		t.Error("Expected oldest dismissed URL to be forgotten")
+	}
+	if got := len(app.Preferences().StringList(KeyClipboardDismissed)); got != MaxDismissedURLs {
+		t.Errorf("Expected %d dismissed URLs, got %d", MaxDismissedURLs, got)
+	}
+}
+
+func TestProxySettings(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	if settings.GetProxy().Mode != DefaultProxyMode {
+		t.Errorf("Expected default proxy mode %q, got %q", DefaultProxyMode, settings.GetProxy().Mode)
+	}
+
+	cfg := network.ProxyConfig{Mode: network.ProxySOCKS5, Host: "proxy.local", Port: 1080, Username: "alice", Password: "secret"}
+	settings.SetProxy(cfg)
+	if got := settings.GetProxy(); got != cfg {
+		t.Errorf("Expected proxy %+v, got %+v", cfg, got)
+	}
+
+	app.Preferences().SetString(KeyProxyMode, "bogus")
+	if settings.GetProxy().Mode != DefaultProxyMode {
+		t.Errorf("Expected unknown mode to fall back to %q", DefaultProxyMode)
+	}
+}
+
+func TestProxyPasswordRemember(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+	cfg := network.ProxyConfig{Mode: network.ProxyHTTP, Host: "proxy.local", Port: 3128, Username: "alice", Password: "secret"}
+
+	// Not remembered by default: kept for the session only
+	settings.SetProxy(cfg)
+	if stored := app.Preferences().String(KeyProxyPassword); stored != "" {
+		t.Errorf("Expected the password not to be stored, got %q", stored)
+	}
+	if got := settings.GetProxy().Password; got != "secret" {
+		t.Errorf("Expected session password, got %q", got)
+	}
+	if got := NewSettings(app).GetProxy().Password; got != "" {
+		t.Errorf("Expected a new session to forget the password, got %q", got)
+	}
+
+	settings.SetProxyRememberPassword(true)
+	if stored := app.Preferences().String(KeyProxyPassword); stored != "secret" {
+		t.Errorf("Expected the remembered password to be stored, got %q", stored)
+	}
+	if got := NewSettings(app).GetProxy().Password; got != "secret" {
+		t.Errorf("Expected a new session to read the stored password, got %q", got)
+	}
+
+	settings.SetProxyRememberPassword(false)
+	if stored := app.Preferences().String(KeyProxyPassword); stored != "" {
+		t.Errorf("Expected turning remember off to remove the password, got %q", stored)
+	}
+	if got := settings.GetProxy().Password; got != "secret" {
+		t.Errorf("Expected the password to last for the session, got %q", got)
+	}
+
+	// A password stored without consent by an earlier version is dropped
+	app.Preferences().SetString(KeyProxyPassword, "legacy")
+	NewSettings(app)
+	if stored := app.Preferences().String(KeyProxyPassword); stored != "" {
+		t.Errorf("Expected the legacy password to be removed, got %q", stored)
+	}
+}
+
+func TestSponsorBlockSettings(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	cfg := settings.GetSponsorBlock()
+	if cfg.Mode != DefaultSponsorBlockMode || !reflect.DeepEqual(cfg.Categories, sponsorblock.DefaultCategories) || cfg.BaseURL != "" {
+		t.Errorf("unexpected default config %+v", cfg)
+	}
+
+	cfg = sponsorblock.Config{
+		Mode:       sponsorblock.ModeRemove,
+		Categories: []sponsorblock.Category{sponsorblock.CategorySelfPromo, sponsorblock.CategoryFiller},
+		BaseURL:    "http://127.0.0.1:8080",
+	}
+	settings.SetSponsorBlock(cfg)
+	if got := settings.GetSponsorBlock(); !reflect.DeepEqual(got, cfg) {
+		t.Errorf("Expected %+v, got %+v", cfg, got)
+	}
+
+	app.Preferences().SetString(KeySponsorBlockMode, "bogus")
+	app.Preferences().SetStringList(KeySponsorBlockCats, []string{"sponsor", "unknown"})
+	cfg = settings.GetSponsorBlock()
+	if cfg.Mode != DefaultSponsorBlockMode || !reflect.DeepEqual(cfg.Categories, []sponsorblock.Category{sponsorblock.CategorySponsor}) {
+		t.Errorf("Expected unknown values to be dropped, got %+v", cfg)
+	}
+}
+
+func TestDownloadWindowSettings(t *testing.T) {
+	app := test.NewApp()
+	settings := NewSettings(app)
+
+	window := settings.GetDownloadWindow()
+	if window.Enabled || window.String() != DefaultWindowStart+"–"+DefaultWindowEnd {
+		t.Errorf("unexpected default window %+v", window)
+	}
+
+	window = schedule.Window{Enabled: true, Start: 22 * time.Hour, End: 6*time.Hour + 30*time.Minute}
+	settings.SetDownloadWindow(window)
+	if got := settings.GetDownloadWindow(); got != window {
+		t.Errorf("Expected %+v, got %+v", window, got)
+	}
+
+	app.Preferences().SetString(KeyWindowEnd, "25:00")
+	if got := settings.GetDownloadWindow(); schedule.FormatClock(got.End) != DefaultWindowEnd {
+		t.Errorf("Expected invalid end to fall back to %s, got %s", DefaultWindowEnd, schedule.FormatClock(got.End))
+	}
+}
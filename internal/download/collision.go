@@ -0,0 +1,160 @@
+package download
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/compress"
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/platform"
+)
+
+// SizeTolerance is the fraction by which an existing file may differ from the
+// expected size and still count as the same video
+const SizeTolerance = 0.01
+
+// SetCollisionPolicy sets what happens when the output file of a download
+// already exists
+func (s *Service) SetCollisionPolicy(policy platform.CollisionPolicy) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+	s.collision = policy
+}
+
+// resolveCollision applies the collision policy to outputPath. It returns the
+// path to download to, and true when the existing file is kept as the result.
+// id, size and duration describe the task's video and are empty when unknown.
+func (s *Service) resolveCollision(ctx context.Context, task *model.DownloadTask, outputPath, id string, size int64, duration time.Duration) (string, bool) {
+	if !platform.FileExists(outputPath) {
+		return outputPath, false
+	}
+	s.tasksMutex.RLock()
+	policy := s.collision
+	s.tasksMutex.RUnlock()
+
+	switch policy {
+	case platform.CollisionSkip:
+		return outputPath, true
+	case platform.CollisionOverwrite:
+		err := os.Remove(outputPath)
+		if err == nil {
+			return outputPath, false
+		}
+		log.Printf("Failed to replace %s: %v", outputPath, err)
+	case platform.CollisionVerify:
+		if s.sameVideo(ctx, task, outputPath, id, size, duration) {
+			return outputPath, true
+		}
+	}
+
+	renamed := platform.NumberedPath(outputPath)
+	log.Printf("Output file %s exists, downloading to %s instead", outputPath, renamed)
+	return renamed, false
+}
+
+// claimOutputPath makes outputPath the task's output unless another unfinished
+// task writes to the same file, in which case the task gets a numbered name
+// instead. Claiming under tasksMutex keeps parallel downloads of videos with
+// the same title from sharing a partial file.
+func (s *Service) claimOutputPath(task *model.DownloadTask, outputPath string) string {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	if s.pathClaimedLocked(task.ID, outputPath) {
+		renamed := platform.NumberedPathAvoiding(outputPath, func(path string) bool {
+			return s.pathClaimedLocked(task.ID, path)
+		})
+		log.Printf("Output file %s is used by another download, downloading to %s instead", outputPath, renamed)
+		outputPath = renamed
+	}
+	task.OutputPath = outputPath
+	s.notifyUpdate(task)
+	return outputPath
+}
+
+// pathClaimedLocked reports whether an unfinished task other than id writes to
+// path or to its work file. Callers must hold tasksMutex.
+func (s *Service) pathClaimedLocked(id, path string) bool {
+	workPath := s.workPathLocked(path)
+	for _, other := range s.tasks {
+		if other.ID == id || other.OutputPath == "" || other.Status.IsFinished() {
+			continue
+		}
+		if other.OutputPath == path || s.workPathLocked(other.OutputPath) == workPath {
+			return true
+		}
+	}
+	return false
+}
+
+// sameVideo reports whether the file at path is the task's video. The file must
+// be identified by its size, by the video ID in its name or by an earlier task
+// that downloaded the same video to it; the duration is compared as well when
+// ffprobe is available. A file that cannot be identified does not match, so it
+// is never mistaken for a different video of similar length.
+func (s *Service) sameVideo(ctx context.Context, task *model.DownloadTask, path, id string, size int64, duration time.Duration) bool {
+	identified := false
+	if size > 0 {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return false
+		}
+		if diff := float64(fi.Size() - size); diff > SizeTolerance*float64(size) || -diff > SizeTolerance*float64(size) {
+			return false
+		}
+		identified = true
+	}
+	if id != "" && strings.Contains(filepath.Base(path), id) {
+		identified = true
+	}
+	if !identified {
+		identified = s.downloadedTo(task, path)
+	}
+	if !identified {
+		return false
+	}
+	if duration > 0 && s.probeMedia != nil {
+		if probed, err := s.probeMedia(ctx, path); err == nil && probed.Duration > 0 {
+			return compress.SameDuration(probed.Duration, duration)
+		}
+	}
+	return true
+}
+
+// downloadedTo reports whether another task completed a download of the same
+// video to path
+func (s *Service) downloadedTo(task *model.DownloadTask, path string) bool {
+	s.tasksMutex.RLock()
+	defer s.tasksMutex.RUnlock()
+	videoID := s.extractVideoID(task.URL)
+	for _, other := range s.tasks {
+		if other.ID != task.ID && other.Status == model.TaskStatusCompleted &&
+			other.OutputPath == path && s.extractVideoID(other.URL) == videoID {
+			return true
+		}
+	}
+	return false
+}
+
+// formatSize returns the size of the selected format, or zero when unknown.
+// Extractors that know the selected format report it first, together with its
+// file type.
+func formatSize(formats []engine.Format) int64 {
+	if len(formats) == 0 || formats[0].Ext == "" || formats[0].Size <= 0 {
+		return 0
+	}
+	return formats[0].Size
+}
+
+// probeMedia returns the ffprobe media reader, or nil when ffprobe is missing
+func probeMedia() func(ctx context.Context, path string) (compress.MediaInfo, error) {
+	if !compress.FFprobeAvailable() {
+		return nil
+	}
+	return compress.ProbeMedia
+}
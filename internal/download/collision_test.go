@@ -0,0 +1,141 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/compress"
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/platform"
+)
+
+func TestCollisionVerifyRenamesDifferentVideo(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	service.probeMedia = nil
+	existing := filepath.Join(service.downloadDir, "Intro.mp4")
+	if err := os.WriteFile(existing, []byte("another video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first := "https://www.youtube.com/watch?v=introintro1"
+	fake.AddVideo(first, engine.FakeVideo{
+		Info: engine.VideoInfo{Title: "Intro", Formats: []engine.Format{{Ext: "mp4", Size: 1000}}},
+		Size: 1000,
+	})
+	task, _ := service.AddTask(first)
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if filepath.Base(done.OutputPath) != "Intro_1.mp4" {
+		t.Errorf("expected the download to be renamed, got %s", done.OutputPath)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "another video" {
+		t.Error("the existing file was changed")
+	}
+
+	// The same video again matches the renamed file by size
+	second := "https://www.youtube.com/watch?v=introintro2"
+	fake.AddVideo(second, engine.FakeVideo{
+		Info: engine.VideoInfo{Title: "Intro", Formats: []engine.Format{{Ext: "mp4", Size: 13}}},
+	})
+	task, _ = service.AddTask(second)
+	done = waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if done.OutputPath != existing || fake.Downloads(second) != 0 {
+		t.Errorf("expected the matching file to be kept, got %s after %d downloads", done.OutputPath, fake.Downloads(second))
+	}
+}
+
+func TestCollisionPolicies(t *testing.T) {
+	tests := []struct {
+		policy     platform.CollisionPolicy
+		output     string
+		downloaded bool
+	}{
+		{policy: platform.CollisionSkip, output: "Intro.mp4"},
+		{policy: platform.CollisionOverwrite, output: "Intro.mp4", downloaded: true},
+		{policy: platform.CollisionRename, output: "Intro_1.mp4", downloaded: true},
+		// Without a known size or ffprobe nothing can be verified
+		{policy: platform.CollisionVerify, output: "Intro_1.mp4", downloaded: true},
+	}
+	for _, test := range tests {
+		service, fake := newFakeService(t, 1)
+		service.probeMedia = nil
+		service.SetCollisionPolicy(test.policy)
+		existing := filepath.Join(service.downloadDir, "Intro.mp4")
+		if err := os.WriteFile(existing, []byte("existing"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		url := "https://www.youtube.com/watch?v=introintro1"
+		fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Intro"}})
+		task, _ := service.AddTask(url)
+		done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+		if filepath.Base(done.OutputPath) != test.output {
+			t.Errorf("%s: expected %s, got %s", test.policy, test.output, done.OutputPath)
+		}
+		if downloaded := fake.Downloads(url) > 0; downloaded != test.downloaded {
+			t.Errorf("%s: expected downloaded=%v", test.policy, test.downloaded)
+		}
+	}
+}
+
+func TestCollisionRenamesParallelDownloadsOfSameTitle(t *testing.T) {
+	service, fake := newFakeService(t, 2)
+	hold := make(chan struct{})
+
+	first := "https://www.youtube.com/watch?v=introintro1"
+	second := "https://www.youtube.com/watch?v=introintro2"
+	for _, url := range []string{first, second} {
+		fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Intro"}, Hold: hold})
+	}
+	a, _ := service.AddTask(first)
+	waitForStart(t, fake, first)
+	b, _ := service.AddTask(second)
+	waitForStart(t, fake, second)
+	close(hold)
+
+	doneA := waitForStatus(t, service, a.ID, model.TaskStatusCompleted)
+	doneB := waitForStatus(t, service, b.ID, model.TaskStatusCompleted)
+	if doneA.OutputPath == doneB.OutputPath {
+		t.Fatalf("both downloads wrote to %s", doneA.OutputPath)
+	}
+	if filepath.Base(doneB.OutputPath) != "Intro_1.mp4" {
+		t.Errorf("expected the second download to be renamed, got %s", doneB.OutputPath)
+	}
+}
+
+func TestCollisionVerifyNeedsSizeOrID(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	service.probeMedia = func(context.Context, string) (compress.MediaInfo, error) {
+		return compress.MediaInfo{Duration: time.Minute, HasVideo: true, HasAudio: true}, nil
+	}
+
+	// A matching duration alone does not identify a file
+	existing := filepath.Join(service.downloadDir, "Outro.mp4")
+	if err := os.WriteFile(existing, []byte("another video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	url := "https://www.youtube.com/watch?v=outrooutro1"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Outro", Duration: 60}})
+	task, _ := service.AddTask(url)
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if filepath.Base(done.OutputPath) != "Outro_1.mp4" {
+		t.Errorf("expected an unidentified file to be kept aside, got %s", done.OutputPath)
+	}
+
+	// An earlier download of the same video does
+	first := "https://www.youtube.com/watch?v=introintro1"
+	fake.AddVideo(first, engine.FakeVideo{Info: engine.VideoInfo{Title: "Intro", Duration: 60}})
+	task, _ = service.AddTask(first)
+	done = waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+
+	again := "https://youtu.be/introintro1"
+	fake.AddVideo(again, engine.FakeVideo{Info: engine.VideoInfo{Title: "Intro", Duration: 60}})
+	task, _ = service.AddTask(again)
+	kept := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if kept.OutputPath != done.OutputPath || fake.Downloads(again) != 0 {
+		t.Errorf("expected %s to be kept, got %s after %d downloads", done.OutputPath, kept.OutputPath, fake.Downloads(again))
+	}
+}
@@ -0,0 +1,150 @@
+package download
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/platform"
+)
+
+// DiskCheckInterval is how often free disk space is checked while downloads run
+const DiskCheckInterval = 5 * time.Second
+
+// ErrInsufficientSpace is reported when a download would leave less free disk
+// space than the configured reserve
+var ErrInsufficientSpace = errors.New("not enough disk space")
+
+// SetDiskReserve sets the free disk space downloads must leave. A download does
+// not start when its expected size plus the reserve does not fit, and running
+// downloads are paused when free space drops below the reserve. Zero disables
+// both checks.
+func (s *Service) SetDiskReserve(bytes int64) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	s.diskReserve = max(bytes, 0)
+	switch {
+	case s.diskReserve > 0 && s.diskMonitorStop == nil:
+		s.diskMonitorStop = make(chan struct{})
+		go s.monitorDiskSpace(s.diskMonitorStop)
+	case s.diskReserve == 0 && s.diskMonitorStop != nil:
+		close(s.diskMonitorStop)
+		s.diskMonitorStop = nil
+	}
+}
+
+// checkDiskSpace returns ErrInsufficientSpace when dir cannot hold needed bytes
+// plus the reserve. Systems that cannot report free space pass the check.
+func (s *Service) checkDiskSpace(dir string, needed int64) error {
+	s.tasksMutex.RLock()
+	reserve := s.diskReserve
+	s.tasksMutex.RUnlock()
+	if reserve == 0 {
+		return nil
+	}
+
+	free, err := s.freeSpace(dir)
+	if err != nil {
+		log.Printf("Free space check for %s skipped: %v", dir, err)
+		return nil
+	}
+	if int64(free) < needed+reserve {
+		return fmt.Errorf("%w: %s free in %s, %s needed plus %s reserve", ErrInsufficientSpace,
+			platform.FormatBytes(int64(free)), dir, platform.FormatBytes(needed), platform.FormatBytes(reserve))
+	}
+	return nil
+}
+
+// expectedSize returns the bytes still to download to workPath, or zero when
+// the size is unknown; a partial file from an earlier attempt is subtracted.
+func expectedSize(formats []engine.Format, workPath string) int64 {
+	size := formatSize(formats)
+	if size == 0 {
+		return 0
+	}
+	for _, path := range engine.PartialPaths(workPath) {
+		if fi, err := os.Stat(path); err == nil && !fi.IsDir() {
+			size -= fi.Size()
+			break
+		}
+	}
+	return max(size, 0)
+}
+
+// monitorDiskSpace pauses running downloads and stops recordings whenever free
+// space drops below the reserve, until stop is closed
+func (s *Service) monitorDiskSpace(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.diskCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.pauseOnLowSpace()
+		}
+	}
+}
+
+// pauseOnLowSpace pauses the running downloads on file systems with less free
+// space than the reserve and publishes EventDiskLow for each of them. Live
+// recordings cannot be resumed, so they are stopped, which keeps the media
+// recorded so far.
+func (s *Service) pauseOnLowSpace() {
+	s.tasksMutex.RLock()
+	reserve := s.diskReserve
+	dirs := make(map[string][]string)
+	recordings := make(map[string]bool)
+	for id, task := range s.tasks {
+		if task.OutputPath == "" {
+			continue
+		}
+		switch task.Status {
+		case model.TaskStatusDownloading:
+			dir := filepath.Dir(s.workPathLocked(task.OutputPath))
+			dirs[dir] = append(dirs[dir], id)
+		case model.TaskStatusRecording:
+			// Recordings are written straight to their output directory
+			dir := filepath.Dir(task.OutputPath)
+			dirs[dir] = append(dirs[dir], id)
+			recordings[id] = true
+		}
+	}
+	s.tasksMutex.RUnlock()
+	if reserve == 0 {
+		return
+	}
+
+	for dir, ids := range dirs {
+		free, err := s.freeSpace(dir)
+		if err != nil || int64(free) >= reserve {
+			continue
+		}
+		message := fmt.Sprintf("%s: %s free in %s, below the %s reserve", ErrInsufficientSpace,
+			platform.FormatBytes(int64(free)), dir, platform.FormatBytes(reserve))
+		log.Printf("Pausing %d downloads: %s", len(ids), message)
+
+		for _, id := range ids {
+			pause := s.PauseTask
+			if recordings[id] {
+				pause = s.StopTask
+			}
+			if err := pause(id); err != nil {
+				log.Printf("Failed to pause task %s: %v", id, err)
+				continue
+			}
+			s.tasksMutex.Lock()
+			if task, ok := s.tasks[id]; ok {
+				task.LastError = message
+				s.events.publish(Event{Type: EventDiskLow, Task: *task})
+			}
+			s.tasksMutex.Unlock()
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package download
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// withFreeSpace makes the service see the given free space and checks it often
+func withFreeSpace(t *testing.T, service *Service, reserve int64) *atomic.Int64 {
+	t.Helper()
+	free := &atomic.Int64{}
+	service.freeSpace = func(string) (uint64, error) { return uint64(free.Load()), nil }
+	service.diskCheckInterval = 10 * time.Millisecond
+	service.SetDiskReserve(reserve)
+	t.Cleanup(func() { service.SetDiskReserve(0) })
+	return free
+}
+
+func TestPreflightRejectsDownloadThatDoesNotFit(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	free := withFreeSpace(t, service, 100<<20)
+	free.Store(500 << 20)
+
+	url := "https://www.youtube.com/watch?v=bigbigbigbi"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{
+		Title:   "Big",
+		Formats: []engine.Format{{Ext: "mp4", Size: 450 << 20}},
+	}})
+
+	task, _ := service.AddTask(url)
+	failed := waitForStatus(t, service, task.ID, model.TaskStatusError)
+	if !strings.Contains(failed.LastError, ErrInsufficientSpace.Error()) {
+		t.Errorf("expected a disk space error, got %q", failed.LastError)
+	}
+	if fake.Downloads(url) != 0 {
+		t.Error("download started without enough space")
+	}
+
+	// Unknown sizes only need the reserve
+	small := "https://www.youtube.com/watch?v=smallsmalls"
+	fake.AddVideo(small, engine.FakeVideo{Info: engine.VideoInfo{Title: "Small"}})
+	task, _ = service.AddTask(small)
+	waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+}
+
+func TestLowSpacePausesRunningDownloads(t *testing.T) {
+	service, fake := newFakeService(t, 2)
+	free := withFreeSpace(t, service, 100<<20)
+	free.Store(1 << 30)
+	events, unsubscribe := service.Subscribe()
+	defer unsubscribe()
+
+	url := "https://www.youtube.com/watch?v=filldiskfil"
+	hold := make(chan struct{})
+	defer close(hold)
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Long"}, Hold: hold})
+
+	task, _ := service.AddTask(url)
+	waitForStart(t, fake, url)
+	waitForStatus(t, service, task.ID, model.TaskStatusDownloading)
+
+	free.Store(50 << 20)
+	paused := waitForStatus(t, service, task.ID, model.TaskStatusPaused)
+	if !strings.Contains(paused.LastError, ErrInsufficientSpace.Error()) {
+		t.Errorf("expected the paused task to explain why, got %q", paused.LastError)
+	}
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Type == EventDiskLow && event.Task.ID == task.ID {
+				return
+			}
+		case <-deadline:
+			t.Fatal("expected a disk_low event")
+		}
+	}
+}
+
+func TestLowSpaceStopsRecordings(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	recordQuickly(service)
+	free := withFreeSpace(t, service, 100<<20)
+	server := newLiveHLSServer(t, 0)
+
+	// A recording does not start below the reserve
+	free.Store(50 << 20)
+	url := "https://www.youtube.com/watch?v=fullfullful"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "On Air", Live: engine.LiveNow, LiveURL: server.URL + "/live.m3u8"}})
+	task, _ := service.AddTask(url)
+	failed := waitForStatus(t, service, task.ID, model.TaskStatusError)
+	if !strings.Contains(failed.LastError, ErrInsufficientSpace.Error()) {
+		t.Errorf("expected a disk space error, got %q", failed.LastError)
+	}
+
+	// A running recording is stopped and keeps what it recorded
+	free.Store(1 << 30)
+	if err := service.RestartTask(task.ID); err != nil {
+		t.Fatalf("RestartTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusRecording)
+	free.Store(50 << 20)
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if !strings.Contains(done.LastError, ErrInsufficientSpace.Error()) {
+		t.Errorf("expected the stopped recording to explain why, got %q", done.LastError)
+	}
+}
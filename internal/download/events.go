@@ -0,0 +1,173 @@
+package download
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// EventType identifies what happened to a task or playlist
+type EventType string
+
+const (
+	// EventTaskStateChanged is published when a task moves to a new non-final status
+	EventTaskStateChanged EventType = "task_state_changed"
+	// EventTaskProgress is published for progress, speed or ETA updates without a status change
+	EventTaskProgress EventType = "task_progress"
+	// EventTaskCompleted is published once a task finished successfully
+	EventTaskCompleted EventType = "task_completed"
+	// EventTaskError is published once a task failed
+	EventTaskError EventType = "task_error"
+	// EventDiskLow is published for each download paused because free disk space fell
+	// below the reserve; the task's LastError explains it
+	EventDiskLow EventType = "disk_low"
+	// EventPlaylistUpdated is published after the state of a playlist or its videos changed
+	EventPlaylistUpdated EventType = "playlist_updated"
+)
+
+// MaxQueuedEvents bounds the events buffered for a subscriber that stopped reading.
+// Beyond it queued progress events are dropped first, as later updates supersede
+// them, then the oldest events.
+const MaxQueuedEvents = 1024
+
+// Event describes a change in the download service.
+// Task and Playlist are snapshots and are safe to read without locking.
+type Event struct {
+	Type     EventType
+	Task     model.DownloadTask // set for task events
+	Playlist *model.Playlist    // set for EventPlaylistUpdated
+}
+
+// eventTypeForStatus classifies a task update based on its previous status
+func eventTypeForStatus(previous, current model.TaskStatus) EventType {
+	if previous == current {
+		return EventTaskProgress
+	}
+	switch current {
+	case model.TaskStatusCompleted:
+		return EventTaskCompleted
+	case model.TaskStatusError, model.TaskStatusVerifyFailed:
+		return EventTaskError
+	default:
+		return EventTaskStateChanged
+	}
+}
+
+// eventBus fans events out to subscribers without blocking publishers
+type eventBus struct {
+	mutex  sync.Mutex
+	subs   map[int]*subscription
+	nextID int
+}
+
+// subscription buffers events for one subscriber and delivers them in order
+type subscription struct {
+	mutex sync.Mutex
+	queue []Event
+	wake  chan struct{}
+	done  chan struct{}
+	out   chan Event
+	once  sync.Once
+}
+
+// newEventBus creates an empty event bus
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[int]*subscription)}
+}
+
+// subscribe registers a subscriber and returns its channel and an unsubscribe function
+func (b *eventBus) subscribe() (<-chan Event, func()) {
+	sub := &subscription{
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+		out:  make(chan Event),
+	}
+
+	b.mutex.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = sub
+	b.mutex.Unlock()
+
+	go sub.deliver()
+
+	unsubscribe := func() {
+		b.mutex.Lock()
+		delete(b.subs, id)
+		b.mutex.Unlock()
+		sub.once.Do(func() { close(sub.done) })
+	}
+	return sub.out, unsubscribe
+}
+
+// publish queues the event for every subscriber; it never blocks on slow consumers
+func (b *eventBus) publish(event Event) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, sub := range b.subs {
+		sub.push(event)
+	}
+}
+
+// push appends an event, replacing a queued progress event of the same task
+// and dropping one event when the queue is full
+func (s *subscription) push(event Event) {
+	s.mutex.Lock()
+	if n := len(s.queue); n > 0 && event.Type == EventTaskProgress {
+		last := s.queue[n-1]
+		if last.Type == EventTaskProgress && last.Task.ID == event.Task.ID {
+			s.queue[n-1] = event
+			s.mutex.Unlock()
+			return
+		}
+	}
+	if len(s.queue) >= MaxQueuedEvents {
+		drop := 0
+		for i, queued := range s.queue {
+			if queued.Type == EventTaskProgress {
+				drop = i
+				break
+			}
+		}
+		s.queue = slices.Delete(s.queue, drop, drop+1)
+	}
+	s.queue = append(s.queue, event)
+	s.mutex.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver forwards queued events to the subscriber until unsubscribed
+func (s *subscription) deliver() {
+	defer close(s.out)
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.wake:
+		}
+
+		for {
+			s.mutex.Lock()
+			if len(s.queue) == 0 {
+				s.mutex.Unlock()
+				break
+			}
+			event := s.queue[0]
+			s.queue = s.queue[1:]
+			s.mutex.Unlock()
+
+			select {
+			case s.out <- event:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package download
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// receiveEvent waits for the next event or fails the test
+func receiveEvent(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return Event{}
+	}
+}
+
+func TestEventTypeForStatus(t *testing.T) {
+	tests := []struct {
+		previous model.TaskStatus
+		current  model.TaskStatus
+		expected EventType
+	}{
+		{"", model.TaskStatusPending, EventTaskStateChanged},
+		{model.TaskStatusPending, model.TaskStatusStarting, EventTaskStateChanged},
+		{model.TaskStatusDownloading, model.TaskStatusDownloading, EventTaskProgress},
+		{model.TaskStatusDownloading, model.TaskStatusCompleted, EventTaskCompleted},
+		{model.TaskStatusStarting, model.TaskStatusError, EventTaskError},
+	}
+
+	for _, test := range tests {
+		if result := eventTypeForStatus(test.previous, test.current); result != test.expected {
+			t.Errorf("eventTypeForStatus(%q, %q) = %s, expected %s", test.previous, test.current, result, test.expected)
+		}
+	}
+}
+
+func TestSubscribeReceivesTaskSnapshots(t *testing.T) {
+	// A zero limit keeps tasks queued so the test does not hit the network
+	service := NewService("/tmp", 0).(*Service)
+
+	events, unsubscribe := service.Subscribe()
+	defer unsubscribe()
+
+	task, err := service.AddTask("https://youtube.com/watch?v=events")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	event := receiveEvent(t, events)
+	if event.Type != EventTaskStateChanged || event.Task.ID != task.ID || event.Task.Status != model.TaskStatusPending {
+		t.Errorf("unexpected event %s for task %s in status %s", event.Type, event.Task.ID, event.Task.Status)
+	}
+
+	if err := service.PauseTask(task.ID); err != nil {
+		t.Fatalf("PauseTask returned error: %v", err)
+	}
+	event = receiveEvent(t, events)
+	if event.Task.Status != model.TaskStatusPaused {
+		t.Errorf("expected paused snapshot, got %s", event.Task.Status)
+	}
+}
+
+func TestSubscriptionCoalescesProgress(t *testing.T) {
+	// No delivery goroutine: inspect the queue directly
+	sub := &subscription{wake: make(chan struct{}, 1)}
+
+	sub.push(Event{Type: EventTaskStateChanged, Task: model.DownloadTask{ID: "a"}})
+	for percent := 1; percent <= 3; percent++ {
+		sub.push(Event{Type: EventTaskProgress, Task: model.DownloadTask{ID: "a", Percent: percent}})
+	}
+	sub.push(Event{Type: EventTaskProgress, Task: model.DownloadTask{ID: "b", Percent: 5}})
+	sub.push(Event{Type: EventTaskCompleted, Task: model.DownloadTask{ID: "a", Percent: 100}})
+
+	expected := []struct {
+		eventType EventType
+		taskID    string
+		percent   int
+	}{
+		{EventTaskStateChanged, "a", 0},
+		{EventTaskProgress, "a", 3},
+		{EventTaskProgress, "b", 5},
+		{EventTaskCompleted, "a", 100},
+	}
+	if len(sub.queue) != len(expected) {
+		t.Fatalf("expected %d queued events, got %d", len(expected), len(sub.queue))
+	}
+	for i, want := range expected {
+		event := sub.queue[i]
+		if event.Type != want.eventType || event.Task.ID != want.taskID || event.Task.Percent != want.percent {
+			t.Errorf("event %d = %s %s %d%%, expected %s %s %d%%", i,
+				event.Type, event.Task.ID, event.Task.Percent, want.eventType, want.taskID, want.percent)
+		}
+	}
+}
+
+func TestSubscriptionQueueIsBounded(t *testing.T) {
+	// No delivery goroutine: a subscriber that stopped reading
+	sub := &subscription{wake: make(chan struct{}, 1)}
+
+	sub.push(Event{Type: EventTaskStateChanged, Task: model.DownloadTask{ID: "first"}})
+	sub.push(Event{Type: EventTaskProgress, Task: model.DownloadTask{ID: "progress"}})
+	for i := range MaxQueuedEvents - 2 {
+		sub.push(Event{Type: EventTaskStateChanged, Task: model.DownloadTask{ID: fmt.Sprint(i)}})
+	}
+
+	// The queued progress event makes room first
+	sub.push(Event{Type: EventTaskCompleted, Task: model.DownloadTask{ID: "done"}})
+	if len(sub.queue) != MaxQueuedEvents {
+		t.Fatalf("expected %d queued events, got %d", MaxQueuedEvents, len(sub.queue))
+	}
+	if slices.ContainsFunc(sub.queue, func(e Event) bool { return e.Type == EventTaskProgress }) {
+		t.Error("expected the progress event to be dropped")
+	}
+
+	// Without progress events the oldest one goes
+	sub.push(Event{Type: EventTaskError, Task: model.DownloadTask{ID: "failed"}})
+	if len(sub.queue) != MaxQueuedEvents {
+		t.Fatalf("expected %d queued events, got %d", MaxQueuedEvents, len(sub.queue))
+	}
+	if first, last := sub.queue[0].Task.ID, sub.queue[len(sub.queue)-1].Task.ID; first != "0" || last != "failed" {
+		t.Errorf("expected queue from %q to %q, got %q to %q", "0", "failed", first, last)
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	bus := newEventBus()
+	events, unsubscribe := bus.subscribe()
+	unsubscribe()
+	unsubscribe() // must be safe to call twice
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected closed channel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("channel was not closed")
+	}
+
+	// Publishing after unsubscribe must not block
+	bus.publish(Event{Type: EventTaskProgress})
+}
@@ -0,0 +1,127 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+)
+
+// ErrUnsupportedURL is returned when no extractor handles a URL
+var ErrUnsupportedURL = errors.New("unsupported URL")
+
+// Extractor resolves and downloads the media of one site.
+// Resolve returns the formats with the one Download would pick for opts first.
+type Extractor interface {
+	// Name identifies the site, e.g. "youtube"
+	Name() string
+
+	// Match reports whether the extractor handles the URL
+	Match(u *url.URL) bool
+
+	// VideoID returns a stable identifier used for duplicate detection, or "" if unknown
+	VideoID(u *url.URL) string
+
+	// Resolve fetches metadata and the available formats
+	Resolve(ctx context.Context, rawURL string, opts engine.Options) (*engine.VideoInfo, error)
+
+	// Download stores the selected format at outputPath. info is what Resolve
+	// returned for the same options: extractors that take the media URL from a
+	// page or player configuration download its first format without fetching
+	// that again, while the engine doing the transfer may still probe the stream.
+	Download(ctx context.Context, rawURL string, info *engine.VideoInfo, outputPath string, opts engine.Options, progress func(engine.Progress)) (*engine.VideoInfo, error)
+}
+
+// Registry routes URLs to extractors. Extractors are tried in registration
+// order, so specific sites must be registered before generic fallbacks.
+type Registry struct {
+	mutex      sync.RWMutex
+	extractors []Extractor
+}
+
+// NewRegistry creates a registry with the given extractors
+func NewRegistry(extractors ...Extractor) *Registry {
+	return &Registry{extractors: extractors}
+}
+
+// DefaultExtractors returns the built-in extractors in matching order:
+// YouTube through eng, then Vimeo, direct media files and manifests, and
+// generic HTML5 pages, which share the native direct engine
+func DefaultExtractors(eng engine.Engine) []Extractor {
+	direct := engine.NewDirect()
+	return []Extractor{
+		NewYouTubeExtractor(eng),
+		NewVimeoExtractor(direct),
+		NewDirectExtractor(direct),
+		NewHTML5Extractor(direct),
+	}
+}
+
+// Register appends an extractor; it is tried after the existing ones
+func (r *Registry) Register(extractor Extractor) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.extractors = append(r.extractors, extractor)
+}
+
+// Find returns the first extractor that handles the URL
+func (r *Registry) Find(rawURL string) (Extractor, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURL, rawURL)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, extractor := range r.extractors {
+		if extractor.Match(u) {
+			return extractor, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedURL, rawURL)
+}
+
+// Names lists the registered extractors in matching order
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	names := make([]string, 0, len(r.extractors))
+	for _, extractor := range r.extractors {
+		names = append(names, extractor.Name())
+	}
+	return names
+}
+
+// parseHeight extracts a pixel height from labels such as "720p", "1080" or "HD 720"
+func parseHeight(label string) int {
+	digits := strings.Builder{}
+	for _, r := range label {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		} else if digits.Len() > 0 {
+			break
+		}
+	}
+	height, _ := strconv.Atoi(digits.String())
+	return height
+}
+
+// extFromURL returns the lower-case file extension of the URL path without the dot
+func extFromURL(u *url.URL) string {
+	return strings.TrimPrefix(strings.ToLower(path.Ext(u.Path)), ".")
+}
+
+// httpClient returns the client from opts or a default one
+func httpClient(opts engine.Options) *http.Client {
+	if opts.HTTPClient != nil {
+		return opts.HTTPClient
+	}
+	return http.DefaultClient
+}
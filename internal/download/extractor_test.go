@@ -0,0 +1,370 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+func TestRegistryFind(t *testing.T) {
+	registry := NewRegistry(DefaultExtractors(engine.NewFake())...)
+
+	tests := []struct {
+		url  string
+		site string
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", SiteYouTube},
+		{"https://youtu.be/dQw4w9WgXcQ", SiteYouTube},
+		{"https://vimeo.com/123456", SiteVimeo},
+		{"https://player.vimeo.com/video/123456?h=abc", SiteVimeo},
+		{"https://vimeo.com/channels/staffpicks/123456", SiteVimeo},
+		{"https://cdn.example.com/media/clip.MP4?token=1", SiteDirect},
+		{"https://example.com/podcast/episode.mp3", SiteDirect},
+		{"https://example.com/watch/42", SiteHTML5},
+		{"https://vimeo.com/about", SiteHTML5},
+	}
+	for _, tt := range tests {
+		extractor, err := registry.Find(tt.url)
+		if err != nil {
+			t.Errorf("Find(%q): %v", tt.url, err)
+			continue
+		}
+		if extractor.Name() != tt.site {
+			t.Errorf("Find(%q) = %s, want %s", tt.url, extractor.Name(), tt.site)
+		}
+	}
+
+	// YouTube links other than videos and playlists are not fetched as web pages
+	for _, raw := range []string{"ftp://example.com/a.mp4", "not a url", "file:///tmp/a.mp4", "https://www.youtube.com/@handle", "https://www.youtube.com/channel/UCxxxxxxxxxxxxxxxxxxxxxx"} {
+		if _, err := registry.Find(raw); !errors.Is(err, ErrUnsupportedURL) {
+			t.Errorf("Find(%q): expected ErrUnsupportedURL, got %v", raw, err)
+		}
+	}
+
+	if names := registry.Names(); strings.Join(names, ",") != "youtube,vimeo,direct,html5" {
+		t.Errorf("unexpected extractor order %v", names)
+	}
+}
+
+func TestParseHTML5Page(t *testing.T) {
+	page := `<html><head><title> Page title </title>
+<meta property="og:title" content="Clip of the day">
+<base href="https://media.example.com/files/">
+</head><body>
+<video controls poster="poster.jpg">
+  <source src="clip-480.webm" type="video/webm" size="480">
+  <source src="/abs/clip-720.mp4" type='video/mp4; codecs="avc1"' label="720p">
+  <source src="stream.m3u8" type="application/x-mpegURL">
+  <source src="/live?format=hls" type="application/x-mpegURL">
+  <source src="blob:https://example.com/123">
+</video>
+<source src="outside.mp4">
+</body></html>`
+	base, _ := url.Parse("https://example.com/watch/42")
+
+	info, err := parseHTML5Page(strings.NewReader(page), base)
+	if err != nil {
+		t.Fatalf("parseHTML5Page: %v", err)
+	}
+	if info.Title != "Clip of the day" {
+		t.Errorf("unexpected title %q", info.Title)
+	}
+	if len(info.Formats) != 3 {
+		t.Fatalf("expected 3 formats, got %+v", info.Formats)
+	}
+	first, second, manifest := info.Formats[0], info.Formats[1], info.Formats[2]
+	if first.URL != "https://media.example.com/files/clip-480.webm" || first.Ext != "webm" || first.Height != 480 {
+		t.Errorf("unexpected first format %+v", first)
+	}
+	if second.URL != "https://media.example.com/abs/clip-720.mp4" || second.MimeType != "video/mp4" || second.Height != 720 {
+		t.Errorf("unexpected second format %+v", second)
+	}
+	if manifest.URL != "https://media.example.com/files/stream.m3u8" {
+		t.Errorf("unexpected manifest format %+v", manifest)
+	}
+}
+
+// newMediaServer serves a page with a <video> tag, a media file, an fMP4 HLS
+// playlist and a Vimeo-style config
+func newMediaServer(t *testing.T, payload []byte) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/files/clip.mp4", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Length", fmt.Sprint(len(payload)))
+		if r.Method == http.MethodGet {
+			w.Write(payload)
+		}
+	})
+	mux.HandleFunc("/watch", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Embedded clip</title></head><body>
+<video><source src="/files/clip.mp4" type="video/mp4" size="720"></video></body></html>`)
+	})
+	mux.HandleFunc("/stream/index.m3u8", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		fmt.Fprint(w, "#EXTM3U\n#EXT-X-MAP:URI=\"init.mp4\"\n#EXTINF:4,\n/files/clip.mp4\n#EXTINF:4,\n/files/clip.mp4\n#EXT-X-ENDLIST\n")
+	})
+	mux.HandleFunc("/stream/init.mp4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "init")
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Nothing here</title></head></html>`)
+	})
+	mux.HandleFunc("/video/43/config", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"video":{"title":"Vimeo stream","duration":8},
+"request":{"files":{"progressive":[],
+  "dash":{"default_cdn":"fastly","cdns":{"fastly":{"url":"%[1]s/stream/playlist.json"}}},
+  "hls":{"default_cdn":"akamai","cdns":{"fastly":{"url":"%[1]s/missing.m3u8"},"akamai":{"url":"%[1]s/stream/index.m3u8"}}}}}}`, "http://"+r.Host)
+	})
+	mux.HandleFunc("/video/42/config", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"video":{"title":"Vimeo clip","duration":12,"owner":{"name":"Someone"}},
+"request":{"files":{"progressive":[
+  {"url":"%[1]s/files/low.mp4","quality":"360p","height":360,"mime":"video/mp4"},
+  {"url":"%[1]s/files/clip.mp4","quality":"720p","mime":"video/mp4"}]}}}`, "http://"+r.Host)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestExtractorsDownload(t *testing.T) {
+	payload := []byte(strings.Repeat("media", 1000))
+	server := newMediaServer(t, payload)
+	direct := &engine.Direct{}
+	vimeo := NewVimeoExtractor(direct)
+	vimeo.configURL = server.URL + "/video/%s/config"
+
+	tests := []struct {
+		name      string
+		extractor Extractor
+		url       string
+		title     string
+	}{
+		{"direct", NewDirectExtractor(direct), server.URL + "/files/clip.mp4", "clip"},
+		{"html5", NewHTML5Extractor(direct), server.URL + "/watch", "Embedded clip"},
+		{"vimeo", vimeo, "https://vimeo.com/42", "Vimeo clip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := engine.Options{Quality: "best"}
+			info, err := tt.extractor.Resolve(context.Background(), tt.url, opts)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if info.Title != tt.title || len(info.Formats) == 0 || info.Formats[0].Ext != "mp4" {
+				t.Fatalf("unexpected info %+v", info)
+			}
+
+			output := filepath.Join(t.TempDir(), "out.mp4")
+			var last engine.Progress
+			if _, err := tt.extractor.Download(context.Background(), tt.url, info, output, opts, func(p engine.Progress) {
+				last = p
+			}); err != nil {
+				t.Fatalf("Download: %v", err)
+			}
+			data, err := os.ReadFile(output)
+			if err != nil || string(data) != string(payload) {
+				t.Fatalf("unexpected output (%d bytes): %v", len(data), err)
+			}
+			if last.DownloadedSize != int64(len(payload)) {
+				t.Errorf("expected final progress %d, got %+v", len(payload), last)
+			}
+		})
+	}
+
+	if _, err := NewHTML5Extractor(direct).Resolve(context.Background(), server.URL+"/empty", engine.Options{}); !errors.Is(err, ErrNoMedia) {
+		t.Errorf("expected ErrNoMedia, got %v", err)
+	}
+}
+
+func TestVimeoFallsBackToStream(t *testing.T) {
+	payload := []byte(strings.Repeat("v", 500))
+	server := newMediaServer(t, payload)
+	vimeo := NewVimeoExtractor(&engine.Direct{})
+	vimeo.configURL = server.URL + "/video/%s/config"
+
+	opts := engine.Options{Quality: "best"}
+	info, err := vimeo.Resolve(context.Background(), "https://vimeo.com/43", opts)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(info.Formats) != 1 || info.Formats[0].URL != server.URL+"/stream/index.m3u8" || info.Formats[0].Ext != "mp4" {
+		t.Fatalf("expected the HLS stream of the default CDN, got %+v", info.Formats)
+	}
+
+	output := filepath.Join(t.TempDir(), "out.mp4")
+	if _, err := vimeo.Download(context.Background(), "https://vimeo.com/43", info, output, opts, nil); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if data, err := os.ReadFile(output); err != nil || string(data) != "init"+string(payload)+string(payload) {
+		t.Fatalf("unexpected output (%d bytes): %v", len(data), err)
+	}
+}
+
+func TestServiceReportsPageWithoutMedia(t *testing.T) {
+	server := newMediaServer(t, []byte("clip"))
+	service, _ := newFakeService(t, 1)
+
+	task, err := service.AddTask(server.URL + "/empty")
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	failed := waitForStatus(t, service, task.ID, model.TaskStatusError)
+	if !strings.Contains(failed.LastError, ErrUnsupportedURL.Error()) || !strings.Contains(failed.LastError, ErrNoMedia.Error()) {
+		t.Errorf("expected an unsupported page without media, got %q", failed.LastError)
+	}
+
+	task, err = service.AddTask(server.URL + "/watch")
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if filepath.Base(done.OutputPath) != "Embedded_clip.mp4" {
+		t.Errorf("unexpected output path %s", done.OutputPath)
+	}
+}
+
+func TestServiceDownloadsDirectURL(t *testing.T) {
+	payload := []byte(strings.Repeat("x", 3000))
+	server := newMediaServer(t, payload)
+	service, _ := newFakeService(t, 1)
+
+	if site, err := service.SiteForURL(server.URL + "/files/clip.mp4"); err != nil || site != SiteDirect {
+		t.Fatalf("SiteForURL = %q, %v", site, err)
+	}
+
+	task, err := service.AddTask(server.URL + "/files/clip.mp4")
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	if task.Site != SiteDirect {
+		t.Errorf("expected site %s, got %s", SiteDirect, task.Site)
+	}
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if filepath.Base(done.OutputPath) != "clip.mp4" {
+		t.Errorf("unexpected output path %s", done.OutputPath)
+	}
+	if fi, err := os.Stat(done.OutputPath); err != nil || fi.Size() != int64(len(payload)) {
+		t.Errorf("unexpected output file: %v", err)
+	}
+
+	if _, err := service.AddTask("ftp://example.com/clip.mp4"); !errors.Is(err, ErrUnsupportedURL) {
+		t.Errorf("expected ErrUnsupportedURL, got %v", err)
+	}
+}
+
+func TestServiceDownloadsHLSManifest(t *testing.T) {
+	payload := []byte(strings.Repeat("s", 2000))
+	server := newMediaServer(t, payload)
+	service, _ := newFakeService(t, 1)
+
+	task, err := service.AddTask(server.URL + "/stream/index.m3u8")
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if filepath.Base(done.OutputPath) != "index.mp4" || done.Percent != 100 {
+		t.Errorf("unexpected completed task: output %s, percent %d", done.OutputPath, done.Percent)
+	}
+	data, err := os.ReadFile(done.OutputPath)
+	if err != nil || string(data) != "init"+string(payload)+string(payload) {
+		t.Errorf("unexpected output (%d bytes): %v", len(data), err)
+	}
+}
+
+func TestServiceSegmentedDirectDownload(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 3*engine.MinSegmentSize/16)
+	var mutex sync.Mutex
+	ranges := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ranges[r.Header.Get("Range")] = true
+		mutex.Unlock()
+		http.ServeContent(w, r, "big.mp4", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer server.Close()
+
+	service, _ := newFakeService(t, 1)
+	service.SetDownloadSegments(3)
+	task, err := service.AddTask(server.URL + "/big.mp4")
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if data, err := os.ReadFile(done.OutputPath); err != nil || !bytes.Equal(data, payload) {
+		t.Fatalf("unexpected output (%d bytes): %v", len(data), err)
+	}
+	if done.Percent != 100 {
+		t.Errorf("expected 100%%, got %d", done.Percent)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	size := int64(len(payload))
+	for i := range int64(3) {
+		start := i * (size / 3)
+		if !ranges[fmt.Sprintf("bytes=%d-%d", start, start+size/3-1)] {
+			t.Errorf("expected range request %d, got %v", i, ranges)
+		}
+	}
+}
+
+func TestServiceSegmentsBoundedByParallelLimit(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 4*engine.MinSegmentSize/16)
+	var mutex sync.Mutex
+	ranges := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		ranges[r.Header.Get("Range")] = true
+		mutex.Unlock()
+		http.ServeContent(w, r, "big.mp4", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer server.Close()
+
+	// Eight parallel downloads leave two of the sixteen connections to each
+	service, _ := newFakeService(t, 1)
+	service.SetDownloadSegments(MaxSegments)
+	service.SetMaxParallelDownloads(8)
+	task, err := service.AddTask(server.URL + "/big.mp4")
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	half := int64(len(payload)) / 2
+	if !ranges[fmt.Sprintf("bytes=0-%d", half-1)] || !ranges[fmt.Sprintf("bytes=%d-%d", half, 2*half-1)] {
+		t.Errorf("expected two ranges, got %v", ranges)
+	}
+}
+
+func TestClampSegments(t *testing.T) {
+	tests := []struct {
+		segments, parallel, want int
+	}{
+		{segments: 4, parallel: 2, want: 4},
+		{segments: 20, parallel: 1, want: MaxSegments},
+		{segments: 8, parallel: 5, want: 3},
+		{segments: 0, parallel: 2, want: 1},
+		{segments: 4, parallel: 0, want: 4},
+	}
+	for _, tt := range tests {
+		if got := ClampSegments(tt.segments, tt.parallel); got != tt.want {
+			t.Errorf("ClampSegments(%d, %d) = %d, want %d", tt.segments, tt.parallel, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,476 @@
+package download
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/ytlink"
+)
+
+// Extractor names
+const (
+	SiteYouTube = "youtube"
+	SiteVimeo   = "vimeo"
+	SiteDirect  = "direct"
+	SiteHTML5   = "html5"
+)
+
+// Vimeo player configuration endpoint
+const VimeoConfigURL = "https://player.vimeo.com/video/%s/config"
+
+// MaxPageSize limits how much of an HTML page is read when looking for media
+const MaxPageSize = 4 << 20
+
+// MediaExtensions are media file extensions handled by the direct extractor
+var MediaExtensions = map[string]bool{
+	"mp4": true, "m4v": true, "webm": true, "mkv": true, "mov": true, "avi": true, "flv": true,
+	"m4a": true, "mp3": true, "ogg": true, "oga": true, "opus": true, "wav": true, "flac": true, "aac": true,
+}
+
+// ManifestExtensions are HLS and DASH manifests handled by the direct extractor
+var ManifestExtensions = map[string]bool{"m3u8": true, "mpd": true}
+
+// ErrNoMedia is returned when a page contains no downloadable media
+var ErrNoMedia = errors.New("no downloadable video found on the page")
+
+// YouTubeExtractor handles YouTube links through the media engine
+type YouTubeExtractor struct {
+	engine engine.Engine
+}
+
+// NewYouTubeExtractor creates the YouTube extractor on top of an engine
+func NewYouTubeExtractor(eng engine.Engine) *YouTubeExtractor {
+	return &YouTubeExtractor{engine: eng}
+}
+
+// Name returns the site name
+func (e *YouTubeExtractor) Name() string { return SiteYouTube }
+
+// Match accepts YouTube video and playlist links
+func (e *YouTubeExtractor) Match(u *url.URL) bool {
+	link, err := ytlink.Parse(u.String())
+	return err == nil && (link.IsVideo() || link.IsPlaylist())
+}
+
+// VideoID returns the YouTube video ID
+func (e *YouTubeExtractor) VideoID(u *url.URL) string {
+	return ytlink.VideoID(u.String())
+}
+
+// Resolve fetches metadata through the engine
+func (e *YouTubeExtractor) Resolve(ctx context.Context, rawURL string, opts engine.Options) (*engine.VideoInfo, error) {
+	return e.engine.Resolve(ctx, rawURL, opts)
+}
+
+// Download downloads through the engine, which resolves the video itself
+func (e *YouTubeExtractor) Download(ctx context.Context, rawURL string, info *engine.VideoInfo, outputPath string, opts engine.Options, progress func(engine.Progress)) (*engine.VideoInfo, error) {
+	return e.engine.Download(ctx, rawURL, outputPath, opts, progress)
+}
+
+// VimeoExtractor downloads the files listed in the Vimeo player configuration:
+// a progressive file when there is one, otherwise the HLS or DASH stream
+type VimeoExtractor struct {
+	configURL string // printf template taking the video ID
+	direct    *engine.Direct
+}
+
+// NewVimeoExtractor creates the Vimeo extractor
+func NewVimeoExtractor(direct *engine.Direct) *VimeoExtractor {
+	return &VimeoExtractor{configURL: VimeoConfigURL, direct: direct}
+}
+
+// Name returns the site name
+func (e *VimeoExtractor) Name() string { return SiteVimeo }
+
+// Match accepts vimeo.com and player.vimeo.com links with a numeric video ID
+func (e *VimeoExtractor) Match(u *url.URL) bool {
+	return vimeoID(u) != ""
+}
+
+// VideoID returns "vimeo_" followed by the numeric ID
+func (e *VimeoExtractor) VideoID(u *url.URL) string {
+	if id := vimeoID(u); id != "" {
+		return "vimeo_" + id
+	}
+	return ""
+}
+
+// vimeoConfig is the subset of the player configuration that is used
+type vimeoConfig struct {
+	Video struct {
+		Title    string `json:"title"`
+		Duration int    `json:"duration"`
+		Owner    struct {
+			Name string `json:"name"`
+		} `json:"owner"`
+	} `json:"video"`
+	Request struct {
+		Files struct {
+			Progressive []struct {
+				URL     string `json:"url"`
+				Quality string `json:"quality"`
+				Height  int    `json:"height"`
+				Mime    string `json:"mime"`
+			} `json:"progressive"`
+			HLS  vimeoStreams `json:"hls"`
+			DASH vimeoStreams `json:"dash"`
+		} `json:"files"`
+	} `json:"request"`
+}
+
+// vimeoStreams lists the manifest of a stream type on each CDN
+type vimeoStreams struct {
+	DefaultCDN string `json:"default_cdn"`
+	CDNs       map[string]struct {
+		URL string `json:"url"`
+	} `json:"cdns"`
+}
+
+// manifestURL returns the manifest on the default CDN, or on the first CDN by
+// name, if the direct engine can download it as kind
+func (s vimeoStreams) manifestURL(kind engine.StreamKind) string {
+	names := make([]string, 0, len(s.CDNs))
+	for name := range s.CDNs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if _, ok := s.CDNs[s.DefaultCDN]; ok {
+		names = append([]string{s.DefaultCDN}, names...)
+	}
+	for _, name := range names {
+		if u := s.CDNs[name].URL; u != "" && engine.KindOf(u, "") == kind {
+			return u
+		}
+	}
+	return ""
+}
+
+// Resolve reads the player configuration. Videos without a progressive file
+// are described by their HLS or, failing that, DASH manifest.
+func (e *VimeoExtractor) Resolve(ctx context.Context, rawURL string, opts engine.Options) (*engine.VideoInfo, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	id := vimeoID(u)
+	if id == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURL, rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(e.configURL, id), nil)
+	if err != nil {
+		return nil, err
+	}
+	// Embedded players only serve their configuration to the embedding page
+	req.Header.Set("Referer", rawURL)
+	resp, err := httpClient(opts).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("vimeo video is private (HTTP %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vimeo config: HTTP %d", resp.StatusCode)
+	}
+
+	var config vimeoConfig
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return nil, fmt.Errorf("vimeo config: %w", err)
+	}
+
+	info := &engine.VideoInfo{
+		ID:       id,
+		Title:    config.Video.Title,
+		Author:   config.Video.Owner.Name,
+		Duration: config.Video.Duration,
+	}
+	for _, file := range config.Request.Files.Progressive {
+		height := file.Height
+		if height == 0 {
+			height = parseHeight(file.Quality)
+		}
+		info.Formats = append(info.Formats, engine.Format{
+			ID:       file.Quality,
+			URL:      file.URL,
+			Quality:  file.Quality,
+			MimeType: file.Mime,
+			Ext:      "mp4",
+			Height:   height,
+		})
+	}
+	if len(info.Formats) > 0 {
+		engine.SortFormats(info.Formats, opts.Quality)
+		return info, nil
+	}
+
+	manifest := config.Request.Files.HLS.manifestURL(engine.KindHLS)
+	if manifest == "" {
+		manifest = config.Request.Files.DASH.manifestURL(engine.KindDASH)
+	}
+	if manifest == "" {
+		return nil, errors.New("vimeo video has no downloadable stream")
+	}
+	// The direct engine picks the variant for opts when downloading the manifest
+	stream, err := e.direct.Resolve(ctx, manifest, opts)
+	if err != nil {
+		return nil, err
+	}
+	preferred := stream.Formats[0]
+	preferred.URL = manifest
+	info.Formats = []engine.Format{preferred}
+	if info.Duration == 0 {
+		info.Duration = stream.Duration
+	}
+	return info, nil
+}
+
+// Download fetches the preferred file or stream of the resolved info
+func (e *VimeoExtractor) Download(ctx context.Context, rawURL string, info *engine.VideoInfo, outputPath string, opts engine.Options, progress func(engine.Progress)) (*engine.VideoInfo, error) {
+	if _, err := e.direct.Download(ctx, info.Formats[0].URL, outputPath, opts, progress); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// vimeoID returns the numeric video ID of a Vimeo link, e.g. vimeo.com/123,
+// vimeo.com/channels/staffpicks/123 or player.vimeo.com/video/123
+func vimeoID(u *url.URL) string {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host != "vimeo.com" && host != "player.vimeo.com" {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if _, err := strconv.ParseUint(segments[i], 10, 64); err == nil {
+			return segments[i]
+		}
+	}
+	return ""
+}
+
+// DirectExtractor downloads URLs that point at a media file or an HLS/DASH
+// manifest through the native direct engine
+type DirectExtractor struct {
+	direct *engine.Direct
+}
+
+// NewDirectExtractor creates the direct media extractor
+func NewDirectExtractor(direct *engine.Direct) *DirectExtractor {
+	return &DirectExtractor{direct: direct}
+}
+
+// Name returns the site name
+func (e *DirectExtractor) Name() string { return SiteDirect }
+
+// Match accepts URLs whose path ends with a media file or manifest extension
+func (e *DirectExtractor) Match(u *url.URL) bool {
+	ext := extFromURL(u)
+	return MediaExtensions[ext] || ManifestExtensions[ext]
+}
+
+// VideoID is unknown; duplicates are detected by URL
+func (e *DirectExtractor) VideoID(u *url.URL) string { return "" }
+
+// Resolve describes the file or the variants of the manifest
+func (e *DirectExtractor) Resolve(ctx context.Context, rawURL string, opts engine.Options) (*engine.VideoInfo, error) {
+	return e.direct.Resolve(ctx, rawURL, opts)
+}
+
+// Download fetches the file, resuming a partial download, or the segments of the manifest
+func (e *DirectExtractor) Download(ctx context.Context, rawURL string, info *engine.VideoInfo, outputPath string, opts engine.Options, progress func(engine.Progress)) (*engine.VideoInfo, error) {
+	return e.direct.Download(ctx, rawURL, outputPath, opts, progress)
+}
+
+// HTML5Extractor finds <video>/<audio> sources and og:video tags on any web page.
+// It matches every http(s) URL except recognised YouTube links and must be
+// registered last; pages without media fail to resolve as unsupported.
+type HTML5Extractor struct {
+	direct *engine.Direct
+}
+
+// NewHTML5Extractor creates the generic HTML5 page extractor
+func NewHTML5Extractor(direct *engine.Direct) *HTML5Extractor {
+	return &HTML5Extractor{direct: direct}
+}
+
+// Name returns the site name
+func (e *HTML5Extractor) Name() string { return SiteHTML5 }
+
+// Match accepts any web page; YouTube channel and other non-video links are
+// left unmatched so they are rejected instead of fetched as pages
+func (e *HTML5Extractor) Match(u *url.URL) bool {
+	_, err := ytlink.Parse(u.String())
+	return err != nil
+}
+
+// VideoID is unknown; duplicates are detected by URL
+func (e *HTML5Extractor) VideoID(u *url.URL) string { return "" }
+
+// Resolve fetches the page and collects its media sources. A page without
+// media is reported as an unsupported URL wrapping ErrNoMedia.
+func (e *HTML5Extractor) Resolve(ctx context.Context, rawURL string, opts engine.Options) (*engine.VideoInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient(opts).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("page request failed: HTTP %d", resp.StatusCode)
+	}
+
+	info, err := parseHTML5Page(io.LimitReader(resp.Body, MaxPageSize), resp.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.Formats) == 0 {
+		return nil, fmt.Errorf("%w: %w", ErrUnsupportedURL, ErrNoMedia)
+	}
+	engine.SortFormats(info.Formats, opts.Quality)
+
+	// The output container of a manifest depends on its segments
+	if preferred := &info.Formats[0]; engine.KindOf(preferred.URL, "") != engine.KindFile {
+		stream, err := e.direct.Resolve(ctx, preferred.URL, opts)
+		if err != nil {
+			return nil, err
+		}
+		preferred.Ext = stream.Formats[0].Ext
+		if info.Duration == 0 {
+			info.Duration = stream.Duration
+		}
+	}
+	return info, nil
+}
+
+// Download fetches the preferred source of the resolved page
+func (e *HTML5Extractor) Download(ctx context.Context, rawURL string, info *engine.VideoInfo, outputPath string, opts engine.Options, progress func(engine.Progress)) (*engine.VideoInfo, error) {
+	if _, err := e.direct.Download(ctx, info.Formats[0].URL, outputPath, opts, progress); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// parseHTML5Page collects the title and media sources of a page. Sources are
+// resolved against base; blob/data URLs and manifests without an .m3u8 or
+// .mpd extension are skipped.
+func parseHTML5Page(r io.Reader, base *url.URL) (*engine.VideoInfo, error) {
+	info := &engine.VideoInfo{}
+	seen := make(map[string]bool)
+	var pageTitle, ogTitle string
+	inTitle, inMedia := false, false
+
+	addSource := func(src, mimeType, label string) {
+		ref, err := url.Parse(strings.TrimSpace(src))
+		if err != nil || src == "" {
+			return
+		}
+		u := base.ResolveReference(ref)
+		ext := extFromURL(u)
+		if (u.Scheme != "http" && u.Scheme != "https") || seen[u.String()] {
+			return
+		}
+		if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+			mimeType = mediaType
+			if engine.KindOf("", mediaType) != engine.KindFile && !ManifestExtensions[ext] {
+				return
+			}
+			if ext == "" || !MediaExtensions[ext] {
+				if subtype, ok := strings.CutPrefix(mediaType, "video/"); ok {
+					ext = subtype
+				} else if subtype, ok := strings.CutPrefix(mediaType, "audio/"); ok {
+					ext = subtype
+				}
+			}
+		}
+		if !MediaExtensions[ext] {
+			ext = ""
+		}
+		seen[u.String()] = true
+		info.Formats = append(info.Formats, engine.Format{
+			ID:       strconv.Itoa(len(info.Formats)),
+			URL:      u.String(),
+			Quality:  label,
+			MimeType: mimeType,
+			Ext:      ext,
+			Height:   parseHeight(label),
+		})
+	}
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, err
+			}
+			info.Title = strings.TrimSpace(ogTitle)
+			if info.Title == "" {
+				info.Title = strings.TrimSpace(pageTitle)
+			}
+			if info.Title == "" {
+				info.Title = engine.TitleFromURL(base)
+			}
+			return info, nil
+		case html.TextToken:
+			if inTitle {
+				pageTitle += string(tokenizer.Text())
+			}
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "video", "audio":
+				inMedia = false
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			attrs := make(map[string]string, len(token.Attr))
+			for _, attr := range token.Attr {
+				attrs[attr.Key] = attr.Val
+			}
+			switch token.Data {
+			case "title":
+				inTitle = tokenType == html.StartTagToken
+			case "base":
+				if href, err := url.Parse(attrs["href"]); err == nil && attrs["href"] != "" {
+					base = base.ResolveReference(href)
+				}
+			case "meta":
+				property := attrs["property"]
+				switch property {
+				case "og:title":
+					ogTitle = attrs["content"]
+				case "og:video", "og:video:url", "og:video:secure_url":
+					addSource(attrs["content"], "", "")
+				}
+			case "video", "audio":
+				inMedia = tokenType == html.StartTagToken
+				addSource(attrs["src"], "", engine.FirstNonEmpty(attrs["height"], attrs["data-quality"]))
+			case "source":
+				if inMedia {
+					label := engine.FirstNonEmpty(attrs["size"], attrs["res"], attrs["data-res"], attrs["label"], attrs["data-quality"])
+					addSource(attrs["src"], attrs["type"], label)
+				}
+			}
+		}
+	}
+}
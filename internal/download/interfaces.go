@@ -1,12 +1,22 @@
 package download
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/network"
+	"github.com/ytget/yt-downloader/internal/platform"
+	"github.com/ytget/yt-downloader/internal/schedule"
+	"github.com/ytget/yt-downloader/internal/sponsorblock"
 )
 
 // Downloader defines the interface for the download service.
 type Downloader interface {
 	SetUpdateCallback(func(*model.DownloadTask))
+
+	// Subscribe returns task and playlist events and a function that ends the subscription
+	Subscribe() (<-chan Event, func())
 	AddTask(url string) (*model.DownloadTask, error)
 	GetTask(id string) (*model.DownloadTask, bool)
 	GetAllTasks() []*model.DownloadTask
@@ -15,13 +25,47 @@ type Downloader interface {
 	PauseTask(id string) error
 	ResumeTask(id string) error
 	RestartTask(id string) error
-	RemoveTask(id string) error
+	RemoveTask(id string, deletePartial bool) error
 	AddPlaylist(playlist *model.Playlist) error
 	GetPlaylist(id string) (*model.Playlist, bool)
 	GetAllPlaylists() []*model.Playlist
 	DownloadPlaylist(playlist *model.Playlist) error
+	PausePlaylist(playlistID string) error
+	ResumePlaylist(playlistID string) error
 	CancelPlaylist(playlistID string) error
-	SetMaxPlaylistParallel(max int)
+
+	// AddTaskAt adds a task that does not start before startAt
+	AddTaskAt(url string, startAt time.Time) (*model.DownloadTask, error)
+
+	// ScheduleTask sets when a queued or stopped task starts; a zero time starts it when a slot is free
+	ScheduleTask(id string, startAt time.Time) error
+
+	// SchedulePlaylist sets when the unfinished videos of a playlist start
+	SchedulePlaylist(playlistID string, startAt time.Time) error
+
+	// SetDownloadWindow sets the daily time window in which queued tasks may start
+	SetDownloadWindow(window schedule.Window)
+
+	// GetQueue returns the queued and paused tasks in start order
+	GetQueue() []*model.DownloadTask
+
+	// MoveTask moves a queued task to a queue position; 0 is the top, a negative index the bottom
+	MoveTask(id string, index int) error
+
+	// DownloadNext moves a task to the top of the queue with high priority
+	DownloadNext(id string) error
+
+	// SetTaskPriority changes where a task sits in the queue relative to others
+	SetTaskPriority(id string, priority model.TaskPriority) error
+
+	// LoadQueue restores the saved queue and keeps the file up to date
+	LoadQueue(path string) ([]*model.DownloadTask, error)
+
+	// SaveQueue writes the unfinished tasks to the queue file
+	SaveQueue() error
+
+	// SiteForURL returns the name of the extractor that handles the URL, or ErrUnsupportedURL
+	SiteForURL(url string) (string, error)
 
 	// SetQualityPreset configures quality selection for downloads (best/medium/audio)
 	SetQualityPreset(preset string)
@@ -29,6 +73,40 @@ type Downloader interface {
 	// SetMaxParallelDownloads sets the maximum number of parallel downloads
 	SetMaxParallelDownloads(max int)
 
+	// SetDownloadSegments sets the number of concurrent connections per file download
+	SetDownloadSegments(segments int)
+
 	// SetDownloadDirectory sets the download directory
 	SetDownloadDirectory(dir string)
+
+	// SetProxy sets the proxy for metadata resolution and media transfer
+	SetProxy(cfg network.ProxyConfig)
+
+	// SetTaskProxy overrides the proxy of one task; an empty URL restores the global proxy
+	SetTaskProxy(id, proxyURL string) error
+
+	// SetCookies sets imported browser cookies sent with every request; nil clears them
+	SetCookies(cookies []*http.Cookie)
+
+	// SetDiskReserve sets the free disk space downloads must leave; zero disables the checks
+	SetDiskReserve(bytes int64)
+
+	// SetCollisionPolicy sets what happens when a download's output file already exists
+	SetCollisionPolicy(policy platform.CollisionPolicy)
+	// SetVerifyMode sets whether finished downloads are checked with ffprobe
+	SetVerifyMode(mode VerifyMode)
+
+	// SetTempDir sets where unfinished downloads are written; empty writes them
+	// next to the finished file
+	SetTempDir(dir string)
+
+	// OrphanedPartials lists unfinished downloads on disk that no task owns
+	OrphanedPartials() []PartialFile
+	// ResumePartial queues the download an orphaned partial file belongs to
+	ResumePartial(path string) (*model.DownloadTask, error)
+	// DeletePartial deletes an orphaned partial download
+	DeletePartial(path string) error
+
+	// SetSponsorBlock sets which SponsorBlock segments are removed or marked as chapters
+	SetSponsorBlock(cfg sponsorblock.Config)
 }
@@ -0,0 +1,237 @@
+package download
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// newFakeService returns a service backed by a fake engine writing into a temp dir
+func newFakeService(t *testing.T, maxParallel int) (*Service, *engine.Fake) {
+	t.Helper()
+	fake := engine.NewFake()
+	return NewServiceWithEngine(t.TempDir(), maxParallel, fake).(*Service), fake
+}
+
+// waitForStatus polls until the task reaches the status or fails the test
+func waitForStatus(t *testing.T, service *Service, id string, status model.TaskStatus) *model.DownloadTask {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		task, ok := service.GetTask(id)
+		if !ok {
+			t.Fatalf("task %s not found", id)
+		}
+		if task.Status == status {
+			return task
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("task %s: expected status %s, got %s (%s)", id, status, task.Status, task.LastError)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// waitForStart waits until the fake engine started downloading the URL
+func waitForStart(t *testing.T, fake *engine.Fake, url string) {
+	t.Helper()
+	for {
+		select {
+		case started := <-fake.Started():
+			if started == url {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("download of %s did not start", url)
+		}
+	}
+}
+
+func TestTaskCompletesWithFakeEngine(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	url := "https://www.youtube.com/watch?v=aaaaaaaaaaa"
+	fake.AddVideo(url, engine.FakeVideo{
+		Info: engine.VideoInfo{ID: "aaaaaaaaaaa", Title: "Fake Video", Formats: []engine.Format{{MimeType: "video/webm"}}},
+		Size: 2048,
+	})
+
+	task, err := service.AddTask(url)
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+
+	if done.Percent != 100 || done.Title != "Fake Video" {
+		t.Errorf("unexpected completed task: percent=%d title=%q", done.Percent, done.Title)
+	}
+	fi, err := os.Stat(done.OutputPath)
+	if err != nil || fi.Size() != 2048 {
+		t.Fatalf("expected 2048 byte file at %s: %v", done.OutputPath, err)
+	}
+	if want := "Fake_Video.webm"; fi.Name() != want {
+		t.Errorf("expected file name %q, got %q", want, fi.Name())
+	}
+}
+
+func TestTaskKeepsChapters(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	service.ffmpeg = false
+	url := "https://www.youtube.com/watch?v=ccccccchapt"
+	chapters := []engine.Chapter{
+		{Title: "Intro", Start: 0, End: time.Minute},
+		{Title: "Talk", Start: time.Minute, End: 2 * time.Minute},
+		{Title: "Q&A", Start: 2 * time.Minute, End: 3 * time.Minute},
+	}
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Lecture", Chapters: chapters}})
+
+	task, _ := service.AddTask(url)
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if len(done.Chapters) != 3 || done.Chapters[2] != (model.Chapter{Title: "Q&A", Start: 2 * time.Minute, End: 3 * time.Minute}) {
+		t.Errorf("unexpected chapters %+v", done.Chapters)
+	}
+}
+
+func TestTaskResolveError(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	url := "https://www.youtube.com/watch?v=bbbbbbbbbbb"
+	fake.AddVideo(url, engine.FakeVideo{ResolveErr: errors.New("video is age-restricted")})
+
+	task, _ := service.AddTask(url)
+	failed := waitForStatus(t, service, task.ID, model.TaskStatusError)
+	if failed.LastError != "Video is age-restricted - "+CookiesHint {
+		t.Errorf("unexpected error message %q", failed.LastError)
+	}
+	if fake.Downloads(url) != 0 {
+		t.Error("download must not start after a resolve error")
+	}
+}
+
+func TestTaskPauseResume(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	url := "https://www.youtube.com/watch?v=ccccccccccc"
+	hold := make(chan struct{})
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Paused"}, Hold: hold})
+
+	task, _ := service.AddTask(url)
+	waitForStart(t, fake, url)
+
+	if err := service.PauseTask(task.ID); err != nil {
+		t.Fatalf("PauseTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusPaused)
+
+	close(hold)
+	if err := service.ResumeTask(task.ID); err != nil {
+		t.Fatalf("ResumeTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if got := fake.Downloads(url); got != 2 {
+		t.Errorf("expected 2 download attempts, got %d", got)
+	}
+}
+
+func TestTaskStopAndRestart(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	url := "https://www.youtube.com/watch?v=ddddddddddd"
+	hold := make(chan struct{})
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Stopped"}, Hold: hold})
+
+	task, _ := service.AddTask(url)
+	waitForStart(t, fake, url)
+
+	if err := service.StopTask(task.ID); err != nil {
+		t.Fatalf("StopTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusStopped)
+	if err := service.ResumeTask(task.ID); err == nil {
+		t.Error("expected error resuming a stopped task")
+	}
+
+	close(hold)
+	if err := service.RestartTask(task.ID); err != nil {
+		t.Fatalf("RestartTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+}
+
+func TestTaskRetryAfterError(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	url := "https://www.youtube.com/watch?v=eeeeeeeeeee"
+	fake.AddVideo(url, engine.FakeVideo{
+		Info:        engine.VideoInfo{Title: "Flaky"},
+		DownloadErr: errors.New("network timeout"),
+		FailTimes:   1,
+	})
+
+	task, _ := service.AddTask(url)
+	failed := waitForStatus(t, service, task.ID, model.TaskStatusError)
+	if failed.LastError != "Network error - please check your connection" {
+		t.Errorf("unexpected error message %q", failed.LastError)
+	}
+
+	if err := service.RestartTask(task.ID); err != nil {
+		t.Fatalf("RestartTask: %v", err)
+	}
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if done.LastError != "" {
+		t.Errorf("expected error to be cleared, got %q", done.LastError)
+	}
+}
+
+func TestPlaylistDownloadsInOrderWithFakeEngine(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+
+	playlist := model.NewPlaylist("https://www.youtube.com/playlist?list=PLfake")
+	playlist.ID = "PLfake"
+	urls := []string{
+		"https://www.youtube.com/watch?v=fffffffff01",
+		"https://www.youtube.com/watch?v=fffffffff02",
+		"https://www.youtube.com/watch?v=fffffffff03",
+	}
+	for i, url := range urls {
+		title := "Item " + string(rune('A'+i))
+		fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: title}})
+		playlist.AddVideo(&model.PlaylistVideo{ID: url[len(url)-11:], Title: title, URL: url, Status: model.VideoStatusPending})
+	}
+	playlist.UpdateStatus(model.PlaylistStatusReady)
+
+	if err := service.AddPlaylist(playlist); err != nil {
+		t.Fatalf("AddPlaylist: %v", err)
+	}
+	if err := service.DownloadPlaylist(playlist); err != nil {
+		t.Fatalf("DownloadPlaylist: %v", err)
+	}
+
+	// One slot: items must start strictly in playlist order
+	for _, url := range urls {
+		select {
+		case started := <-fake.Started():
+			if started != url {
+				t.Fatalf("expected %s to start next, got %s", url, started)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("download of %s did not start", url)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		snapshot, _ := service.GetPlaylist(playlist.ID)
+		if snapshot.Status == model.PlaylistStatusCompleted {
+			for _, video := range snapshot.Videos {
+				if video.Status != model.VideoStatusCompleted || video.OutputPath == "" {
+					t.Errorf("video %s: status %s, output %q", video.ID, video.Status, video.OutputPath)
+				}
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("playlist did not complete, status %s", snapshot.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,157 @@
+package download
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/platform"
+)
+
+// Live broadcast constants
+const (
+	// LivePollInterval is how often an upcoming broadcast is checked for having started
+	LivePollInterval = 30 * time.Second
+	// liveCountdownInterval refreshes the countdown of a waiting task
+	liveCountdownInterval = time.Second
+	// liveNameTimeFormat is appended to recording file names so repeated recordings do not collide
+	liveNameTimeFormat = "2006-01-02 15-04-05"
+)
+
+// waitForBroadcast waits for an upcoming broadcast to start, showing a countdown
+// to its scheduled start. The task gives up its download slot while it waits,
+// so a premiere hours away does not block the queue, and takes it back when the
+// broadcast starts even if that briefly exceeds the parallel limit. It returns
+// the metadata of the started broadcast, or the context error when stopped.
+func (s *Service) waitForBroadcast(ctx context.Context, task *model.DownloadTask, extractor Extractor, info *engine.VideoInfo, opts engine.Options) (*engine.VideoInfo, error) {
+	s.tasksMutex.Lock()
+	if task.Status == model.TaskStatusDownloading {
+		task.Status = model.TaskStatusWaiting
+	}
+	task.ScheduledStart = info.ScheduledStart
+	s.activeCount--
+	s.fillSlotsLocked()
+	s.notifyUpdate(task)
+	s.tasksMutex.Unlock()
+	log.Printf("Task %s waits for a broadcast scheduled at %v", task.ID, info.ScheduledStart)
+
+	defer func() {
+		s.tasksMutex.Lock()
+		s.activeCount++
+		if task.Status == model.TaskStatusWaiting {
+			task.Status = model.TaskStatusDownloading
+		}
+		task.ScheduledStart = time.Time{}
+		s.tasksMutex.Unlock()
+	}()
+
+	s.tasksMutex.RLock()
+	poll := s.livePoll
+	s.tasksMutex.RUnlock()
+
+	tick := liveCountdownInterval
+	if poll < tick {
+		tick = poll
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	nextCheck := nextBroadcastCheck(time.Now(), info.ScheduledStart, poll)
+	for info.Live == engine.LiveUpcoming {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case now := <-ticker.C:
+			if now.Before(nextCheck) {
+				s.tasksMutex.Lock()
+				s.notifyUpdate(task)
+				s.tasksMutex.Unlock()
+				continue
+			}
+			latest, err := extractor.Resolve(ctx, task.URL, opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				// Keep waiting: the page may be briefly unavailable around the start
+				log.Printf("Task %s: checking the broadcast failed: %v", task.ID, err)
+			} else {
+				info = latest
+				s.tasksMutex.Lock()
+				task.ScheduledStart = info.ScheduledStart
+				s.notifyUpdate(task)
+				s.tasksMutex.Unlock()
+			}
+			nextCheck = nextBroadcastCheck(now, info.ScheduledStart, poll)
+		}
+	}
+	return info, nil
+}
+
+// nextBroadcastCheck returns when an upcoming broadcast is checked next: after
+// the poll interval, or at its scheduled start if that comes first
+func nextBroadcastCheck(now, scheduled time.Time, poll time.Duration) time.Time {
+	next := now.Add(poll)
+	if scheduled.After(now) && scheduled.Before(next) {
+		return scheduled
+	}
+	return next
+}
+
+// recordLive records a live broadcast until it ends or the user stops it.
+// Stopping or pausing a recording finalises the file, so the task completes
+// with the media received so far.
+func (s *Service) recordLive(ctx context.Context, task *model.DownloadTask, info *engine.VideoInfo, opts engine.Options, outputDir string) {
+	name := s.outputBase(task, info) + " " + time.Now().Format(liveNameTimeFormat)
+	outputPath := filepath.Join(outputDir, name+"."+s.recorder.RecordingExt())
+
+	// A recording has no known size, but must not start on a disk below the reserve
+	if err := s.checkDiskSpace(outputDir, 0); err != nil {
+		log.Printf("Task %s not recorded: %v", task.ID, err)
+
+		s.tasksMutex.Lock()
+		task.Status = model.TaskStatusError
+		task.LastError = err.Error()
+		task.FinishedAt = time.Now()
+		s.notifyUpdate(task)
+		s.tasksMutex.Unlock()
+		return
+	}
+
+	s.tasksMutex.Lock()
+	if task.Status == model.TaskStatusDownloading {
+		task.Status = model.TaskStatusRecording
+	}
+	task.OutputPath = outputPath
+	if info.Title != "" && (task.Title == "" || strings.HasPrefix(task.Title, "http")) {
+		task.Title = info.Title
+	}
+	s.notifyUpdate(task)
+	s.tasksMutex.Unlock()
+	log.Printf("Recording live broadcast for task %s to %s", task.ID, outputPath)
+
+	err := s.recorder.Record(ctx, info.LiveURL, outputPath, opts, func(p engine.Progress) {
+		s.tasksMutex.Lock()
+		task.Recorded = p.Recorded
+		task.FileSize = p.DownloadedSize
+		s.notifyUpdate(task)
+		s.tasksMutex.Unlock()
+	})
+
+	s.tasksMutex.Lock()
+	if err != nil {
+		s.failTaskLocked(ctx, task, err)
+	} else {
+		task.Status = model.TaskStatusCompleted
+		task.Progress = 1.0
+		task.Percent = 100
+		delete(s.stopModes, task.ID)
+		_ = platform.NotifyMediaScanner(outputPath)
+	}
+	task.FinishedAt = time.Now()
+	s.notifyUpdate(task)
+	s.tasksMutex.Unlock()
+}
@@ -0,0 +1,150 @@
+package download
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// newLiveHLSServer serves a live playlist that gains a segment on every request
+// and ends after the given number of segments, or never when it is zero
+func newLiveHLSServer(t *testing.T, segments int) *httptest.Server {
+	t.Helper()
+	var mutex sync.Mutex
+	published := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/live.m3u8" {
+			fmt.Fprintf(w, "segment %s;", r.URL.Path)
+			return
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		var b strings.Builder
+		b.WriteString("#EXTM3U\n#EXT-X-TARGETDURATION:0.02\n#EXT-X-PLAYLIST-TYPE:EVENT\n")
+		for i := range published {
+			fmt.Fprintf(&b, "#EXTINF:1.0,\nseg%d.ts\n", i)
+		}
+		if published == segments {
+			b.WriteString("#EXT-X-ENDLIST\n")
+		} else {
+			published++
+		}
+		fmt.Fprint(w, b.String())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// recordQuickly makes the service record without ffmpeg and check broadcasts quickly
+func recordQuickly(service *Service) {
+	service.recorder = &engine.Direct{}
+	service.livePoll = 20 * time.Millisecond
+}
+
+func TestServiceRecordsLiveBroadcastUntilStopped(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	recordQuickly(service)
+	server := newLiveHLSServer(t, 0)
+	url := "https://www.youtube.com/watch?v=lllllllllll"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "On Air", Live: engine.LiveNow, LiveURL: server.URL + "/live.m3u8"}})
+
+	task, _ := service.AddTask(url)
+	waitForStatus(t, service, task.ID, model.TaskStatusRecording)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		current, _ := service.GetTask(task.ID)
+		if current.Recorded >= 3*time.Second {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("recording made no progress: %+v", current)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := service.StopTask(task.ID); err != nil {
+		t.Fatalf("StopTask: %v", err)
+	}
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if !strings.HasPrefix(filepath.Base(done.OutputPath), "On_Air ") || filepath.Ext(done.OutputPath) != ".ts" {
+		t.Errorf("unexpected recording path %s", done.OutputPath)
+	}
+	data, err := os.ReadFile(done.OutputPath)
+	if err != nil || !strings.HasPrefix(string(data), "segment /seg0.ts;segment /seg1.ts;") {
+		t.Errorf("unexpected recording %q: %v", data, err)
+	}
+	if _, err := os.Stat(done.OutputPath + engine.PartSuffix); !os.IsNotExist(err) {
+		t.Error("partial recording must be finalised")
+	}
+}
+
+func TestServiceWaitsForScheduledBroadcast(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	recordQuickly(service)
+	url := "https://www.youtube.com/watch?v=uuuuuuuuuuu"
+	start := time.Now().Add(time.Hour)
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Premiere", Live: engine.LiveUpcoming, ScheduledStart: start}})
+
+	task, _ := service.AddTask(url)
+	waiting := waitForStatus(t, service, task.ID, model.TaskStatusWaiting)
+	if !waiting.ScheduledStart.Equal(start) {
+		t.Errorf("expected scheduled start %v, got %v", start, waiting.ScheduledStart)
+	}
+
+	// A waiting broadcast does not hold the only download slot
+	other := "https://www.youtube.com/watch?v=ooooooooooo"
+	fake.AddVideo(other, engine.FakeVideo{Info: engine.VideoInfo{Title: "Other"}})
+	otherTask, _ := service.AddTask(other)
+	waitForStatus(t, service, otherTask.ID, model.TaskStatusCompleted)
+
+	// The broadcast starts and ends after three segments
+	server := newLiveHLSServer(t, 3)
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Premiere", Live: engine.LiveNow, LiveURL: server.URL + "/live.m3u8"}})
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if done.Recorded != 3*time.Second || !done.ScheduledStart.IsZero() {
+		t.Errorf("unexpected recorded task %+v", done)
+	}
+
+	service.tasksMutex.RLock()
+	defer service.tasksMutex.RUnlock()
+	if service.activeCount != 0 {
+		t.Errorf("expected no active slots, got %d", service.activeCount)
+	}
+}
+
+func TestServiceStopWhileWaiting(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	recordQuickly(service)
+	url := "https://www.youtube.com/watch?v=sssssssssss"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Later", Live: engine.LiveUpcoming}})
+
+	task, _ := service.AddTask(url)
+	waitForStatus(t, service, task.ID, model.TaskStatusWaiting)
+	if err := service.PauseTask(task.ID); err != nil {
+		t.Fatalf("PauseTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusPaused)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		service.tasksMutex.RLock()
+		active := service.activeCount
+		service.tasksMutex.RUnlock()
+		if active == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the slot to be released, %d active", active)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
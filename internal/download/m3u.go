@@ -0,0 +1,50 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// M3U constants
+const (
+	M3UExtension = ".m3u8"
+	M3UHeader    = "#EXTM3U"
+	// M3UUnknownDuration is written to #EXTINF when duration is not known
+	M3UUnknownDuration = -1
+)
+
+// M3UEntry is a single media file listed in an M3U playlist
+type M3UEntry struct {
+	Title string
+	Path  string
+}
+
+// WriteM3U writes an extended M3U (UTF-8) playlist. Entry paths inside the
+// playlist directory are written relative to it so the folder stays portable.
+func WriteM3U(path string, entries []M3UEntry) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create playlist dir: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString(M3UHeader + "\n")
+	for _, entry := range entries {
+		location := entry.Path
+		if rel, err := filepath.Rel(dir, entry.Path); err == nil && !strings.HasPrefix(rel, "..") {
+			location = filepath.ToSlash(rel)
+		}
+		title := strings.ReplaceAll(entry.Title, "\n", " ")
+		b.WriteString(fmt.Sprintf("#EXTINF:%d,%s\n", M3UUnknownDuration, title))
+		b.WriteString(location + "\n")
+	}
+
+	// Write via temp file so players never see a half-written playlist
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write playlist file: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,40 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteM3U(t *testing.T) {
+	dir := t.TempDir()
+	playlistPath := filepath.Join(dir, "My_List"+M3UExtension)
+	outside := filepath.Join(t.TempDir(), "elsewhere.mp4")
+
+	entries := []M3UEntry{
+		{Title: "First", Path: filepath.Join(dir, "01_First.mp4")},
+		{Title: "Second", Path: filepath.Join(dir, "02_Second.webm")},
+		{Title: "Outside", Path: outside},
+	}
+
+	if err := WriteM3U(playlistPath, entries); err != nil {
+		t.Fatalf("WriteM3U returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(playlistPath)
+	if err != nil {
+		t.Fatalf("failed to read playlist: %v", err)
+	}
+
+	expected := "#EXTM3U\n" +
+		"#EXTINF:-1,First\n01_First.mp4\n" +
+		"#EXTINF:-1,Second\n02_Second.webm\n" +
+		"#EXTINF:-1,Outside\n" + outside + "\n"
+	if string(data) != expected {
+		t.Errorf("unexpected playlist content:\n%s\nexpected:\n%s", data, expected)
+	}
+
+	if _, err := os.Stat(playlistPath + ".tmp"); !os.IsNotExist(err) {
+		t.Error("expected temporary file to be renamed")
+	}
+}
@@ -0,0 +1,243 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// Partial downloads
+const (
+	// PartInfoSuffix names the file next to a partial download that records its
+	// source, so the download can be resumed after its task is gone
+	PartInfoSuffix = ".part.json"
+	// PartInfoPermissions keeps the source URL private
+	PartInfoPermissions = 0600
+)
+
+// ErrUnknownSource is returned when a partial file cannot be resumed because
+// its source URL was not recorded
+var ErrUnknownSource = errors.New("source of the partial download is unknown")
+
+// PartialFile is an unfinished download on disk that no task owns
+type PartialFile struct {
+	Path       string    `json:"path"`
+	OutputPath string    `json:"output_path"` // file the download was meant to become
+	Size       int64     `json:"size"`
+	Modified   time.Time `json:"modified"`
+	URL        string    `json:"url,omitempty"` // empty when unknown; only known sources can be resumed
+	Title      string    `json:"title,omitempty"`
+
+	filenamePrefix string
+}
+
+// partInfo is saved next to a partial download
+type partInfo struct {
+	URL            string `json:"url"`
+	Title          string `json:"title,omitempty"`
+	OutputPath     string `json:"output_path"`
+	FilenamePrefix string `json:"filename_prefix,omitempty"`
+}
+
+// SetTempDir sets where unfinished downloads are written. Finished files are
+// moved to their target directory; empty keeps partial files next to them.
+func (s *Service) SetTempDir(dir string) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+	s.tempDir = dir
+}
+
+// workPath returns where the download of outputPath is written before it is
+// moved into place
+func (s *Service) workPath(outputPath string) string {
+	s.tasksMutex.RLock()
+	defer s.tasksMutex.RUnlock()
+	return s.workPathLocked(outputPath)
+}
+
+// workPathLocked returns the work path of outputPath. Files in the temp dir are
+// prefixed with a hash of their target directory, so equal names from different
+// directories do not collide. Callers must hold tasksMutex.
+func (s *Service) workPathLocked(outputPath string) string {
+	if s.tempDir == "" {
+		return outputPath
+	}
+	sum := sha256.Sum256([]byte(filepath.Dir(outputPath)))
+	return filepath.Join(s.tempDir, hex.EncodeToString(sum[:4])+"-"+filepath.Base(outputPath))
+}
+
+// writePartInfo records the source of the download at workPath
+func writePartInfo(workPath string, task *model.DownloadTask, outputPath string) {
+	data, err := json.Marshal(partInfo{
+		URL:            task.URL,
+		Title:          task.Title,
+		OutputPath:     outputPath,
+		FilenamePrefix: task.FilenamePrefix,
+	})
+	if err == nil {
+		err = os.WriteFile(workPath+PartInfoSuffix, data, PartInfoPermissions)
+	}
+	if err != nil {
+		log.Printf("Failed to record the source of %s: %v", workPath, err)
+	}
+}
+
+// readPartInfo reads the source recorded for the download at workPath
+func readPartInfo(workPath string) (partInfo, bool) {
+	var info partInfo
+	data, err := os.ReadFile(workPath + PartInfoSuffix)
+	if err != nil || json.Unmarshal(data, &info) != nil {
+		return partInfo{}, false
+	}
+	return info, true
+}
+
+// removePartials deletes the unfinished data of the download at workPath
+func removePartials(workPath string) {
+	for _, path := range append(engine.PartialPaths(workPath), workPath+PartInfoSuffix) {
+		if err := os.RemoveAll(path); err != nil {
+			log.Printf("Failed to delete partial download %s: %v", path, err)
+		}
+	}
+}
+
+// OrphanedPartials lists the unfinished downloads in the download directory,
+// its subdirectories, the task output directories and the temp dir that no
+// task owns, newest first
+func (s *Service) OrphanedPartials() []PartialFile {
+	s.tasksMutex.RLock()
+	dirs := map[string]bool{s.downloadDir: true}
+	if s.tempDir != "" {
+		dirs[s.tempDir] = true
+	}
+	owned := make(map[string]bool)
+	for _, task := range s.tasks {
+		if task.OutputDir != "" {
+			dirs[task.OutputDir] = true
+		}
+		if task.OutputPath != "" {
+			owned[task.OutputPath] = true
+			owned[s.workPathLocked(task.OutputPath)] = true
+		}
+	}
+	s.tasksMutex.RUnlock()
+
+	if entries, err := os.ReadDir(s.downloadDir); err == nil {
+		for _, entry := range entries {
+			if _, partial := engine.PartialTarget(entry.Name()); entry.IsDir() && !partial {
+				dirs[filepath.Join(s.downloadDir, entry.Name())] = true
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var partials []PartialFile
+	for dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			workPath, ok := engine.PartialTarget(path)
+			if !ok || owned[workPath] || seen[workPath] {
+				continue
+			}
+			seen[workPath] = true
+			partials = append(partials, describePartial(workPath))
+		}
+	}
+	sort.Slice(partials, func(i, j int) bool { return partials[i].Modified.After(partials[j].Modified) })
+	return partials
+}
+
+// describePartial sums up the unfinished data of the download at workPath
+func describePartial(workPath string) PartialFile {
+	partial := PartialFile{Path: workPath, OutputPath: workPath}
+	for _, path := range engine.PartialPaths(workPath) {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if partial.Path == workPath {
+			partial.Path = path
+		}
+		if fi.ModTime().After(partial.Modified) {
+			partial.Modified = fi.ModTime()
+		}
+		partial.Size += pathSize(path, fi)
+	}
+	if info, ok := readPartInfo(workPath); ok {
+		partial.URL, partial.Title, partial.filenamePrefix = info.URL, info.Title, info.FilenamePrefix
+		if info.OutputPath != "" {
+			partial.OutputPath = info.OutputPath
+		}
+	}
+	return partial
+}
+
+// pathSize returns the size of a file, or of all files in a directory
+func pathSize(path string, fi os.FileInfo) int64 {
+	if !fi.IsDir() {
+		return fi.Size()
+	}
+	var size int64
+	filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// orphanedPartial returns the orphaned partial download listed at path
+func (s *Service) orphanedPartial(path string) (PartialFile, error) {
+	for _, partial := range s.OrphanedPartials() {
+		if partial.Path == path {
+			return partial, nil
+		}
+	}
+	return PartialFile{}, fmt.Errorf("no orphaned partial download at %s", path)
+}
+
+// ResumePartial queues the download an orphaned partial file belongs to; it
+// continues from the data already on disk
+func (s *Service) ResumePartial(path string) (*model.DownloadTask, error) {
+	partial, err := s.orphanedPartial(path)
+	if err != nil {
+		return nil, err
+	}
+	if partial.URL == "" {
+		return nil, ErrUnknownSource
+	}
+	return s.addTask(partial.URL, taskOptions{
+		OutputDir:      filepath.Dir(partial.OutputPath),
+		FilenamePrefix: partial.filenamePrefix,
+		Title:          partial.Title,
+	})
+}
+
+// DeletePartial deletes an orphaned partial download
+func (s *Service) DeletePartial(path string) error {
+	partial, err := s.orphanedPartial(path)
+	if err != nil {
+		return err
+	}
+	workPath, _ := engine.PartialTarget(partial.Path)
+	removePartials(workPath)
+	return nil
+}
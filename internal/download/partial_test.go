@@ -0,0 +1,130 @@
+package download
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// waitForRemoval polls until the path is gone or fails the test
+func waitForRemoval(t *testing.T, path string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s to be deleted", path)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTempDirKeepsPartialsOutOfTarget(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	tempDir := t.TempDir()
+	service.SetTempDir(tempDir)
+
+	url := "https://www.youtube.com/watch?v=tempdirtemp"
+	hold := make(chan struct{})
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Staged"}, Hold: hold})
+	task, _ := service.AddTask(url)
+	waitForStart(t, fake, url)
+
+	if entries, _ := os.ReadDir(service.downloadDir); len(entries) != 0 {
+		t.Errorf("expected nothing in the download dir while downloading, got %d entries", len(entries))
+	}
+	close(hold)
+
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if done.OutputPath != filepath.Join(service.downloadDir, "Staged.mp4") {
+		t.Errorf("unexpected output path %s", done.OutputPath)
+	}
+	if fi, err := os.Stat(done.OutputPath); err != nil || fi.Size() != engine.DefaultFakeSize {
+		t.Errorf("expected the finished file in the download dir: %v", err)
+	}
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+		t.Errorf("expected an empty temp dir, got %d entries", len(entries))
+	}
+}
+
+func TestRemoveTaskDeletesPartial(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	url := "https://www.youtube.com/watch?v=removepart1"
+	hold := make(chan struct{})
+	defer close(hold)
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Removed"}, Hold: hold})
+
+	task, _ := service.AddTask(url)
+	waitForStart(t, fake, url)
+	partPath := filepath.Join(service.downloadDir, "Removed.mp4"+engine.PartSuffix)
+	if _, err := os.Stat(partPath); err != nil {
+		t.Fatalf("expected a partial file: %v", err)
+	}
+	if orphans := service.OrphanedPartials(); len(orphans) != 0 {
+		t.Errorf("the partial file of a task is not orphaned, got %v", orphans)
+	}
+
+	if err := service.RemoveTask(task.ID, true); err != nil {
+		t.Fatalf("RemoveTask: %v", err)
+	}
+	waitForRemoval(t, partPath)
+	waitForRemoval(t, filepath.Join(service.downloadDir, "Removed.mp4"+PartInfoSuffix))
+}
+
+func TestOrphanedPartials(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	dir := service.downloadDir
+
+	// A partial with a recorded source and one left by an older version
+	resumable := filepath.Join(dir, "Intro.mp4")
+	writePartInfo(resumable, &model.DownloadTask{URL: "https://www.youtube.com/watch?v=orphanintro"}, resumable)
+	os.WriteFile(resumable+engine.PartSuffix, make([]byte, 100), 0644)
+	unknown := filepath.Join(dir, "Other.mp4")
+	os.WriteFile(unknown+engine.PartSuffix+".tmp", make([]byte, 50), 0644)
+	os.WriteFile(filepath.Join(dir, "Done.mp4"), make([]byte, 10), 0644)
+
+	orphans := service.OrphanedPartials()
+	if len(orphans) != 2 {
+		t.Fatalf("expected 2 orphaned partials, got %v", orphans)
+	}
+	byOutput := make(map[string]PartialFile)
+	for _, orphan := range orphans {
+		byOutput[orphan.OutputPath] = orphan
+	}
+	if byOutput[resumable].Size != 100 || byOutput[resumable].URL == "" || byOutput[unknown].URL != "" {
+		t.Errorf("unexpected orphans %v", orphans)
+	}
+
+	if _, err := service.ResumePartial(byOutput[unknown].Path); !errors.Is(err, ErrUnknownSource) {
+		t.Errorf("expected ErrUnknownSource, got %v", err)
+	}
+	if err := service.DeletePartial(byOutput[unknown].Path); err != nil {
+		t.Fatalf("DeletePartial: %v", err)
+	}
+	if _, err := os.Stat(byOutput[unknown].Path); !os.IsNotExist(err) {
+		t.Error("expected the partial file to be deleted")
+	}
+
+	fake.AddVideo(byOutput[resumable].URL, engine.FakeVideo{Info: engine.VideoInfo{Title: "Intro"}})
+	task, err := service.ResumePartial(byOutput[resumable].Path)
+	if err != nil {
+		t.Fatalf("ResumePartial: %v", err)
+	}
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if done.OutputPath != resumable {
+		t.Errorf("expected the download to finish at %s, got %s", resumable, done.OutputPath)
+	}
+	if orphans := service.OrphanedPartials(); len(orphans) != 0 {
+		t.Errorf("expected no orphans after resuming, got %v", orphans)
+	}
+	if err := service.DeletePartial(resumable + engine.PartSuffix); err == nil {
+		t.Error("expected an error for a path that is not an orphaned partial")
+	}
+}
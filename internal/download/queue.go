@@ -0,0 +1,314 @@
+package download
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// Queue persistence
+const (
+	QueueFilePermissions = 0600
+	queueSaveDelay       = 500 * time.Millisecond
+)
+
+// QueueEntry is a saved unfinished task
+type QueueEntry struct {
+	URL            string             `json:"url"`
+	Title          string             `json:"title,omitempty"`
+	Priority       model.TaskPriority `json:"priority,omitempty"`
+	Paused         bool               `json:"paused,omitempty"`
+	StartAt        time.Time          `json:"start_at"`
+	OutputDir      string             `json:"output_dir,omitempty"`
+	FilenamePrefix string             `json:"filename_prefix,omitempty"`
+	Proxy          string             `json:"proxy,omitempty"`
+}
+
+// queueState tracks the saved queue file; guarded by tasksMutex except for
+// saveMutex, which serializes writes
+type queueState struct {
+	path      string
+	saveTimer *time.Timer
+	saveMutex sync.Mutex
+}
+
+// inQueue reports whether a task waits in the queue and can be reordered
+func inQueue(task *model.DownloadTask) bool {
+	return task.Status.IsQueued() || task.Status == model.TaskStatusPaused
+}
+
+// GetQueue returns snapshots of the queued and paused tasks in start order
+func (s *Service) GetQueue() []*model.DownloadTask {
+	s.tasksMutex.RLock()
+	defer s.tasksMutex.RUnlock()
+
+	order := s.queueLocked()
+	snapshots := make([]*model.DownloadTask, len(order))
+	for i, task := range order {
+		snapshot := *task
+		snapshots[i] = &snapshot
+	}
+	return snapshots
+}
+
+// MoveTask moves a queued or paused task to the given position in the queue;
+// 0 is the top and a negative index the bottom
+func (s *Service) MoveTask(id string, index int) error {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	task, err := s.queuedTaskLocked(id)
+	if err != nil {
+		return err
+	}
+	s.moveLocked(task, index)
+	s.fillSlotsLocked()
+	return nil
+}
+
+// DownloadNext raises a task to high priority and moves it to the top of the
+// queue, so it starts in the next free slot. A paused task is resumed.
+func (s *Service) DownloadNext(id string) error {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	task, err := s.queuedTaskLocked(id)
+	if err != nil {
+		return err
+	}
+	task.Priority = model.PriorityHigh
+	if task.Status == model.TaskStatusPaused {
+		task.Status = model.TaskStatusPending
+		task.StartedAt = time.Now()
+	}
+	s.moveLocked(task, 0)
+	s.notifyUpdate(task)
+	s.fillSlotsLocked()
+	return nil
+}
+
+// SetTaskPriority changes the priority of a task. A queued or paused task moves
+// behind the other queued tasks of its new priority.
+func (s *Service) SetTaskPriority(id string, priority model.TaskPriority) error {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return fmt.Errorf("task not found: %s", id)
+	}
+	task.Priority = priority
+	if inQueue(task) {
+		s.placeByPriorityLocked(task)
+	}
+	s.notifyUpdate(task)
+	s.markQueueDirtyLocked()
+	s.fillSlotsLocked()
+	return nil
+}
+
+// queuedTaskLocked returns a task that may be reordered. Callers must hold tasksMutex.
+func (s *Service) queuedTaskLocked(id string) (*model.DownloadTask, error) {
+	task, exists := s.tasks[id]
+	if !exists {
+		return nil, fmt.Errorf("task not found: %s", id)
+	}
+	if !inQueue(task) {
+		return nil, fmt.Errorf("task is not queued: %s", task.Status)
+	}
+	return task, nil
+}
+
+// queueLocked returns the queued and paused tasks ordered by queue position.
+// Callers must hold tasksMutex.
+func (s *Service) queueLocked() []*model.DownloadTask {
+	var order []*model.DownloadTask
+	for _, task := range s.tasks {
+		if inQueue(task) {
+			order = append(order, task)
+		}
+	}
+	sort.Slice(order, func(i, j int) bool { return order[i].QueueSeq < order[j].QueueSeq })
+	return order
+}
+
+// placeByPriorityLocked moves a queued task behind the last queued task of the
+// same or a higher priority. Callers must hold tasksMutex.
+func (s *Service) placeByPriorityLocked(task *model.DownloadTask) {
+	index := 0
+	for i, other := range withoutTask(s.queueLocked(), task) {
+		if other.Priority >= task.Priority {
+			index = i + 1
+		}
+	}
+	s.moveLocked(task, index)
+}
+
+// moveLocked puts a queued task at the given queue position. The queued tasks
+// swap their positions among themselves, so running and finished tasks keep
+// theirs. Callers must hold tasksMutex.
+func (s *Service) moveLocked(task *model.DownloadTask, index int) {
+	current := s.queueLocked()
+	order := withoutTask(current, task)
+	if index < 0 || index > len(order) {
+		index = len(order)
+	}
+	order = append(order[:index], append([]*model.DownloadTask{task}, order[index:]...)...)
+
+	seqs := make([]uint64, len(current))
+	for i, queued := range current {
+		seqs[i] = queued.QueueSeq
+	}
+	for i, queued := range order {
+		if queued.QueueSeq != seqs[i] {
+			queued.QueueSeq = seqs[i]
+			s.notifyUpdate(queued)
+		}
+	}
+	s.markQueueDirtyLocked()
+}
+
+// withoutTask returns a copy of the order without the task
+func withoutTask(order []*model.DownloadTask, task *model.DownloadTask) []*model.DownloadTask {
+	rest := make([]*model.DownloadTask, 0, len(order))
+	for _, other := range order {
+		if other != task {
+			rest = append(rest, other)
+		}
+	}
+	return rest
+}
+
+// LoadQueue restores the tasks saved in the queue file and keeps the file up to
+// date from now on. Saved tasks are queued again in their saved order; playlist
+// videos come back as single downloads. A missing file restores nothing.
+func (s *Service) LoadQueue(path string) ([]*model.DownloadTask, error) {
+	s.tasksMutex.Lock()
+	s.queue.path = path
+	s.tasksMutex.Unlock()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []QueueEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse queue file: %w", err)
+	}
+
+	var restored []*model.DownloadTask
+	for _, entry := range entries {
+		task, err := s.addTask(entry.URL, taskOptions{
+			OutputDir:      entry.OutputDir,
+			FilenamePrefix: entry.FilenamePrefix,
+			StartAt:        entry.StartAt,
+			Priority:       entry.Priority,
+			Title:          entry.Title,
+			Proxy:          entry.Proxy,
+			Paused:         entry.Paused,
+			Append:         true,
+		})
+		if err != nil {
+			log.Printf("Failed to restore queued task %s: %v", entry.URL, err)
+			continue
+		}
+		restored = append(restored, task)
+	}
+	return restored, nil
+}
+
+// SaveQueue writes the unfinished tasks to the queue file right away
+func (s *Service) SaveQueue() error {
+	s.queue.saveMutex.Lock()
+	defer s.queue.saveMutex.Unlock()
+
+	s.tasksMutex.Lock()
+	if s.queue.saveTimer != nil {
+		s.queue.saveTimer.Stop()
+		s.queue.saveTimer = nil
+	}
+	path := s.queue.path
+	entries := s.queueEntriesLocked()
+	s.tasksMutex.Unlock()
+
+	if path == "" {
+		return nil
+	}
+	return writeQueueFile(path, entries)
+}
+
+// markQueueDirtyLocked saves the queue file shortly, so bursts of changes are
+// written once. Callers must hold tasksMutex.
+func (s *Service) markQueueDirtyLocked() {
+	if s.queue.path == "" || s.queue.saveTimer != nil {
+		return
+	}
+	s.queue.saveTimer = time.AfterFunc(queueSaveDelay, func() {
+		if err := s.SaveQueue(); err != nil {
+			log.Printf("Failed to save download queue: %v", err)
+		}
+	})
+}
+
+// queueEntriesLocked returns the unfinished tasks in queue order; running tasks
+// are saved so they start again. Callers must hold tasksMutex.
+func (s *Service) queueEntriesLocked() []QueueEntry {
+	var tasks []*model.DownloadTask
+	for _, task := range s.tasks {
+		if inQueue(task) || task.Status.IsActive() {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].QueueSeq < tasks[j].QueueSeq })
+
+	entries := make([]QueueEntry, 0, len(tasks))
+	for _, task := range tasks {
+		entries = append(entries, QueueEntry{
+			URL:            task.URL,
+			Title:          task.Title,
+			Priority:       task.Priority,
+			Paused:         task.Status == model.TaskStatusPaused,
+			StartAt:        task.StartAt,
+			OutputDir:      task.OutputDir,
+			FilenamePrefix: task.FilenamePrefix,
+			Proxy:          task.Proxy,
+		})
+	}
+	return entries
+}
+
+// writeQueueFile replaces the queue file; a failed write keeps the old one
+func writeQueueFile(path string, entries []QueueEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create queue file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(QueueFilePermissions); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write queue file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,137 @@
+package download
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// queueURLs returns the URLs of the queued tasks in start order
+func queueURLs(service *Service) []string {
+	var urls []string
+	for _, task := range service.GetQueue() {
+		urls = append(urls, task.URL)
+	}
+	return urls
+}
+
+func assertQueue(t *testing.T, service *Service, want ...string) {
+	t.Helper()
+	got := queueURLs(service)
+	if len(got) != len(want) {
+		t.Fatalf("expected queue %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected queue %v, got %v", want, got)
+		}
+	}
+}
+
+func TestQueueOrdersByPriorityThenArrival(t *testing.T) {
+	service, _ := newFakeService(t, 1)
+	service.SetDownloadWindow(closedWindow())
+
+	const (
+		a = "https://www.youtube.com/watch?v=aaaaaaaaaaa"
+		b = "https://www.youtube.com/watch?v=bbbbbbbbbbb"
+		c = "https://www.youtube.com/watch?v=ccccccccccc"
+		d = "https://www.youtube.com/watch?v=ddddddddddd"
+	)
+	service.addTask(a, taskOptions{})
+	service.addTask(b, taskOptions{Priority: model.PriorityLow})
+	service.addTask(c, taskOptions{})
+	service.addTask(d, taskOptions{Priority: model.PriorityHigh})
+	assertQueue(t, service, d, a, c, b)
+
+	// Lowering a task moves it behind the tasks of its new priority
+	task, _ := service.GetTaskByVideoID("ddddddddddd")
+	if err := service.SetTaskPriority(task.ID, model.PriorityLow); err != nil {
+		t.Fatalf("SetTaskPriority: %v", err)
+	}
+	assertQueue(t, service, a, c, b, d)
+}
+
+func TestMoveTaskAndDownloadNext(t *testing.T) {
+	service, _ := newFakeService(t, 1)
+	service.SetDownloadWindow(closedWindow())
+
+	const (
+		a = "https://www.youtube.com/watch?v=aaaaaaaaaaa"
+		b = "https://www.youtube.com/watch?v=bbbbbbbbbbb"
+		c = "https://www.youtube.com/watch?v=ccccccccccc"
+	)
+	first, _ := service.AddTask(a)
+	service.AddTask(b)
+	last, _ := service.AddTask(c)
+
+	if err := service.MoveTask(first.ID, -1); err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+	assertQueue(t, service, b, c, a)
+	if err := service.MoveTask(first.ID, 1); err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+	assertQueue(t, service, b, a, c)
+
+	if err := service.PauseTask(last.ID); err != nil {
+		t.Fatalf("PauseTask: %v", err)
+	}
+	if err := service.DownloadNext(last.ID); err != nil {
+		t.Fatalf("DownloadNext: %v", err)
+	}
+	assertQueue(t, service, c, b, a)
+	next, _ := service.GetTask(last.ID)
+	if next.Priority != model.PriorityHigh || !next.Status.IsQueued() {
+		t.Errorf("expected a queued high-priority task, got %s with %s priority", next.Status, next.Priority)
+	}
+
+	if err := service.StopTask(first.ID); err != nil {
+		t.Fatalf("StopTask: %v", err)
+	}
+	if err := service.MoveTask(first.ID, 0); err == nil {
+		t.Error("stopped tasks must not be moved")
+	}
+}
+
+func TestQueueSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	service, _ := newFakeService(t, 1)
+	service.SetDownloadWindow(closedWindow())
+	if restored, err := service.LoadQueue(path); err != nil || restored != nil {
+		t.Fatalf("expected nothing to restore, got %v, %v", restored, err)
+	}
+
+	const (
+		a = "https://www.youtube.com/watch?v=aaaaaaaaaaa"
+		b = "https://www.youtube.com/watch?v=bbbbbbbbbbb"
+		c = "https://www.youtube.com/watch?v=ccccccccccc"
+	)
+	service.AddTask(a)
+	paused, _ := service.AddTask(b)
+	service.addTask(c, taskOptions{Priority: model.PriorityHigh})
+	if err := service.PauseTask(paused.ID); err != nil {
+		t.Fatalf("PauseTask: %v", err)
+	}
+	if err := service.SaveQueue(); err != nil {
+		t.Fatalf("SaveQueue: %v", err)
+	}
+
+	restarted, _ := newFakeService(t, 1)
+	restarted.SetDownloadWindow(closedWindow())
+	restored, err := restarted.LoadQueue(path)
+	if err != nil {
+		t.Fatalf("LoadQueue: %v", err)
+	}
+	if len(restored) != 3 {
+		t.Fatalf("expected 3 restored tasks, got %d", len(restored))
+	}
+	assertQueue(t, restarted, c, a, b)
+	if task, _ := restarted.GetTaskByVideoID("bbbbbbbbbbb"); task.Status != model.TaskStatusPaused {
+		t.Errorf("expected the paused task to stay paused, got %s", task.Status)
+	}
+	if task, _ := restarted.GetTaskByVideoID("ccccccccccc"); task.Priority != model.PriorityHigh {
+		t.Errorf("expected high priority to be restored, got %s", task.Priority)
+	}
+}
@@ -0,0 +1,140 @@
+package download
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/schedule"
+)
+
+// AddTaskAt adds a download task that does not start before the given time.
+// A zero time behaves like AddTask.
+func (s *Service) AddTaskAt(url string, startAt time.Time) (*model.DownloadTask, error) {
+	return s.addTask(url, taskOptions{StartAt: startAt})
+}
+
+// ScheduleTask sets when a queued, paused, stopped or failed task starts; a zero
+// time starts it as soon as a slot is free. The task rejoins the queue at its
+// original position.
+func (s *Service) ScheduleTask(id string, startAt time.Time) error {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return fmt.Errorf("task not found: %s", id)
+	}
+	if task.Status.IsActive() || task.Status == model.TaskStatusCompleted {
+		return fmt.Errorf("task cannot be scheduled in status: %s", task.Status)
+	}
+
+	task.StartAt = startAt
+	if !task.Status.IsQueued() {
+		task.Status = model.TaskStatusPending
+		task.LastError = ""
+		task.FinishedAt = time.Time{}
+		delete(s.stopModes, id)
+	}
+	s.notifyUpdate(task)
+	s.fillSlotsLocked()
+	return nil
+}
+
+// SchedulePlaylist sets when the unfinished videos of a playlist start; a zero
+// time starts them as soon as slots are free
+func (s *Service) SchedulePlaylist(playlistID string, startAt time.Time) error {
+	s.playlistsMutex.Lock()
+	defer s.playlistsMutex.Unlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return fmt.Errorf("playlist not found: %s", playlistID)
+	}
+	playlist.StartAt = startAt
+
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	for _, video := range playlist.Videos {
+		if task, ok := s.tasks[video.TaskID]; ok && task.Status.IsQueued() {
+			task.StartAt = startAt
+			s.notifyUpdate(task)
+		}
+	}
+	s.fillSlotsLocked()
+	s.publishPlaylistLocked(playlist)
+	return nil
+}
+
+// SetDownloadWindow sets the daily time window in which queued tasks may start.
+// Running downloads are not interrupted when the window closes.
+func (s *Service) SetDownloadWindow(window schedule.Window) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	s.window = window
+	s.fillSlotsLocked()
+}
+
+// scheduleLocked moves queued tasks between Pending and Scheduled according to
+// their start time and the download window, and arms a timer for the next
+// change. Callers must hold tasksMutex.
+func (s *Service) scheduleLocked() {
+	now := time.Now()
+	var wake time.Time
+	queued := false
+	for _, task := range s.tasks {
+		if !task.Status.IsQueued() {
+			continue
+		}
+		queued = true
+
+		start := now
+		if task.StartAt.After(now) {
+			start = task.StartAt
+		}
+		start = s.window.NextOpen(start)
+
+		if !start.After(now) {
+			if task.Status == model.TaskStatusScheduled {
+				task.Status = model.TaskStatusPending
+				task.ScheduledStart = time.Time{}
+				s.notifyUpdate(task)
+			}
+			continue
+		}
+		if task.Status != model.TaskStatusScheduled || !task.ScheduledStart.Equal(start) {
+			task.Status = model.TaskStatusScheduled
+			task.ScheduledStart = start
+			s.notifyUpdate(task)
+		}
+		if wake.IsZero() || start.Before(wake) {
+			wake = start
+		}
+	}
+
+	// Queued tasks turn Scheduled when the window closes
+	if change := s.window.NextChange(now); queued && !change.IsZero() && (wake.IsZero() || change.Before(wake)) {
+		wake = change
+	}
+	s.armScheduleTimerLocked(wake, now)
+}
+
+// armScheduleTimerLocked reschedules the queue at the given time; a zero time
+// cancels the pending check. Callers must hold tasksMutex.
+func (s *Service) armScheduleTimerLocked(wake, now time.Time) {
+	if s.scheduleTimer != nil {
+		s.scheduleTimer.Stop()
+		s.scheduleTimer = nil
+	}
+	if wake.IsZero() {
+		return
+	}
+	s.scheduleTimer = time.AfterFunc(wake.Sub(now), func() {
+		s.tasksMutex.Lock()
+		defer s.tasksMutex.Unlock()
+
+		s.fillSlotsLocked()
+	})
+}
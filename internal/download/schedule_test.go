@@ -0,0 +1,93 @@
+package download
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/schedule"
+)
+
+// closedWindow returns a download window that opens in two hours
+func closedWindow() schedule.Window {
+	now := time.Now()
+	offset := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	return schedule.Window{Enabled: true, Start: (offset + 2*time.Hour) % schedule.Day, End: (offset + 3*time.Hour) % schedule.Day}
+}
+
+func TestAddTaskAtStartsAtRequestedTime(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	url := "https://www.youtube.com/watch?v=ddddddeferr"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Deferred"}})
+
+	startAt := time.Now().Add(200 * time.Millisecond)
+	task, err := service.AddTaskAt(url, startAt)
+	if err != nil {
+		t.Fatalf("AddTaskAt: %v", err)
+	}
+	if task.Status != model.TaskStatusScheduled || !task.ScheduledStart.Equal(startAt) {
+		t.Fatalf("expected a Scheduled task starting at %v, got %s at %v", startAt, task.Status, task.ScheduledStart)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if fake.Downloads(url) != 0 {
+		t.Fatal("download started before the requested time")
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if time.Now().Before(startAt) {
+		t.Error("task completed before the requested start time")
+	}
+}
+
+func TestDownloadWindowHoldsQueuedTasks(t *testing.T) {
+	service, fake := newFakeService(t, 2)
+	window := closedWindow()
+	service.SetDownloadWindow(window)
+	url := "https://www.youtube.com/watch?v=wwwwwwindow"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Night"}})
+
+	task, _ := service.AddTask(url)
+	if task.Status != model.TaskStatusScheduled || !window.Contains(task.ScheduledStart) {
+		t.Fatalf("expected the task to wait for the window, got %s at %v", task.Status, task.ScheduledStart)
+	}
+
+	// Pausing and resuming keeps it out of the queue until the window opens
+	if err := service.PauseTask(task.ID); err != nil {
+		t.Fatalf("PauseTask: %v", err)
+	}
+	if err := service.ResumeTask(task.ID); err != nil {
+		t.Fatalf("ResumeTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusScheduled)
+
+	service.SetDownloadWindow(schedule.Window{})
+	waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+}
+
+func TestScheduleTaskRequeuesStoppedTask(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	service.SetDownloadWindow(closedWindow())
+	url := "https://www.youtube.com/watch?v=rrrrrrequeu"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Later"}})
+
+	task, _ := service.AddTask(url)
+	if err := service.StopTask(task.ID); err != nil {
+		t.Fatalf("StopTask: %v", err)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusStopped)
+
+	service.SetDownloadWindow(schedule.Window{})
+	startAt := time.Now().Add(50 * time.Millisecond)
+	if err := service.ScheduleTask(task.ID, startAt); err != nil {
+		t.Fatalf("ScheduleTask: %v", err)
+	}
+	scheduled := waitForStatus(t, service, task.ID, model.TaskStatusScheduled)
+	if !scheduled.StartAt.Equal(startAt) {
+		t.Errorf("expected StartAt %v, got %v", startAt, scheduled.StartAt)
+	}
+	waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+
+	if err := service.ScheduleTask(task.ID, time.Time{}); err == nil {
+		t.Error("completed tasks must not be scheduled")
+	}
+}
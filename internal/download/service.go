@@ -2,8 +2,13 @@ package download
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,10 +16,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ytget/yt-downloader/internal/compress"
+	"github.com/ytget/yt-downloader/internal/engine"
 	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/network"
 	"github.com/ytget/yt-downloader/internal/platform"
-	"github.com/ytget/ytdlp/types"
-	"github.com/ytget/ytdlp/v2"
+	"github.com/ytget/yt-downloader/internal/schedule"
+	"github.com/ytget/yt-downloader/internal/sponsorblock"
 )
 
 // min returns the minimum of two integers
@@ -31,17 +39,77 @@ type Service struct {
 	tasksMutex  sync.RWMutex
 	maxParallel int
 	activeCount int
+	queueSeq    uint64 // last queue position handed out; guarded by tasksMutex
 	downloadDir string
 	onUpdate    func(*model.DownloadTask) // callback for UI updates
 
+	// Event subscriptions; lastStatus classifies updates and is guarded by tasksMutex
+	events     *eventBus
+	lastStatus map[string]model.TaskStatus
+
+	// cancels aborts running downloads on stop or pause; guarded by tasksMutex
+	cancels map[string]context.CancelFunc
+
+	// engine resolves and downloads YouTube media; extractors route URLs of all sites
+	engine     engine.Engine
+	extractors *Registry
+
+	// recorder records live broadcasts; livePoll is how often an upcoming one is checked
+	recorder *engine.Direct
+	livePoll time.Duration
+
+	// ffmpeg reports whether chapters can be embedded and segments cut out of finished files
+	ffmpeg bool
+
+	// sponsorBlock selects the segments removed or marked after a download; guarded by tasksMutex
+	sponsorBlock sponsorblock.Config
+
 	// Quality preset: "best" | "medium" | "audio"
 	qualityPreset string
 
+	// proxy is applied to metadata resolution and media transfer; guarded by tasksMutex
+	proxy network.ProxyConfig
+
+	// cookies are imported browser cookies sent with every request; guarded by tasksMutex
+	cookies []*http.Cookie
+
+	// segments is the requested number of connections per file download,
+	// bounded by ClampSegments when a download starts; guarded by tasksMutex
+	segments int
+
+	// window limits when queued tasks may start; scheduleTimer wakes the queue at the
+	// next start time or window change. Both are guarded by tasksMutex.
+	window        schedule.Window
+	scheduleTimer *time.Timer
+
+	// queue saves unfinished tasks so the queue survives a restart
+	queue queueState
+
+	// diskReserve is the free space downloads must leave; diskMonitorStop ends the
+	// low-space monitor. Both are guarded by tasksMutex.
+	diskReserve       int64
+	diskMonitorStop   chan struct{}
+	diskCheckInterval time.Duration
+	freeSpace         func(path string) (uint64, error)
+
+	// collision decides what happens to an existing output file; guarded by tasksMutex.
+	// probeMedia reads media files to verify them, nil without ffprobe.
+	collision  platform.CollisionPolicy
+	probeMedia func(ctx context.Context, path string) (compress.MediaInfo, error)
+
+	// verifyMode decides whether finished downloads are checked; verifyRetries
+	// counts the downloads repeated per task. Both are guarded by tasksMutex.
+	verifyMode    VerifyMode
+	verifyRetries map[string]int
+
+	// tempDir holds unfinished downloads when set; discardPartials maps removed
+	// running tasks to the work path deleted once they stop. Both are guarded by tasksMutex.
+	tempDir         string
+	discardPartials map[string]string
+
 	// Playlist support
-	playlists           map[string]*model.Playlist
-	playlistsMutex      sync.RWMutex
-	playlistQueue       chan *model.Playlist
-	maxPlaylistParallel int
+	playlists      map[string]*model.Playlist
+	playlistsMutex sync.RWMutex
 
 	// Internal progress state for speed calculation (delta-based)
 	progressState map[string]struct {
@@ -210,9 +278,10 @@ func (s *Service) updateSmoothedUI(taskID string) {
 	// Restart timer if task is still downloading
 	s.tasksMutex.RLock()
 	task, exists := s.tasks[taskID]
+	downloading := exists && task.Status == model.TaskStatusDownloading
 	s.tasksMutex.RUnlock()
 
-	if exists && task.Status == model.TaskStatusDownloading {
+	if downloading {
 		state.mutex.Lock()
 		if state.timer != nil {
 			state.timer.Stop()
@@ -224,18 +293,37 @@ func (s *Service) updateSmoothedUI(taskID string) {
 	}
 }
 
-// NewService creates a new download service
+// NewService creates a new download service backed by the ytdlp engine
 func NewService(downloadDir string, maxParallel int) Downloader {
+	return NewServiceWithEngine(downloadDir, maxParallel, engine.NewYTDLP())
+}
+
+// NewServiceWithEngine creates a download service that uses the given engine,
+// e.g. a fake engine in tests
+func NewServiceWithEngine(downloadDir string, maxParallel int, eng engine.Engine) Downloader {
 	return &Service{
+		engine:        eng,
+		extractors:    NewRegistry(DefaultExtractors(eng)...),
+		recorder:      engine.NewDirect(),
+		livePoll:      LivePollInterval,
+		freeSpace:     platform.FreeSpace,
+		ffmpeg:        compress.FFmpegAvailable(),
+		probeMedia:    probeMedia(),
+		collision:     platform.CollisionVerify,
 		tasks:         make(map[string]*model.DownloadTask),
 		maxParallel:   maxParallel,
 		downloadDir:   downloadDir,
 		qualityPreset: "best",
+		proxy:         network.ProxyConfig{Mode: network.ProxySystem},
+		segments:      1,
+		sponsorBlock:  sponsorblock.Config{Mode: sponsorblock.ModeOff},
+
+		events:     newEventBus(),
+		lastStatus: make(map[string]model.TaskStatus),
+		cancels:    make(map[string]context.CancelFunc),
 
 		// Playlist support
-		playlists:           make(map[string]*model.Playlist),
-		playlistQueue:       make(chan *model.Playlist, 10),
-		maxPlaylistParallel: 3, // Limit concurrent playlist downloads
+		playlists: make(map[string]*model.Playlist),
 
 		progressState: make(map[string]struct {
 			lastBytes int64
@@ -245,6 +333,13 @@ func NewService(downloadDir string, maxParallel int) Downloader {
 		smoothingState: make(map[string]*SmoothingState),
 
 		stopModes: make(map[string]StopMode),
+
+		discardPartials: make(map[string]string),
+
+		verifyMode:    VerifyOff,
+		verifyRetries: make(map[string]int),
+
+		diskCheckInterval: DiskCheckInterval,
 	}
 }
 
@@ -257,19 +352,54 @@ const (
 	StopModeStop
 )
 
-// SetUpdateCallback sets the callback function for task updates
+// SetUpdateCallback sets the callback function for task updates.
+// The callback runs while the service lock is held and receives the live task;
+// prefer Subscribe for consumers that read tasks later or from other goroutines.
 func (s *Service) SetUpdateCallback(callback func(*model.DownloadTask)) {
 	s.onUpdate = callback
 }
 
+// Subscribe returns a channel of task and playlist events and a function to stop receiving them.
+// Events are delivered in order; consecutive progress updates of a task may be coalesced.
+func (s *Service) Subscribe() (<-chan Event, func()) {
+	return s.events.subscribe()
+}
+
 // AddTask adds a new download task
 func (s *Service) AddTask(url string) (*model.DownloadTask, error) {
+	return s.addTask(url, taskOptions{})
+}
+
+// taskOptions are the optional settings of a new task
+type taskOptions struct {
+	PlaylistID     string    // owning playlist
+	OutputDir      string    // target directory override
+	FilenamePrefix string    // prepended to the file name
+	StartAt        time.Time // defers the start when non-zero
+	Priority       model.TaskPriority
+
+	// Restored tasks keep their saved state and join the end of the queue
+	Title  string
+	Proxy  string
+	Paused bool
+	Append bool
+}
+
+// addTask adds a new download task. It joins the queue after the queued tasks
+// of the same or a higher priority unless opts.Append is set.
+func (s *Service) addTask(url string, opts taskOptions) (*model.DownloadTask, error) {
 	s.tasksMutex.Lock()
 	defer s.tasksMutex.Unlock()
 
-	// Check for duplicate URLs
+	extractor, err := s.extractors.Find(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check for duplicates; different links to the same video match
+	videoID := s.extractVideoID(url)
 	for _, task := range s.tasks {
-		if task.URL == url && !task.Status.IsFinished() {
+		if s.extractVideoID(task.URL) == videoID && !task.Status.IsFinished() {
 			return nil, fmt.Errorf("task already exists for URL: %s", url)
 		}
 	}
@@ -277,40 +407,62 @@ func (s *Service) AddTask(url string) (*model.DownloadTask, error) {
 	task := &model.DownloadTask{
 		ID:        generateTaskID(),
 		URL:       url,
-		Title:     "", // Leave empty; UI will fallback to URL until real title arrives
+		Site:      extractor.Name(),
+		Title:     opts.Title, // Usually empty; UI will fallback to URL until real title arrives
 		Status:    model.TaskStatusPending,
 		Progress:  0.0,
 		Percent:   0,
 		ETASec:    -1,
 		StartedAt: time.Now(),
+
+		OutputDir:      opts.OutputDir,
+		FilenamePrefix: opts.FilenamePrefix,
+
+		Priority:   opts.Priority,
+		PlaylistID: opts.PlaylistID,
+		StartAt:    opts.StartAt,
+		Proxy:      opts.Proxy,
+	}
+	if opts.Paused {
+		task.Status = model.TaskStatusPaused
 	}
 
+	s.queueSeq++
+	task.QueueSeq = s.queueSeq
 	s.tasks[task.ID] = task
+	if !opts.Append {
+		s.placeByPriorityLocked(task)
+	}
+	s.notifyUpdate(task)
 
 	// Try to start task if we have capacity
-	if s.activeCount < s.maxParallel {
-		go s.startTask(task)
-	}
+	s.fillSlotsLocked()
 
-	return task, nil
+	snapshot := *task
+	return &snapshot, nil
 }
 
-// GetTask returns a task by ID
+// GetTask returns a snapshot of a task by ID
 func (s *Service) GetTask(id string) (*model.DownloadTask, bool) {
 	s.tasksMutex.RLock()
 	defer s.tasksMutex.RUnlock()
 	task, exists := s.tasks[id]
-	return task, exists
+	if !exists {
+		return nil, false
+	}
+	snapshot := *task
+	return &snapshot, true
 }
 
-// GetAllTasks returns all tasks
+// GetAllTasks returns snapshots of all tasks
 func (s *Service) GetAllTasks() []*model.DownloadTask {
 	s.tasksMutex.RLock()
 	defer s.tasksMutex.RUnlock()
 
 	tasks := make([]*model.DownloadTask, 0, len(s.tasks))
 	for _, task := range s.tasks {
-		tasks = append(tasks, task)
+		snapshot := *task
+		tasks = append(tasks, &snapshot)
 	}
 	return tasks
 }
@@ -323,7 +475,8 @@ func (s *Service) GetTaskByVideoID(videoID string) (*model.DownloadTask, bool) {
 
 	for _, task := range s.tasks {
 		if s.extractVideoID(task.URL) == videoID {
-			return task, true
+			snapshot := *task
+			return &snapshot, true
 		}
 	}
 	return nil, false
@@ -342,10 +495,10 @@ func (s *Service) StopTask(id string) error {
 
 	log.Printf("StopTask called for task %s with status: %s", id, task.Status)
 
-	// If task is pending, paused, or in error state, resolve to Stopped immediately
-	if task.Status == model.TaskStatusPending ||
+	// If task is queued, paused, or in error state, resolve to Stopped immediately
+	if task.Status.IsQueued() ||
 		task.Status == model.TaskStatusPaused ||
-		task.Status == model.TaskStatusError {
+		task.Status.IsFailed() {
 		log.Printf("Task %s: converting from %s to Stopped immediately", id, task.Status)
 		task.Status = model.TaskStatusStopped
 		task.LastError = "" // Clear error when converting to stopped
@@ -364,6 +517,7 @@ func (s *Service) StopTask(id string) error {
 	// Set stopping status and mark mode as hard stop
 	task.Status = model.TaskStatusStopping
 	s.stopModes[id] = StopModeStop
+	s.cancelDownloadLocked(id)
 	s.notifyUpdate(task)
 
 	// The actual stopping will be handled in the task goroutine
@@ -372,6 +526,7 @@ func (s *Service) StopTask(id string) error {
 
 // PauseTask requests pausing a running task.
 // Active tasks are transitioned to Stopping and will end as Paused.
+// Queued tasks are paused immediately and keep their queue position.
 func (s *Service) PauseTask(id string) error {
 	s.tasksMutex.Lock()
 	defer s.tasksMutex.Unlock()
@@ -381,6 +536,12 @@ func (s *Service) PauseTask(id string) error {
 		return fmt.Errorf("task not found: %s", id)
 	}
 
+	if task.Status.IsQueued() {
+		task.Status = model.TaskStatusPaused
+		s.notifyUpdate(task)
+		return nil
+	}
+
 	if !task.Status.IsActive() {
 		// Not running; nothing to pause
 		return nil
@@ -388,6 +549,7 @@ func (s *Service) PauseTask(id string) error {
 
 	task.Status = model.TaskStatusStopping
 	s.stopModes[id] = StopModePause
+	s.cancelDownloadLocked(id)
 	s.notifyUpdate(task)
 	return nil
 }
@@ -408,15 +570,14 @@ func (s *Service) ResumeTask(id string) error {
 		return fmt.Errorf("task is not paused: %s", task.Status)
 	}
 
-	// Reset to pending state
+	// Reset to pending state; clear the reason it was paused, e.g. low disk space
 	task.Status = model.TaskStatusPending
+	task.LastError = ""
 	task.StartedAt = time.Now() // Update start time for new attempt
 	s.notifyUpdate(task)
 
-	// Try to start if we have capacity
-	if s.activeCount < s.maxParallel {
-		go s.startTask(task)
-	}
+	// Try to start if we have capacity; the task keeps its queue position
+	s.fillSlotsLocked()
 
 	return nil
 }
@@ -432,10 +593,10 @@ func (s *Service) RestartTask(id string) error {
 	}
 
 	// Can only restart failed, stopped, paused, or pending tasks
-	if task.Status != model.TaskStatusError &&
+	if !task.Status.IsFailed() &&
 		task.Status != model.TaskStatusStopped &&
 		task.Status != model.TaskStatusPaused &&
-		task.Status != model.TaskStatusPending {
+		!task.Status.IsQueued() {
 		return fmt.Errorf("task cannot be restarted in status: %s", task.Status)
 	}
 
@@ -451,16 +612,15 @@ func (s *Service) RestartTask(id string) error {
 
 	s.notifyUpdate(task)
 
-	// Try to start if we have capacity
-	if s.activeCount < s.maxParallel {
-		go s.startTask(task)
-	}
+	// Try to start if we have capacity; the task keeps its queue position
+	s.fillSlotsLocked()
 
 	return nil
 }
 
-// RemoveTask removes a task from the service
-func (s *Service) RemoveTask(id string) error {
+// RemoveTask removes a task from the service. With deletePartial the data of an
+// unfinished download is deleted as well.
+func (s *Service) RemoveTask(id string, deletePartial bool) error {
 	s.tasksMutex.Lock()
 	defer s.tasksMutex.Unlock()
 
@@ -469,71 +629,80 @@ func (s *Service) RemoveTask(id string) error {
 		return fmt.Errorf("task not found: %s", id)
 	}
 
+	// Delete the unfinished data; a running task does so once it has stopped
+	if deletePartial && task.OutputPath != "" && task.Status != model.TaskStatusCompleted {
+		workPath := s.workPathLocked(task.OutputPath)
+		if task.Status.IsActive() {
+			s.discardPartials[id] = workPath
+		} else {
+			removePartials(workPath)
+		}
+	}
+
 	// Stop the task if it's active
 	if task.Status.IsActive() {
 		task.Status = model.TaskStatusStopping
+		s.cancelDownloadLocked(id)
+		s.notifyUpdate(task)
+	} else if !task.Status.IsFinished() {
+		// Let subscribers such as playlists know the queued task is gone
+		task.Status = model.TaskStatusStopped
 		s.notifyUpdate(task)
 	}
 
 	// Remove from tasks map
 	delete(s.tasks, id)
+	delete(s.lastStatus, id)
+	delete(s.verifyRetries, id)
 
 	return nil
 }
 
-// startTask starts downloading a task
+// startTask downloads a task whose slot was reserved by launchLocked
 func (s *Service) startTask(task *model.DownloadTask) {
-	s.tasksMutex.Lock()
-	s.activeCount++
-	task.Status = model.TaskStatusStarting
-	s.tasksMutex.Unlock()
-
-	s.notifyUpdate(task)
-
 	defer func() {
 		s.tasksMutex.Lock()
 		s.activeCount--
+		// Hand the freed slot to the next task in the queue
+		s.fillSlotsLocked()
+		workPath, discard := s.discardPartials[task.ID]
+		delete(s.discardPartials, task.ID)
 		s.tasksMutex.Unlock()
 
-		// Try to start next pending task
-		s.startNextPendingTask()
+		if discard {
+			removePartials(workPath)
+		}
 	}()
 
-	// Update status to downloading
+	// Update status to downloading unless a stop arrived while starting
 	s.tasksMutex.Lock()
-	task.Status = model.TaskStatusDownloading
-	s.tasksMutex.Unlock()
+	if task.Status == model.TaskStatusStarting {
+		task.Status = model.TaskStatusDownloading
+	}
 	s.notifyUpdate(task)
 
+	// Create context with cancellation; StopTask and PauseTask cancel it directly
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancels[task.ID] = cancel
+	if task.Status == model.TaskStatusStopping {
+		// A stop request arrived while the task was starting
+		cancel()
+	}
+	s.tasksMutex.Unlock()
+
+	defer func() {
+		s.tasksMutex.Lock()
+		delete(s.cancels, task.ID)
+		s.tasksMutex.Unlock()
+		cancel()
+	}()
+
 	// Start smoothing timer for this task
 	s.startSmoothingTimer(task.ID)
 
 	log.Printf("Starting download for task %s: %s", task.ID, task.URL)
 
-	// Create context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Monitor for stop requests
-	go func() {
-		for {
-			s.tasksMutex.RLock()
-			status := task.Status
-			s.tasksMutex.RUnlock()
-
-			if status == model.TaskStatusStopping {
-				log.Printf("Task %s requested to stop, canceling download", task.ID)
-				cancel()
-				return
-			}
-			if status.IsFinished() {
-				return
-			}
-			time.Sleep(100 * time.Millisecond)
-		}
-	}()
-
-	// Configure new ytdlp downloader (pure Go)
+	// Configure the engine format selection
 	quality := "best"
 	ext := ""
 	switch s.qualityPreset {
@@ -545,10 +714,33 @@ func (s *Service) startTask(task *model.DownloadTask) {
 		quality, ext = "best", "" // MVP: keep progressive best; audio-only later
 	}
 
-	d := ytdlp.New().WithFormat(quality, ext)
+	// Route resolution and transfer through the task or global proxy
+	s.tasksMutex.RLock()
+	proxy, proxyOverride, cookies := s.proxy, task.Proxy, s.cookies
+	segments := ClampSegments(s.segments, s.maxParallel)
+	preset, verifyMode := s.qualityPreset, s.verifyMode
+	s.tasksMutex.RUnlock()
+	client, clientErr := newHTTPClient(proxy, proxyOverride, cookies)
+	if clientErr != nil {
+		log.Printf("Proxy configuration failed for task %s: %v", task.ID, clientErr)
+
+		s.tasksMutex.Lock()
+		task.Status = model.TaskStatusError
+		task.LastError = "Proxy: " + clientErr.Error()
+		task.FinishedAt = time.Now()
+		s.notifyUpdate(task)
+		s.tasksMutex.Unlock()
+		return
+	}
+
+	opts := engine.Options{Quality: quality, Ext: ext, HTTPClient: client, Segments: segments}
 
 	// Resolve metadata and select format to compute output path
-	_, info, resErr := d.ResolveURL(ctx, task.URL)
+	extractor, resErr := s.extractors.Find(task.URL)
+	var info *engine.VideoInfo
+	if resErr == nil {
+		info, resErr = extractor.Resolve(ctx, task.URL, opts)
+	}
 	if resErr != nil {
 		// Handle ResolveURL error immediately
 		log.Printf("ResolveURL failed for task %s: %v", task.ID, resErr)
@@ -557,75 +749,149 @@ func (s *Service) startTask(task *model.DownloadTask) {
 		task.Status = model.TaskStatusError
 		task.LastError = s.parseYouTubeError(resErr)
 		task.FinishedAt = time.Now()
-		s.tasksMutex.Unlock()
 		s.notifyUpdate(task)
+		s.tasksMutex.Unlock()
 		return
 	}
 
+	if info != nil && len(info.Chapters) > 0 {
+		s.tasksMutex.Lock()
+		task.Chapters = convertChapters(info.Chapters)
+		s.tasksMutex.Unlock()
+	}
+
+	// An upcoming broadcast is waited for; it may turn into a live or a regular video
+	if info != nil && info.Live == engine.LiveUpcoming {
+		if info, resErr = s.waitForBroadcast(ctx, task, extractor, info, opts); resErr != nil {
+			s.tasksMutex.Lock()
+			s.failTaskLocked(ctx, task, resErr)
+			task.FinishedAt = time.Now()
+			s.notifyUpdate(task)
+			s.tasksMutex.Unlock()
+			return
+		}
+	}
+
 	// Compute output file path
-	outputPath := s.downloadDir
-	if info != nil {
-		base := strings.TrimSpace(info.Title)
-		if base == "" {
-			base = "video"
+	s.tasksMutex.RLock()
+	outputDir := s.downloadDir
+	s.tasksMutex.RUnlock()
+	if task.OutputDir != "" {
+		outputDir = task.OutputDir
+		if err := platform.CreateDirectoryIfNotExists(outputDir); err != nil {
+			log.Printf("failed to ensure output dir %s: %v", outputDir, err)
 		}
-		base = s.sanitizeFilename(base)
+	}
+	if info != nil && info.Live == engine.LiveNow {
+		s.recordLive(ctx, task, info, opts, outputDir)
+		return
+	}
+	outputPath := outputDir
+	if info != nil {
+		base := s.outputBase(task, info)
 		extGuess := s.guessExtFromFormats(info.Formats)
 		if extGuess == "" {
 			extGuess = "mp4"
 		}
-		outputPath = filepath.Join(s.downloadDir, base+"."+extGuess)
+		outputPath = filepath.Join(outputDir, base+"."+extGuess)
+	}
+
+	var duration time.Duration
+	var size int64
+	var videoID string
+	var format engine.Format // the selected format, which the finished file is checked against
+	if info != nil {
+		duration = time.Duration(info.Duration) * time.Second
+		size = formatSize(info.Formats)
+		videoID = info.ID
+		if len(info.Formats) > 0 {
+			format = info.Formats[0]
+		}
 	}
 
-	// If file already exists and has size, short-circuit
-	if fi, statErr := os.Stat(outputPath); statErr == nil && fi.Size() > 0 {
+	// An existing file is kept, replaced or downloaded next to, as configured
+	outputPath, done := s.resolveCollision(ctx, task, outputPath, videoID, size, duration)
+	if done {
 		s.tasksMutex.Lock()
 		task.Status = model.TaskStatusCompleted
 		task.Progress = 1.0
 		task.Percent = 100
 		task.OutputPath = outputPath
+		s.notifyUpdate(task)
 		s.tasksMutex.Unlock()
+		return
+	}
+
+	// Expose expected output early (for UI actions like copy path); a file
+	// another queued download writes to gets a numbered name
+	outputPath = s.claimOutputPath(task, outputPath)
+
+	// Unfinished data stays out of the target directory when a temp dir is set
+	workPath := s.workPath(outputPath)
+	if workPath != outputPath {
+		if err := platform.CreateDirectoryIfNotExists(filepath.Dir(workPath)); err != nil {
+			log.Printf("failed to ensure temp dir %s: %v", filepath.Dir(workPath), err)
+		}
+	}
+
+	var needed int64
+	if info != nil {
+		needed = expectedSize(info.Formats, workPath)
+	}
+
+	// Refuse to start a download that would fill the disk
+	if err := s.checkDiskSpace(filepath.Dir(workPath), needed); err != nil {
+		log.Printf("Task %s not started: %v", task.ID, err)
+
+		s.tasksMutex.Lock()
+		task.Status = model.TaskStatusError
+		task.LastError = err.Error()
+		task.FinishedAt = time.Now()
 		s.notifyUpdate(task)
+		s.tasksMutex.Unlock()
+		s.stopSmoothingTimer(task.ID)
 		return
 	}
 
-	// Set progress callback and output path
-	d = d.WithOutputPath(outputPath).WithProgress(func(p ytdlp.Progress) {
+	// Start download; the source is recorded so an orphaned partial file can be resumed.
+	// The finished file stays a partial file through post-processing and gets
+	// its final name last, so media scanners never see it incomplete.
+	writePartInfo(workPath, task, outputPath)
+	opts.KeepPart = true
+	partPath := workPath + engine.PartSuffix
+	info, err := extractor.Download(ctx, task.URL, info, workPath, opts, func(p engine.Progress) {
 		s.updateTaskProgressFromNew(task, p)
 	})
-
-	// Expose expected output early (for UI actions like copy path)
-	s.tasksMutex.Lock()
-	task.OutputPath = outputPath
-	s.tasksMutex.Unlock()
-	s.notifyUpdate(task)
-
-	// Start download
-	info, err := d.Download(ctx, task.URL)
+	if err == nil {
+		s.applySponsorBlock(ctx, task, extractor.Name(), partPath, duration, client)
+		s.embedTaskChapters(ctx, task, partPath)
+		err = platform.MoveFile(partPath, outputPath)
+	}
+	if err == nil {
+		os.Remove(workPath + PartInfoSuffix)
+	}
+
+	// Check the finished file before reporting it as complete
+	var verifyErr error
+	if err == nil && verifyMode != VerifyOff {
+		s.tasksMutex.RLock()
+		expected := duration - task.RemovedDuration
+		s.tasksMutex.RUnlock()
+		verifyErr = s.verifyFile(ctx, outputPath, expectedMedia(preset, format, outputPath, expected))
+		if errors.Is(verifyErr, context.Canceled) {
+			// Stopped during the check: the file was not judged
+			err, verifyErr = ctx.Err(), nil
+		}
+	}
 
 	// Update final status
 	s.tasksMutex.Lock()
 	if err != nil {
-		mode, wasStopped := s.stopModes[task.ID]
-		if wasStopped {
-			if mode == StopModePause {
-				task.Status = model.TaskStatusPaused
-			} else {
-				task.Status = model.TaskStatusStopped
-			}
-			delete(s.stopModes, task.ID)
-		} else if ctx.Err() == context.Canceled {
-			if mode := s.stopModes[task.ID]; mode == StopModePause {
-				task.Status = model.TaskStatusPaused
-			} else {
-				task.Status = model.TaskStatusStopped
-			}
-			delete(s.stopModes, task.ID)
-		} else {
-			task.Status = model.TaskStatusError
-			task.LastError = s.parseYouTubeError(err)
-		}
+		s.failTaskLocked(ctx, task, err)
+	} else if verifyErr != nil {
+		s.failVerificationLocked(task, verifyErr)
 	} else {
+		delete(s.verifyRetries, task.ID)
 		task.Status = model.TaskStatusCompleted
 		task.Progress = 1.0
 		task.Percent = 100
@@ -655,16 +921,71 @@ func (s *Service) startTask(task *model.DownloadTask) {
 		}
 	}
 	task.FinishedAt = time.Now()
+	s.notifyUpdate(task)
 	s.tasksMutex.Unlock()
 
 	// Stop smoothing timer for this task
 	s.stopSmoothingTimer(task.ID)
+}
 
-	s.notifyUpdate(task)
+// failTaskLocked ends a task whose download returned err: as Paused or Stopped
+// when the user cancelled it, as Error otherwise. Callers must hold tasksMutex.
+func (s *Service) failTaskLocked(ctx context.Context, task *model.DownloadTask, err error) {
+	mode, wasStopped := s.stopModes[task.ID]
+	if wasStopped {
+		if mode == StopModePause {
+			task.Status = model.TaskStatusPaused
+		} else {
+			task.Status = model.TaskStatusStopped
+		}
+		delete(s.stopModes, task.ID)
+	} else if ctx.Err() == context.Canceled {
+		if mode := s.stopModes[task.ID]; mode == StopModePause {
+			task.Status = model.TaskStatusPaused
+		} else {
+			task.Status = model.TaskStatusStopped
+		}
+		delete(s.stopModes, task.ID)
+	} else {
+		task.Status = model.TaskStatusError
+		task.LastError = s.parseYouTubeError(err)
+	}
+}
+
+// embedTaskChapters writes the task's chapters into the downloaded file when the
+// container supports them. Failures are logged: the file is still usable.
+func (s *Service) embedTaskChapters(ctx context.Context, task *model.DownloadTask, outputPath string) {
+	s.tasksMutex.RLock()
+	chapters, enabled := task.Chapters, s.ffmpeg
+	s.tasksMutex.RUnlock()
+	if !enabled || len(chapters) == 0 || !compress.SupportsChapters(outputPath) {
+		return
+	}
+	if err := compress.EmbedChapters(ctx, outputPath, chapters); err != nil {
+		log.Printf("Embedding chapters for task %s failed: %v", task.ID, err)
+	}
+}
+
+// convertChapters maps engine chapters to task chapters
+func convertChapters(list []engine.Chapter) []model.Chapter {
+	chapters := make([]model.Chapter, 0, len(list))
+	for _, c := range list {
+		chapters = append(chapters, model.Chapter{Title: c.Title, Start: c.Start, End: c.End})
+	}
+	return chapters
+}
+
+// outputBase returns the sanitized file name of a task without extension
+func (s *Service) outputBase(task *model.DownloadTask, info *engine.VideoInfo) string {
+	base := strings.TrimSpace(info.Title)
+	if base == "" {
+		base = "video"
+	}
+	return s.sanitizeFilename(task.FilenamePrefix + base)
 }
 
 // Replace old progress updater with one that accepts new Progress
-func (s *Service) updateTaskProgressFromNew(task *model.DownloadTask, p ytdlp.Progress) {
+func (s *Service) updateTaskProgressFromNew(task *model.DownloadTask, p engine.Progress) {
 	s.tasksMutex.Lock()
 	defer s.tasksMutex.Unlock()
 
@@ -723,7 +1044,13 @@ func (s *Service) updateTaskProgressFromNew(task *model.DownloadTask, p ytdlp.Pr
 }
 
 // guessExtFromFormats returns a preferred extension based on available formats.
-func (s *Service) guessExtFromFormats(list []types.Format) string {
+func (s *Service) guessExtFromFormats(list []engine.Format) string {
+	// Extractors that know the selected file type report it first
+	for _, f := range list {
+		if f.Ext != "" {
+			return f.Ext
+		}
+	}
 	for _, f := range list {
 		if strings.Contains(strings.ToLower(f.MimeType), "video/mp4") {
 			return "mp4"
@@ -737,26 +1064,62 @@ func (s *Service) guessExtFromFormats(list []types.Format) string {
 	return ""
 }
 
-// startNextPendingTask starts the next pending task if we have capacity
-func (s *Service) startNextPendingTask() {
-	s.tasksMutex.Lock()
-	defer s.tasksMutex.Unlock()
-
-	if s.activeCount >= s.maxParallel {
-		return
+// fillSlotsLocked starts pending tasks in queue order until the parallel limit is reached.
+// Every free slot pulls the next task from the shared queue, so single downloads and
+// playlist items are scheduled under the same limit. Tasks that may not start yet are
+// marked Scheduled first. Callers must hold tasksMutex.
+func (s *Service) fillSlotsLocked() {
+	s.scheduleLocked()
+	for s.activeCount < s.maxParallel {
+		next := s.nextPendingLocked()
+		if next == nil {
+			return
+		}
+		s.launchLocked(next)
 	}
+}
 
-	// Find next pending task
+// nextPendingLocked returns the pending task with the lowest queue position, or nil
+func (s *Service) nextPendingLocked() *model.DownloadTask {
+	var next *model.DownloadTask
 	for _, task := range s.tasks {
-		if task.Status == model.TaskStatusPending {
-			go s.startTask(task)
-			return
+		if task.Status != model.TaskStatusPending {
+			continue
+		}
+		if next == nil || task.QueueSeq < next.QueueSeq {
+			next = task
 		}
 	}
+	return next
 }
 
-// notifyUpdate calls the update callback if set
+// launchLocked reserves a download slot for the task and starts it.
+// The slot is taken synchronously so concurrent adds cannot exceed maxParallel.
+func (s *Service) launchLocked(task *model.DownloadTask) {
+	s.activeCount++
+	task.Status = model.TaskStatusStarting
+	s.notifyUpdate(task)
+	go s.startTask(task)
+}
+
+// cancelDownloadLocked aborts the running download of a task, if any
+func (s *Service) cancelDownloadLocked(id string) {
+	if cancel, ok := s.cancels[id]; ok {
+		log.Printf("Task %s requested to stop, canceling download", id)
+		cancel()
+	}
+}
+
+// notifyUpdate publishes a task snapshot to subscribers and calls the update callback if set.
+// Callers must hold tasksMutex so the snapshot is consistent.
 func (s *Service) notifyUpdate(task *model.DownloadTask) {
+	previous := s.lastStatus[task.ID]
+	s.lastStatus[task.ID] = task.Status
+	if previous != task.Status {
+		s.markQueueDirtyLocked()
+	}
+	s.events.publish(Event{Type: eventTypeForStatus(previous, task.Status), Task: *task})
+
 	if s.onUpdate != nil {
 		s.onUpdate(task)
 	}
@@ -774,89 +1137,23 @@ func generateTaskID() string {
 	return "task-" + id.String()
 }
 
-// extractVideoID extracts video ID from various YouTube URL formats
-func (s *Service) extractVideoID(url string) string {
-	// Simple extraction for common YouTube URL patterns
-	if strings.Contains(url, "youtube.com/watch?v=") {
-		parts := strings.Split(url, "v=")
-		if len(parts) > 1 {
-			videoID := strings.Split(parts[1], "&")[0]
-			// Clean video ID from any additional parameters
-			videoID = strings.Split(videoID, "#")[0]
-			videoID = strings.Split(videoID, "/")[0]
-			if len(videoID) == 11 { // YouTube video IDs are 11 characters
-				return videoID
-			}
-		}
-	} else if strings.Contains(url, "youtu.be/") {
-		parts := strings.Split(url, "youtu.be/")
-		if len(parts) > 1 {
-			videoID := strings.Split(parts[1], "?")[0]
-			videoID = strings.Split(videoID, "#")[0]
-			if len(videoID) == 11 {
+// extractVideoID returns a stable identifier for a URL: the ID reported by the
+// matching extractor (e.g. the YouTube video ID), otherwise a hash of the whole URL
+func (s *Service) extractVideoID(rawURL string) string {
+	if extractor, err := s.extractors.Find(rawURL); err == nil {
+		if u, err := url.Parse(strings.TrimSpace(rawURL)); err == nil {
+			if videoID := extractor.VideoID(u); videoID != "" {
 				return videoID
 			}
 		}
 	}
 
-	// For other URLs, try to extract some identifier
-	if strings.Contains(url, "vimeo.com/") {
-		parts := strings.Split(url, "vimeo.com/")
-		if len(parts) > 1 {
-			videoID := strings.Split(parts[1], "/")[0]
-			videoID = strings.Split(videoID, "?")[0]
-			videoID = strings.Split(videoID, "#")[0]
-			if videoID != "" {
-				return "vimeo_" + videoID
-			}
-		}
-	}
-
-	// Generic fallback - use hash of URL
-	hash := fmt.Sprintf("%x", len(url))
-	return "video_" + hash[:8]
-}
-
-// generateUserFriendlyPath generates a user-friendly file path with original title
-//
-//lint:ignore U1000 reserved for future rename feature
-func (s *Service) generateUserFriendlyPath(task *model.DownloadTask) string {
-	if task.Title == "" || task.OutputPath == "" {
-		return ""
-	}
-
-	// Get the directory and extension from the original OutputPath
-	dir := filepath.Dir(task.OutputPath)
-	ext := filepath.Ext(task.OutputPath)
-	if ext == "" {
-		ext = ".mp4" // Default extension
-	}
-
-	// Clean the title to make it safe for filenames
-	safeTitle := s.sanitizeFilename(task.Title)
-
-	// Create the new path
-	newPath := filepath.Join(dir, safeTitle+ext)
-
-	// Check if the file already exists with this name
-	if _, err := os.Stat(newPath); err == nil {
-		// File exists, add a number suffix
-		counter := 1
-		for {
-			newPath = filepath.Join(dir, fmt.Sprintf("%s_%d%s", safeTitle, counter, ext))
-			if _, err := os.Stat(newPath); err != nil {
-				break
-			}
-			counter++
-		}
-	}
-
-	return newPath
+	// Generic fallback - hash of the full URL
+	sum := sha256.Sum256([]byte(rawURL))
+	return "video_" + hex.EncodeToString(sum[:8])
 }
 
 // sanitizeFilename removes or replaces characters that are not safe for filenames
-//
-//lint:ignore U1000 reserved for future rename feature
 func (s *Service) sanitizeFilename(filename string) string {
 	// Replace unsafe characters
 	unsafe := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"}
@@ -880,6 +1177,9 @@ func (s *Service) sanitizeFilename(filename string) string {
 	return result
 }
 
+// CookiesHint tells users how to unlock content that needs a signed-in account
+const CookiesHint = "import cookies of a signed-in account in Settings"
+
 // parseYouTubeError converts YouTube-specific errors to user-friendly messages
 func (s *Service) parseYouTubeError(err error) string {
 	if err == nil {
@@ -890,8 +1190,15 @@ func (s *Service) parseYouTubeError(err error) string {
 	errStr = strings.ToLower(errStr)
 
 	// Age restriction
-	if strings.Contains(errStr, "age restricted") || strings.Contains(errStr, "age-restricted") {
-		return "Video is age-restricted and cannot be downloaded"
+	if strings.Contains(errStr, "age restricted") || strings.Contains(errStr, "age-restricted") ||
+		strings.Contains(errStr, "confirm your age") {
+		return "Video is age-restricted - " + CookiesHint
+	}
+
+	// Channel memberships
+	if strings.Contains(errStr, "members-only") || strings.Contains(errStr, "members only") ||
+		strings.Contains(errStr, "join this channel") {
+		return "Video is for channel members only - " + CookiesHint
 	}
 
 	// Geographic restriction
@@ -921,7 +1228,7 @@ func (s *Service) parseYouTubeError(err error) string {
 
 	// Authentication issues
 	if strings.Contains(errStr, "auth") || strings.Contains(errStr, "login") {
-		return "Authentication required - video may be private"
+		return "Authentication required - " + CookiesHint
 	}
 
 	// Return original error if no specific pattern matches
@@ -930,7 +1237,8 @@ func (s *Service) parseYouTubeError(err error) string {
 
 // Playlist methods
 
-// AddPlaylist adds a new playlist for downloading
+// AddPlaylist registers a playlist with the service.
+// The service keeps its own copy; changes are reported through EventPlaylistUpdated.
 func (s *Service) AddPlaylist(playlist *model.Playlist) error {
 	s.playlistsMutex.Lock()
 	defer s.playlistsMutex.Unlock()
@@ -940,126 +1248,299 @@ func (s *Service) AddPlaylist(playlist *model.Playlist) error {
 		return fmt.Errorf("playlist already exists: %s", playlist.ID)
 	}
 
-	s.playlists[playlist.ID] = playlist
-
-	// Add to download queue
-	select {
-	case s.playlistQueue <- playlist:
-		// Successfully queued
-	default:
-		// Queue is full, start processing immediately
-		go s.processPlaylist(playlist)
-	}
-
+	s.playlists[playlist.ID] = playlist.Clone()
 	return nil
 }
 
-// GetPlaylist returns a playlist by ID
+// GetPlaylist returns a snapshot of a playlist by ID
 func (s *Service) GetPlaylist(id string) (*model.Playlist, bool) {
 	s.playlistsMutex.RLock()
 	defer s.playlistsMutex.RUnlock()
 	playlist, exists := s.playlists[id]
-	return playlist, exists
+	if !exists {
+		return nil, false
+	}
+	return playlist.Clone(), true
 }
 
-// GetAllPlaylists returns all playlists
+// GetAllPlaylists returns snapshots of all playlists
 func (s *Service) GetAllPlaylists() []*model.Playlist {
 	s.playlistsMutex.RLock()
 	defer s.playlistsMutex.RUnlock()
 
 	playlists := make([]*model.Playlist, 0, len(s.playlists))
 	for _, playlist := range s.playlists {
-		playlists = append(playlists, playlist)
+		playlists = append(playlists, playlist.Clone())
 	}
 	return playlists
 }
 
-// DownloadPlaylist starts downloading a playlist with chunked approach
+// DownloadPlaylist queues the playlist's pending videos in playlist order.
+// The videos share the global parallel limit with all other tasks.
+// The playlist is registered first if AddPlaylist was not called for it.
 func (s *Service) DownloadPlaylist(playlist *model.Playlist) error {
-	if !playlist.IsReadyForDownload() {
+	s.playlistsMutex.Lock()
+	defer s.playlistsMutex.Unlock()
+
+	owned, exists := s.playlists[playlist.ID]
+	if !exists {
+		owned = playlist.Clone()
+		s.playlists[playlist.ID] = owned
+	}
+	if !owned.IsReadyForDownload() {
 		return fmt.Errorf("playlist is not ready for download")
 	}
 
 	// Update playlist status
-	playlist.UpdateStatus(model.PlaylistStatusDownloading)
+	owned.UpdateStatus(model.PlaylistStatusDownloading)
+
+	// Subscribe before queueing so no task event is missed
+	events, unsubscribe := s.Subscribe()
 
-	// Start processing the playlist
-	go s.processPlaylist(playlist)
+	queued := s.enqueuePlaylistVideos(owned)
+	if queued == 0 {
+		unsubscribe()
+		owned.UpdateStatus(model.PlaylistStatusCompleted)
+	} else {
+		go s.trackPlaylist(owned.ID, events, unsubscribe)
+	}
+	s.publishPlaylistLocked(owned)
 
 	return nil
 }
 
-// processPlaylist processes a playlist by downloading videos in chunks
-func (s *Service) processPlaylist(playlist *model.Playlist) {
-	// Get pending videos
-	pendingVideos := playlist.GetPendingVideos()
-	if len(pendingVideos) == 0 {
+// enqueuePlaylistVideos adds a task for every pending video and returns how many were queued.
+// Tasks get consecutive queue positions, so the playlist is downloaded in order.
+// Callers must hold playlistsMutex.
+func (s *Service) enqueuePlaylistVideos(playlist *model.Playlist) int {
+	queued := 0
+	for _, video := range playlist.GetPendingVideos() {
+		outputDir, prefix := s.playlistOutputOptions(playlist, video)
+		task, err := s.addTask(video.URL, taskOptions{
+			PlaylistID:     playlist.ID,
+			OutputDir:      outputDir,
+			FilenamePrefix: prefix,
+			StartAt:        playlist.StartAt,
+		})
+		if err != nil {
+			playlist.UpdateVideoStatus(video.ID, model.VideoStatusError)
+			video.Error = err.Error()
+			continue
+		}
+		video.TaskID = task.ID
+		queued++
+	}
+	return queued
+}
+
+// trackPlaylist applies task events to a playlist until all its tasks are finished
+// or the playlist is cancelled
+func (s *Service) trackPlaylist(playlistID string, events <-chan Event, unsubscribe func()) {
+	defer unsubscribe()
+
+	for event := range events {
+		if event.Task.PlaylistID != playlistID {
+			continue
+		}
+		if !s.applyPlaylistEvent(playlistID, event) {
+			return
+		}
+	}
+}
+
+// applyPlaylistEvent mirrors a task event into its playlist video.
+// It returns false once the playlist no longer needs tracking.
+func (s *Service) applyPlaylistEvent(playlistID string, event Event) bool {
+	s.playlistsMutex.Lock()
+	defer s.playlistsMutex.Unlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists || !playlist.IsActive() {
+		// Cancelled or removed; paused playlists are still tracked
+		return false
+	}
+
+	task := event.Task
+	for _, video := range playlist.Videos {
+		if video.TaskID != task.ID {
+			continue
+		}
+
+		status := videoStatusForTask(task.Status)
+		switch status {
+		case model.VideoStatusCompleted:
+			playlist.UpdateVideoOutputPath(video.ID, task.OutputPath, task.FileSize)
+			playlist.UpdateVideoProgress(video.ID, 100.0)
+		case model.VideoStatusError:
+			video.Error = task.LastError
+		default:
+			playlist.UpdateVideoProgress(video.ID, task.Progress)
+		}
+		video.Speed = task.Speed
+		video.ETASec = task.ETASec
+		playlist.UpdateVideoStatus(video.ID, status)
+
+		if event.Type == EventTaskCompleted && playlist.WriteM3U {
+			if err := s.writePlaylistM3U(playlist); err != nil {
+				log.Printf("Failed to write M3U for playlist %s: %v", playlist.ID, err)
+			}
+		}
+		break
+	}
+
+	done := s.playlistFinishedLocked(playlist)
+	if done {
 		playlist.UpdateStatus(model.PlaylistStatusCompleted)
-		return
 	}
+	s.publishPlaylistLocked(playlist)
+	return !done
+}
 
-	// Process videos in chunks
-	chunkSize := s.maxPlaylistParallel
-	for i := 0; i < len(pendingVideos); i += chunkSize {
-		end := min(i+chunkSize, len(pendingVideos))
-		chunk := pendingVideos[i:end]
-
-		// Download chunk of videos
-		var wg sync.WaitGroup
-		for _, video := range chunk {
-			wg.Add(1)
-			go func(v *model.PlaylistVideo) {
-				defer wg.Done()
-				s.downloadPlaylistVideo(playlist, v)
-			}(video)
+// playlistFinishedLocked reports whether every queued video reached a final state
+func (s *Service) playlistFinishedLocked(playlist *model.Playlist) bool {
+	for _, video := range playlist.Videos {
+		if video.TaskID == "" {
+			continue
+		}
+		switch video.Status {
+		case model.VideoStatusCompleted, model.VideoStatusError, model.VideoStatusSkipped:
+		default:
+			return false
 		}
+	}
+	return true
+}
 
-		// Wait for current chunk to complete
-		wg.Wait()
+// publishPlaylistLocked publishes a snapshot of the playlist. Callers must hold playlistsMutex.
+func (s *Service) publishPlaylistLocked(playlist *model.Playlist) {
+	s.events.publish(Event{Type: EventPlaylistUpdated, Playlist: playlist.Clone()})
+}
 
-		// Check if playlist was cancelled
-		if playlist.Status == model.PlaylistStatusError {
-			return
+// videoStatusForTask maps a task status to the status shown for a playlist video
+func videoStatusForTask(status model.TaskStatus) model.VideoStatus {
+	switch status {
+	case model.TaskStatusPending, model.TaskStatusScheduled:
+		return model.VideoStatusPending
+	case model.TaskStatusPaused:
+		return model.VideoStatusPaused
+	case model.TaskStatusCompleted:
+		return model.VideoStatusCompleted
+	case model.TaskStatusStopped:
+		return model.VideoStatusSkipped
+	case model.TaskStatusError, model.TaskStatusVerifyFailed:
+		return model.VideoStatusError
+	default:
+		return model.VideoStatusDownloading
+	}
+}
+
+// playlistOutputOptions returns the output directory and filename prefix for a playlist video.
+// Without UseSubfolder both are empty and the video goes to the download directory as is.
+func (s *Service) playlistOutputOptions(playlist *model.Playlist, video *model.PlaylistVideo) (string, string) {
+	if !playlist.UseSubfolder {
+		return "", ""
+	}
+	index := playlist.GetVideoIndex(video.ID)
+	return s.playlistDir(playlist), formatIndexPrefix(index, len(playlist.Videos))
+}
+
+// playlistDir returns the directory holding a playlist's files and its M3U
+func (s *Service) playlistDir(playlist *model.Playlist) string {
+	s.tasksMutex.RLock()
+	dir := s.downloadDir
+	s.tasksMutex.RUnlock()
+	if !playlist.UseSubfolder {
+		return dir
+	}
+	name := s.sanitizeFilename(strings.TrimSpace(playlist.Title))
+	if name == "" {
+		name = s.sanitizeFilename(playlist.ID)
+	}
+	return filepath.Join(dir, name)
+}
+
+// writePlaylistM3U rewrites the playlist's .m3u8 file with completed videos in playlist order
+func (s *Service) writePlaylistM3U(playlist *model.Playlist) error {
+	dir := s.playlistDir(playlist)
+	name := s.sanitizeFilename(strings.TrimSpace(playlist.Title))
+	if name == "" {
+		name = "playlist"
+	}
+
+	var entries []M3UEntry
+	for _, video := range playlist.Videos {
+		if video.Status != model.VideoStatusCompleted || video.OutputPath == "" {
+			continue
 		}
+		entries = append(entries, M3UEntry{Title: video.Title, Path: video.OutputPath})
 	}
+	return WriteM3U(filepath.Join(dir, name+M3UExtension), entries)
+}
 
-	// Mark playlist as completed
-	playlist.UpdateStatus(model.PlaylistStatusCompleted)
+// formatIndexPrefix returns a zero-padded index prefix wide enough for the playlist size
+func formatIndexPrefix(index, total int) string {
+	width := len(fmt.Sprint(total))
+	if width < 2 {
+		width = 2
+	}
+	return fmt.Sprintf("%0*d ", width, index)
 }
 
-// downloadPlaylistVideo downloads a single video from a playlist
-func (s *Service) downloadPlaylistVideo(playlist *model.Playlist, video *model.PlaylistVideo) {
-	// Update video status
-	playlist.UpdateVideoStatus(video.ID, model.VideoStatusDownloading)
+// PausePlaylist pauses all queued and running videos of a playlist
+func (s *Service) PausePlaylist(playlistID string) error {
+	s.playlistsMutex.Lock()
+	defer s.playlistsMutex.Unlock()
 
-	// Create download task for this video
-	task, err := s.AddTask(video.URL)
-	if err != nil {
-		playlist.UpdateVideoStatus(video.ID, model.VideoStatusError)
-		video.Error = err.Error()
-		return
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return fmt.Errorf("playlist not found: %s", playlistID)
+	}
+	if playlist.Status != model.PlaylistStatusDownloading {
+		return fmt.Errorf("playlist is not downloading: %s", playlist.Status)
 	}
 
-	// Monitor task progress
-	go func() {
-		for range time.NewTicker(100 * time.Millisecond).C {
-			if task.Status.IsFinished() {
-				if task.Status == model.TaskStatusCompleted {
-					playlist.UpdateVideoOutputPath(video.ID, task.OutputPath, task.FileSize)
-					playlist.UpdateVideoStatus(video.ID, model.VideoStatusCompleted)
-					playlist.UpdateVideoProgress(video.ID, 100.0)
-				} else {
-					playlist.UpdateVideoStatus(video.ID, model.VideoStatusError)
-					video.Error = task.LastError
-				}
-				return
-			}
-			playlist.UpdateVideoProgress(video.ID, task.Progress)
+	playlist.UpdateStatus(model.PlaylistStatusPaused)
+
+	for _, taskID := range s.playlistTaskIDs(playlist) {
+		if err := s.PauseTask(taskID); err != nil {
+			log.Printf("Failed to pause task %s of playlist %s: %v", taskID, playlistID, err)
 		}
-	}()
+	}
+	s.publishPlaylistLocked(playlist)
+
+	return nil
+}
+
+// ResumePlaylist resumes paused videos of a playlist in their original order
+func (s *Service) ResumePlaylist(playlistID string) error {
+	s.playlistsMutex.Lock()
+	defer s.playlistsMutex.Unlock()
+
+	playlist, exists := s.playlists[playlistID]
+	if !exists {
+		return fmt.Errorf("playlist not found: %s", playlistID)
+	}
+	if playlist.Status != model.PlaylistStatusPaused {
+		return fmt.Errorf("playlist is not paused: %s", playlist.Status)
+	}
+
+	playlist.UpdateStatus(model.PlaylistStatusDownloading)
+
+	for _, taskID := range s.playlistTaskIDs(playlist) {
+		task, ok := s.GetTask(taskID)
+		if !ok || task.Status != model.TaskStatusPaused {
+			continue
+		}
+		if err := s.ResumeTask(taskID); err != nil {
+			log.Printf("Failed to resume task %s of playlist %s: %v", taskID, playlistID, err)
+		}
+	}
+	s.publishPlaylistLocked(playlist)
+
+	return nil
 }
 
-// CancelPlaylist cancels a playlist download
+// CancelPlaylist stops all videos of a playlist and marks unfinished ones as skipped
 func (s *Service) CancelPlaylist(playlistID string) error {
 	s.playlistsMutex.Lock()
 	defer s.playlistsMutex.Unlock()
@@ -1069,22 +1550,42 @@ func (s *Service) CancelPlaylist(playlistID string) error {
 		return fmt.Errorf("playlist not found: %s", playlistID)
 	}
 
-	// Update status
-	playlist.UpdateStatus(model.PlaylistStatusError)
+	playlist.UpdateStatus(model.PlaylistStatusCancelled)
+
+	// Stop queued, paused and running tasks so they release their slots
+	for _, taskID := range s.playlistTaskIDs(playlist) {
+		if err := s.StopTask(taskID); err != nil {
+			log.Printf("Failed to stop task %s of playlist %s: %v", taskID, playlistID, err)
+		}
+	}
 
-	// Cancel all downloading videos
 	for _, video := range playlist.Videos {
-		if video.Status == model.VideoStatusDownloading {
+		if video.Status == model.VideoStatusPending ||
+			video.Status == model.VideoStatusDownloading ||
+			video.Status == model.VideoStatusPaused {
 			playlist.UpdateVideoStatus(video.ID, model.VideoStatusSkipped)
 		}
 	}
+	s.publishPlaylistLocked(playlist)
 
 	return nil
 }
 
-// SetMaxPlaylistParallel sets the maximum number of concurrent playlist downloads
-func (s *Service) SetMaxPlaylistParallel(max int) {
-	s.maxPlaylistParallel = max
+// playlistTaskIDs returns IDs of the playlist's tasks that have not finished yet
+func (s *Service) playlistTaskIDs(playlist *model.Playlist) []string {
+	s.tasksMutex.RLock()
+	defer s.tasksMutex.RUnlock()
+
+	var ids []string
+	for _, video := range playlist.Videos {
+		if video.TaskID == "" {
+			continue
+		}
+		if task, ok := s.tasks[video.TaskID]; ok && !task.Status.IsFinished() {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
 }
 
 // SetQualityPreset sets quality preset for downloads
@@ -1098,6 +1599,98 @@ func (s *Service) SetQualityPreset(preset string) {
 	}
 }
 
+// SiteForURL returns the name of the extractor that handles the URL, e.g. "youtube" or "vimeo"
+func (s *Service) SiteForURL(rawURL string) (string, error) {
+	extractor, err := s.extractors.Find(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return extractor.Name(), nil
+}
+
+// SetProxy sets the proxy used by downloads started from now on
+func (s *Service) SetProxy(cfg network.ProxyConfig) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	s.proxy = cfg
+}
+
+// SetCookies sets the cookies sent by downloads started from now on, e.g. for
+// age-restricted or members-only videos. Nil clears them.
+func (s *Service) SetCookies(cookies []*http.Cookie) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	s.cookies = cookies
+}
+
+// Connection limits of segmented downloads
+const (
+	// MaxSegments bounds the connections of a single download
+	MaxSegments = 8
+	// MaxConnections bounds parallel downloads times segments per download
+	MaxConnections = 16
+)
+
+// SetDownloadSegments sets how many connections a single file download may use.
+// Downloads use fewer when the parallel limit leaves less, see ClampSegments.
+// It applies to downloads started from now on; a paused segmented download
+// keeps its original ranges when resumed.
+func (s *Service) SetDownloadSegments(segments int) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	s.segments = max(segments, 1)
+}
+
+// ClampSegments bounds the connections per download to MaxSegments and to what
+// MaxConnections leaves for maxParallel downloads
+func ClampSegments(segments, maxParallel int) int {
+	limit := MaxSegments
+	if maxParallel > 0 {
+		limit = min(limit, MaxConnections/maxParallel)
+	}
+	return max(1, min(segments, limit))
+}
+
+// SetTaskProxy overrides the proxy of one task, e.g. for geo-restricted videos.
+// An empty proxyURL restores the global proxy. It takes effect on the next start.
+func (s *Service) SetTaskProxy(id, proxyURL string) error {
+	if _, err := network.ParseProxyURL(proxyURL); err != nil {
+		return err
+	}
+
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return fmt.Errorf("task not found: %s", id)
+	}
+	task.Proxy = strings.TrimSpace(proxyURL)
+	s.notifyUpdate(task)
+	return nil
+}
+
+// newHTTPClient builds the client for one download from the global proxy, an optional
+// override and the imported cookies
+func newHTTPClient(global network.ProxyConfig, override string, cookies []*http.Cookie) (*http.Client, error) {
+	cfg := global
+	if override != "" {
+		parsed, err := network.ParseProxyURL(override)
+		if err != nil {
+			return nil, err
+		}
+		cfg = parsed
+	}
+	jar, err := network.NewCookieJar(cookies)
+	if err != nil {
+		return nil, err
+	}
+	return network.NewHTTPClient(cfg, jar)
+}
+
 // SetMaxParallelDownloads sets the maximum number of parallel downloads
 func (s *Service) SetMaxParallelDownloads(max int) {
 	s.tasksMutex.Lock()
@@ -1111,6 +1704,9 @@ func (s *Service) SetMaxParallelDownloads(max int) {
 	}
 
 	s.maxParallel = max
+
+	// A raised limit takes effect immediately
+	s.fillSlotsLocked()
 }
 
 // SetDownloadDirectory sets the download directory
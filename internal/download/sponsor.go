@@ -0,0 +1,68 @@
+package download
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/compress"
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/sponsorblock"
+	"github.com/ytget/yt-downloader/internal/ytlink"
+)
+
+// SetSponsorBlock sets how downloads started from now on treat SponsorBlock segments
+func (s *Service) SetSponsorBlock(cfg sponsorblock.Config) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+
+	s.sponsorBlock = cfg
+}
+
+// applySponsorBlock queries the segments of a finished YouTube download and
+// either cuts them out of the file or marks them as chapters. Failures are
+// logged: the file is still usable as downloaded.
+func (s *Service) applySponsorBlock(ctx context.Context, task *model.DownloadTask, site, outputPath string, duration time.Duration, client *http.Client) {
+	s.tasksMutex.RLock()
+	cfg, chapters, ffmpeg := s.sponsorBlock, task.Chapters, s.ffmpeg
+	s.tasksMutex.RUnlock()
+
+	videoID := ytlink.VideoID(task.URL)
+	if !cfg.Enabled() || site != SiteYouTube || videoID == "" {
+		return
+	}
+	segments, err := sponsorblock.NewClient(cfg.BaseURL, client).Segments(ctx, videoID, cfg.Categories)
+	if err != nil {
+		log.Printf("SponsorBlock lookup for task %s failed: %v", task.ID, err)
+		return
+	}
+	segments = sponsorblock.Merge(segments)
+	if len(segments) == 0 {
+		return
+	}
+
+	switch cfg.Mode {
+	case sponsorblock.ModeRemove:
+		if !ffmpeg {
+			log.Printf("SponsorBlock segments of task %s kept: ffmpeg is not available", task.ID)
+			return
+		}
+		ranges := make([]compress.TimeRange, 0, len(segments))
+		for _, segment := range segments {
+			ranges = append(ranges, compress.TimeRange{Start: segment.Start, End: segment.End})
+		}
+		if err := compress.RemoveRanges(ctx, outputPath, ranges, duration); err != nil {
+			log.Printf("Removing SponsorBlock segments of task %s failed: %v", task.ID, err)
+			return
+		}
+		s.tasksMutex.Lock()
+		task.RemovedDuration = sponsorblock.Total(segments)
+		task.Chapters = sponsorblock.RemovedChapters(chapters, segments)
+		s.tasksMutex.Unlock()
+	case sponsorblock.ModeChapters:
+		s.tasksMutex.Lock()
+		task.Chapters = sponsorblock.MarkedChapters(chapters, segments, duration)
+		s.tasksMutex.Unlock()
+	}
+}
@@ -0,0 +1,77 @@
+package download
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/sponsorblock"
+)
+
+// newSponsorBlockServer serves one sponsor segment for the video ID
+func newSponsorBlockServer(t *testing.T, videoID string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("videoID") != videoID {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[{"category":"sponsor","actionType":"skip","segment":[30,45]}]`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSponsorBlockMarksSegmentsAsChapters(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	service.ffmpeg = false
+	server := newSponsorBlockServer(t, "sssssponsor")
+	service.SetSponsorBlock(sponsorblock.Config{
+		Mode:       sponsorblock.ModeChapters,
+		Categories: []sponsorblock.Category{sponsorblock.CategorySponsor},
+		BaseURL:    server.URL,
+	})
+	url := "https://www.youtube.com/watch?v=sssssponsor"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Talk", Duration: 120}})
+
+	task, _ := service.AddTask(url)
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	expected := []model.Chapter{
+		{Title: "Video", Start: 0, End: 30 * time.Second},
+		{Title: "Sponsor", Start: 30 * time.Second, End: 45 * time.Second},
+		{Title: "Video", Start: 45 * time.Second, End: 2 * time.Minute},
+	}
+	if len(done.Chapters) != len(expected) {
+		t.Fatalf("unexpected chapters %+v", done.Chapters)
+	}
+	for i := range expected {
+		if done.Chapters[i] != expected[i] {
+			t.Errorf("chapter %d = %+v, expected %+v", i, done.Chapters[i], expected[i])
+		}
+	}
+	if done.RemovedDuration != 0 {
+		t.Errorf("marking must not remove anything, got %v", done.RemovedDuration)
+	}
+}
+
+func TestSponsorBlockRemoveNeedsFFmpeg(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	service.ffmpeg = false
+	server := newSponsorBlockServer(t, "rrrrrremove")
+	service.SetSponsorBlock(sponsorblock.Config{
+		Mode:       sponsorblock.ModeRemove,
+		Categories: sponsorblock.DefaultCategories,
+		BaseURL:    server.URL,
+	})
+	url := "https://www.youtube.com/watch?v=rrrrrremove"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Talk", Duration: 120}})
+
+	task, _ := service.AddTask(url)
+	done := waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if done.RemovedDuration != 0 || len(done.Chapters) != 0 {
+		t.Errorf("file must be kept as downloaded: removed=%v chapters=%+v", done.RemovedDuration, done.Chapters)
+	}
+}
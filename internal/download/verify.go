@@ -0,0 +1,151 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/compress"
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// VerifyMode selects what happens after a download finishes
+type VerifyMode string
+
+const (
+	VerifyOff   VerifyMode = "off"   // trust the download
+	VerifyMark  VerifyMode = "mark"  // check the file and mark failures
+	VerifyRetry VerifyMode = "retry" // check the file and download it again on failure
+)
+
+// VerifyModes lists the modes in the order shown in settings
+var VerifyModes = []VerifyMode{VerifyOff, VerifyMark, VerifyRetry}
+
+// Verification limits
+const (
+	// VerifyRetries is how often VerifyRetry downloads a file again before the
+	// task is marked as failed
+	VerifyRetries = 2
+	// VerifyDurationFraction is the share of the expected duration a file may
+	// differ by, when that is more than compress.DurationTolerance
+	VerifyDurationFraction = 0.01
+)
+
+// ErrVerification is reported when a downloaded file fails the integrity check
+var ErrVerification = errors.New("verification failed")
+
+// audioExtensions are file types that hold audio only
+var audioExtensions = map[string]bool{
+	".mp3": true, ".m4a": true, ".aac": true, ".opus": true, ".ogg": true,
+	".oga": true, ".flac": true, ".wav": true, ".mka": true,
+}
+
+// audioCodecs are codec names that mark a format with an audio track
+var audioCodecs = []string{"mp4a", "opus", "vorbis", "ac-3", "ec-3", "flac", "mp3"}
+
+// IsValid reports whether the mode is known
+func (m VerifyMode) IsValid() bool {
+	for _, mode := range VerifyModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// SetVerifyMode sets whether finished downloads are checked with ffprobe and
+// what happens to files that fail the check
+func (s *Service) SetVerifyMode(mode VerifyMode) {
+	s.tasksMutex.Lock()
+	defer s.tasksMutex.Unlock()
+	s.verifyMode = mode
+}
+
+// mediaCheck is what a finished download must contain
+type mediaCheck struct {
+	Duration time.Duration // expected length; zero skips the check
+	Video    bool
+	Audio    bool
+}
+
+// expectedMedia returns what the download of the selected format must contain:
+// a video stream unless the preset or the format is audio only, and an audio
+// stream for the audio preset and for formats whose codecs include audio
+func expectedMedia(preset string, format engine.Format, outputPath string, duration time.Duration) mediaCheck {
+	mediaType, params, _ := mime.ParseMediaType(format.MimeType)
+	audioOnly := strings.HasPrefix(mediaType, "audio/") || audioExtensions[strings.ToLower(filepath.Ext(outputPath))]
+
+	check := mediaCheck{Duration: duration, Video: preset != "audio" && !audioOnly, Audio: preset == "audio" || audioOnly}
+	for _, codec := range strings.Split(params["codecs"], ",") {
+		for _, audio := range audioCodecs {
+			if strings.HasPrefix(strings.TrimSpace(codec), audio) {
+				check.Audio = true
+			}
+		}
+	}
+	return check
+}
+
+// verifyFile checks a finished download with ffprobe: the container must be
+// readable, hold the expected streams and last as long as the video. Without
+// ffprobe nothing is checked.
+func (s *Service) verifyFile(ctx context.Context, path string, check mediaCheck) error {
+	if s.probeMedia == nil {
+		log.Printf("Verification of %s skipped: ffprobe is not available", path)
+		return nil
+	}
+	info, err := s.probeMedia(ctx, path)
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case err != nil:
+		return fmt.Errorf("%w: unreadable file: %v", ErrVerification, err)
+	case check.Video && !info.HasVideo:
+		return fmt.Errorf("%w: no video stream", ErrVerification)
+	case check.Audio && !info.HasAudio:
+		return fmt.Errorf("%w: no audio stream", ErrVerification)
+	case check.Duration > 0 && !sameLength(info.Duration, check.Duration):
+		return fmt.Errorf("%w: duration %s instead of %s", ErrVerification,
+			info.Duration.Round(time.Second), check.Duration.Round(time.Second))
+	}
+	return nil
+}
+
+// sameLength reports whether a file lasts as long as expected, allowing for
+// container rounding and for long videos a small relative difference
+func sameLength(actual, expected time.Duration) bool {
+	tolerance := max(compress.DurationTolerance, time.Duration(float64(expected)*VerifyDurationFraction))
+	diff := actual - expected
+	return diff <= tolerance && diff >= -tolerance
+}
+
+// failVerificationLocked handles a download that failed verification: with
+// VerifyRetry and retries left the file is deleted and the task queued again,
+// otherwise it is marked VerifyFailed. Callers must hold tasksMutex.
+func (s *Service) failVerificationLocked(task *model.DownloadTask, err error) {
+	log.Printf("Task %s: %v", task.ID, err)
+	task.LastError = err.Error()
+
+	if s.verifyMode == VerifyRetry && s.verifyRetries[task.ID] < VerifyRetries {
+		s.verifyRetries[task.ID]++
+		if removeErr := os.Remove(task.OutputPath); removeErr != nil {
+			log.Printf("Failed to delete %s: %v", task.OutputPath, removeErr)
+		}
+		// The path is claimed again when the download restarts
+		task.OutputPath = ""
+		task.Status = model.TaskStatusPending
+		task.Progress = 0
+		task.Percent = 0
+		return
+	}
+
+	delete(s.verifyRetries, task.ID)
+	task.Status = model.TaskStatusVerifyFailed
+}
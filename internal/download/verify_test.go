@@ -0,0 +1,130 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/compress"
+	"github.com/ytget/yt-downloader/internal/engine"
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+func TestVerifyMarksBrokenDownload(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	service.SetVerifyMode(VerifyMark)
+	service.probeMedia = func(context.Context, string) (compress.MediaInfo, error) {
+		return compress.MediaInfo{Duration: 60 * time.Second, HasAudio: true}, nil
+	}
+
+	url := "https://www.youtube.com/watch?v=novideonovi"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Silent", Duration: 60}})
+	task, _ := service.AddTask(url)
+	failed := waitForStatus(t, service, task.ID, model.TaskStatusVerifyFailed)
+	if !strings.Contains(failed.LastError, "no video stream") {
+		t.Errorf("expected the missing stream to be reported, got %q", failed.LastError)
+	}
+	if fake.Downloads(url) != 1 {
+		t.Errorf("expected a single download, got %d", fake.Downloads(url))
+	}
+}
+
+func TestVerifyRetriesBrokenDownload(t *testing.T) {
+	service, fake := newFakeService(t, 1)
+	service.SetVerifyMode(VerifyRetry)
+	var probes atomic.Int32
+	service.probeMedia = func(context.Context, string) (compress.MediaInfo, error) {
+		if probes.Add(1) == 1 {
+			return compress.MediaInfo{}, errors.New("moov atom not found")
+		}
+		return compress.MediaInfo{Duration: 61 * time.Second, HasVideo: true, HasAudio: true}, nil
+	}
+
+	url := "https://www.youtube.com/watch?v=truncatedvi"
+	fake.AddVideo(url, engine.FakeVideo{Info: engine.VideoInfo{Title: "Truncated", Duration: 60}})
+	task, _ := service.AddTask(url)
+	waitForStatus(t, service, task.ID, model.TaskStatusCompleted)
+	if fake.Downloads(url) != 2 {
+		t.Errorf("expected the broken file to be downloaded again, got %d downloads", fake.Downloads(url))
+	}
+
+	// A file that never passes is marked after the retries run out
+	service.probeMedia = func(context.Context, string) (compress.MediaInfo, error) {
+		return compress.MediaInfo{Duration: 20 * time.Second, HasVideo: true, HasAudio: true}, nil
+	}
+	short := "https://www.youtube.com/watch?v=shortshorts"
+	fake.AddVideo(short, engine.FakeVideo{Info: engine.VideoInfo{Title: "Short", Duration: 60}})
+	task, _ = service.AddTask(short)
+	failed := waitForStatus(t, service, task.ID, model.TaskStatusVerifyFailed)
+	if !strings.Contains(failed.LastError, ErrVerification.Error()) {
+		t.Errorf("expected a verification error, got %q", failed.LastError)
+	}
+	if fake.Downloads(short) != VerifyRetries+1 {
+		t.Errorf("expected %d downloads, got %d", VerifyRetries+1, fake.Downloads(short))
+	}
+}
+
+func TestVerifyRetryReleasesOutputPath(t *testing.T) {
+	service, _ := newFakeService(t, 1)
+	service.SetVerifyMode(VerifyRetry)
+	path := filepath.Join(service.downloadDir, "Broken.mp4")
+	os.WriteFile(path, make([]byte, 10), 0644)
+
+	task := &model.DownloadTask{ID: "broken", Status: model.TaskStatusDownloading, OutputPath: path}
+	service.tasksMutex.Lock()
+	service.failVerificationLocked(task, ErrVerification)
+	service.tasksMutex.Unlock()
+	if task.Status != model.TaskStatusPending || task.OutputPath != "" {
+		t.Errorf("expected a queued task without an output path, got %s at %q", task.Status, task.OutputPath)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the broken file to be deleted")
+	}
+}
+
+func TestExpectedMedia(t *testing.T) {
+	tests := []struct {
+		name   string
+		preset string
+		format engine.Format
+		output string
+		video  bool
+		audio  bool
+	}{
+		{name: "unknown format", preset: "best", output: "a.mp4", video: true},
+		{name: "progressive", preset: "best", format: engine.Format{MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`}, output: "a.mp4", video: true, audio: true},
+		{name: "video only", preset: "medium", format: engine.Format{MimeType: `video/webm; codecs="vp9"`}, output: "a.webm", video: true},
+		{name: "audio format", preset: "best", format: engine.Format{MimeType: `audio/webm; codecs="opus"`}, output: "a.webm", audio: true},
+		{name: "audio extension", preset: "best", output: "a.m4a", audio: true},
+		{name: "audio preset", preset: "audio", output: "a.mp4", audio: true},
+	}
+	for _, test := range tests {
+		check := expectedMedia(test.preset, test.format, test.output, time.Minute)
+		if check.Video != test.video || check.Audio != test.audio {
+			t.Errorf("%s: expected video=%v audio=%v, got %+v", test.name, test.video, test.audio, check)
+		}
+	}
+}
+
+func TestSameLength(t *testing.T) {
+	tests := []struct {
+		actual, expected time.Duration
+		same             bool
+	}{
+		{actual: 61 * time.Second, expected: time.Minute, same: true},
+		{actual: 55 * time.Second, expected: time.Minute},
+		// Long videos allow a relative difference
+		{actual: 2*time.Hour - 50*time.Second, expected: 2 * time.Hour, same: true},
+		{actual: 2*time.Hour - 5*time.Minute, expected: 2 * time.Hour},
+	}
+	for _, test := range tests {
+		if got := sameLength(test.actual, test.expected); got != test.same {
+			t.Errorf("sameLength(%s, %s) = %v, want %v", test.actual, test.expected, got, test.same)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinChapters is the fewest timestamps a description needs to count as a
+// chapter list, matching the rule YouTube applies to its own chapter bar
+const MinChapters = 3
+
+// Chapter is a named section of a video
+type Chapter struct {
+	Title string
+	Start time.Duration
+	End   time.Duration // zero for the last chapter when the duration is unknown
+}
+
+// Chapter lines put the timestamp before or after the title, e.g.
+// "00:00 Intro", "1. 0:00 - Intro", "(1:02:03) Finale" or "Intro - 0:00"
+var (
+	leadingTimestamp  = regexp.MustCompile(`^(?:\d{1,3}[.)]\s+|[-*•]\s*)?\(?((?:\d{1,2}:)?\d{1,2}:\d{2})\)?\s*[-–—:|.]?\s*(.*)$`)
+	trailingTimestamp = regexp.MustCompile(`^(.*?)\s*[-–—:|]?\s*\(?((?:\d{1,2}:)?\d{1,2}:\d{2})\)?$`)
+)
+
+// ParseChapters reads chapter markers from a video description. Like YouTube
+// it requires at least MinChapters ascending timestamps starting at 0:00;
+// otherwise the timestamps are ordinary links and nil is returned. duration
+// is the video length in seconds, zero if unknown.
+func ParseChapters(description string, duration int) []Chapter {
+	var chapters []Chapter
+	for _, line := range strings.Split(description, "\n") {
+		line = strings.TrimSpace(line)
+		var stamp, title string
+		if m := leadingTimestamp.FindStringSubmatch(line); m != nil {
+			stamp, title = m[1], m[2]
+		} else if m := trailingTimestamp.FindStringSubmatch(line); m != nil {
+			title, stamp = m[1], m[2]
+		} else {
+			continue
+		}
+		start, ok := parseTimestamp(stamp)
+		if !ok {
+			continue
+		}
+		if len(chapters) == 0 && start != 0 {
+			// A list must start at the beginning of the video
+			continue
+		}
+		if len(chapters) > 0 && start <= chapters[len(chapters)-1].Start {
+			return nil
+		}
+		if duration > 0 && start >= time.Duration(duration)*time.Second {
+			break
+		}
+		title = strings.TrimSpace(title)
+		if title == "" {
+			title = "Chapter " + strconv.Itoa(len(chapters)+1)
+		}
+		chapters = append(chapters, Chapter{Title: title, Start: start})
+	}
+	if len(chapters) < MinChapters {
+		return nil
+	}
+	for i := range chapters[:len(chapters)-1] {
+		chapters[i].End = chapters[i+1].Start
+	}
+	if duration > 0 {
+		chapters[len(chapters)-1].End = time.Duration(duration) * time.Second
+	}
+	return chapters
+}
+
+// parseTimestamp parses "m:ss", "mm:ss" or "h:mm:ss"
+func parseTimestamp(value string) (time.Duration, bool) {
+	var total int
+	parts := strings.Split(value, ":")
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || (i > 0 && n >= 60) {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+	return time.Duration(total) * time.Second, true
+}
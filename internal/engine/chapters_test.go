@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseChapters(t *testing.T) {
+	description := `Full lecture on compilers.
+
+Timestamps:
+0:00 Intro
+1. 02:30 - Lexing
+(10:05) Parsing: LL and LR
+Code generation - 1:02:03
+Links: https://example.com 5:00 is not a chapter line`
+
+	chapters := ParseChapters(description, 4000)
+	expected := []Chapter{
+		{Title: "Intro", Start: 0, End: 150 * time.Second},
+		{Title: "Lexing", Start: 150 * time.Second, End: 605 * time.Second},
+		{Title: "Parsing: LL and LR", Start: 605 * time.Second, End: 3723 * time.Second},
+		{Title: "Code generation", Start: 3723 * time.Second, End: 4000 * time.Second},
+	}
+	if !reflect.DeepEqual(chapters, expected) {
+		t.Errorf("unexpected chapters:\n%+v\nwant\n%+v", chapters, expected)
+	}
+}
+
+func TestParseChaptersRejectsNonChapterTimestamps(t *testing.T) {
+	cases := map[string]string{
+		"too few":         "0:00 Intro\n1:00 End",
+		"not from start":  "0:10 Intro\n1:00 Middle\n2:00 End",
+		"not ascending":   "0:00 Intro\n2:00 Middle\n1:00 End",
+		"no timestamps":   "Just a description",
+		"invalid seconds": "0:00 Intro\n1:75 Middle\n2:00 End",
+	}
+	for name, description := range cases {
+		if chapters := ParseChapters(description, 0); chapters != nil {
+			t.Errorf("%s: expected no chapters, got %+v", name, chapters)
+		}
+	}
+}
+
+func TestParseChaptersUnknownDuration(t *testing.T) {
+	chapters := ParseChapters("00:00\n00:30 Second\n01:00 Third", 0)
+	if len(chapters) != 3 || chapters[0].Title != "Chapter 1" || chapters[2].End != 0 || chapters[1].End != time.Minute {
+		t.Errorf("unexpected chapters %+v", chapters)
+	}
+}
@@ -0,0 +1,393 @@
+package engine
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mpdDynamic is the MPD type of live presentations
+const mpdDynamic = "dynamic"
+
+// mpd is the subset of a DASH media presentation description that is used
+type mpd struct {
+	Type     string      `xml:"type,attr"`
+	Duration string      `xml:"mediaPresentationDuration,attr"`
+	BaseURL  string      `xml:"BaseURL"`
+	Periods  []mpdPeriod `xml:"Period"`
+}
+
+type mpdPeriod struct {
+	Duration       string             `xml:"duration,attr"`
+	BaseURL        string             `xml:"BaseURL"`
+	AdaptationSets []mpdAdaptationSet `xml:"AdaptationSet"`
+}
+
+type mpdAdaptationSet struct {
+	MimeType        string              `xml:"mimeType,attr"`
+	ContentType     string              `xml:"contentType,attr"`
+	BaseURL         string              `xml:"BaseURL"`
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *mpdSegmentList     `xml:"SegmentList"`
+	Representations []mpdRepresentation `xml:"Representation"`
+}
+
+type mpdRepresentation struct {
+	ID              string              `xml:"id,attr"`
+	Bandwidth       int                 `xml:"bandwidth,attr"`
+	Height          int                 `xml:"height,attr"`
+	MimeType        string              `xml:"mimeType,attr"`
+	BaseURL         string              `xml:"BaseURL"`
+	SegmentTemplate *mpdSegmentTemplate `xml:"SegmentTemplate"`
+	SegmentList     *mpdSegmentList     `xml:"SegmentList"`
+}
+
+type mpdSegmentTemplate struct {
+	Media          string `xml:"media,attr"`
+	Initialization string `xml:"initialization,attr"`
+	StartNumber    *int64 `xml:"startNumber,attr"`
+	Timescale      int64  `xml:"timescale,attr"`
+	Duration       int64  `xml:"duration,attr"`
+	Timeline       []struct {
+		T *int64 `xml:"t,attr"`
+		D int64  `xml:"d,attr"`
+		R int64  `xml:"r,attr"`
+	} `xml:"SegmentTimeline>S"`
+}
+
+type mpdSegmentList struct {
+	Initialization struct {
+		SourceURL string `xml:"sourceURL,attr"`
+	} `xml:"Initialization"`
+	SegmentURLs []struct {
+		Media string `xml:"media,attr"`
+	} `xml:"SegmentURL"`
+}
+
+// dashStream is a representation with its resolved segment URLs
+type dashStream struct {
+	ID        string
+	Audio     bool
+	Height    int
+	Bandwidth int
+	MimeType  string
+	Segments  []string // the initialization segment, if any, comes first
+}
+
+// dashTemplateVar matches $Identifier$ and $Identifier%0Nd$ in segment templates
+var dashTemplateVar = regexp.MustCompile(`\$(RepresentationID|Number|Time|Bandwidth)(%0\d+d)?\$`)
+
+// isoDuration matches the ISO 8601 durations used by MPDs, e.g. PT1H2M3.5S
+var isoDuration = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseISODuration returns an ISO 8601 duration in seconds
+func parseISODuration(value string) (float64, error) {
+	match := isoDuration.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	var seconds float64
+	for i, unit := range []float64{86400, 3600, 60, 1} {
+		if match[i+1] != "" {
+			n, _ := strconv.ParseFloat(match[i+1], 64)
+			seconds += n * unit
+		}
+	}
+	return seconds, nil
+}
+
+// expandTemplate fills the identifiers of a segment template
+func expandTemplate(template, representationID string, bandwidth int, number, time int64) string {
+	expanded := dashTemplateVar.ReplaceAllStringFunc(template, func(match string) string {
+		parts := dashTemplateVar.FindStringSubmatch(match)
+		format := "%d"
+		if parts[2] != "" {
+			format = parts[2]
+		}
+		switch parts[1] {
+		case "RepresentationID":
+			return representationID
+		case "Number":
+			return fmt.Sprintf(format, number)
+		case "Time":
+			return fmt.Sprintf(format, time)
+		default:
+			return fmt.Sprintf(format, bandwidth)
+		}
+	})
+	return strings.ReplaceAll(expanded, "$$", "$")
+}
+
+// parseDASH parses a static MPD and returns the streams of its first period.
+// Live (dynamic) presentations return ErrLiveStream.
+func parseDASH(data []byte, base *url.URL) (streams []dashStream, duration float64, err error) {
+	var manifest mpd
+	if err := xml.Unmarshal(data, &manifest); err != nil {
+		return nil, 0, fmt.Errorf("invalid DASH manifest: %w", err)
+	}
+	if manifest.Type == mpdDynamic {
+		return nil, 0, ErrLiveStream
+	}
+	if len(manifest.Periods) == 0 {
+		return nil, 0, errors.New("DASH manifest has no periods")
+	}
+	period := manifest.Periods[0]
+	if manifest.Duration != "" {
+		duration, _ = parseISODuration(manifest.Duration)
+	}
+	if period.Duration != "" {
+		duration, _ = parseISODuration(period.Duration)
+	}
+
+	resolve := func(base *url.URL, ref string) *url.URL {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil || ref == "" {
+			return base
+		}
+		return base.ResolveReference(u)
+	}
+	base = resolve(resolve(base, manifest.BaseURL), period.BaseURL)
+
+	for _, set := range period.AdaptationSets {
+		setBase := resolve(base, set.BaseURL)
+		for _, rep := range set.Representations {
+			repBase := resolve(setBase, rep.BaseURL)
+			mimeType := FirstNonEmpty(rep.MimeType, set.MimeType)
+			kind := set.ContentType
+			if kind == "" {
+				kind, _, _ = strings.Cut(mimeType, "/")
+			}
+			if kind != "video" && kind != "audio" {
+				continue
+			}
+
+			stream := dashStream{
+				ID:        rep.ID,
+				Audio:     kind == "audio",
+				Height:    rep.Height,
+				Bandwidth: rep.Bandwidth,
+				MimeType:  mimeType,
+			}
+			switch {
+			case rep.SegmentTemplate != nil || set.SegmentTemplate != nil:
+				template := rep.SegmentTemplate
+				if template == nil {
+					template = set.SegmentTemplate
+				}
+				refs, err := templateSegments(template, rep, duration)
+				if err != nil {
+					return nil, 0, err
+				}
+				for _, ref := range refs {
+					stream.Segments = append(stream.Segments, resolve(repBase, ref).String())
+				}
+			case rep.SegmentList != nil || set.SegmentList != nil:
+				list := rep.SegmentList
+				if list == nil {
+					list = set.SegmentList
+				}
+				if list.Initialization.SourceURL != "" {
+					stream.Segments = append(stream.Segments, resolve(repBase, list.Initialization.SourceURL).String())
+				}
+				for _, segment := range list.SegmentURLs {
+					stream.Segments = append(stream.Segments, resolve(repBase, segment.Media).String())
+				}
+			default:
+				// A single file per representation
+				stream.Segments = []string{repBase.String()}
+			}
+			streams = append(streams, stream)
+		}
+	}
+	if len(streams) == 0 {
+		return nil, 0, errors.New("DASH manifest has no audio or video streams")
+	}
+	return streams, duration, nil
+}
+
+// templateSegments lists the segment references of a SegmentTemplate, initialization first
+func templateSegments(template *mpdSegmentTemplate, rep mpdRepresentation, duration float64) ([]string, error) {
+	var refs []string
+	if template.Initialization != "" {
+		refs = append(refs, expandTemplate(template.Initialization, rep.ID, rep.Bandwidth, 0, 0))
+	}
+	number := int64(1)
+	if template.StartNumber != nil {
+		number = *template.StartNumber
+	}
+	timescale := template.Timescale
+	if timescale == 0 {
+		timescale = 1
+	}
+
+	if len(template.Timeline) > 0 {
+		var time int64
+		end := int64(math.Ceil(duration * float64(timescale)))
+		for i, s := range template.Timeline {
+			if s.T != nil {
+				time = *s.T
+			}
+			if s.D <= 0 {
+				return nil, errors.New("DASH segment timeline with zero duration")
+			}
+			repeat := s.R
+			if repeat < 0 {
+				// Repeat until the next entry or the end of the period
+				limit := end
+				if i+1 < len(template.Timeline) && template.Timeline[i+1].T != nil {
+					limit = *template.Timeline[i+1].T
+				}
+				repeat = (limit-time+s.D-1)/s.D - 1
+			}
+			for j := int64(0); j <= repeat; j++ {
+				refs = append(refs, expandTemplate(template.Media, rep.ID, rep.Bandwidth, number, time))
+				number++
+				time += s.D
+			}
+		}
+		return refs, nil
+	}
+
+	if template.Duration <= 0 || duration <= 0 {
+		return nil, errors.New("DASH segment template without duration")
+	}
+	count := int64(math.Ceil(duration * float64(timescale) / float64(template.Duration)))
+	for i := int64(0); i < count; i++ {
+		refs = append(refs, expandTemplate(template.Media, rep.ID, rep.Bandwidth, number+i, i*template.Duration))
+	}
+	return refs, nil
+}
+
+// dashExt maps a DASH mime type to a file extension
+func dashExt(mimeType string) string {
+	switch mimeType {
+	case "video/webm", "audio/webm":
+		return "webm"
+	case "audio/mp4":
+		return "m4a"
+	default:
+		return "mp4"
+	}
+}
+
+// fetchDASH downloads and parses a manifest
+func fetchDASH(ctx context.Context, client *http.Client, rawURL string) ([]dashStream, float64, error) {
+	data, finalURL, err := fetchBytes(ctx, client, rawURL, MaxManifestSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parseDASH(data, finalURL)
+}
+
+// resolveDASH lists the video representations, or the audio ones for audio-only presentations
+func (d *Direct) resolveDASH(ctx context.Context, client *http.Client, u *url.URL) (*VideoInfo, error) {
+	streams, duration, err := fetchDASH(ctx, client, u.String())
+	if err != nil {
+		return nil, err
+	}
+	hasVideo := false
+	for _, stream := range streams {
+		hasVideo = hasVideo || !stream.Audio
+	}
+
+	info := &VideoInfo{Duration: int(math.Round(duration))}
+	for _, stream := range streams {
+		if stream.Audio == hasVideo {
+			continue
+		}
+		quality := ""
+		if stream.Height > 0 {
+			quality = fmt.Sprintf("%dp", stream.Height)
+		}
+		info.Formats = append(info.Formats, Format{
+			ID:       stream.ID,
+			URL:      u.String(),
+			Quality:  quality,
+			MimeType: stream.MimeType,
+			Ext:      dashExt(stream.MimeType),
+			Height:   stream.Height,
+			Bitrate:  stream.Bandwidth,
+		})
+	}
+	return info, nil
+}
+
+// downloadDASH fetches the representation with the given ID and, for video, the
+// best audio representation, then joins the segments and merges both with ffmpeg.
+// The result is left at outputPath+PartSuffix.
+func (d *Direct) downloadDASH(ctx context.Context, client *http.Client, manifestURL, representationID, outputPath string, progress func(Progress)) error {
+	streams, _, err := fetchDASH(ctx, client, manifestURL)
+	if err != nil {
+		return err
+	}
+	var video, audio *dashStream
+	for i := range streams {
+		stream := &streams[i]
+		if stream.ID == representationID && video == nil {
+			video = stream
+		}
+		if stream.Audio && (audio == nil || stream.Bandwidth > audio.Bandwidth) {
+			audio = stream
+		}
+	}
+	if video == nil {
+		return fmt.Errorf("DASH representation %q not found", representationID)
+	}
+	if video.Audio {
+		audio = nil
+	}
+	if audio != nil && d.FFmpeg == "" {
+		return ErrNeedsFFmpeg
+	}
+
+	segmentsDir := outputPath + SegmentsDirSuffix
+	if err := os.MkdirAll(segmentsDir, SegmentsDirPerm); err != nil {
+		return err
+	}
+	var jobs []segmentJob
+	addStream := func(stream *dashStream, prefix string) []string {
+		var files []string
+		for i, segmentURL := range stream.Segments {
+			path := filepath.Join(segmentsDir, fmt.Sprintf("%s%06d.seg", prefix, i))
+			jobs = append(jobs, segmentJob{URL: segmentURL, Path: path})
+			files = append(files, path)
+		}
+		return files
+	}
+	videoFiles := addStream(video, "v")
+	var audioFiles []string
+	if audio != nil {
+		audioFiles = addStream(audio, "a")
+	}
+	if err := fetchSegments(ctx, client, jobs, progress); err != nil {
+		return err
+	}
+
+	if audio == nil {
+		if err := concatFiles(outputPath+PartSuffix, videoFiles); err != nil {
+			return err
+		}
+	} else {
+		videoPath := filepath.Join(segmentsDir, "video."+dashExt(video.MimeType))
+		audioPath := filepath.Join(segmentsDir, "audio."+dashExt(audio.MimeType))
+		if err := concatFiles(videoPath, videoFiles); err != nil {
+			return err
+		}
+		if err := concatFiles(audioPath, audioFiles); err != nil {
+			return err
+		}
+		if err := d.merge(ctx, videoPath, audioPath, outputPath); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(segmentsDir)
+}
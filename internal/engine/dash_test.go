@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{"PT10S", 10},
+		{"PT1H2M3.5S", 3723.5},
+		{"P1DT1M", 86460},
+		{"PT0.25S", 0.25},
+	}
+	for _, tt := range tests {
+		if got, err := parseISODuration(tt.value); err != nil || got != tt.want {
+			t.Errorf("parseISODuration(%q) = %v, %v", tt.value, got, err)
+		}
+	}
+	if _, err := parseISODuration("10 seconds"); err == nil {
+		t.Error("expected error for an invalid duration")
+	}
+}
+
+func TestExpandTemplate(t *testing.T) {
+	got := expandTemplate("$RepresentationID$/$Bandwidth$/seg-$Number%05d$-$Time$.m4s?a=$$", "v1", 800000, 42, 9000)
+	if want := "v1/800000/seg-00042-9000.m4s?a=$"; got != want {
+		t.Errorf("expandTemplate = %q, want %q", got, want)
+	}
+}
+
+const sampleMPD = `<?xml version="1.0"?>
+<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" type="static" mediaPresentationDuration="PT10S">
+  <BaseURL>media/</BaseURL>
+  <Period>
+    <AdaptationSet mimeType="video/mp4">
+      <SegmentTemplate initialization="$RepresentationID$/init.mp4" media="$RepresentationID$/$Number$.m4s" startNumber="1" timescale="1000" duration="4000"/>
+      <Representation id="v360" bandwidth="500000" height="360"/>
+      <Representation id="v720" bandwidth="1500000" height="720"/>
+    </AdaptationSet>
+    <AdaptationSet contentType="audio" mimeType="audio/mp4">
+      <Representation id="a64" bandwidth="64000">
+        <SegmentTemplate initialization="a64/init.mp4" media="a64/t$Time$.m4s" timescale="100">
+          <SegmentTimeline><S t="0" d="400" r="1"/><S d="200"/></SegmentTimeline>
+        </SegmentTemplate>
+      </Representation>
+      <Representation id="a128" bandwidth="128000"><BaseURL>audio-128.m4a</BaseURL></Representation>
+    </AdaptationSet>
+    <AdaptationSet mimeType="text/vtt"><Representation id="subs"/></AdaptationSet>
+  </Period>
+</MPD>`
+
+func TestParseDASH(t *testing.T) {
+	base, _ := url.Parse("https://cdn.example.com/show/manifest.mpd")
+	streams, duration, err := parseDASH([]byte(sampleMPD), base)
+	if err != nil {
+		t.Fatalf("parseDASH: %v", err)
+	}
+	if duration != 10 || len(streams) != 4 {
+		t.Fatalf("unexpected duration %v and streams %+v", duration, streams)
+	}
+
+	video := streams[1]
+	wantVideo := []string{
+		"https://cdn.example.com/show/media/v720/init.mp4",
+		"https://cdn.example.com/show/media/v720/1.m4s",
+		"https://cdn.example.com/show/media/v720/2.m4s",
+		"https://cdn.example.com/show/media/v720/3.m4s",
+	}
+	if video.ID != "v720" || video.Audio || video.Height != 720 || strings.Join(video.Segments, " ") != strings.Join(wantVideo, " ") {
+		t.Errorf("unexpected video stream %+v", video)
+	}
+	timeline := streams[2]
+	wantTimeline := []string{
+		"https://cdn.example.com/show/media/a64/init.mp4",
+		"https://cdn.example.com/show/media/a64/t0.m4s",
+		"https://cdn.example.com/show/media/a64/t400.m4s",
+		"https://cdn.example.com/show/media/a64/t800.m4s",
+	}
+	if !timeline.Audio || strings.Join(timeline.Segments, " ") != strings.Join(wantTimeline, " ") {
+		t.Errorf("unexpected timeline stream %+v", timeline)
+	}
+	if single := streams[3]; len(single.Segments) != 1 || single.Segments[0] != "https://cdn.example.com/show/media/audio-128.m4a" {
+		t.Errorf("unexpected single file stream %+v", single)
+	}
+
+	live := strings.Replace(sampleMPD, `type="static"`, `type="dynamic"`, 1)
+	if _, _, err := parseDASH([]byte(live), base); !errors.Is(err, ErrLiveStream) {
+		t.Errorf("expected ErrLiveStream, got %v", err)
+	}
+}
+
+// newDASHServer serves a video-only presentation and one with separate audio
+func newDASHServer(t *testing.T) (*httptest.Server, [][]byte) {
+	t.Helper()
+	parts := [][]byte{[]byte("init;"), bytes.Repeat([]byte("a"), 3000), bytes.Repeat([]byte("b"), 3000), []byte("tail")}
+	videoOnly := `<MPD type="static" mediaPresentationDuration="PT9S"><Period><AdaptationSet mimeType="video/mp4">
+<SegmentTemplate initialization="init.mp4" media="seg$Number%03d$.m4s" startNumber="0" duration="3"/>
+<Representation id="v" bandwidth="1000" height="480"/></AdaptationSet></Period></MPD>`
+	withAudio := strings.Replace(videoOnly, "</AdaptationSet></Period>",
+		`</AdaptationSet><AdaptationSet mimeType="audio/mp4"><Representation id="a" bandwidth="64"><BaseURL>audio.m4a</BaseURL></Representation></AdaptationSet></Period>`, 1)
+
+	files := map[string][]byte{
+		"/video.mpd":  []byte(videoOnly),
+		"/muxed.mpd":  []byte(withAudio),
+		"/init.mp4":   parts[0],
+		"/seg000.m4s": parts[1],
+		"/seg001.m4s": parts[2],
+		"/seg002.m4s": parts[3],
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+	return server, parts
+}
+
+func TestDirectDownloadDASH(t *testing.T) {
+	server, parts := newDASHServer(t)
+	direct := &Direct{}
+
+	info, err := direct.Resolve(context.Background(), server.URL+"/video.mpd", Options{})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if info.Duration != 9 || len(info.Formats) != 1 || info.Formats[0].ID != "v" || info.Formats[0].Ext != "mp4" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+
+	output := filepath.Join(t.TempDir(), "video.mp4")
+	if _, err := direct.Download(context.Background(), server.URL+"/video.mpd", output, Options{}, nil); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if data, err := os.ReadFile(output); err != nil || !bytes.Equal(data, bytes.Join(parts, nil)) {
+		t.Errorf("unexpected output (%d bytes): %v", len(data), err)
+	}
+
+	// KeepPart leaves the finished file for the caller to rename
+	kept := filepath.Join(t.TempDir(), "kept.mp4")
+	if _, err := direct.Download(context.Background(), server.URL+"/video.mpd", kept, Options{KeepPart: true}, nil); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if _, err := os.Stat(kept); !os.IsNotExist(err) {
+		t.Error("expected no file under the final name")
+	}
+	if data, err := os.ReadFile(kept + PartSuffix); err != nil || !bytes.Equal(data, bytes.Join(parts, nil)) {
+		t.Errorf("unexpected partial file (%d bytes): %v", len(data), err)
+	}
+
+	// Separate audio needs ffmpeg to merge; fail before fetching anything
+	merged := filepath.Join(t.TempDir(), "muxed.mp4")
+	if _, err := direct.Download(context.Background(), server.URL+"/muxed.mpd", merged, Options{}, nil); !errors.Is(err, ErrNeedsFFmpeg) {
+		t.Errorf("expected ErrNeedsFFmpeg, got %v", err)
+	}
+	if _, err := os.Stat(merged + SegmentsDirSuffix); !os.IsNotExist(err) {
+		t.Error("no segments must be fetched without ffmpeg")
+	}
+}
@@ -0,0 +1,358 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Partial download files
+const (
+	// PartSuffix marks a file that is still being downloaded; it is renamed on completion
+	PartSuffix = ".part"
+	// SegmentsDirSuffix names the directory holding the fetched segments of a stream
+	SegmentsDirSuffix = ".parts"
+	// PartFilePerm is the permission of partial and segment files
+	PartFilePerm = 0644
+	// SegmentsDirPerm is the permission of segment directories
+	SegmentsDirPerm = 0755
+)
+
+// Transfer constants
+const (
+	// progressInterval is the number of bytes between progress reports of file transfers
+	progressInterval = 256 * 1024
+	// transferBufferSize is the copy buffer of file transfers
+	transferBufferSize = 32 * 1024
+	// MaxManifestSize limits how much of an HLS or DASH manifest is read
+	MaxManifestSize = 8 << 20
+)
+
+// StreamKind tells how a media URL is fetched
+type StreamKind string
+
+const (
+	KindFile StreamKind = "file"
+	KindHLS  StreamKind = "hls"
+	KindDASH StreamKind = "dash"
+)
+
+// Errors of the direct engine
+var (
+	ErrNotSupported = errors.New("not supported by this engine")
+	ErrLiveStream   = errors.New("live stream can only be recorded, not downloaded as a file")
+	ErrNeedsFFmpeg  = errors.New("ffmpeg is required to merge separate audio and video streams")
+)
+
+// Direct is a native engine for plain media URLs (resumed with HTTP Range
+// requests) and HLS/DASH manifests (segments fetched one by one, AES-128
+// HLS segments decrypted, then concatenated or remuxed with ffmpeg). Live
+// HLS playlists are reported by Resolve and recorded with Record.
+type Direct struct {
+	// FFmpeg is the ffmpeg executable; empty disables remuxing and merging
+	FFmpeg string
+}
+
+// NewDirect creates the direct engine, using ffmpeg from PATH when present
+func NewDirect() *Direct {
+	ffmpeg, _ := exec.LookPath(FFmpegCommand)
+	return &Direct{FFmpeg: ffmpeg}
+}
+
+// KindOf classifies a media URL by its extension and, if known, content type
+func KindOf(rawURL, contentType string) StreamKind {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+	switch {
+	case strings.Contains(mediaType, "mpegurl"):
+		return KindHLS
+	case mediaType == "application/dash+xml":
+		return KindDASH
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		switch strings.ToLower(path.Ext(u.Path)) {
+		case ".m3u8", ".m3u":
+			return KindHLS
+		case ".mpd":
+			return KindDASH
+		}
+	}
+	return KindFile
+}
+
+// Resolve describes a media file or the variants of a manifest
+func (d *Direct) Resolve(ctx context.Context, rawURL string, opts Options) (*VideoInfo, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	client := clientFor(opts)
+
+	var info *VideoInfo
+	switch KindOf(rawURL, "") {
+	case KindHLS:
+		info, err = d.resolveHLS(ctx, client, u)
+	case KindDASH:
+		info, err = d.resolveDASH(ctx, client, u)
+	default:
+		info, err = d.resolveFile(ctx, client, u)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Title == "" {
+		info.Title = TitleFromURL(u)
+	}
+	SortFormats(info.Formats, opts.Quality)
+
+	// A master playlist is live if the variant it would record is; a variant
+	// that cannot be fetched is reported by Download instead
+	if KindOf(rawURL, "") == KindHLS && info.Live == LiveNone && info.Formats[0].URL != rawURL {
+		if variant, err := fetchHLS(ctx, client, info.Formats[0].URL); err == nil && variant.Live() {
+			info.Live, info.LiveURL = LiveNow, rawURL
+		}
+	}
+	return info, nil
+}
+
+// Download stores the media at outputPath. Plain files resume from outputPath+PartSuffix,
+// or are split over opts.Segments connections, and streams reuse segments already
+// fetched into outputPath+SegmentsDirSuffix. Every kind of media is finished in
+// outputPath+PartSuffix and renamed unless opts.KeepPart is set.
+func (d *Direct) Download(ctx context.Context, rawURL, outputPath string, opts Options, progress func(Progress)) (*VideoInfo, error) {
+	info, err := d.Resolve(ctx, rawURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	if info.Live != LiveNone {
+		return nil, ErrLiveStream
+	}
+	client := clientFor(opts)
+	format := info.Formats[0]
+
+	switch KindOf(rawURL, "") {
+	case KindHLS:
+		err = d.downloadHLS(ctx, client, format.URL, outputPath, progress)
+	case KindDASH:
+		err = d.downloadDASH(ctx, client, rawURL, format.ID, outputPath, progress)
+	default:
+		err = fetchSegmented(ctx, client, format.URL, outputPath, opts.Segments, progress)
+	}
+	if err == nil {
+		err = finishPart(outputPath, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// finishPart renames the finished outputPath+PartSuffix to outputPath, unless
+// opts.KeepPart leaves that to the caller
+func finishPart(outputPath string, opts Options) error {
+	if opts.KeepPart {
+		return nil
+	}
+	return os.Rename(outputPath+PartSuffix, outputPath)
+}
+
+// PlaylistItems is not supported for direct URLs
+func (d *Direct) PlaylistItems(ctx context.Context, playlistID string, opts Options) ([]PlaylistItem, error) {
+	return nil, ErrNotSupported
+}
+
+// resolveFile describes a single file from a HEAD request; servers that
+// reject HEAD are still downloaded
+func (d *Direct) resolveFile(ctx context.Context, client *http.Client, u *url.URL) (*VideoInfo, error) {
+	format := Format{ID: string(KindFile), URL: u.String(), Ext: strings.TrimPrefix(strings.ToLower(path.Ext(u.Path)), ".")}
+	if req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil); err == nil {
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				format.Size = max(resp.ContentLength, 0)
+				format.MimeType = resp.Header.Get("Content-Type")
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &VideoInfo{Formats: []Format{format}}, nil
+}
+
+// fetchToFile downloads mediaURL into path, resuming from the bytes already in it
+func fetchToFile(ctx context.Context, client *http.Client, mediaURL, path string, progress func(Progress)) error {
+	var offset int64
+	if fi, err := os.Stat(path); err == nil {
+		offset = fi.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	var total int64
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return fmt.Errorf("unexpected Content-Range %q for resume at %d", resp.Header.Get("Content-Range"), offset)
+		}
+		flags |= os.O_APPEND
+		total = size
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file already holds the whole content
+		if _, size, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && size == offset {
+			if progress != nil {
+				progress(Progress{TotalSize: size, DownloadedSize: size})
+			}
+			return nil
+		}
+		return fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		// No range support: start over
+		flags |= os.O_TRUNC
+		offset = 0
+		total = max(resp.ContentLength, 0)
+	default:
+		return fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	}
+
+	file, err := os.OpenFile(path, flags, PartFilePerm)
+	if err != nil {
+		return err
+	}
+	written, copyErr := copyWithProgress(ctx, file, resp.Body, offset, total, progress)
+	if closeErr := file.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		return copyErr
+	}
+	if total > 0 && written != total {
+		return fmt.Errorf("download incomplete: got %d of %d bytes", written, total)
+	}
+	if progress != nil {
+		progress(Progress{TotalSize: written, DownloadedSize: written})
+	}
+	return nil
+}
+
+// copyWithProgress copies src to dst, reporting the running total starting at offset
+func copyWithProgress(ctx context.Context, dst io.Writer, src io.Reader, offset, total int64, progress func(Progress)) (int64, error) {
+	written, reported := offset, offset
+	buf := make([]byte, transferBufferSize)
+	for {
+		n, readErr := src.Read(buf)
+		if n > 0 {
+			if _, err := dst.Write(buf[:n]); err != nil {
+				return written, err
+			}
+			written += int64(n)
+			if progress != nil && written-reported >= progressInterval {
+				reported = written
+				progress(Progress{TotalSize: total, DownloadedSize: written})
+			}
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			if err := ctx.Err(); err != nil {
+				return written, err
+			}
+			return written, readErr
+		}
+	}
+}
+
+// parseContentRange parses "bytes start-end/size"; size is zero when unknown ("*")
+func parseContentRange(value string) (start, size int64, ok bool) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(value), "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rangePart, sizePart, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	if sizePart != "*" {
+		var err error
+		if size, err = strconv.ParseInt(sizePart, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	if rangePart == "*" {
+		return 0, size, true
+	}
+	startPart, _, found := strings.Cut(rangePart, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(startPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+// fetchBytes reads a small resource such as a manifest or a key
+func fetchBytes(ctx context.Context, client *http.Client, rawURL string, limit int64) ([]byte, *url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("GET %s: HTTP %d", rawURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, resp.Request.URL, nil
+}
+
+// clientFor returns the client from opts or the default client
+func clientFor(opts Options) *http.Client {
+	if opts.HTTPClient != nil {
+		return opts.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// TitleFromURL derives a title from the file name of a URL
+func TitleFromURL(u *url.URL) string {
+	name := path.Base(u.Path)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "" || name == "." || name == "/" {
+		return u.Hostname()
+	}
+	return name
+}
@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		url         string
+		contentType string
+		want        StreamKind
+	}{
+		{"https://cdn.example.com/clip.mp4", "", KindFile},
+		{"https://cdn.example.com/master.M3U8?token=1", "", KindHLS},
+		{"https://cdn.example.com/manifest.mpd", "", KindDASH},
+		{"https://cdn.example.com/play", "application/vnd.apple.mpegurl", KindHLS},
+		{"https://cdn.example.com/play", "application/dash+xml; charset=utf-8", KindDASH},
+		{"https://cdn.example.com/play", "video/mp4", KindFile},
+	}
+	for _, tt := range tests {
+		if got := KindOf(tt.url, tt.contentType); got != tt.want {
+			t.Errorf("KindOf(%q, %q) = %s, want %s", tt.url, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		value       string
+		start, size int64
+		ok          bool
+	}{
+		{"bytes 100-199/1000", 100, 1000, true},
+		{"bytes 0-99/*", 0, 0, true},
+		{"bytes */1000", 0, 1000, true},
+		{"items 0-1/2", 0, 0, false},
+		{"bytes x-1/2", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, size, ok := parseContentRange(tt.value)
+		if start != tt.start || size != tt.size || ok != tt.ok {
+			t.Errorf("parseContentRange(%q) = %d, %d, %v", tt.value, start, size, ok)
+		}
+	}
+}
+
+// rangeRecorder serves a payload and records the Range headers it receives
+type rangeRecorder struct {
+	mutex   sync.Mutex
+	ranges  []string
+	payload []byte
+	noRange bool
+}
+
+func (r *rangeRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	r.ranges = append(r.ranges, req.Header.Get("Range"))
+	r.mutex.Unlock()
+	if r.noRange {
+		req.Header.Del("Range")
+	}
+	http.ServeContent(w, req, "clip.mp4", time.Time{}, bytes.NewReader(r.payload))
+}
+
+func TestDownloadFileResumesPartialFile(t *testing.T) {
+	payload := []byte(strings.Repeat("0123456789", 100000))
+
+	tests := []struct {
+		name      string
+		noRange   bool
+		wantRange string
+	}{
+		{"range supported", false, "bytes=400000-"},
+		{"range ignored", true, "bytes=400000-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &rangeRecorder{payload: payload, noRange: tt.noRange}
+			server := httptest.NewServer(recorder)
+			defer server.Close()
+
+			output := filepath.Join(t.TempDir(), "clip.mp4")
+			if err := os.WriteFile(output+PartSuffix, payload[:400000], PartFilePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			var last Progress
+			direct := &Direct{}
+			if _, err := direct.Download(context.Background(), server.URL+"/clip.mp4", output, Options{}, func(p Progress) {
+				last = p
+			}); err != nil {
+				t.Fatalf("Download: %v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil || !bytes.Equal(data, payload) {
+				t.Fatalf("unexpected output (%d bytes): %v", len(data), err)
+			}
+			if _, err := os.Stat(output + PartSuffix); !os.IsNotExist(err) {
+				t.Error("partial file must be renamed on completion")
+			}
+			if got := recorder.ranges[len(recorder.ranges)-1]; got != tt.wantRange {
+				t.Errorf("expected Range %q, got %q", tt.wantRange, got)
+			}
+			if last.DownloadedSize != int64(len(payload)) || last.TotalSize != int64(len(payload)) {
+				t.Errorf("unexpected final progress %+v", last)
+			}
+		})
+	}
+}
+
+func TestDownloadFileAlreadyComplete(t *testing.T) {
+	payload := []byte("complete")
+	server := httptest.NewServer(&rangeRecorder{payload: payload})
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "clip.mp4")
+	os.WriteFile(output+PartSuffix, payload, PartFilePerm)
+	if _, err := (&Direct{}).Download(context.Background(), server.URL+"/clip.mp4", output, Options{}, nil); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if data, _ := os.ReadFile(output); !bytes.Equal(data, payload) {
+		t.Errorf("unexpected output %q", data)
+	}
+}
+
+func TestDownloadFileKeepsPartialFileOnError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "missing.mp4")
+	if _, err := (&Direct{}).Download(context.Background(), server.URL+"/missing.mp4", output, Options{}, nil); err == nil {
+		t.Fatal("expected error for HTTP 404")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("expected no output file after a failed request")
+	}
+}
@@ -0,0 +1,9 @@
+package engine
+
+// Package engine defines the media engine used by the download service and
+// the playlist parsers: resolving video metadata, downloading media with
+// progress reporting and listing playlist items. YTDLP wraps the pure Go
+// github.com/ytget/ytdlp library; Direct natively downloads plain media
+// URLs with Range-based resume and HLS/DASH manifests segment by segment,
+// and records live HLS broadcasts; Fake is a deterministic in-memory engine for tests that simulates
+// progress, errors and cancellation.
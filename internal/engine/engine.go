@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Options configures a single engine call
+type Options struct {
+	// Quality is a format selector such as "best" or "height<=480"
+	Quality string
+	// Ext is an optional preferred file extension
+	Ext string
+	// HTTPClient carries the proxy and cookies; nil uses a default client
+	HTTPClient *http.Client
+	// Segments is the number of concurrent connections for a single file;
+	// values below 2 use one stream. Engines that cannot split ignore it.
+	Segments int
+	// KeepPart leaves the finished download at outputPath+PartSuffix, so the
+	// caller can post-process it before it appears under its final name
+	KeepPart bool
+}
+
+// Format describes an available media format
+type Format struct {
+	ID       string
+	URL      string
+	Quality  string
+	MimeType string
+	Ext      string // file extension without dot, empty if unknown
+	Height   int    // video height in pixels, zero if unknown
+	Bitrate  int
+	Size     int64
+}
+
+// LiveStatus tells whether a video is a live broadcast
+type LiveStatus string
+
+const (
+	LiveNone     LiveStatus = ""         // a regular video
+	LiveNow      LiveStatus = "live"     // broadcasting now
+	LiveUpcoming LiveStatus = "upcoming" // scheduled live stream or premiere
+)
+
+// VideoInfo contains video metadata and the available formats
+type VideoInfo struct {
+	ID       string
+	Title    string
+	Author   string
+	Duration int // seconds
+	Formats  []Format
+	Chapters []Chapter
+
+	// Live broadcasts are recorded from LiveURL, an HLS playlist, instead of downloaded
+	Live           LiveStatus
+	ScheduledStart time.Time // start of an upcoming broadcast, zero if unknown
+	LiveURL        string
+}
+
+// Progress describes an ongoing download
+type Progress struct {
+	TotalSize      int64 // zero if unknown
+	DownloadedSize int64
+	Recorded       time.Duration // media recorded so far, for live recordings
+}
+
+// PlaylistItem is one entry of a playlist
+type PlaylistItem struct {
+	VideoID string
+	Title   string
+	Index   int
+}
+
+// Engine resolves, downloads and lists media. Implementations must honour
+// context cancellation and return the context error when cancelled.
+type Engine interface {
+	// Resolve fetches metadata for a video URL
+	Resolve(ctx context.Context, url string, opts Options) (*VideoInfo, error)
+
+	// Download stores the video at outputPath, reporting progress when it is not nil
+	Download(ctx context.Context, url, outputPath string, opts Options, progress func(Progress)) (*VideoInfo, error)
+
+	// PlaylistItems lists all items of a playlist
+	PlaylistItems(ctx context.Context, playlistID string, opts Options) ([]PlaylistItem, error)
+}
@@ -0,0 +1,217 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Fake engine defaults
+const (
+	DefaultFakeSize  = 4096
+	DefaultFakeSteps = 4
+	FakeFilePerm     = 0644
+)
+
+// ErrFakeNotFound is returned for URLs and playlists the fake does not know
+var ErrFakeNotFound = errors.New("fake engine: not found")
+
+// FakeVideo scripts the behaviour of one URL in the fake engine
+type FakeVideo struct {
+	Info VideoInfo
+	// Size is the number of bytes written on success; zero uses DefaultFakeSize
+	Size int64
+	// Steps is the number of progress updates per download; zero uses DefaultFakeSteps
+	Steps int
+	// ResolveErr fails metadata resolution
+	ResolveErr error
+	// DownloadErr fails the download after the first progress update
+	DownloadErr error
+	// FailTimes limits DownloadErr to the first n downloads; zero fails every time
+	FailTimes int
+	// Hold, when set, blocks every download after its first progress update
+	// until the channel is closed or the context is cancelled
+	Hold chan struct{}
+}
+
+// FakePlaylist scripts the behaviour of one playlist ID in the fake engine
+type FakePlaylist struct {
+	Items []PlaylistItem
+	Err   error
+}
+
+// Fake is a deterministic in-memory engine for tests. Like the real engines it
+// writes outputPath+PartSuffix, which holds Size bytes and is renamed to the
+// output path on success, and it never touches the network.
+type Fake struct {
+	mutex     sync.Mutex
+	videos    map[string]*FakeVideo
+	playlists map[string]*FakePlaylist
+	downloads map[string]int
+	started   chan string
+
+	// StepDelay is waited between progress updates
+	StepDelay time.Duration
+}
+
+// NewFake creates an empty fake engine
+func NewFake() *Fake {
+	return &Fake{
+		videos:    make(map[string]*FakeVideo),
+		playlists: make(map[string]*FakePlaylist),
+		downloads: make(map[string]int),
+		started:   make(chan string, 64),
+	}
+}
+
+// AddVideo registers the behaviour for a URL
+func (f *Fake) AddVideo(url string, video FakeVideo) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.videos[url] = &video
+}
+
+// AddPlaylist registers the items or error for a playlist ID
+func (f *Fake) AddPlaylist(playlistID string, playlist FakePlaylist) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.playlists[playlistID] = &playlist
+}
+
+// Downloads returns how many times a download of the URL was started
+func (f *Fake) Downloads(url string) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.downloads[url]
+}
+
+// Started receives the URL of every download after its first progress update.
+// Sends are dropped when nobody reads and the buffer is full.
+func (f *Fake) Started() <-chan string {
+	return f.started
+}
+
+// Resolve returns the scripted metadata of the URL
+func (f *Fake) Resolve(ctx context.Context, url string, opts Options) (*VideoInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	video, err := f.video(url)
+	if err != nil {
+		return nil, err
+	}
+	if video.ResolveErr != nil {
+		return nil, video.ResolveErr
+	}
+	info := video.Info
+	return &info, nil
+}
+
+// Download simulates a transfer in Steps progress updates and writes the file
+func (f *Fake) Download(ctx context.Context, url, outputPath string, opts Options, progress func(Progress)) (*VideoInfo, error) {
+	video, err := f.video(url)
+	if err != nil {
+		return nil, err
+	}
+	if video.ResolveErr != nil {
+		return nil, video.ResolveErr
+	}
+
+	f.mutex.Lock()
+	f.downloads[url]++
+	attempt := f.downloads[url]
+	f.mutex.Unlock()
+
+	size, steps := video.Size, video.Steps
+	if size <= 0 {
+		size = DefaultFakeSize
+	}
+	if steps <= 0 {
+		steps = DefaultFakeSteps
+	}
+
+	for step := 1; step <= steps; step++ {
+		if err := f.wait(ctx, f.StepDelay); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(Progress{TotalSize: size, DownloadedSize: size * int64(step) / int64(steps)})
+		}
+		if step > 1 {
+			continue
+		}
+		if err := os.WriteFile(outputPath+PartSuffix, make([]byte, size/int64(steps)), FakeFilePerm); err != nil {
+			return nil, fmt.Errorf("fake engine: %w", err)
+		}
+
+		select {
+		case f.started <- url:
+		default:
+		}
+		if video.DownloadErr != nil && (video.FailTimes == 0 || attempt <= video.FailTimes) {
+			return nil, video.DownloadErr
+		}
+		if video.Hold != nil {
+			select {
+			case <-video.Hold:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if err := os.WriteFile(outputPath+PartSuffix, make([]byte, size), FakeFilePerm); err != nil {
+		return nil, fmt.Errorf("fake engine: %w", err)
+	}
+	if err := finishPart(outputPath, opts); err != nil {
+		return nil, fmt.Errorf("fake engine: %w", err)
+	}
+	info := video.Info
+	return &info, nil
+}
+
+// PlaylistItems returns the scripted items of the playlist
+func (f *Fake) PlaylistItems(ctx context.Context, playlistID string, opts Options) ([]PlaylistItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mutex.Lock()
+	playlist, ok := f.playlists[playlistID]
+	f.mutex.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: playlist %s", ErrFakeNotFound, playlistID)
+	}
+	if playlist.Err != nil {
+		return nil, playlist.Err
+	}
+	return append([]PlaylistItem(nil), playlist.Items...), nil
+}
+
+// video returns the scripted behaviour of a URL
+func (f *Fake) video(url string) (*FakeVideo, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	video, ok := f.videos[url]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFakeNotFound, url)
+	}
+	return video, nil
+}
+
+// wait sleeps for d unless the context is cancelled first
+func (f *Fake) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Compile-time checks that the engines satisfy the interface
+var (
+	_ Engine = (*YTDLP)(nil)
+	_ Engine = (*Fake)(nil)
+	_ Engine = (*Direct)(nil)
+)
+
+func TestFakeDownloadProgress(t *testing.T) {
+	fake := NewFake()
+	fake.AddVideo("https://v/1", FakeVideo{Info: VideoInfo{ID: "1", Title: "One"}, Size: 100, Steps: 4})
+
+	info, err := fake.Resolve(context.Background(), "https://v/1", Options{})
+	if err != nil || info.Title != "One" {
+		t.Fatalf("Resolve: %v %v", info, err)
+	}
+
+	output := filepath.Join(t.TempDir(), "one.mp4")
+	var updates []Progress
+	if _, err := fake.Download(context.Background(), "https://v/1", output, Options{}, func(p Progress) {
+		updates = append(updates, p)
+	}); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	if len(updates) != 4 || updates[0].DownloadedSize != 25 || updates[3].DownloadedSize != 100 {
+		t.Errorf("unexpected progress updates: %+v", updates)
+	}
+	if fi, err := os.Stat(output); err != nil || fi.Size() != 100 {
+		t.Errorf("expected 100 byte output, got %v %v", fi, err)
+	}
+	if fake.Downloads("https://v/1") != 1 {
+		t.Errorf("expected one recorded download, got %d", fake.Downloads("https://v/1"))
+	}
+}
+
+func TestFakeErrors(t *testing.T) {
+	boom := errors.New("boom")
+	fake := NewFake()
+	fake.AddVideo("https://v/resolve", FakeVideo{ResolveErr: boom})
+	fake.AddVideo("https://v/flaky", FakeVideo{DownloadErr: boom, FailTimes: 1})
+	fake.AddPlaylist("PLbad", FakePlaylist{Err: boom})
+	dir := t.TempDir()
+
+	if _, err := fake.Resolve(context.Background(), "https://v/resolve", Options{}); !errors.Is(err, boom) {
+		t.Errorf("expected resolve error, got %v", err)
+	}
+	if _, err := fake.Resolve(context.Background(), "https://v/unknown", Options{}); !errors.Is(err, ErrFakeNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := fake.Download(context.Background(), "https://v/flaky", filepath.Join(dir, "a"), Options{}, nil); !errors.Is(err, boom) {
+		t.Errorf("expected first download to fail, got %v", err)
+	}
+	if _, err := fake.Download(context.Background(), "https://v/flaky", filepath.Join(dir, "a"), Options{}, nil); err != nil {
+		t.Errorf("expected retry to succeed, got %v", err)
+	}
+	if _, err := fake.PlaylistItems(context.Background(), "PLbad", Options{}); !errors.Is(err, boom) {
+		t.Errorf("expected playlist error, got %v", err)
+	}
+}
+
+func TestFakeHoldAndCancel(t *testing.T) {
+	hold := make(chan struct{})
+	fake := NewFake()
+	fake.AddVideo("https://v/held", FakeVideo{Hold: hold})
+	output := filepath.Join(t.TempDir(), "held")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := fake.Download(ctx, "https://v/held", output, Options{}, nil)
+		done <- err
+	}()
+
+	select {
+	case url := <-fake.Started():
+		if url != "https://v/held" {
+			t.Fatalf("unexpected started URL %s", url)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("download did not start")
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("cancelled download must not write the file")
+	}
+
+	close(hold)
+	if _, err := fake.Download(context.Background(), "https://v/held", output, Options{}, nil); err != nil {
+		t.Fatalf("released download failed: %v", err)
+	}
+}
+
+func TestFakePlaylistItems(t *testing.T) {
+	fake := NewFake()
+	fake.AddPlaylist("PL1", FakePlaylist{Items: []PlaylistItem{{VideoID: "a", Index: 1}, {VideoID: "b", Index: 2}}})
+
+	items, err := fake.PlaylistItems(context.Background(), "PL1", Options{})
+	if err != nil || len(items) != 2 || items[1].VideoID != "b" {
+		t.Fatalf("unexpected items %v (%v)", items, err)
+	}
+	if _, err := fake.PlaylistItems(context.Background(), "PL2", Options{}); !errors.Is(err, ErrFakeNotFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
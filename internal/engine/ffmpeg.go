@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// FFmpeg invocation
+const (
+	// FFmpegCommand is the ffmpeg executable looked up in PATH
+	FFmpegCommand = "ffmpeg"
+	// FFmpegLogLevel keeps ffmpeg quiet unless something fails
+	FFmpegLogLevel = "error"
+)
+
+// muxers names the ffmpeg output formats of extensions that differ from the format name
+var muxers = map[string]string{
+	".m4a": "ipod",
+	".m4v": "mp4",
+	".mkv": "matroska",
+	".mka": "matroska",
+	".ts":  "mpegts",
+	".oga": "ogg",
+	".aac": "adts",
+}
+
+// MuxerArgs returns the ffmpeg arguments that select the output format of path.
+// ffmpeg cannot tell the container of a partial file from its name, so it is
+// taken from the extension before PartSuffix; other paths need no arguments.
+func MuxerArgs(path string) []string {
+	if !strings.HasSuffix(path, PartSuffix) {
+		return nil
+	}
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(path, PartSuffix)))
+	format, ok := muxers[ext]
+	if !ok {
+		format = strings.TrimPrefix(ext, ".")
+	}
+	if format == "" {
+		return nil
+	}
+	return []string{"-f", format}
+}
+
+// remux copies the streams of input into the container implied by the extension
+// of output and leaves the result at output+PartSuffix
+func (d *Direct) remux(ctx context.Context, input, output string) error {
+	return d.runFFmpeg(ctx, output, "-i", input, "-map", "0", "-c", "copy")
+}
+
+// merge muxes the first video stream of video and the first audio stream of
+// audio into output+PartSuffix
+func (d *Direct) merge(ctx context.Context, video, audio, output string) error {
+	return d.runFFmpeg(ctx, output, "-i", video, "-i", audio, "-map", "0:v:0", "-map", "1:a:0", "-c", "copy")
+}
+
+// runFFmpeg runs ffmpeg with the input arguments and writes output+PartSuffix,
+// removing it on failure. The caller renames the finished file, so nothing
+// incomplete ever appears under the output name.
+func (d *Direct) runFFmpeg(ctx context.Context, output string, inputArgs ...string) error {
+	if d.FFmpeg == "" {
+		return ErrNeedsFFmpeg
+	}
+	partPath := output + PartSuffix
+	args := append([]string{"-y", "-loglevel", FFmpegLogLevel}, inputArgs...)
+	args = append(args, MuxerArgs(partPath)...)
+	args = append(args, partPath)
+	out, err := exec.CommandContext(ctx, d.FFmpeg, args...).CombinedOutput()
+	if err != nil {
+		os.Remove(partPath)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("ffmpeg failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMuxerArgs(t *testing.T) {
+	tests := map[string][]string{
+		"/videos/Intro.mp4.part":  {"-f", "mp4"},
+		"/videos/Intro.webm.part": {"-f", "webm"},
+		"/videos/Song.m4a.part":   {"-f", "ipod"},
+		"/videos/Live.ts.part":    {"-f", "mpegts"},
+		"/videos/Intro.mp4":       nil,
+		"/videos/Intro.part":      nil,
+	}
+	for path, expected := range tests {
+		if got := MuxerArgs(path); !slices.Equal(got, expected) {
+			t.Errorf("MuxerArgs(%s) = %v, expected %v", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortFormats orders formats by preference for the quality selector, best first.
+// "height<=N" prefers the tallest format not above N; anything else prefers the tallest.
+// Formats of unknown height rank after formats that satisfy the limit.
+func SortFormats(formats []Format, quality string) {
+	maxHeight := 0
+	if limit, ok := strings.CutPrefix(quality, "height<="); ok {
+		maxHeight, _ = strconv.Atoi(limit)
+	}
+	fits := func(f Format) bool {
+		return f.Height > 0 && (maxHeight == 0 || f.Height <= maxHeight)
+	}
+	sort.SliceStable(formats, func(i, j int) bool {
+		a, b := formats[i], formats[j]
+		if fits(a) != fits(b) {
+			return fits(a)
+		}
+		if a.Height != b.Height {
+			if fits(a) {
+				return a.Height > b.Height
+			}
+			// Neither fits: the smallest known height is closest to the limit
+			return a.Height != 0 && (b.Height == 0 || a.Height < b.Height)
+		}
+		return a.Bitrate > b.Bitrate
+	})
+}
+
+// FirstNonEmpty returns the first non-empty value, e.g. of alternative attributes
+func FirstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
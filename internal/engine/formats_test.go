@@ -0,0 +1,21 @@
+package engine
+
+import "testing"
+
+func TestSortFormats(t *testing.T) {
+	formats := []Format{
+		{ID: "360", Height: 360},
+		{ID: "unknown"},
+		{ID: "1080", Height: 1080},
+		{ID: "480", Height: 480},
+	}
+
+	SortFormats(formats, "best")
+	if formats[0].ID != "1080" || formats[3].ID != "unknown" {
+		t.Errorf("best: unexpected order %v", formats)
+	}
+	SortFormats(formats, "height<=480")
+	if formats[0].ID != "480" || formats[1].ID != "360" || formats[2].ID != "1080" {
+		t.Errorf("height<=480: unexpected order %v", formats)
+	}
+}
@@ -0,0 +1,355 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HLS key methods
+const (
+	hlsMethodNone   = "NONE"
+	hlsMethodAES128 = "AES-128"
+)
+
+// hlsVariant is a stream listed by a master playlist
+type hlsVariant struct {
+	URL       string
+	Bandwidth int
+	Height    int
+	Codecs    string
+}
+
+// hlsKey is the encryption of the segments following an EXT-X-KEY tag
+type hlsKey struct {
+	Method string
+	URI    string
+	IV     []byte // nil: derived from the media sequence number
+}
+
+// hlsSegment is one media segment of a media playlist
+type hlsSegment struct {
+	URL      string
+	Duration float64
+	Sequence int64
+	Key      *hlsKey
+	Init     string // EXT-X-MAP initialization section, empty for MPEG-TS
+}
+
+// hlsPlaylist is a parsed master or media playlist
+type hlsPlaylist struct {
+	Variants []hlsVariant
+	Segments []hlsSegment
+	EndList  bool
+	Type     string  // EXT-X-PLAYLIST-TYPE: VOD, EVENT or empty
+	Target   float64 // EXT-X-TARGETDURATION in seconds
+}
+
+// Live reports whether a media playlist is still growing
+func (p *hlsPlaylist) Live() bool {
+	return len(p.Variants) == 0 && !p.EndList && p.Type != "VOD"
+}
+
+// Duration returns the total duration of the segments in seconds
+func (p *hlsPlaylist) Duration() float64 {
+	var total float64
+	for _, segment := range p.Segments {
+		total += segment.Duration
+	}
+	return total
+}
+
+// parseHLS parses a master or media playlist, resolving URIs against base
+func parseHLS(data []byte, base *url.URL) (*hlsPlaylist, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxManifestSize)
+	if !scanner.Scan() || strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff")) != "#EXTM3U" {
+		return nil, errors.New("not an HLS playlist")
+	}
+
+	resolve := func(ref string) (string, error) {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(u).String(), nil
+	}
+
+	playlist := &hlsPlaylist{}
+	var (
+		sequence    int64
+		duration    float64
+		key         *hlsKey
+		initURL     string
+		nextVariant *hlsVariant
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		tag, value, _ := strings.Cut(line, ":")
+		switch {
+		case tag == "#EXT-X-STREAM-INF":
+			attrs := parseAttributes(value)
+			bandwidth, _ := strconv.Atoi(attrs["BANDWIDTH"])
+			variant := hlsVariant{Bandwidth: bandwidth, Codecs: attrs["CODECS"]}
+			if _, height, ok := strings.Cut(attrs["RESOLUTION"], "x"); ok {
+				variant.Height, _ = strconv.Atoi(height)
+			}
+			nextVariant = &variant
+		case tag == "#EXT-X-TARGETDURATION":
+			playlist.Target, _ = strconv.ParseFloat(value, 64)
+		case tag == "#EXT-X-MEDIA-SEQUENCE":
+			sequence, _ = strconv.ParseInt(value, 10, 64)
+		case tag == "#EXT-X-PLAYLIST-TYPE":
+			playlist.Type = strings.ToUpper(value)
+		case tag == "#EXT-X-ENDLIST":
+			playlist.EndList = true
+		case tag == "#EXTINF":
+			durationPart, _, _ := strings.Cut(value, ",")
+			duration, _ = strconv.ParseFloat(durationPart, 64)
+		case tag == "#EXT-X-KEY":
+			attrs := parseAttributes(value)
+			method := strings.ToUpper(attrs["METHOD"])
+			switch method {
+			case hlsMethodNone:
+				key = nil
+			case hlsMethodAES128:
+				uri, err := resolve(attrs["URI"])
+				if err != nil || attrs["URI"] == "" {
+					return nil, errors.New("HLS key without URI")
+				}
+				key = &hlsKey{Method: method, URI: uri}
+				if iv := attrs["IV"]; iv != "" {
+					if key.IV, err = parseIV(iv); err != nil {
+						return nil, err
+					}
+				}
+			default:
+				return nil, fmt.Errorf("unsupported HLS encryption %s", method)
+			}
+		case tag == "#EXT-X-MAP":
+			attrs := parseAttributes(value)
+			if _, hasRange := attrs["BYTERANGE"]; hasRange {
+				return nil, errors.New("HLS byte-range segments are not supported")
+			}
+			var err error
+			if initURL, err = resolve(attrs["URI"]); err != nil {
+				return nil, err
+			}
+		case tag == "#EXT-X-BYTERANGE":
+			return nil, errors.New("HLS byte-range segments are not supported")
+		case strings.HasPrefix(line, "#"):
+			// Comments and tags that do not affect fetching
+		default:
+			uri, err := resolve(line)
+			if err != nil {
+				return nil, err
+			}
+			if nextVariant != nil {
+				nextVariant.URL = uri
+				playlist.Variants = append(playlist.Variants, *nextVariant)
+				nextVariant = nil
+				continue
+			}
+			playlist.Segments = append(playlist.Segments, hlsSegment{
+				URL:      uri,
+				Duration: duration,
+				Sequence: sequence,
+				Key:      key,
+				Init:     initURL,
+			})
+			sequence++
+			duration = 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(playlist.Variants) == 0 && len(playlist.Segments) == 0 && playlist.EndList {
+		return nil, errors.New("HLS playlist has no segments")
+	}
+	return playlist, nil
+}
+
+// parseAttributes parses an HLS attribute list such as BANDWIDTH=1,CODECS="a,b"
+func parseAttributes(value string) map[string]string {
+	attrs := make(map[string]string)
+	for value != "" {
+		name, rest, found := strings.Cut(value, "=")
+		if !found {
+			break
+		}
+		var attr string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				attr, rest = rest[1:], ""
+			} else {
+				attr, rest = rest[1:end+1], rest[end+2:]
+			}
+			_, rest, _ = strings.Cut(rest, ",")
+		} else {
+			attr, rest, _ = strings.Cut(rest, ",")
+		}
+		attrs[strings.ToUpper(strings.TrimSpace(name))] = attr
+		value = strings.TrimSpace(rest)
+	}
+	return attrs
+}
+
+// parseIV parses a 0x-prefixed 128-bit hexadecimal IV
+func parseIV(value string) ([]byte, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(value, "0x"), "0X")
+	iv, err := hex.DecodeString(fmt.Sprintf("%032s", digits))
+	if err != nil || len(iv) != 16 {
+		return nil, fmt.Errorf("invalid HLS IV %q", value)
+	}
+	return iv, nil
+}
+
+// sequenceIV is the default IV of a segment: its media sequence number as a big-endian 128-bit integer
+func sequenceIV(sequence int64) []byte {
+	iv := make([]byte, 16)
+	binary.BigEndian.PutUint64(iv[8:], uint64(sequence))
+	return iv
+}
+
+// fetchHLS downloads and parses a playlist
+func fetchHLS(ctx context.Context, client *http.Client, rawURL string) (*hlsPlaylist, error) {
+	data, finalURL, err := fetchBytes(ctx, client, rawURL, MaxManifestSize)
+	if err != nil {
+		return nil, err
+	}
+	return parseHLS(data, finalURL)
+}
+
+// hlsExt is the extension of the file produced from a playlist: fragmented MP4
+// segments are already MP4, MPEG-TS segments become MP4 only when ffmpeg can remux them
+func (d *Direct) hlsExt(fragmented bool) string {
+	if fragmented || d.FFmpeg != "" {
+		return "mp4"
+	}
+	return "ts"
+}
+
+// resolveHLS lists the variants of a master playlist, or the stream of a media playlist
+func (d *Direct) resolveHLS(ctx context.Context, client *http.Client, u *url.URL) (*VideoInfo, error) {
+	playlist, err := fetchHLS(ctx, client, u.String())
+	if err != nil {
+		return nil, err
+	}
+	info := &VideoInfo{}
+	if len(playlist.Variants) == 0 {
+		if playlist.Live() {
+			info.Live, info.LiveURL = LiveNow, u.String()
+		}
+		info.Duration = int(math.Round(playlist.Duration()))
+		fragmented := len(playlist.Segments) > 0 && playlist.Segments[0].Init != ""
+		info.Formats = []Format{{ID: string(KindHLS), URL: u.String(), Ext: d.hlsExt(fragmented)}}
+		return info, nil
+	}
+	for i, variant := range playlist.Variants {
+		quality := ""
+		if variant.Height > 0 {
+			quality = fmt.Sprintf("%dp", variant.Height)
+		}
+		info.Formats = append(info.Formats, Format{
+			ID:       fmt.Sprintf("hls-%d", i),
+			URL:      variant.URL,
+			Quality:  quality,
+			MimeType: "application/vnd.apple.mpegurl",
+			Ext:      d.hlsExt(false),
+			Height:   variant.Height,
+			Bitrate:  variant.Bandwidth,
+		})
+	}
+	return info, nil
+}
+
+// downloadHLS fetches the segments of a media playlist into outputPath+SegmentsDirSuffix,
+// decrypts AES-128 segments, joins them and remuxes MPEG-TS to the output container.
+// The result is left at outputPath+PartSuffix.
+func (d *Direct) downloadHLS(ctx context.Context, client *http.Client, playlistURL, outputPath string, progress func(Progress)) error {
+	playlist, err := fetchHLS(ctx, client, playlistURL)
+	if err != nil {
+		return err
+	}
+	if len(playlist.Variants) > 0 {
+		return errors.New("HLS variant playlist points at another master playlist")
+	}
+	if playlist.Live() {
+		return ErrLiveStream
+	}
+
+	segmentsDir := outputPath + SegmentsDirSuffix
+	if err := os.MkdirAll(segmentsDir, SegmentsDirPerm); err != nil {
+		return err
+	}
+
+	keys := make(map[string][]byte)
+	var jobs []segmentJob
+	var files []string
+	inits := make(map[string]bool)
+	for i, segment := range playlist.Segments {
+		if segment.Init != "" && !inits[segment.Init] {
+			job := segmentJob{URL: segment.Init, Path: filepath.Join(segmentsDir, fmt.Sprintf("init%d.mp4", len(inits)))}
+			inits[segment.Init] = true
+			jobs = append(jobs, job)
+			files = append(files, job.Path)
+		}
+		job := segmentJob{URL: segment.URL, Path: filepath.Join(segmentsDir, fmt.Sprintf("%06d.seg", i))}
+		if segment.Key != nil {
+			key, ok := keys[segment.Key.URI]
+			if !ok {
+				key, _, err = fetchBytes(ctx, client, segment.Key.URI, 16)
+				if err != nil {
+					return fmt.Errorf("HLS key: %w", err)
+				}
+				if len(key) != 16 {
+					return fmt.Errorf("HLS key has %d bytes, expected 16", len(key))
+				}
+				keys[segment.Key.URI] = key
+			}
+			job.Key, job.IV = key, segment.Key.IV
+			if job.IV == nil {
+				job.IV = sequenceIV(segment.Sequence)
+			}
+		}
+		jobs = append(jobs, job)
+		files = append(files, job.Path)
+	}
+
+	if err := fetchSegments(ctx, client, jobs, progress); err != nil {
+		return err
+	}
+
+	fragmented := len(inits) > 0
+	if fragmented || d.FFmpeg == "" || strings.EqualFold(filepath.Ext(outputPath), ".ts") {
+		// Already in the output container: join the segments in place
+		if err := concatFiles(outputPath+PartSuffix, files); err != nil {
+			return err
+		}
+	} else {
+		joined := filepath.Join(segmentsDir, "joined.ts")
+		if err := concatFiles(joined, files); err != nil {
+			return err
+		}
+		if err := d.remux(ctx, joined, outputPath); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(segmentsDir)
+}
@@ -0,0 +1,254 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseHLSMaster(t *testing.T) {
+	master := `#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360,CODECS="avc1.4d401e,mp4a.40.2"
+low/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=2500000,RESOLUTION=1280x720
+https://other.example.com/hd.m3u8
+`
+	base, _ := url.Parse("https://cdn.example.com/show/master.m3u8")
+	playlist, err := parseHLS([]byte(master), base)
+	if err != nil {
+		t.Fatalf("parseHLS: %v", err)
+	}
+	if len(playlist.Variants) != 2 || playlist.Live() {
+		t.Fatalf("unexpected playlist %+v", playlist)
+	}
+	low := playlist.Variants[0]
+	if low.URL != "https://cdn.example.com/show/low/index.m3u8" || low.Bandwidth != 800000 || low.Height != 360 || low.Codecs != "avc1.4d401e,mp4a.40.2" {
+		t.Errorf("unexpected variant %+v", low)
+	}
+	if playlist.Variants[1].URL != "https://other.example.com/hd.m3u8" {
+		t.Errorf("unexpected variant %+v", playlist.Variants[1])
+	}
+}
+
+func TestParseHLSMedia(t *testing.T) {
+	media := `#EXTM3U
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:7
+#EXT-X-KEY:METHOD=AES-128,URI="key.bin",IV=0x000102030405060708090a0b0c0d0e0f
+#EXTINF:4.0,
+seg7.ts
+#EXT-X-KEY:METHOD=NONE
+#EXTINF:2.5,
+seg8.ts
+#EXT-X-ENDLIST
+`
+	base, _ := url.Parse("https://cdn.example.com/show/index.m3u8")
+	playlist, err := parseHLS([]byte(media), base)
+	if err != nil {
+		t.Fatalf("parseHLS: %v", err)
+	}
+	if len(playlist.Segments) != 2 || playlist.Live() || playlist.Duration() != 6.5 {
+		t.Fatalf("unexpected playlist %+v", playlist)
+	}
+	first, second := playlist.Segments[0], playlist.Segments[1]
+	if first.Sequence != 7 || first.Key == nil || first.Key.URI != "https://cdn.example.com/show/key.bin" || first.Key.IV[15] != 0x0f {
+		t.Errorf("unexpected first segment %+v", first)
+	}
+	if second.Sequence != 8 || second.Key != nil || second.URL != "https://cdn.example.com/show/seg8.ts" {
+		t.Errorf("unexpected second segment %+v", second)
+	}
+
+	live, err := parseHLS([]byte("#EXTM3U\n#EXTINF:4,\nseg1.ts\n"), base)
+	if err != nil || !live.Live() {
+		t.Errorf("expected a live playlist, got %+v %v", live, err)
+	}
+	if _, err := parseHLS([]byte("<html></html>"), base); err == nil {
+		t.Error("expected error for a non-playlist")
+	}
+	if _, err := parseHLS([]byte("#EXTM3U\n#EXT-X-KEY:METHOD=SAMPLE-AES,URI=\"k\"\n"), base); err == nil {
+		t.Error("expected error for SAMPLE-AES")
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+	attrs := parseAttributes(`BANDWIDTH=1280000,CODECS="avc1.4d401e,mp4a.40.2",RESOLUTION=640x360,NAME="a=b"`)
+	want := map[string]string{"BANDWIDTH": "1280000", "CODECS": "avc1.4d401e,mp4a.40.2", "RESOLUTION": "640x360", "NAME": "a=b"}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("%s = %q, want %q", key, attrs[key], value)
+		}
+	}
+}
+
+// encryptAES128 encrypts data with AES-128-CBC and PKCS#7 padding like an HLS packager
+func encryptAES128(t *testing.T, data, key, iv []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	padded := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	encrypted := make([]byte, len(padded))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, padded)
+	return encrypted
+}
+
+// hlsServer serves a master playlist, an encrypted VOD media playlist and a live playlist
+type hlsServer struct {
+	*httptest.Server
+	segments [][]byte // clear segment contents
+	mutex    sync.Mutex
+	requests map[string]int
+}
+
+func newHLSServer(t *testing.T) *hlsServer {
+	t.Helper()
+	key := []byte("0123456789abcdef")
+	explicitIV := bytes.Repeat([]byte{0x42}, 16)
+	s := &hlsServer{requests: make(map[string]int)}
+	for i := range 3 {
+		s.segments = append(s.segments, []byte(strings.Repeat(fmt.Sprintf("segment-%d;", i), 5000+i)))
+	}
+
+	files := map[string][]byte{
+		"/master.m3u8": []byte(`#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=500000,RESOLUTION=426x240
+low/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=1500000,RESOLUTION=1280x720
+hd/index.m3u8
+`),
+		// The first segment uses the IV derived from media sequence 10, the others an explicit IV
+		"/hd/index.m3u8": []byte(`#EXTM3U
+#EXT-X-VERSION:3
+#EXT-X-TARGETDURATION:4
+#EXT-X-MEDIA-SEQUENCE:10
+#EXT-X-PLAYLIST-TYPE:VOD
+#EXT-X-KEY:METHOD=AES-128,URI="/keys/1"
+#EXTINF:4.0,
+seg0.ts
+#EXT-X-KEY:METHOD=AES-128,URI="/keys/1",IV=0x42424242424242424242424242424242
+#EXTINF:4.0,
+seg1.ts
+#EXTINF:3.0,
+seg2.ts
+#EXT-X-ENDLIST
+`),
+		"/live.m3u8":  []byte("#EXTM3U\n#EXT-X-TARGETDURATION:4\n#EXTINF:4.0,\nhd/seg0.ts\n"),
+		"/keys/1":     key,
+		"/hd/seg0.ts": encryptAES128(t, s.segments[0], key, sequenceIV(10)),
+		"/hd/seg1.ts": encryptAES128(t, s.segments[1], key, explicitIV),
+		"/hd/seg2.ts": encryptAES128(t, s.segments[2], key, explicitIV),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.requests[r.URL.Path]++
+		s.mutex.Unlock()
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, ".m3u8") {
+			w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		}
+		w.Write(data)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *hlsServer) count(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests[path]
+}
+
+func TestDirectResolveHLS(t *testing.T) {
+	server := newHLSServer(t)
+	direct := &Direct{}
+
+	info, err := direct.Resolve(context.Background(), server.URL+"/master.m3u8", Options{Quality: "height<=480"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if info.Title != "master" || len(info.Formats) != 2 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if preferred := info.Formats[0]; preferred.Height != 240 || preferred.URL != server.URL+"/low/index.m3u8" || preferred.Ext != "ts" {
+		t.Errorf("unexpected preferred format %+v", preferred)
+	}
+
+	media, err := direct.Resolve(context.Background(), server.URL+"/hd/index.m3u8", Options{})
+	if err != nil || media.Duration != 11 {
+		t.Errorf("unexpected media playlist info %+v %v", media, err)
+	}
+
+	live, err := direct.Resolve(context.Background(), server.URL+"/live.m3u8", Options{})
+	if err != nil || live.Live != LiveNow || live.LiveURL != server.URL+"/live.m3u8" {
+		t.Errorf("expected a live stream, got %+v %v", live, err)
+	}
+	if _, err := direct.Download(context.Background(), server.URL+"/live.m3u8", filepath.Join(t.TempDir(), "live.ts"), Options{}, nil); !errors.Is(err, ErrLiveStream) {
+		t.Errorf("expected ErrLiveStream, got %v", err)
+	}
+}
+
+func TestDirectDownloadHLSDecryptsAndResumes(t *testing.T) {
+	server := newHLSServer(t)
+	direct := &Direct{}
+	output := filepath.Join(t.TempDir(), "show.ts")
+
+	// A previous attempt already fetched the first segment
+	segmentsDir := output + SegmentsDirSuffix
+	os.MkdirAll(segmentsDir, SegmentsDirPerm)
+	os.WriteFile(filepath.Join(segmentsDir, "000000.seg"), server.segments[0], PartFilePerm)
+
+	var updates []Progress
+	if _, err := direct.Download(context.Background(), server.URL+"/master.m3u8", output, Options{Quality: "best"}, func(p Progress) {
+		updates = append(updates, p)
+	}); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil || !bytes.Equal(data, bytes.Join(server.segments, nil)) {
+		t.Fatalf("unexpected output (%d bytes): %v", len(data), err)
+	}
+	if _, err := os.Stat(segmentsDir); !os.IsNotExist(err) {
+		t.Error("segment directory must be removed after joining")
+	}
+	if server.count("/hd/seg0.ts") != 0 || server.count("/hd/seg1.ts") != 1 || server.count("/keys/1") != 1 {
+		t.Errorf("unexpected requests %v", server.requests)
+	}
+	last := updates[len(updates)-1]
+	if last.DownloadedSize != int64(len(data)) || last.TotalSize < last.DownloadedSize {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+	for i := 1; i < len(updates); i++ {
+		if updates[i].DownloadedSize < updates[i-1].DownloadedSize {
+			t.Fatalf("progress went backwards: %+v", updates)
+		}
+	}
+}
+
+func TestDecryptAES128RejectsWrongKey(t *testing.T) {
+	iv := make([]byte, 16)
+	encrypted := encryptAES128(t, []byte("payload"), []byte("0123456789abcdef"), iv)
+	if plain, err := decryptAES128(encrypted, []byte("0123456789abcdef"), iv); err != nil || string(plain) != "payload" {
+		t.Errorf("decryptAES128 = %q, %v", plain, err)
+	}
+	if _, err := decryptAES128(encrypted, []byte("fedcba9876543210"), iv); err == nil {
+		t.Error("expected a padding error with the wrong key")
+	}
+}
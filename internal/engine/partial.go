@@ -0,0 +1,34 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ytdlpTempSuffix is appended by the ytdlp library to the file it downloads into
+const ytdlpTempSuffix = ".tmp"
+
+// partialSuffixes mark the data of unfinished downloads; longer suffixes first
+var partialSuffixes = []string{PartSuffix + ytdlpTempSuffix, RecordingStreamSuffix, PartSuffix, SegmentsDirSuffix}
+
+// PartialPaths returns where an unfinished download of outputPath keeps its
+// data: the partial file, the temporary file of the ytdlp library and the
+// segments directory of streams and segmented downloads
+func PartialPaths(outputPath string) []string {
+	paths := make([]string, len(partialSuffixes))
+	for i, suffix := range partialSuffixes {
+		paths[i] = outputPath + suffix
+	}
+	return paths
+}
+
+// PartialTarget returns the output path the unfinished download at path belongs to
+func PartialTarget(path string) (string, bool) {
+	name := filepath.Base(path)
+	for _, suffix := range partialSuffixes {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.TrimSuffix(path, suffix), true
+		}
+	}
+	return "", false
+}
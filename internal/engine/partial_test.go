@@ -0,0 +1,26 @@
+package engine
+
+import "testing"
+
+func TestPartialTarget(t *testing.T) {
+	tests := map[string]string{
+		"/videos/Intro.mp4.part":     "/videos/Intro.mp4",
+		"/videos/Intro.mp4.part.tmp": "/videos/Intro.mp4",
+		"/videos/Live.ts.parts":      "/videos/Live.ts",
+		"/videos/Intro.mp4":          "",
+		"/videos/notes.tmp":          "",
+		"/videos/.part":              "",
+	}
+	for path, expected := range tests {
+		target, ok := PartialTarget(path)
+		if target != expected || ok != (expected != "") {
+			t.Errorf("PartialTarget(%s) = %q, %v; expected %q", path, target, ok, expected)
+		}
+	}
+
+	for _, path := range PartialPaths("/videos/Intro.mp4") {
+		if target, ok := PartialTarget(path); !ok || target != "/videos/Intro.mp4" {
+			t.Errorf("PartialPaths returned %s, which PartialTarget does not map back", path)
+		}
+	}
+}
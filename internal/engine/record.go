@@ -0,0 +1,231 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Live recording constants
+const (
+	// LiveEdgeSegments is how many of the newest segments a recording starts
+	// with when the playlist does not keep the whole broadcast
+	LiveEdgeSegments = 3
+	// LiveMaxFailures ends a recording after this many consecutive playlist or segment errors
+	LiveMaxFailures = 5
+	// MaxSegmentSize limits a single live segment held in memory
+	MaxSegmentSize = 256 << 20
+	// defaultTargetDuration is the playlist reload interval when the playlist does not declare one
+	defaultTargetDuration = 6 * time.Second
+)
+
+// RecordingStreamSuffix names the recorded MPEG-TS stream while it is remuxed
+const RecordingStreamSuffix = ".ts" + PartSuffix
+
+// ErrNothingRecorded is returned when a recording ends before its first segment
+var ErrNothingRecorded = errors.New("recording ended before any media was received")
+
+// RecordingExt is the extension of recordings: MPEG-TS broadcasts are remuxed
+// to MP4 when ffmpeg is available
+func (d *Direct) RecordingExt() string {
+	return d.hlsExt(false)
+}
+
+// Record records a live HLS broadcast to outputPath until the playlist ends
+// or ctx is cancelled. EVENT playlists are recorded from the start of the
+// broadcast, others from the live edge. Cancellation is the normal way to
+// stop a recording: the media received so far is finalised at outputPath and
+// nil is returned. Progress reports bytes written and the recorded duration.
+func (d *Direct) Record(ctx context.Context, playlistURL, outputPath string, opts Options, progress func(Progress)) error {
+	client := clientFor(opts)
+	mediaURL, err := d.recordingPlaylist(ctx, client, playlistURL, opts)
+	if err != nil {
+		return err
+	}
+
+	partPath := outputPath + PartSuffix
+	file, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, PartFilePerm)
+	if err != nil {
+		return err
+	}
+
+	recorder := &liveRecorder{client: client, file: file, keys: make(map[string][]byte), progress: progress, lastSequence: -1}
+	recordErr := recorder.run(ctx, mediaURL)
+	if closeErr := file.Close(); recordErr == nil {
+		recordErr = closeErr
+	}
+	if recorder.written == 0 {
+		os.Remove(partPath)
+		if recordErr != nil {
+			return recordErr
+		}
+		return ErrNothingRecorded
+	}
+	if recordErr != nil {
+		// Keep what was recorded but still report the failure
+		if finalErr := d.finaliseRecording(partPath, outputPath, recorder.fragmented); finalErr != nil {
+			return finalErr
+		}
+		return fmt.Errorf("recording interrupted: %w", recordErr)
+	}
+	return d.finaliseRecording(partPath, outputPath, recorder.fragmented)
+}
+
+// recordingPlaylist returns the media playlist to record, choosing a variant of a master playlist by quality
+func (d *Direct) recordingPlaylist(ctx context.Context, client *http.Client, playlistURL string, opts Options) (string, error) {
+	playlist, err := fetchHLS(ctx, client, playlistURL)
+	if err != nil {
+		return "", err
+	}
+	if len(playlist.Variants) == 0 {
+		return playlistURL, nil
+	}
+	formats := make([]Format, 0, len(playlist.Variants))
+	for _, variant := range playlist.Variants {
+		formats = append(formats, Format{URL: variant.URL, Height: variant.Height, Bitrate: variant.Bandwidth})
+	}
+	SortFormats(formats, opts.Quality)
+	return formats[0].URL, nil
+}
+
+// finaliseRecording turns the recorded stream into outputPath. It runs even
+// after the recording context was cancelled, so ffmpeg gets its own context.
+// ffmpeg writes through partPath, so the recorded stream is moved aside first
+// and put back when remuxing fails.
+func (d *Direct) finaliseRecording(partPath, outputPath string, fragmented bool) error {
+	if fragmented || d.FFmpeg == "" || strings.EqualFold(filepath.Ext(outputPath), ".ts") {
+		return os.Rename(partPath, outputPath)
+	}
+	streamPath := outputPath + RecordingStreamSuffix
+	if err := os.Rename(partPath, streamPath); err != nil {
+		return err
+	}
+	if err := d.remux(context.Background(), streamPath, outputPath); err != nil {
+		os.Rename(streamPath, partPath)
+		return err
+	}
+	if err := os.Rename(partPath, outputPath); err != nil {
+		return err
+	}
+	return os.Remove(streamPath)
+}
+
+// liveRecorder appends the new segments of a live media playlist to a file
+type liveRecorder struct {
+	client       *http.Client
+	file         *os.File
+	keys         map[string][]byte
+	progress     func(Progress)
+	lastSequence int64
+	initURL      string // initialization section already written
+	fragmented   bool
+	written      int64
+	recorded     time.Duration
+}
+
+// run reloads the playlist until it ends or ctx is cancelled
+func (r *liveRecorder) run(ctx context.Context, mediaURL string) error {
+	failures := 0
+	first := true
+	for {
+		playlist, err := fetchHLS(ctx, r.client, mediaURL)
+		if err == nil {
+			segments := playlist.Segments
+			if first && playlist.Type != "EVENT" && len(segments) > LiveEdgeSegments {
+				segments = segments[len(segments)-LiveEdgeSegments:]
+			}
+			first = false
+			err = r.appendSegments(ctx, segments)
+		}
+		if ctx.Err() != nil {
+			// Stopped by the user
+			return nil
+		}
+		if err != nil {
+			failures++
+			if failures >= LiveMaxFailures {
+				return err
+			}
+		} else {
+			failures = 0
+			if playlist.EndList {
+				return nil
+			}
+		}
+
+		reload := defaultTargetDuration
+		if err == nil && playlist.Target > 0 {
+			// Reload at half the target duration to stay close to the live edge
+			reload = time.Duration(playlist.Target * float64(time.Second) / 2)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reload):
+		}
+	}
+}
+
+// appendSegments writes the segments not recorded yet
+func (r *liveRecorder) appendSegments(ctx context.Context, segments []hlsSegment) error {
+	for _, segment := range segments {
+		if segment.Sequence <= r.lastSequence {
+			continue
+		}
+		if segment.Init != "" && segment.Init != r.initURL {
+			if r.initURL != "" {
+				return errors.New("live playlist changed its initialization section")
+			}
+			data, _, err := fetchBytes(ctx, r.client, segment.Init, MaxSegmentSize)
+			if err != nil {
+				return err
+			}
+			if err := r.write(data); err != nil {
+				return err
+			}
+			r.initURL, r.fragmented = segment.Init, true
+		}
+
+		data, _, err := fetchBytes(ctx, r.client, segment.URL, MaxSegmentSize)
+		if err != nil {
+			return err
+		}
+		if segment.Key != nil {
+			key, ok := r.keys[segment.Key.URI]
+			if !ok {
+				if key, _, err = fetchBytes(ctx, r.client, segment.Key.URI, 16); err != nil {
+					return fmt.Errorf("HLS key: %w", err)
+				}
+				r.keys[segment.Key.URI] = key
+			}
+			iv := segment.Key.IV
+			if iv == nil {
+				iv = sequenceIV(segment.Sequence)
+			}
+			if data, err = decryptAES128(data, key, iv); err != nil {
+				return err
+			}
+		}
+		if err := r.write(data); err != nil {
+			return err
+		}
+		r.lastSequence = segment.Sequence
+		r.recorded += time.Duration(segment.Duration * float64(time.Second))
+		if r.progress != nil {
+			r.progress(Progress{DownloadedSize: r.written, Recorded: r.recorded})
+		}
+	}
+	return nil
+}
+
+// write appends data to the recording
+func (r *liveRecorder) write(data []byte) error {
+	n, err := r.file.Write(data)
+	r.written += int64(n)
+	return err
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// liveServer publishes one more segment of a broadcast on every playlist request
+type liveServer struct {
+	*httptest.Server
+	segments  [][]byte
+	event     bool // EVENT playlist keeping every segment
+	end       bool // add EXT-X-ENDLIST once every segment is published
+	mutex     sync.Mutex
+	published int
+}
+
+func newLiveServer(t *testing.T, count, published int, event, end bool) *liveServer {
+	t.Helper()
+	s := &liveServer{event: event, end: end, published: published}
+	for i := range count {
+		s.segments = append(s.segments, []byte(strings.Repeat(fmt.Sprintf("live-%d;", i), 100)))
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/live.m3u8" {
+			var index int
+			if _, err := fmt.Sscanf(r.URL.Path, "/seg%d.ts", &index); err != nil || index >= len(s.segments) {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(s.segments[index])
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		fmt.Fprint(w, s.playlist())
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// playlist renders the current playlist and publishes the next segment
+func (s *liveServer) playlist() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	first := 0
+	if !s.event {
+		first = max(s.published-5, 0)
+	}
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-TARGETDURATION:0.02\n")
+	if s.event {
+		b.WriteString("#EXT-X-PLAYLIST-TYPE:EVENT\n")
+	}
+	fmt.Fprintf(&b, "#EXT-X-MEDIA-SEQUENCE:%d\n", first)
+	for i := first; i < s.published; i++ {
+		fmt.Fprintf(&b, "#EXTINF:2.0,\nseg%d.ts\n", i)
+	}
+	if s.published == len(s.segments) && s.end {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	}
+	s.published = min(s.published+1, len(s.segments))
+	return b.String()
+}
+
+func TestDirectRecordEventFromStart(t *testing.T) {
+	server := newLiveServer(t, 6, 4, true, true)
+	output := filepath.Join(t.TempDir(), "live.ts")
+
+	var last Progress
+	if err := (&Direct{}).Record(context.Background(), server.URL+"/live.m3u8", output, Options{}, func(p Progress) {
+		last = p
+	}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil || !bytes.Equal(data, bytes.Join(server.segments, nil)) {
+		t.Fatalf("unexpected recording (%d bytes): %v", len(data), err)
+	}
+	if last.Recorded != 12*time.Second || last.DownloadedSize != int64(len(data)) {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+	if _, err := os.Stat(output + PartSuffix); !os.IsNotExist(err) {
+		t.Error("partial recording must be renamed")
+	}
+}
+
+func TestDirectRecordFromLiveEdgeUntilStopped(t *testing.T) {
+	server := newLiveServer(t, 100, 5, false, false)
+	output := filepath.Join(t.TempDir(), "live.ts")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := (&Direct{}).Record(ctx, server.URL+"/live.m3u8", output, Options{}, func(p Progress) {
+		if p.Recorded >= 10*time.Second {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatalf("stopping a recording must finalise it, got %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Record reads the playlist once to pick a variant, so the recording starts
+	// three segments behind the sixth published one and has no gaps
+	expected := bytes.Join(server.segments[3:], nil)
+	if len(data) < 5*len(server.segments[0]) || !bytes.HasPrefix(expected, data) {
+		t.Errorf("unexpected recording (%d bytes)", len(data))
+	}
+}
+
+func TestDirectRecordStoppedBeforeFirstSegment(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/live.m3u8" {
+			fmt.Fprint(w, "#EXTM3U\n#EXT-X-TARGETDURATION:2\n#EXTINF:2.0,\nseg0.ts\n")
+			return
+		}
+		// The user stops the recording while the first segment is requested
+		cancel()
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "live.ts")
+	if err := (&Direct{}).Record(ctx, server.URL+"/live.m3u8", output, Options{}, nil); err != ErrNothingRecorded {
+		t.Errorf("expected ErrNothingRecorded, got %v", err)
+	}
+	if _, err := os.Stat(output + PartSuffix); !os.IsNotExist(err) {
+		t.Error("an empty recording must be removed")
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("an empty recording must not be kept")
+	}
+}
@@ -0,0 +1,254 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Segmented download constants
+const (
+	// MinSegmentSize is the smallest byte range worth its own connection
+	MinSegmentSize = 1 << 20
+	// segmentLayoutFile names the file describing the ranges of an interrupted segmented download
+	segmentLayoutFile = "layout.json"
+)
+
+// byteRange is an inclusive range of a file
+type byteRange struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+}
+
+// Len returns the number of bytes in the range
+func (r byteRange) Len() int64 { return r.End - r.Start + 1 }
+
+// segmentLayout records how a file was split so that a resumed download
+// keeps the ranges of the first attempt even if the segment count changed
+type segmentLayout struct {
+	URL    string      `json:"url"`
+	Size   int64       `json:"size"`
+	Ranges []byteRange `json:"ranges"`
+}
+
+// splitRanges divides size bytes into at most n ranges of at least MinSegmentSize
+func splitRanges(size int64, n int) []byteRange {
+	n = int(min(int64(n), max(size/MinSegmentSize, 1)))
+	ranges := make([]byteRange, 0, n)
+	chunk := size / int64(n)
+	for i := range n {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+		if i == n-1 {
+			end = size - 1
+		}
+		ranges = append(ranges, byteRange{Start: start, End: end})
+	}
+	return ranges
+}
+
+// probeRanges reports the size of mediaURL if the server serves byte ranges, otherwise zero
+func probeRanges(ctx context.Context, client *http.Client, mediaURL string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, nil
+	}
+	_, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+	if !ok {
+		return 0, nil
+	}
+	return size, nil
+}
+
+// DownloadFileSegmented downloads a single file over up to segments concurrent
+// connections, one byte range each, and joins the ranges into outputPath.
+// Each range is kept in outputPath+SegmentsDirSuffix and resumed on its own,
+// so a paused download continues every range where it stopped. Servers that
+// do not support ranges, small files and a segment count below 2 fall back
+// to a single request that resumes outputPath+PartSuffix.
+func DownloadFileSegmented(ctx context.Context, client *http.Client, mediaURL, outputPath string, segments int, progress func(Progress)) error {
+	if err := fetchSegmented(ctx, client, mediaURL, outputPath, segments, progress); err != nil {
+		return err
+	}
+	return os.Rename(outputPath+PartSuffix, outputPath)
+}
+
+// fetchSegmented is DownloadFileSegmented without the final rename: the
+// finished file is left at outputPath+PartSuffix
+func fetchSegmented(ctx context.Context, client *http.Client, mediaURL, outputPath string, segments int, progress func(Progress)) error {
+	segmentsDir := outputPath + SegmentsDirSuffix
+	layout, err := loadSegmentLayout(segmentsDir, mediaURL)
+	if err != nil {
+		return err
+	}
+	if layout == nil {
+		if segments < 2 {
+			return fetchToFile(ctx, client, mediaURL, outputPath+PartSuffix, progress)
+		}
+		size, err := probeRanges(ctx, client, mediaURL)
+		if err != nil {
+			return err
+		}
+		if size < 2*MinSegmentSize {
+			return fetchToFile(ctx, client, mediaURL, outputPath+PartSuffix, progress)
+		}
+		layout = &segmentLayout{URL: mediaURL, Size: size, Ranges: splitRanges(size, segments)}
+		if err := saveSegmentLayout(segmentsDir, layout); err != nil {
+			return err
+		}
+	}
+
+	files := make([]string, len(layout.Ranges))
+	done := make([]int64, len(layout.Ranges))
+	for i := range layout.Ranges {
+		files[i] = filepath.Join(segmentsDir, fmt.Sprintf("%03d.range", i))
+		if fi, err := os.Stat(files[i]); err == nil {
+			done[i] = min(fi.Size(), layout.Ranges[i].Len())
+		}
+	}
+
+	// Aggregate the byte counts of all ranges into one progress stream
+	var mutex sync.Mutex
+	var reported int64
+	report := func(index int, downloaded int64, force bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		done[index] = downloaded
+		var total int64
+		for _, n := range done {
+			total += n
+		}
+		if progress != nil && (force || total-reported >= progressInterval) {
+			reported = total
+			progress(Progress{TotalSize: layout.Size, DownloadedSize: total})
+		}
+	}
+	report(0, done[0], true)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, len(layout.Ranges))
+	var wg sync.WaitGroup
+	for i, r := range layout.Ranges {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := fetchRange(ctx, client, mediaURL, files[i], r, func(downloaded int64) {
+				report(i, downloaded, false)
+			})
+			if err != nil {
+				errs <- fmt.Errorf("range %d-%d: %w", r.Start, r.End, err)
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	// Prefer the error that stopped the others over the cancellations it caused
+	var firstErr error
+	for err := range errs {
+		if firstErr == nil || errors.Is(firstErr, context.Canceled) {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	if err := concatFiles(outputPath+PartSuffix, files); err != nil {
+		return err
+	}
+	report(0, done[0], true)
+	return os.RemoveAll(segmentsDir)
+}
+
+// fetchRange downloads the byte range r into path, continuing after the bytes already in it
+func fetchRange(ctx context.Context, client *http.Client, mediaURL, path string, r byteRange, progress func(downloaded int64)) error {
+	var have int64
+	if fi, err := os.Stat(path); err == nil {
+		have = fi.Size()
+	}
+	if have >= r.Len() {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", r.Start+have, r.End))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("download failed: HTTP %d", resp.StatusCode)
+	}
+	if start, _, ok := parseContentRange(resp.Header.Get("Content-Range")); !ok || start != r.Start+have {
+		return fmt.Errorf("unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, PartFilePerm)
+	if err != nil {
+		return err
+	}
+	written, copyErr := copyWithProgress(ctx, file, resp.Body, have, r.Len(), func(p Progress) {
+		progress(p.DownloadedSize)
+	})
+	if closeErr := file.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	progress(written)
+	if copyErr != nil {
+		return copyErr
+	}
+	if written != r.Len() {
+		return fmt.Errorf("download incomplete: got %d of %d bytes", written, r.Len())
+	}
+	return nil
+}
+
+// loadSegmentLayout reads the layout of an interrupted segmented download of mediaURL.
+// It returns nil if there is none or it belongs to another URL.
+func loadSegmentLayout(dir, mediaURL string) (*segmentLayout, error) {
+	data, err := os.ReadFile(filepath.Join(dir, segmentLayoutFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var layout segmentLayout
+	if err := json.Unmarshal(data, &layout); err != nil || layout.URL != mediaURL || len(layout.Ranges) == 0 {
+		// Stale or foreign state: start over
+		return nil, os.RemoveAll(dir)
+	}
+	return &layout, nil
+}
+
+// saveSegmentLayout stores the layout of a segmented download
+func saveSegmentLayout(dir string, layout *segmentLayout) error {
+	if err := os.MkdirAll(dir, SegmentsDirPerm); err != nil {
+		return err
+	}
+	data, err := json.Marshal(layout)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, segmentLayoutFile), data, PartFilePerm)
+}
@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSplitRanges(t *testing.T) {
+	tests := []struct {
+		size int64
+		n    int
+		want []byteRange
+	}{
+		{4 * MinSegmentSize, 4, []byteRange{
+			{0, MinSegmentSize - 1},
+			{MinSegmentSize, 2*MinSegmentSize - 1},
+			{2 * MinSegmentSize, 3*MinSegmentSize - 1},
+			{3 * MinSegmentSize, 4*MinSegmentSize - 1},
+		}},
+		// Too small for four ranges of MinSegmentSize
+		{2*MinSegmentSize + 1, 4, []byteRange{{0, MinSegmentSize - 1}, {MinSegmentSize, 2 * MinSegmentSize}}},
+		{100, 3, []byteRange{{0, 99}}},
+	}
+	for _, tt := range tests {
+		if got := splitRanges(tt.size, tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("splitRanges(%d, %d) = %v, want %v", tt.size, tt.n, got, tt.want)
+		}
+	}
+}
+
+// segmentPayload is large enough for four ranges
+func segmentPayload() []byte {
+	payload := make([]byte, 4*MinSegmentSize+12345)
+	for i := range payload {
+		payload[i] = byte(i % 251)
+	}
+	return payload
+}
+
+func TestDownloadFileSegmented(t *testing.T) {
+	payload := segmentPayload()
+	recorder := &rangeRecorder{payload: payload}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "big.mp4")
+	var last Progress
+	direct := &Direct{}
+	if _, err := direct.Download(context.Background(), server.URL+"/big.mp4", output, Options{Segments: 4}, func(p Progress) {
+		last = p
+	}); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil || !bytes.Equal(data, payload) {
+		t.Fatalf("unexpected output (%d bytes): %v", len(data), err)
+	}
+	if _, err := os.Stat(output + SegmentsDirSuffix); !os.IsNotExist(err) {
+		t.Error("range directory must be removed after joining")
+	}
+	chunk := int64(len(payload)) / 4
+	for i := range int64(4) {
+		end := (i+1)*chunk - 1
+		if i == 3 {
+			end = int64(len(payload)) - 1
+		}
+		want := "bytes=" + itoa(i*chunk) + "-" + itoa(end)
+		if !slices.Contains(recorder.ranges, want) {
+			t.Errorf("expected a request for %s, got %v", want, recorder.ranges)
+		}
+	}
+	if last.DownloadedSize != int64(len(payload)) || last.TotalSize != int64(len(payload)) {
+		t.Errorf("unexpected final progress %+v", last)
+	}
+}
+
+func TestDownloadFileSegmentedResumesEachRange(t *testing.T) {
+	payload := segmentPayload()
+	recorder := &rangeRecorder{payload: payload}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+	mediaURL := server.URL + "/big.mp4"
+
+	// A paused attempt with two ranges: the first half-done, the second complete
+	output := filepath.Join(t.TempDir(), "big.mp4")
+	dir := output + SegmentsDirSuffix
+	size := int64(len(payload))
+	layout := &segmentLayout{URL: mediaURL, Size: size, Ranges: []byteRange{{0, size/2 - 1}, {size / 2, size - 1}}}
+	if err := saveSegmentLayout(dir, layout); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "000.range"), payload[:1000], PartFilePerm)
+	os.WriteFile(filepath.Join(dir, "001.range"), payload[size/2:], PartFilePerm)
+
+	// The layout wins over a changed segment count
+	var first Progress
+	if err := DownloadFileSegmented(context.Background(), http.DefaultClient, mediaURL, output, 8, func(p Progress) {
+		if first.TotalSize == 0 {
+			first = p
+		}
+	}); err != nil {
+		t.Fatalf("DownloadFileSegmented: %v", err)
+	}
+
+	if data, err := os.ReadFile(output); err != nil || !bytes.Equal(data, payload) {
+		t.Fatalf("unexpected output (%d bytes): %v", len(data), err)
+	}
+	if want := []string{"bytes=1000-" + itoa(size/2-1)}; !slices.Equal(recorder.ranges, want) {
+		t.Errorf("expected only the missing bytes to be requested, got %v", recorder.ranges)
+	}
+	if first.DownloadedSize != 1000+size-size/2 {
+		t.Errorf("expected progress to start from the bytes on disk, got %+v", first)
+	}
+}
+
+func TestDownloadFileSegmentedFallsBackWithoutRanges(t *testing.T) {
+	payload := segmentPayload()
+	recorder := &rangeRecorder{payload: payload, noRange: true}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "big.mp4")
+	if err := DownloadFileSegmented(context.Background(), http.DefaultClient, server.URL+"/big.mp4", output, 4, nil); err != nil {
+		t.Fatalf("DownloadFileSegmented: %v", err)
+	}
+	if data, _ := os.ReadFile(output); !bytes.Equal(data, payload) {
+		t.Fatal("unexpected output")
+	}
+	// One probe and one plain transfer
+	if len(recorder.ranges) != 2 || recorder.ranges[1] != "" {
+		t.Errorf("unexpected requests %v", recorder.ranges)
+	}
+}
+
+func TestDownloadFileSegmentedKeepsRangesOnError(t *testing.T) {
+	payload := segmentPayload()
+	recorder := &rangeRecorder{payload: payload}
+	failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The second of two ranges starts in the middle of the file
+		if strings.HasPrefix(r.Header.Get("Range"), "bytes="+itoa(int64(len(payload))/2)+"-") {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		recorder.ServeHTTP(w, r)
+	})
+	server := httptest.NewServer(failing)
+	defer server.Close()
+
+	output := filepath.Join(t.TempDir(), "big.mp4")
+	if err := DownloadFileSegmented(context.Background(), http.DefaultClient, server.URL+"/big.mp4", output, 2, nil); err == nil {
+		t.Fatal("expected an error when a range fails")
+	}
+	if _, err := os.Stat(filepath.Join(output+SegmentsDirSuffix, segmentLayoutFile)); err != nil {
+		t.Errorf("layout must be kept for resuming: %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("no output file may exist after a failed download")
+	}
+}
+
+func itoa(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
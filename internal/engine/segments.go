@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// segmentJob is one file of a segmented stream
+type segmentJob struct {
+	URL  string
+	Path string // destination of the fetched (and decrypted) segment
+	Key  []byte // AES-128 key, nil for clear segments
+	IV   []byte
+}
+
+// fetchSegments downloads the jobs in order. Segments already present are
+// skipped so an interrupted download resumes where it stopped; a segment that
+// was cut off is resumed from its partial file. Progress is reported in bytes
+// with the total estimated from the average size of the segments so far.
+func fetchSegments(ctx context.Context, client *http.Client, jobs []segmentJob, progress func(Progress)) error {
+	var done int64
+	doneCount := 0
+	report := func(current, currentTotal int64) {
+		if progress == nil {
+			return
+		}
+		downloaded := done + current
+		var total int64
+		switch {
+		case doneCount > 0:
+			total = done / int64(doneCount) * int64(len(jobs))
+		case currentTotal > 0:
+			total = currentTotal * int64(len(jobs))
+		}
+		progress(Progress{TotalSize: max(total, downloaded), DownloadedSize: downloaded})
+	}
+
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if fi, err := os.Stat(job.Path); err == nil {
+			done += fi.Size()
+			doneCount++
+			report(0, 0)
+			continue
+		}
+
+		partPath := job.Path + PartSuffix
+		var segmentProgress func(Progress)
+		if job.Key == nil {
+			// Encrypted segments shrink when decrypted, so they are only counted once done
+			segmentProgress = func(p Progress) { report(p.DownloadedSize, p.TotalSize) }
+		}
+		err := fetchToFile(ctx, client, job.URL, partPath, segmentProgress)
+		if err != nil {
+			return fmt.Errorf("segment %s: %w", filepath.Base(job.Path), err)
+		}
+		if job.Key != nil {
+			if err := decryptFile(partPath, job.Key, job.IV); err != nil {
+				os.Remove(partPath)
+				return fmt.Errorf("segment %s: %w", filepath.Base(job.Path), err)
+			}
+		}
+		if err := os.Rename(partPath, job.Path); err != nil {
+			return err
+		}
+		if fi, err := os.Stat(job.Path); err == nil {
+			done += fi.Size()
+		}
+		doneCount++
+		report(0, 0)
+	}
+	return nil
+}
+
+// decryptFile decrypts an AES-128-CBC file with PKCS#7 padding in place
+func decryptFile(path string, key, iv []byte) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	plain, err := decryptAES128(data, key, iv)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, plain, PartFilePerm)
+}
+
+// decryptAES128 decrypts AES-128-CBC data and removes the PKCS#7 padding
+func decryptAES128(data, key, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("encrypted segment is not a whole number of blocks")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(plain[len(plain)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("invalid padding in decrypted segment (wrong key?)")
+	}
+	return plain[:len(plain)-padding], nil
+}
+
+// concatFiles writes the files one after another into output
+func concatFiles(output string, inputs []string) (err error) {
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, PartFilePerm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(output)
+		}
+	}()
+	for _, input := range inputs {
+		if err := appendFile(file, input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendFile copies the content of path to w
+func appendFile(w io.Writer, path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	_, err = io.Copy(w, in)
+	return err
+}
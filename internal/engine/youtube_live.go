@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/ytlink"
+)
+
+// playerResponseMarker precedes the player response embedded in a watch page
+const playerResponseMarker = "ytInitialPlayerResponse = "
+
+// MaxWatchPageSize limits how much of a watch page is read when probing for a live broadcast
+const MaxWatchPageSize = 4 << 20
+
+// livePlayerResponse holds the live broadcast fields of a YouTube player response
+type livePlayerResponse struct {
+	PlayabilityStatus struct {
+		LiveStreamability struct {
+			LiveStreamabilityRenderer struct {
+				OfflineSlate struct {
+					LiveStreamOfflineSlateRenderer struct {
+						ScheduledStartTime string `json:"scheduledStartTime"`
+					} `json:"liveStreamOfflineSlateRenderer"`
+				} `json:"offlineSlate"`
+			} `json:"liveStreamabilityRenderer"`
+		} `json:"liveStreamability"`
+	} `json:"playabilityStatus"`
+	VideoDetails struct {
+		VideoID    string `json:"videoId"`
+		Title      string `json:"title"`
+		Author     string `json:"author"`
+		IsLive     bool   `json:"isLive"`
+		IsUpcoming bool   `json:"isUpcoming"`
+	} `json:"videoDetails"`
+	StreamingData struct {
+		HLSManifestURL string `json:"hlsManifestUrl"`
+	} `json:"streamingData"`
+	Microformat struct {
+		PlayerMicroformatRenderer struct {
+			LiveBroadcastDetails struct {
+				StartTimestamp string `json:"startTimestamp"`
+			} `json:"liveBroadcastDetails"`
+		} `json:"playerMicroformatRenderer"`
+	} `json:"microformat"`
+}
+
+// probeLive reads the watch page of a YouTube video and describes it if it is
+// live now or scheduled; it returns nil for regular videos and on any failure
+func (e *YTDLP) probeLive(ctx context.Context, rawURL string, opts Options) *VideoInfo {
+	videoID := ytlink.VideoID(rawURL)
+	if videoID == "" {
+		return nil
+	}
+	watchURL := e.watchURL
+	if watchURL == "" {
+		watchURL = ytlink.CanonicalVideoURL
+	}
+	page, _, err := fetchBytes(ctx, clientFor(opts), fmt.Sprintf(watchURL, videoID), MaxWatchPageSize)
+	if err != nil {
+		return nil
+	}
+	response, err := parsePlayerResponse(page)
+	if err != nil {
+		return nil
+	}
+	return response.videoInfo()
+}
+
+// parsePlayerResponse extracts the player response embedded in a watch page
+func parsePlayerResponse(page []byte) (*livePlayerResponse, error) {
+	start := bytes.Index(page, []byte(playerResponseMarker))
+	if start < 0 {
+		return nil, fmt.Errorf("player response not found")
+	}
+	var response livePlayerResponse
+	// The decoder stops at the end of the object, ignoring the script after it
+	if err := json.NewDecoder(bytes.NewReader(page[start+len(playerResponseMarker):])).Decode(&response); err != nil {
+		return nil, fmt.Errorf("player response: %w", err)
+	}
+	return &response, nil
+}
+
+// videoInfo describes a live or upcoming broadcast, or returns nil for other videos
+func (r *livePlayerResponse) videoInfo() *VideoInfo {
+	details := r.VideoDetails
+	info := &VideoInfo{ID: details.VideoID, Title: details.Title, Author: details.Author}
+	switch {
+	case details.IsUpcoming:
+		info.Live = LiveUpcoming
+		info.ScheduledStart = r.scheduledStart()
+	case details.IsLive && r.StreamingData.HLSManifestURL != "":
+		info.Live = LiveNow
+		info.LiveURL = r.StreamingData.HLSManifestURL
+	default:
+		return nil
+	}
+	return info
+}
+
+// scheduledStart returns the announced start of an upcoming broadcast, zero if unknown
+func (r *livePlayerResponse) scheduledStart() time.Time {
+	slate := r.PlayabilityStatus.LiveStreamability.LiveStreamabilityRenderer.OfflineSlate.LiveStreamOfflineSlateRenderer
+	if seconds, err := strconv.ParseInt(slate.ScheduledStartTime, 10, 64); err == nil && seconds > 0 {
+		return time.Unix(seconds, 0)
+	}
+	details := r.Microformat.PlayerMicroformatRenderer.LiveBroadcastDetails
+	if start, err := time.Parse(time.RFC3339, details.StartTimestamp); err == nil {
+		return start
+	}
+	return time.Time{}
+}
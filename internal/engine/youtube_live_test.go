@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const upcomingWatchPage = `<html><script>var ytInitialPlayerResponse = {"playabilityStatus":{"status":"LIVE_STREAM_OFFLINE",
+"liveStreamability":{"liveStreamabilityRenderer":{"offlineSlate":{"liveStreamOfflineSlateRenderer":{"scheduledStartTime":"1893456000"}}}}},
+"videoDetails":{"videoId":"dQw4w9WgXcQ","title":"Premiere","author":"Channel","isUpcoming":true}};var meta = {};</script></html>`
+
+const liveWatchPage = `<script>var ytInitialPlayerResponse = {"videoDetails":{"videoId":"dQw4w9WgXcQ","title":"On air","isLive":true},
+"streamingData":{"hlsManifestUrl":"https://manifest.example.com/index.m3u8"}};</script>`
+
+func TestParsePlayerResponse(t *testing.T) {
+	response, err := parsePlayerResponse([]byte(upcomingWatchPage))
+	if err != nil {
+		t.Fatalf("parsePlayerResponse: %v", err)
+	}
+	info := response.videoInfo()
+	if info == nil || info.Live != LiveUpcoming || info.Title != "Premiere" || info.Author != "Channel" ||
+		!info.ScheduledStart.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected upcoming info %+v", info)
+	}
+
+	response, err = parsePlayerResponse([]byte(liveWatchPage))
+	if err != nil {
+		t.Fatalf("parsePlayerResponse: %v", err)
+	}
+	if info := response.videoInfo(); info == nil || info.Live != LiveNow || info.LiveURL != "https://manifest.example.com/index.m3u8" {
+		t.Errorf("unexpected live info %+v", info)
+	}
+
+	regular := `var ytInitialPlayerResponse = {"videoDetails":{"videoId":"dQw4w9WgXcQ","title":"Video"}};`
+	if response, err := parsePlayerResponse([]byte(regular)); err != nil || response.videoInfo() != nil {
+		t.Errorf("a regular video must not be live: %+v %v", response, err)
+	}
+	if _, err := parsePlayerResponse([]byte("<html></html>")); err == nil {
+		t.Error("expected an error without a player response")
+	}
+}
+
+func TestScheduledStartFromMicroformat(t *testing.T) {
+	page := `ytInitialPlayerResponse = {"videoDetails":{"isUpcoming":true},
+"microformat":{"playerMicroformatRenderer":{"liveBroadcastDetails":{"startTimestamp":"2030-01-01T10:00:00+00:00"}}}}`
+	response, err := parsePlayerResponse([]byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start := response.scheduledStart(); !start.Equal(time.Date(2030, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected scheduled start %v", start)
+	}
+}
+
+func TestYTDLPProbeLive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("v") != "dQw4w9WgXcQ" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, liveWatchPage)
+	}))
+	defer server.Close()
+
+	e := &YTDLP{watchURL: server.URL + "/watch?v=%s"}
+	info := e.probeLive(context.Background(), "https://youtu.be/dQw4w9WgXcQ", Options{})
+	if info == nil || info.Live != LiveNow || info.ID != "dQw4w9WgXcQ" {
+		t.Errorf("unexpected probe result %+v", info)
+	}
+	if info := e.probeLive(context.Background(), "https://example.com/video.mp4", Options{}); info != nil {
+		t.Errorf("non-YouTube URLs must not be probed, got %+v", info)
+	}
+}
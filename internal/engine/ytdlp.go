@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"context"
+	"mime"
+	"net/url"
+	"strconv"
+
+	"github.com/ytget/ytdlp/types"
+	"github.com/ytget/ytdlp/v2"
+)
+
+// YTDLP is the production engine backed by the pure Go ytdlp library. Live
+// and upcoming broadcasts, which the library cannot download, are detected
+// from the watch page so they can be recorded.
+type YTDLP struct {
+	// watchURL is the watch page format with the video ID; empty uses the canonical URL
+	watchURL string
+}
+
+// NewYTDLP creates the ytdlp-backed engine
+func NewYTDLP() *YTDLP {
+	return &YTDLP{}
+}
+
+// Resolve fetches metadata and selects a format for the URL; the selected
+// format is reported first, with its file type. Videos the library fails on
+// or reports without a duration are checked for a live broadcast.
+func (e *YTDLP) Resolve(ctx context.Context, url string, opts Options) (*VideoInfo, error) {
+	mediaURL, info, err := e.downloader(opts).ResolveURL(ctx, url)
+	if err != nil || info == nil || info.Duration == 0 {
+		if live := e.probeLive(ctx, url, opts); live != nil {
+			return live, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	resolved := convertVideoInfo(info)
+	resolved.Formats = selectedFirst(resolved.Formats, mediaURL)
+	return resolved, nil
+}
+
+// Download stores the selected format of the video at outputPath. Like the other
+// engines it writes outputPath+PartSuffix and renames it on completion unless
+// opts.KeepPart is set; the library keeps its own temporary file next to that.
+// With opts.Segments above one the resolved stream is fetched over that many
+// connections instead, which works around per-connection throttling.
+func (e *YTDLP) Download(ctx context.Context, url, outputPath string, opts Options, progress func(Progress)) (*VideoInfo, error) {
+	if opts.Segments > 1 {
+		return e.downloadSegmented(ctx, url, outputPath, opts, progress)
+	}
+	partPath := outputPath + PartSuffix
+	d := e.downloader(opts).WithOutputPath(partPath)
+	if progress != nil {
+		d = d.WithProgress(func(p ytdlp.Progress) {
+			progress(Progress{TotalSize: p.TotalSize, DownloadedSize: p.DownloadedSize})
+		})
+	}
+	info, err := d.Download(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if err := finishPart(outputPath, opts); err != nil {
+		return nil, err
+	}
+	return convertVideoInfo(info), nil
+}
+
+// downloadSegmented resolves the stream of the selected format and fetches it
+// with DownloadFileSegmented's byte ranges
+func (e *YTDLP) downloadSegmented(ctx context.Context, url, outputPath string, opts Options, progress func(Progress)) (*VideoInfo, error) {
+	mediaURL, info, err := e.downloader(opts).ResolveURL(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if err := fetchSegmented(ctx, clientFor(opts), mediaURL, outputPath, opts.Segments, progress); err != nil {
+		return nil, err
+	}
+	if err := finishPart(outputPath, opts); err != nil {
+		return nil, err
+	}
+	resolved := convertVideoInfo(info)
+	resolved.Formats = selectedFirst(resolved.Formats, mediaURL)
+	return resolved, nil
+}
+
+// PlaylistItems lists all items of a playlist, following continuations
+func (e *YTDLP) PlaylistItems(ctx context.Context, playlistID string, opts Options) ([]PlaylistItem, error) {
+	items, err := e.downloader(opts).GetPlaylistItemsAll(ctx, playlistID, 0)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]PlaylistItem, 0, len(items))
+	for _, it := range items {
+		result = append(result, PlaylistItem{VideoID: it.VideoID, Title: it.Title, Index: it.Index})
+	}
+	return result, nil
+}
+
+// downloader creates a library downloader for one call
+func (e *YTDLP) downloader(opts Options) *ytdlp.Downloader {
+	d := ytdlp.New().WithFormat(opts.Quality, opts.Ext)
+	if opts.HTTPClient != nil {
+		d = d.WithHTTPClient(opts.HTTPClient)
+	}
+	return d
+}
+
+// convertVideoInfo maps library metadata to engine types
+func convertVideoInfo(info *ytdlp.VideoInfo) *VideoInfo {
+	if info == nil {
+		return nil
+	}
+	return &VideoInfo{
+		ID:       info.ID,
+		Title:    info.Title,
+		Author:   info.Author,
+		Duration: info.Duration,
+		Formats:  convertFormats(info.Formats),
+		Chapters: ParseChapters(info.Description, info.Duration),
+	}
+}
+
+// selectedFirst moves the format the library selected for mediaURL to the
+// front and sets its file type and size. Deciphered URLs differ from the
+// format URL, so they are matched by the itag they carry.
+func selectedFirst(formats []Format, mediaURL string) []Format {
+	itag := ""
+	if u, err := url.Parse(mediaURL); err == nil {
+		itag = u.Query().Get("itag")
+	}
+	for i, f := range formats {
+		if f.URL != mediaURL && (itag == "" || f.ID != itag) {
+			continue
+		}
+		if mediaType, _, err := mime.ParseMediaType(f.MimeType); err == nil {
+			f.Ext = dashExt(mediaType)
+		}
+		if f.Size <= 0 {
+			f.Size = contentLength(mediaURL)
+		}
+		ordered := append([]Format{f}, formats[:i]...)
+		return append(ordered, formats[i+1:]...)
+	}
+	return formats
+}
+
+// contentLength returns the "clen" size YouTube stream URLs carry, or zero
+func contentLength(mediaURL string) int64 {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return 0
+	}
+	size, _ := strconv.ParseInt(u.Query().Get("clen"), 10, 64)
+	return max(size, 0)
+}
+
+// convertFormats maps library formats to engine formats
+func convertFormats(list []types.Format) []Format {
+	formats := make([]Format, 0, len(list))
+	for _, f := range list {
+		formats = append(formats, Format{
+			ID:       strconv.Itoa(f.Itag),
+			URL:      f.URL,
+			Quality:  f.Quality,
+			MimeType: f.MimeType,
+			Bitrate:  f.Bitrate,
+			Size:     f.Size,
+		})
+	}
+	return formats
+}
@@ -0,0 +1,30 @@
+package engine
+
+import "testing"
+
+func TestSelectedFirst(t *testing.T) {
+	formats := []Format{
+		{ID: "18", URL: "https://rr1.googlevideo.com/videoplayback?itag=18&n=abc", MimeType: `video/mp4; codecs="avc1.42001E, mp4a.40.2"`, Size: 1000},
+		{ID: "251", URL: "https://rr1.googlevideo.com/videoplayback?itag=251&clen=500", MimeType: `audio/webm; codecs="opus"`},
+	}
+
+	// A deciphered URL is matched by its itag
+	ordered := selectedFirst(formats, "https://rr1.googlevideo.com/videoplayback?itag=251&clen=500&n=xyz")
+	if ordered[0].ID != "251" || ordered[0].Ext != "webm" || ordered[0].Size != 500 || ordered[1].ID != "18" {
+		t.Errorf("unexpected order %+v", ordered)
+	}
+	if formats[1].Ext != "" {
+		t.Error("the input formats must not change")
+	}
+
+	ordered = selectedFirst(formats, formats[0].URL)
+	if ordered[0].ID != "18" || ordered[0].Ext != "mp4" || ordered[0].Size != 1000 {
+		t.Errorf("unexpected selected format %+v", ordered[0])
+	}
+
+	// An unknown URL keeps the order and leaves the file type unknown
+	ordered = selectedFirst(formats, "https://example.com/video.mp4")
+	if ordered[0].ID != "18" || ordered[0].Ext != "" {
+		t.Errorf("unexpected formats %+v", ordered)
+	}
+}
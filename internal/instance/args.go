@@ -0,0 +1,50 @@
+package instance
+
+import (
+	"strings"
+)
+
+// Scheme is the custom URL scheme the app can be registered for, e.g.
+// ytdl://www.youtube.com/watch?v=ID or ytdl:https://youtu.be/ID
+const Scheme = "ytdl"
+
+// NormalizeURL converts a ytdl:// link into the https URL it wraps.
+// Other values are returned trimmed and unchanged.
+func NormalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	lower := strings.ToLower(raw)
+	prefix := Scheme + ":"
+	if !strings.HasPrefix(lower, prefix) {
+		return raw
+	}
+
+	rest := strings.TrimPrefix(raw[len(prefix):], "//")
+	restLower := strings.ToLower(rest)
+	// Some launchers collapse "https://" into "https:/" inside the wrapped link
+	for _, wrapped := range []string{"https:", "http:"} {
+		if strings.HasPrefix(restLower, wrapped) {
+			return wrapped + "//" + strings.TrimLeft(rest[len(wrapped):], "/")
+		}
+	}
+	if rest == "" {
+		return ""
+	}
+	return "https://" + rest
+}
+
+// URLArgs returns the command-line arguments that are http(s) or ytdl links,
+// normalized with NormalizeURL. Flags and other arguments are ignored.
+func URLArgs(args []string) []string {
+	var urls []string
+	for _, arg := range args {
+		lower := strings.ToLower(strings.TrimSpace(arg))
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") &&
+			!strings.HasPrefix(lower, Scheme+":") {
+			continue
+		}
+		if url := NormalizeURL(arg); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
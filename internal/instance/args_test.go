@@ -0,0 +1,41 @@
+package instance
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain https", " https://youtu.be/abc ", "https://youtu.be/abc"},
+		{"scheme with host", "ytdl://www.youtube.com/watch?v=abc", "https://www.youtube.com/watch?v=abc"},
+		{"wrapped https", "ytdl://https://youtu.be/abc", "https://youtu.be/abc"},
+		{"wrapped without slashes", "ytdl:https://youtu.be/abc", "https://youtu.be/abc"},
+		{"collapsed slashes", "ytdl://https:/youtu.be/abc", "https://youtu.be/abc"},
+		{"wrapped http", "YTDL://http://example.com/v.mp4", "http://example.com/v.mp4"},
+		{"empty link", "ytdl://", ""},
+		{"other value", "not a url", "not a url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeURL(tt.in); got != tt.want {
+				t.Errorf("NormalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLArgs(t *testing.T) {
+	args := []string{"-psn_0_12345", "https://youtu.be/a", "file.txt", "ytdl://youtu.be/b", "ytdl://"}
+	want := []string{"https://youtu.be/a", "https://youtu.be/b"}
+	if got := URLArgs(args); !reflect.DeepEqual(got, want) {
+		t.Errorf("URLArgs = %v, want %v", got, want)
+	}
+	if got := URLArgs(nil); got != nil {
+		t.Errorf("URLArgs(nil) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,5 @@
+package instance
+
+// Package instance keeps a single running copy of the app. The first launch
+// listens on a Unix domain socket in the user config directory; later launches
+// forward their URL arguments (including ytdl:// links) to it and exit.
@@ -0,0 +1,179 @@
+package instance
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/platform"
+)
+
+// Lock constants
+const (
+	SocketName   = "instance.sock"
+	DialTimeout  = 2 * time.Second
+	ReplyTimeout = 30 * time.Second
+	// MaxSocketPathLen keeps the path below the sun_path limit on every platform
+	MaxSocketPathLen = 100
+
+	replyOK    = "ok"
+	replyError = "error "
+)
+
+// ErrAlreadyRunning is returned by Acquire when another instance owns the socket
+var ErrAlreadyRunning = errors.New("another instance is already running")
+
+// Lock is held by the primary instance and receives URLs forwarded by later launches
+type Lock struct {
+	path     string
+	listener net.Listener
+
+	mutex  sync.Mutex
+	closed bool
+}
+
+// DefaultSocketPath returns the socket location in the user config directory,
+// falling back to the temp directory when that path is too long for a socket
+func DefaultSocketPath() (string, error) {
+	dir, err := platform.AppConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, SocketName)
+	if len(path) > MaxSocketPathLen {
+		return filepath.Join(os.TempDir(), fmt.Sprintf("%s-%d.sock", platform.AppConfigDirName, os.Getuid())), nil
+	}
+	return path, nil
+}
+
+// Acquire becomes the primary instance by listening on path.
+// It returns ErrAlreadyRunning when another instance answers on the socket;
+// a stale socket left by a crashed instance is removed and reused.
+func Acquire(path string) (*Lock, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		if isRunning(path) {
+			return nil, ErrAlreadyRunning
+		}
+		if removeErr := os.Remove(path); removeErr != nil && !os.IsNotExist(removeErr) {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", removeErr)
+		}
+		listener, err = net.Listen("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+		}
+	}
+	return &Lock{path: path, listener: listener}, nil
+}
+
+// Serve handles forwarded URLs with handler until Close is called.
+// Connections made before Serve wait in the listen backlog.
+func (l *Lock) Serve(handler func(url string) error) {
+	go func() {
+		for {
+			conn, err := l.listener.Accept()
+			if err != nil {
+				if !l.isClosed() {
+					log.Printf("instance: accept failed: %v", err)
+				}
+				return
+			}
+			go l.handleConn(conn, handler)
+		}
+	}()
+}
+
+// Close stops listening and removes the socket file
+func (l *Lock) Close() error {
+	l.mutex.Lock()
+	if l.closed {
+		l.mutex.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mutex.Unlock()
+
+	err := l.listener.Close()
+	if removeErr := os.Remove(l.path); removeErr != nil && !os.IsNotExist(removeErr) && err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// Path returns the socket path held by the lock
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// isClosed reports whether Close was called
+func (l *Lock) isClosed() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.closed
+}
+
+// handleConn reads one URL per line and answers each with "ok" or "error <message>"
+func (l *Lock) handleConn(conn net.Conn, handler func(url string) error) {
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(ReplyTimeout))
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		url := strings.TrimSpace(scanner.Text())
+		if url == "" {
+			continue
+		}
+		reply := replyOK
+		if err := handler(url); err != nil {
+			reply = replyError + strings.ReplaceAll(err.Error(), "\n", " ")
+		}
+		if _, err := fmt.Fprintln(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+// Forward sends URLs to the instance listening on path and returns the first rejection.
+// An empty list only checks that the instance is reachable.
+func Forward(path string, urls []string) error {
+	conn, err := net.DialTimeout("unix", path, DialTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to running instance: %w", err)
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(ReplyTimeout))
+	reader := bufio.NewReader(conn)
+	var firstErr error
+	for _, url := range urls {
+		if _, err := fmt.Fprintln(conn, url); err != nil {
+			return fmt.Errorf("failed to send URL: %w", err)
+		}
+		reply, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read reply: %w", err)
+		}
+		reply = strings.TrimSpace(reply)
+		if reply != replyOK && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %s", url, strings.TrimPrefix(reply, replyError))
+		}
+	}
+	return firstErr
+}
+
+// isRunning reports whether an instance accepts connections on path
+func isRunning(path string) bool {
+	conn, err := net.DialTimeout("unix", path, DialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,117 @@
+package instance
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func socketPath(t *testing.T) string {
+	t.Helper()
+	// Keep the path short enough for sun_path on every platform
+	dir, err := os.MkdirTemp("", "ytdl")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, SocketName)
+}
+
+func TestAcquireRejectsSecondInstance(t *testing.T) {
+	path := socketPath(t)
+
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer lock.Close()
+
+	if _, err := Acquire(path); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("second Acquire error = %v, want ErrAlreadyRunning", err)
+	}
+
+	if err := lock.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("socket file should be removed after Close, stat err = %v", err)
+	}
+
+	again, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire after Close: %v", err)
+	}
+	again.Close()
+}
+
+func TestAcquireReplacesStaleSocket(t *testing.T) {
+	path := socketPath(t)
+
+	// Simulate a crashed instance: the socket file exists but nobody listens
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	if unixListener, ok := listener.(*net.UnixListener); ok {
+		unixListener.SetUnlinkOnClose(false)
+	}
+	listener.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("stale socket should exist: %v", err)
+	}
+
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire over stale socket: %v", err)
+	}
+	lock.Close()
+}
+
+func TestForwardDeliversURLs(t *testing.T) {
+	path := socketPath(t)
+
+	lock, err := Acquire(path)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer lock.Close()
+
+	var mutex sync.Mutex
+	var received []string
+	lock.Serve(func(url string) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if strings.Contains(url, "bad") {
+			return errors.New("invalid URL")
+		}
+		received = append(received, url)
+		return nil
+	})
+
+	if err := Forward(path, nil); err != nil {
+		t.Fatalf("Forward without URLs: %v", err)
+	}
+
+	urls := []string{"https://youtu.be/a", "https://bad.example", "https://youtu.be/b"}
+	err = Forward(path, urls)
+	if err == nil || !strings.Contains(err.Error(), "https://bad.example: invalid URL") {
+		t.Fatalf("Forward error = %v, want rejection of the bad URL", err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	want := []string{"https://youtu.be/a", "https://youtu.be/b"}
+	if strings.Join(received, " ") != strings.Join(want, " ") {
+		t.Fatalf("received %v, want %v", received, want)
+	}
+}
+
+func TestForwardWithoutInstance(t *testing.T) {
+	if err := Forward(socketPath(t), []string{"https://youtu.be/a"}); err == nil {
+		t.Fatal("Forward should fail when no instance is running")
+	}
+}
@@ -1,6 +1,10 @@
 package model
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,7 +15,9 @@ const (
 	PlaylistStatusParsing     PlaylistStatus = "parsing"
 	PlaylistStatusReady       PlaylistStatus = "ready"
 	PlaylistStatusDownloading PlaylistStatus = "downloading"
+	PlaylistStatusPaused      PlaylistStatus = "paused"
 	PlaylistStatusCompleted   PlaylistStatus = "completed"
+	PlaylistStatusCancelled   PlaylistStatus = "cancelled"
 	PlaylistStatusError       PlaylistStatus = "error"
 )
 
@@ -39,6 +45,7 @@ type PlaylistVideo struct {
 	Error      string      `json:"error,omitempty"`
 	OutputPath string      `json:"output_path,omitempty"` // Path to downloaded file
 	FileSize   int64       `json:"file_size,omitempty"`   // File size in bytes
+	TaskID     string      `json:"task_id,omitempty"`     // Download task queued for this video
 	// Runtime telemetry (mirrors DownloadTask fields)
 	Speed     string    `json:"speed,omitempty"`   // human readable speed (e.g., "1.2MB/s")
 	ETASec    int       `json:"eta_sec,omitempty"` // ETA in seconds, -1 if unknown
@@ -58,6 +65,13 @@ type Playlist struct {
 	Error       string           `json:"error,omitempty"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
+
+	// Output layout
+	UseSubfolder bool `json:"use_subfolder"` // own folder named after the title, files prefixed by index
+	WriteM3U     bool `json:"write_m3u"`     // keep an .m3u8 file listing downloaded videos in order
+
+	// StartAt defers the start of the playlist's downloads; zero starts them right away
+	StartAt time.Time `json:"start_at,omitempty"`
 }
 
 // NewPlaylist creates a new playlist instance
@@ -72,6 +86,17 @@ func NewPlaylist(url string) *Playlist {
 	}
 }
 
+// Clone returns a deep copy of the playlist and its videos
+func (p *Playlist) Clone() *Playlist {
+	clone := *p
+	clone.Videos = make([]*PlaylistVideo, len(p.Videos))
+	for i, video := range p.Videos {
+		videoCopy := *video
+		clone.Videos[i] = &videoCopy
+	}
+	return &clone
+}
+
 // AddVideo adds a video to the playlist
 func (p *Playlist) AddVideo(video *PlaylistVideo) {
 	p.Videos = append(p.Videos, video)
@@ -179,6 +204,11 @@ func (p *Playlist) IsReadyForDownload() bool {
 	return p.Status == PlaylistStatusReady && p.TotalVideos > 0
 }
 
+// IsActive checks if playlist videos are queued, downloading or paused
+func (p *Playlist) IsActive() bool {
+	return p.Status == PlaylistStatusDownloading || p.Status == PlaylistStatusPaused
+}
+
 // HasErrors checks if any video has errors
 func (p *Playlist) HasErrors() bool {
 	for _, video := range p.Videos {
@@ -188,3 +218,127 @@ func (p *Playlist) HasErrors() bool {
 	}
 	return false
 }
+
+// GetVideoIndex returns the 1-based position of a video in the playlist, or 0 if absent
+func (p *Playlist) GetVideoIndex(videoID string) int {
+	for i, video := range p.Videos {
+		if video.ID == videoID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// IsSelected reports whether the video is included in the download.
+// Deselected videos are represented by VideoStatusSkipped.
+func (v *PlaylistVideo) IsSelected() bool {
+	return v.Status != VideoStatusSkipped
+}
+
+// SetVideoSelected includes or excludes a single video while the playlist is under review
+func (p *Playlist) SetVideoSelected(videoID string, selected bool) {
+	for _, video := range p.Videos {
+		if video.ID == videoID {
+			p.setSelected(video, selected)
+			break
+		}
+	}
+}
+
+// SelectAll includes or excludes every video of the playlist
+func (p *Playlist) SelectAll(selected bool) {
+	for _, video := range p.Videos {
+		p.setSelected(video, selected)
+	}
+}
+
+// SelectRange keeps only videos whose 1-based playlist position is within [from, to]
+func (p *Playlist) SelectRange(from, to int) error {
+	if from < 1 || to < from || from > len(p.Videos) {
+		return fmt.Errorf("invalid range %d-%d for playlist of %d videos", from, to, len(p.Videos))
+	}
+	for i, video := range p.Videos {
+		position := i + 1
+		p.setSelected(video, position >= from && position <= to)
+	}
+	return nil
+}
+
+// FilterByTitle deselects selected videos whose title does not match the regular expression
+func (p *Playlist) FilterByTitle(pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid title pattern: %w", err)
+	}
+	for _, video := range p.Videos {
+		if video.IsSelected() && !re.MatchString(video.Title) {
+			p.setSelected(video, false)
+		}
+	}
+	return nil
+}
+
+// FilterByDuration deselects selected videos whose known duration is outside [min, max].
+// A zero bound is ignored. Videos with unknown duration are left untouched.
+func (p *Playlist) FilterByDuration(min, max time.Duration) {
+	for _, video := range p.Videos {
+		if !video.IsSelected() {
+			continue
+		}
+		d, ok := ParseDuration(video.Duration)
+		if !ok {
+			continue
+		}
+		if (min > 0 && d < min) || (max > 0 && d > max) {
+			p.setSelected(video, false)
+		}
+	}
+}
+
+// GetSelectedCount returns the number of videos included in the download
+func (p *Playlist) GetSelectedCount() int {
+	count := 0
+	for _, video := range p.Videos {
+		if video.IsSelected() {
+			count++
+		}
+	}
+	return count
+}
+
+// setSelected toggles between pending and skipped; videos that already
+// started or finished keep their status.
+func (p *Playlist) setSelected(video *PlaylistVideo, selected bool) {
+	switch {
+	case selected && video.Status == VideoStatusSkipped:
+		video.Status = VideoStatusPending
+	case !selected && video.Status == VideoStatusPending:
+		video.Status = VideoStatusSkipped
+	default:
+		return
+	}
+	video.UpdatedAt = time.Now()
+	p.UpdatedAt = video.UpdatedAt
+}
+
+// ParseDuration parses "SS", "MM:SS" or "HH:MM:SS" into a duration.
+// It returns false for empty or unknown values.
+func ParseDuration(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+	var seconds int
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(seconds) * time.Second, true
+}
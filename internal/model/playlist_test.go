@@ -0,0 +1,155 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestPlaylist(titles ...string) *Playlist {
+	p := NewPlaylist("https://www.youtube.com/playlist?list=PLtest")
+	for i, title := range titles {
+		p.AddVideo(&PlaylistVideo{
+			ID:     string(rune('a' + i)),
+			Title:  title,
+			Status: VideoStatusPending,
+		})
+	}
+	return p
+}
+
+func TestPlaylist_SelectRange(t *testing.T) {
+	p := newTestPlaylist("one", "two", "three", "four", "five")
+
+	if err := p.SelectRange(2, 4); err != nil {
+		t.Fatalf("SelectRange(2, 4) returned error: %v", err)
+	}
+
+	expected := []VideoStatus{VideoStatusSkipped, VideoStatusPending, VideoStatusPending, VideoStatusPending, VideoStatusSkipped}
+	for i, video := range p.Videos {
+		if video.Status != expected[i] {
+			t.Errorf("video %d status = %s, expected %s", i+1, video.Status, expected[i])
+		}
+	}
+	if got := p.GetSelectedCount(); got != 3 {
+		t.Errorf("GetSelectedCount() = %d, expected 3", got)
+	}
+
+	invalid := [][2]int{{0, 2}, {3, 2}, {6, 7}}
+	for _, r := range invalid {
+		if err := p.SelectRange(r[0], r[1]); err == nil {
+			t.Errorf("SelectRange(%d, %d) expected error, got nil", r[0], r[1])
+		}
+	}
+}
+
+func TestPlaylist_SelectionKeepsStartedVideos(t *testing.T) {
+	p := newTestPlaylist("one", "two")
+	p.Videos[0].Status = VideoStatusCompleted
+
+	p.SelectAll(false)
+	if p.Videos[0].Status != VideoStatusCompleted {
+		t.Errorf("completed video status changed to %s", p.Videos[0].Status)
+	}
+	if p.Videos[1].Status != VideoStatusSkipped {
+		t.Errorf("pending video status = %s, expected %s", p.Videos[1].Status, VideoStatusSkipped)
+	}
+
+	p.SetVideoSelected(p.Videos[1].ID, true)
+	if p.Videos[1].Status != VideoStatusPending {
+		t.Errorf("reselected video status = %s, expected %s", p.Videos[1].Status, VideoStatusPending)
+	}
+}
+
+func TestPlaylist_FilterByTitle(t *testing.T) {
+	p := newTestPlaylist("Lecture 1 - Intro", "Q&A session", "Lecture 2 - Sets")
+
+	if err := p.FilterByTitle(`^Lecture \d+`); err != nil {
+		t.Fatalf("FilterByTitle returned error: %v", err)
+	}
+	if got := p.GetSelectedCount(); got != 2 {
+		t.Errorf("GetSelectedCount() = %d, expected 2", got)
+	}
+	if p.Videos[1].IsSelected() {
+		t.Error("expected non-matching video to be deselected")
+	}
+
+	if err := p.FilterByTitle("("); err == nil {
+		t.Error("expected error for invalid pattern, got nil")
+	}
+}
+
+func TestPlaylist_FilterByDuration(t *testing.T) {
+	p := newTestPlaylist("short", "medium", "long", "unknown")
+	p.Videos[0].Duration = "00:45"
+	p.Videos[1].Duration = "12:30"
+	p.Videos[2].Duration = "01:10:00"
+	p.Videos[3].Duration = "Unknown"
+
+	p.FilterByDuration(time.Minute, time.Hour)
+
+	expected := []bool{false, true, false, true}
+	for i, video := range p.Videos {
+		if video.IsSelected() != expected[i] {
+			t.Errorf("video %q selected = %v, expected %v", video.Title, video.IsSelected(), expected[i])
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"45", 45 * time.Second, true},
+		{"03:15", 3*time.Minute + 15*time.Second, true},
+		{"1:02:03", time.Hour + 2*time.Minute + 3*time.Second, true},
+		{"", 0, false},
+		{"Unknown", 0, false},
+		{"1:2:3:4", 0, false},
+	}
+
+	for _, test := range tests {
+		result, ok := ParseDuration(test.input)
+		if ok != test.ok || result != test.expected {
+			t.Errorf("ParseDuration(%q) = %v, %v, expected %v, %v", test.input, result, ok, test.expected, test.ok)
+		}
+	}
+}
+
+func TestPlaylist_IsActive(t *testing.T) {
+	tests := map[PlaylistStatus]bool{
+		PlaylistStatusReady:       false,
+		PlaylistStatusDownloading: true,
+		PlaylistStatusPaused:      true,
+		PlaylistStatusCompleted:   false,
+		PlaylistStatusCancelled:   false,
+		PlaylistStatusError:       false,
+	}
+
+	for status, expected := range tests {
+		p := newTestPlaylist("one")
+		p.UpdateStatus(status)
+		if p.IsActive() != expected {
+			t.Errorf("IsActive() for %s = %v, expected %v", status, p.IsActive(), expected)
+		}
+	}
+}
+
+func TestPlaylist_Clone(t *testing.T) {
+	p := newTestPlaylist("one", "two")
+	clone := p.Clone()
+
+	clone.UpdateStatus(PlaylistStatusDownloading)
+	clone.UpdateVideoStatus(clone.Videos[0].ID, VideoStatusCompleted)
+
+	if p.Status != PlaylistStatusParsing {
+		t.Errorf("original status changed to %s", p.Status)
+	}
+	if p.Videos[0].Status != VideoStatusPending {
+		t.Errorf("original video status changed to %s", p.Videos[0].Status)
+	}
+	if len(clone.Videos) != len(p.Videos) {
+		t.Errorf("clone has %d videos, expected %d", len(clone.Videos), len(p.Videos))
+	}
+}
@@ -7,12 +7,22 @@ const (
 	// TaskStatusPending means the task is queued but not started
 	TaskStatusPending TaskStatus = "Pending"
 
+	// TaskStatusScheduled means the task is queued but may not start before a
+	// requested time or outside the allowed download window
+	TaskStatusScheduled TaskStatus = "Scheduled"
+
 	// TaskStatusStarting means the task is in the process of starting
 	TaskStatusStarting TaskStatus = "Starting"
 
 	// TaskStatusDownloading means the download is in progress
 	TaskStatusDownloading TaskStatus = "Downloading"
 
+	// TaskStatusWaiting means the task waits for a scheduled live broadcast or premiere to begin
+	TaskStatusWaiting TaskStatus = "Waiting"
+
+	// TaskStatusRecording means a live broadcast is being recorded
+	TaskStatusRecording TaskStatus = "Recording"
+
 	// TaskStatusStopping means the task is in the process of stopping
 	TaskStatusStopping TaskStatus = "Stopping"
 
@@ -27,6 +37,10 @@ const (
 
 	// TaskStatusError means the task failed with an error
 	TaskStatusError TaskStatus = "Error"
+
+	// TaskStatusVerifyFailed means the download finished but the file failed the
+	// integrity check
+	TaskStatusVerifyFailed TaskStatus = "Verification failed"
 )
 
 // String returns the string representation of TaskStatus
@@ -36,10 +50,21 @@ func (ts TaskStatus) String() string {
 
 // IsActive returns true if the task is in an active state
 func (ts TaskStatus) IsActive() bool {
-	return ts == TaskStatusStarting || ts == TaskStatusDownloading || ts == TaskStatusStopping
+	return ts == TaskStatusStarting || ts == TaskStatusDownloading || ts == TaskStatusStopping ||
+		ts == TaskStatusWaiting || ts == TaskStatusRecording
 }
 
-// IsFinished returns true if the task is in a finished state (completed, stopped, or error)
+// IsQueued returns true if the task waits in the queue for a download slot
+func (ts TaskStatus) IsQueued() bool {
+	return ts == TaskStatusPending || ts == TaskStatusScheduled
+}
+
+// IsFinished returns true if the task is in a finished state (completed, stopped, or failed)
 func (ts TaskStatus) IsFinished() bool {
-	return ts == TaskStatusCompleted || ts == TaskStatusStopped || ts == TaskStatusError
+	return ts == TaskStatusCompleted || ts == TaskStatusStopped || ts.IsFailed()
+}
+
+// IsFailed returns true if the task failed with an error or its file failed verification
+func (ts TaskStatus) IsFailed() bool {
+	return ts == TaskStatusError || ts == TaskStatusVerifyFailed
 }
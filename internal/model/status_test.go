@@ -8,9 +8,12 @@ func TestTaskStatus_IsActive(t *testing.T) {
 		expected bool
 	}{
 		{TaskStatusPending, false},
+		{TaskStatusScheduled, false},
 		{TaskStatusStarting, true},
 		{TaskStatusDownloading, true},
 		{TaskStatusStopping, true},
+		{TaskStatusWaiting, true},
+		{TaskStatusRecording, true},
 		{TaskStatusStopped, false},
 		{TaskStatusCompleted, false},
 		{TaskStatusError, false},
@@ -30,12 +33,16 @@ func TestTaskStatus_IsFinished(t *testing.T) {
 		expected bool
 	}{
 		{TaskStatusPending, false},
+		{TaskStatusScheduled, false},
 		{TaskStatusStarting, false},
 		{TaskStatusDownloading, false},
 		{TaskStatusStopping, false},
+		{TaskStatusWaiting, false},
+		{TaskStatusRecording, false},
 		{TaskStatusStopped, true},
 		{TaskStatusCompleted, true},
 		{TaskStatusError, true},
+		{TaskStatusVerifyFailed, true},
 	}
 
 	for _, test := range tests {
@@ -55,3 +62,29 @@ func TestTaskStatus_String(t *testing.T) {
 		t.Errorf("TaskStatus.String() = %s, expected %s", result, expected)
 	}
 }
+
+func TestTaskStatus_IsQueued(t *testing.T) {
+	for _, status := range []TaskStatus{TaskStatusPending, TaskStatusScheduled} {
+		if !status.IsQueued() {
+			t.Errorf("TaskStatus(%s).IsQueued() = false, expected true", status)
+		}
+	}
+	for _, status := range []TaskStatus{TaskStatusStarting, TaskStatusPaused, TaskStatusWaiting, TaskStatusCompleted} {
+		if status.IsQueued() {
+			t.Errorf("TaskStatus(%s).IsQueued() = true, expected false", status)
+		}
+	}
+}
+
+func TestTaskStatus_IsFailed(t *testing.T) {
+	for _, status := range []TaskStatus{TaskStatusError, TaskStatusVerifyFailed} {
+		if !status.IsFailed() {
+			t.Errorf("TaskStatus(%s).IsFailed() = false, expected true", status)
+		}
+	}
+	for _, status := range []TaskStatus{TaskStatusCompleted, TaskStatusStopped, TaskStatusPaused} {
+		if status.IsFailed() {
+			t.Errorf("TaskStatus(%s).IsFailed() = true, expected false", status)
+		}
+	}
+}
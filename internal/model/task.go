@@ -10,6 +10,7 @@ import (
 type DownloadTask struct {
 	ID         string
 	URL        string
+	Site       string // extractor that handles the URL, e.g. "youtube", "vimeo", "direct"
 	Status     TaskStatus
 	Progress   float64   // 0.0 to 1.0
 	Percent    int       // 0 to 100
@@ -22,6 +23,74 @@ type DownloadTask struct {
 	Title      string    // video title
 	Duration   string    // video duration
 	FileSize   int64     // file size in bytes
+
+	// Output overrides (used by playlist downloads)
+	OutputDir      string // target directory; service download dir when empty
+	FilenamePrefix string // prepended to the title before sanitizing, e.g. "007 "
+
+	// Queue placement
+	QueueSeq   uint64       // position in the download queue; lower starts first
+	Priority   TaskPriority // where the task joins the queue relative to others
+	PlaylistID string       // owning playlist, empty for single downloads
+
+	// StartAt defers the start of the task; zero starts it as soon as a slot is free
+	StartAt time.Time
+
+	// Proxy overrides the global proxy for this task, e.g. "socks5://host:1080"
+	Proxy string
+
+	// Live broadcasts
+	ScheduledStart time.Time     // start of the broadcast a Waiting task waits for, or when a Scheduled task starts
+	Recorded       time.Duration // media recorded so far from a live broadcast
+
+	// Chapters read from the video metadata, empty if the video has none
+	Chapters []Chapter
+
+	// RemovedDuration is the length of the SponsorBlock segments cut out of the file
+	RemovedDuration time.Duration
+}
+
+// TaskPriority decides where a task joins the download queue: after the
+// queued tasks of the same or a higher priority, before lower ones
+type TaskPriority int
+
+const (
+	PriorityLow    TaskPriority = -1
+	PriorityNormal TaskPriority = 0
+	PriorityHigh   TaskPriority = 1
+)
+
+// String returns the name of the priority
+func (p TaskPriority) String() string {
+	switch {
+	case p > PriorityNormal:
+		return "high"
+	case p < PriorityNormal:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// ParseTaskPriority parses "low", "normal" or "high"
+func ParseTaskPriority(name string) (TaskPriority, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "low":
+		return PriorityLow, nil
+	case "normal", "":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	default:
+		return PriorityNormal, fmt.Errorf("unknown priority: %s", name)
+	}
+}
+
+// Chapter is a named section of a downloaded video
+type Chapter struct {
+	Title string
+	Start time.Duration
+	End   time.Duration // zero when the last chapter runs to the end of the file
 }
 
 // CompressionTask represents a single compression task
@@ -58,6 +127,36 @@ func (dt *DownloadTask) GetETAString() string {
 	return b.String()
 }
 
+// GetCountdownString returns the time left until ScheduledStart as hh:mm:ss or mm:ss,
+// "00:00" once it has passed, or "—" if no start is scheduled
+func (dt *DownloadTask) GetCountdownString(now time.Time) string {
+	if dt.ScheduledStart.IsZero() {
+		return "—"
+	}
+	return formatClock(int(max(dt.ScheduledStart.Sub(now).Round(time.Second).Seconds(), 0)))
+}
+
+// GetRecordedString returns the recorded duration of a live broadcast as hh:mm:ss or mm:ss
+func (dt *DownloadTask) GetRecordedString() string {
+	return formatClock(int(dt.Recorded.Seconds()))
+}
+
+// GetRemovedString returns the duration cut out of the file as hh:mm:ss or mm:ss
+func (dt *DownloadTask) GetRemovedString() string {
+	return formatClock(int(dt.RemovedDuration.Round(time.Second).Seconds()))
+}
+
+// formatClock formats seconds as hh:mm:ss, or mm:ss below an hour
+func formatClock(totalSeconds int) string {
+	hours := totalSeconds / 3600
+	minutes := (totalSeconds % 3600) / 60
+	seconds := totalSeconds % 60
+	if hours > 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%02d:%02d", minutes, seconds)
+}
+
 // GetDisplayTitle returns title, filename, or URL in order of preference
 func (dt *DownloadTask) GetDisplayTitle() string {
 	// First priority: video title (non-URL)
@@ -28,6 +28,37 @@ func TestDownloadTask_GetETAString(t *testing.T) {
 	}
 }
 
+func TestDownloadTask_LiveStrings(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		start    time.Time
+		expected string
+	}{
+		{time.Time{}, "—"},
+		{now.Add(-time.Minute), "00:00"},
+		{now.Add(95 * time.Second), "01:35"},
+		{now.Add(26*time.Hour + 3*time.Second), "26:00:03"},
+	}
+	for _, test := range tests {
+		task := &DownloadTask{ScheduledStart: test.start}
+		if result := task.GetCountdownString(now); result != test.expected {
+			t.Errorf("GetCountdownString() with start %v = %s, expected %s", test.start, result, test.expected)
+		}
+	}
+
+	task := &DownloadTask{Recorded: time.Hour + 2*time.Minute + 3500*time.Millisecond}
+	if result := task.GetRecordedString(); result != "01:02:03" {
+		t.Errorf("GetRecordedString() = %s, expected 01:02:03", result)
+	}
+}
+
+func TestDownloadTask_GetRemovedString(t *testing.T) {
+	task := &DownloadTask{RemovedDuration: 95600 * time.Millisecond}
+	if result := task.GetRemovedString(); result != "01:36" {
+		t.Errorf("GetRemovedString() = %s, expected 01:36", result)
+	}
+}
+
 func TestDownloadTask_GetDisplayTitle(t *testing.T) {
 	tests := []struct {
 		title    string
@@ -77,3 +108,18 @@ func TestDownloadTask_Creation(t *testing.T) {
 		t.Errorf("Expected StartedAt to be %v, got %v", now, task.StartedAt)
 	}
 }
+
+func TestParseTaskPriority(t *testing.T) {
+	for _, priority := range []TaskPriority{PriorityLow, PriorityNormal, PriorityHigh} {
+		parsed, err := ParseTaskPriority(priority.String())
+		if err != nil || parsed != priority {
+			t.Errorf("ParseTaskPriority(%q) = %v, %v", priority.String(), parsed, err)
+		}
+	}
+	if parsed, err := ParseTaskPriority(""); err != nil || parsed != PriorityNormal {
+		t.Errorf("expected an empty name to mean normal, got %v, %v", parsed, err)
+	}
+	if _, err := ParseTaskPriority("urgent"); err == nil {
+		t.Error("expected an error for an unknown priority")
+	}
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Netscape cookies.txt format
+const (
+	NetscapeHeader  = "# Netscape HTTP Cookie File"
+	httpOnlyPrefix  = "#HttpOnly_"
+	netscapeFields  = 7
+	netscapeTrue    = "TRUE"
+	netscapeFalse   = "FALSE"
+	sessionExpiry   = 0
+	maxCookieLength = 1 << 20
+)
+
+// ParseNetscapeCookies reads cookies in the Netscape cookies.txt format used by
+// browser extensions and yt-dlp. Expired cookies are skipped.
+func ParseNetscapeCookies(r io.Reader) ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	now := time.Now()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCookieLength)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, httpOnlyPrefix) {
+			httpOnly = true
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != netscapeFields {
+			return nil, fmt.Errorf("line %d: expected %d tab-separated fields, got %d", lineNo, netscapeFields, len(fields))
+		}
+		expiry, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", lineNo, fields[4])
+		}
+
+		cookie := &http.Cookie{
+			Domain:   fields[0],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], netscapeTrue),
+			Name:     fields[5],
+			Value:    fields[6],
+			HttpOnly: httpOnly,
+		}
+		if expiry != sessionExpiry {
+			cookie.Expires = time.Unix(expiry, 0)
+			if cookie.Expires.Before(now) {
+				continue
+			}
+		}
+		cookies = append(cookies, cookie)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cookies: %w", err)
+	}
+	return cookies, nil
+}
+
+// WriteNetscapeCookies writes cookies in the Netscape cookies.txt format
+func WriteNetscapeCookies(w io.Writer, cookies []*http.Cookie) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, NetscapeHeader)
+	for _, cookie := range cookies {
+		domain := cookie.Domain
+		if cookie.HttpOnly {
+			domain = httpOnlyPrefix + domain
+		}
+		includeSubdomains := netscapeFalse
+		if strings.HasPrefix(cookie.Domain, ".") {
+			includeSubdomains = netscapeTrue
+		}
+		secure := netscapeFalse
+		if cookie.Secure {
+			secure = netscapeTrue
+		}
+		var expiry int64 = sessionExpiry
+		if !cookie.Expires.IsZero() {
+			expiry = cookie.Expires.Unix()
+		}
+		path := cookie.Path
+		if path == "" {
+			path = "/"
+		}
+		fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			domain, includeSubdomains, path, secure, expiry, cookie.Name, cookie.Value)
+	}
+	return bw.Flush()
+}
+
+// NewCookieJar returns a jar preloaded with cookies, or nil when there are none
+func NewCookieJar(cookies []*http.Cookie) (http.CookieJar, error) {
+	if len(cookies) == 0 {
+		return nil, nil
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The jar accepts cookies per origin, so group them by domain and scheme
+	byOrigin := make(map[string][]*http.Cookie)
+	for _, cookie := range cookies {
+		host := strings.TrimPrefix(cookie.Domain, ".")
+		if host == "" {
+			continue
+		}
+		scheme := "http"
+		if cookie.Secure {
+			scheme = "https"
+		}
+		origin := scheme + "://" + host + "/"
+		byOrigin[origin] = append(byOrigin[origin], cookie)
+	}
+	for origin, group := range byOrigin {
+		u, err := url.Parse(origin)
+		if err != nil {
+			continue
+		}
+		jar.SetCookies(u, group)
+	}
+	return jar, nil
+}
@@ -0,0 +1,117 @@
+package network
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNetscapeCookies(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour).Unix()
+	input := strings.Join([]string{
+		NetscapeHeader,
+		"# comment",
+		"",
+		".youtube.com\tTRUE\t/\tTRUE\t" + strconv.FormatInt(future, 10) + "\tSID\tsecret",
+		"#HttpOnly_.youtube.com\tTRUE\t/\tTRUE\t0\tHSID\tsession",
+		".youtube.com\tTRUE\t/\tFALSE\t1\tOLD\texpired",
+	}, "\r\n")
+
+	cookies, err := ParseNetscapeCookies(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseNetscapeCookies: %v", err)
+	}
+	if len(cookies) != 2 {
+		t.Fatalf("expected 2 cookies, got %d", len(cookies))
+	}
+	if c := cookies[0]; c.Name != "SID" || c.Value != "secret" || !c.Secure || c.HttpOnly || c.Expires.Unix() != future {
+		t.Errorf("unexpected first cookie: %+v", c)
+	}
+	if c := cookies[1]; c.Name != "HSID" || !c.HttpOnly || !c.Expires.IsZero() {
+		t.Errorf("unexpected session cookie: %+v", c)
+	}
+}
+
+func TestParseNetscapeCookiesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"too few fields", ".youtube.com\tTRUE\t/\tTRUE\t0\tSID"},
+		{"bad expiry", ".youtube.com\tTRUE\t/\tTRUE\tsoon\tSID\tvalue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseNetscapeCookies(strings.NewReader(tt.input)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestWriteNetscapeCookiesRoundTrip(t *testing.T) {
+	expires := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+	cookies := []*http.Cookie{
+		{Name: "SID", Value: "a", Domain: ".youtube.com", Path: "/", Secure: true, Expires: expires},
+		{Name: "PREF", Value: "b", Domain: "www.youtube.com", HttpOnly: true},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteNetscapeCookies(&buf, cookies); err != nil {
+		t.Fatalf("WriteNetscapeCookies: %v", err)
+	}
+	parsed, err := ParseNetscapeCookies(&buf)
+	if err != nil {
+		t.Fatalf("ParseNetscapeCookies: %v", err)
+	}
+	if len(parsed) != 2 {
+		t.Fatalf("expected 2 cookies, got %d", len(parsed))
+	}
+	if !parsed[0].Expires.Equal(expires) || !parsed[0].Secure {
+		t.Errorf("unexpected first cookie: %+v", parsed[0])
+	}
+	if parsed[1].Path != "/" || !parsed[1].HttpOnly {
+		t.Errorf("unexpected second cookie: %+v", parsed[1])
+	}
+}
+
+func TestNewCookieJar(t *testing.T) {
+	jar, err := NewCookieJar(nil)
+	if err != nil || jar != nil {
+		t.Fatalf("expected nil jar without cookies, got %v (%v)", jar, err)
+	}
+
+	jar, err = NewCookieJar([]*http.Cookie{
+		{Name: "SID", Value: "yt", Domain: ".youtube.com", Path: "/", Secure: true},
+		{Name: "NID", Value: "g", Domain: ".google.com", Path: "/"},
+	})
+	if err != nil {
+		t.Fatalf("NewCookieJar: %v", err)
+	}
+
+	tests := []struct {
+		rawURL string
+		want   string
+	}{
+		{"https://www.youtube.com/watch?v=x", "yt"},
+		{"https://m.youtube.com/", "yt"},
+		{"http://www.youtube.com/", ""},
+		{"https://accounts.google.com/", "g"},
+		{"https://example.com/", ""},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.rawURL)
+		got := jar.Cookies(u)
+		value := ""
+		if len(got) > 0 {
+			value = got[0].Value
+		}
+		if value != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.rawURL, value, tt.want)
+		}
+	}
+}
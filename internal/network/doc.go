@@ -0,0 +1,6 @@
+package network
+
+// Package network builds the HTTP clients used for metadata resolution,
+// playlist parsing and media transfer, applying the configured proxy
+// (HTTP, HTTPS or SOCKS5 with optional credentials, or the system environment)
+// and imported cookies in the Netscape cookies.txt format.
@@ -0,0 +1,155 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProxyMode selects how requests reach the network
+type ProxyMode string
+
+const (
+	ProxyNone   ProxyMode = "none"   // direct connection, environment ignored
+	ProxySystem ProxyMode = "system" // HTTP_PROXY/HTTPS_PROXY/NO_PROXY from the environment
+	ProxyHTTP   ProxyMode = "http"
+	ProxyHTTPS  ProxyMode = "https"
+	ProxySOCKS5 ProxyMode = "socks5"
+)
+
+// Transport timeouts; there is no overall client timeout because media transfers are long
+const (
+	DialTimeout           = 30 * time.Second
+	TLSHandshakeTimeout   = 15 * time.Second
+	ResponseHeaderTimeout = 30 * time.Second
+	IdleConnTimeout       = 90 * time.Second
+	MaxIdleConns          = 100
+)
+
+// ProxyModes lists the modes in the order shown in settings
+var ProxyModes = []ProxyMode{ProxyNone, ProxySystem, ProxyHTTP, ProxyHTTPS, ProxySOCKS5}
+
+// ProxyConfig describes the proxy used for all network requests
+type ProxyConfig struct {
+	Mode     ProxyMode
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// NeedsServer reports whether the mode requires host and port
+func (m ProxyMode) NeedsServer() bool {
+	return m == ProxyHTTP || m == ProxyHTTPS || m == ProxySOCKS5
+}
+
+// Validate checks that the configuration is complete
+func (c ProxyConfig) Validate() error {
+	switch c.Mode {
+	case ProxyNone, ProxySystem:
+		return nil
+	case ProxyHTTP, ProxyHTTPS, ProxySOCKS5:
+	default:
+		return fmt.Errorf("unknown proxy mode %q", c.Mode)
+	}
+	if strings.TrimSpace(c.Host) == "" {
+		return fmt.Errorf("proxy host is required")
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		return fmt.Errorf("proxy port must be between 1 and 65535")
+	}
+	if c.Password != "" && c.Username == "" {
+		return fmt.Errorf("proxy password requires a username")
+	}
+	return nil
+}
+
+// URL returns the proxy URL for server modes, or nil for none and system
+func (c ProxyConfig) URL() (*url.URL, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if !c.Mode.NeedsServer() {
+		return nil, nil
+	}
+	u := &url.URL{
+		Scheme: string(c.Mode),
+		Host:   net.JoinHostPort(strings.TrimSpace(c.Host), strconv.Itoa(c.Port)),
+	}
+	if c.Username != "" {
+		u.User = url.UserPassword(c.Username, c.Password)
+	}
+	return u, nil
+}
+
+// String returns the proxy URL with the password masked, for logs and UI
+func (c ProxyConfig) String() string {
+	u, err := c.URL()
+	if err != nil || u == nil {
+		return string(c.Mode)
+	}
+	return u.Redacted()
+}
+
+// ParseProxyURL parses "http://", "https://" or "socks5://[user:pass@]host:port".
+// An empty string yields ProxyNone.
+func ParseProxyURL(raw string) (ProxyConfig, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ProxyConfig{Mode: ProxyNone}, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ProxyConfig{}, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	mode := ProxyMode(strings.ToLower(u.Scheme))
+	if mode == "socks5h" {
+		mode = ProxySOCKS5
+	}
+	if !mode.NeedsServer() {
+		return ProxyConfig{}, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return ProxyConfig{}, fmt.Errorf("proxy URL must include a port")
+	}
+	cfg := ProxyConfig{Mode: mode, Host: u.Hostname(), Port: port}
+	if u.User != nil {
+		cfg.Username = u.User.Username()
+		cfg.Password, _ = u.User.Password()
+	}
+	return cfg, cfg.Validate()
+}
+
+// NewHTTPClient returns a client that routes requests according to cfg and sends
+// cookies from jar, which may be nil. Each call builds a new transport so callers
+// may adjust it freely.
+func NewHTTPClient(cfg ProxyConfig, jar http.CookieJar) (*http.Client, error) {
+	transport := &http.Transport{
+		DialContext:           (&net.Dialer{Timeout: DialTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   TLSHandshakeTimeout,
+		ResponseHeaderTimeout: ResponseHeaderTimeout,
+		IdleConnTimeout:       IdleConnTimeout,
+		MaxIdleConns:          MaxIdleConns,
+	}
+
+	switch cfg.Mode {
+	case ProxyNone, "":
+		transport.Proxy = nil
+	case ProxySystem:
+		transport.Proxy = http.ProxyFromEnvironment
+	default:
+		proxyURL, err := cfg.URL()
+		if err != nil {
+			return nil, err
+		}
+		// net/http speaks HTTP CONNECT, HTTPS and SOCKS5 proxies natively
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return &http.Client{Transport: transport, Jar: jar}, nil
+}
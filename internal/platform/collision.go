@@ -0,0 +1,56 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CollisionPolicy decides what happens when an output file already exists
+type CollisionPolicy string
+
+const (
+	CollisionSkip      CollisionPolicy = "skip"      // keep the existing file as the result
+	CollisionOverwrite CollisionPolicy = "overwrite" // replace the existing file
+	CollisionRename    CollisionPolicy = "rename"    // write to "name_1.ext", "name_2.ext", ...
+	CollisionVerify    CollisionPolicy = "verify"    // keep the file if it matches, rename otherwise
+)
+
+// CollisionPolicies lists the policies in the order shown in settings
+var CollisionPolicies = []CollisionPolicy{CollisionVerify, CollisionRename, CollisionSkip, CollisionOverwrite}
+
+// IsValid reports whether the policy is known
+func (p CollisionPolicy) IsValid() bool {
+	for _, policy := range CollisionPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// FileExists reports whether path is an existing non-empty file
+func FileExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir() && fi.Size() > 0
+}
+
+// NumberedPath returns the first of "name_1.ext", "name_2.ext", ... next to
+// path that does not exist yet
+func NumberedPath(path string) string {
+	return NumberedPathAvoiding(path, nil)
+}
+
+// NumberedPathAvoiding is NumberedPath that also skips the names taken reports,
+// such as files other downloads are about to write. taken may be nil.
+func NumberedPathAvoiding(path string, taken func(string) bool) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for counter := 1; ; counter++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, counter, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) && (taken == nil || !taken(candidate)) {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNumberedPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Intro.mp4")
+
+	if got := NumberedPath(path); got != filepath.Join(dir, "Intro_1.mp4") {
+		t.Errorf("expected Intro_1.mp4, got %s", got)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Intro_1.mp4"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := NumberedPath(path); got != filepath.Join(dir, "Intro_2.mp4") {
+		t.Errorf("expected Intro_2.mp4, got %s", got)
+	}
+
+	taken := func(path string) bool { return filepath.Base(path) == "Intro_2.mp4" }
+	if got := NumberedPathAvoiding(path, taken); got != filepath.Join(dir, "Intro_3.mp4") {
+		t.Errorf("expected Intro_3.mp4, got %s", got)
+	}
+}
+
+func TestFileExists(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.mp4")
+	full := filepath.Join(dir, "full.mp4")
+	os.WriteFile(empty, nil, 0644)
+	os.WriteFile(full, []byte("data"), 0644)
+
+	if FileExists(empty) || FileExists(dir) || FileExists(filepath.Join(dir, "missing.mp4")) {
+		t.Error("empty files, directories and missing files do not count as existing")
+	}
+	if !FileExists(full) {
+		t.Error("expected a non-empty file to exist")
+	}
+	if !CollisionVerify.IsValid() || CollisionPolicy("ask").IsValid() {
+		t.Error("unexpected policy validity")
+	}
+}
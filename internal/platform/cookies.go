@@ -0,0 +1,438 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/network"
+)
+
+// Cookie storage in AppConfigDir
+const (
+	CookiesFileName        = "cookies.txt"
+	CookiesFilePermissions = 0600
+)
+
+// Browser identifies a browser whose cookie database can be imported
+type Browser string
+
+const (
+	BrowserFirefox  Browser = "firefox"
+	BrowserChromium Browser = "chromium"
+)
+
+// Browser cookie import
+const (
+	SQLiteCommand       = "sqlite3"
+	BrowserQueryTimeout = 10 * time.Second
+
+	// Chromium on Linux without a keyring encrypts values with a fixed password
+	chromiumPassword      = "peanuts"
+	chromiumSalt          = "saltysalt"
+	chromiumIterations    = 1
+	chromiumKeyLength     = 16
+	chromiumV10Prefix     = "v10"
+	chromiumHashedVersion = 24 // from this meta version values start with sha256(host)
+	chromiumHashLength    = 32
+	// Chromium stores expiry as microseconds since 1601-01-01
+	chromiumEpochOffset = 11644473600
+	// Recent Firefox versions store expiry in milliseconds
+	firefoxMillisThreshold = 1e11
+)
+
+// CookieDomains limits browser imports to the sites the downloader talks to
+var CookieDomains = []string{"youtube.com", "google.com"}
+
+// Browser profile locations relative to the home directory
+var (
+	FirefoxProfileRoots  = []string{".mozilla/firefox", "snap/firefox/common/.mozilla/firefox"}
+	ChromiumProfileRoots = []string{".config/chromium", ".config/google-chrome", "snap/chromium/common/chromium"}
+)
+
+// Errors returned by browser cookie import
+var (
+	ErrBrowserCookiesUnsupported = errors.New("reading browser cookies is only supported on Linux")
+	ErrBrowserProfileNotFound    = errors.New("browser cookie database not found")
+	ErrNoCookiesFound            = errors.New("no YouTube cookies found")
+	ErrCookiesKeyring            = errors.New("cookies are encrypted with the system keyring; export cookies.txt instead")
+)
+
+// CookiesPath returns the location of the stored cookies file
+func CookiesPath() (string, error) {
+	dir, err := AppConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, CookiesFileName), nil
+}
+
+// LoadCookies returns the stored cookies, or nil if none were imported
+func LoadCookies() ([]*http.Cookie, error) {
+	path, err := CookiesPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return network.ParseNetscapeCookies(file)
+}
+
+// SaveCookies replaces the stored cookies. The file is readable by the owner only.
+func SaveCookies(cookies []*http.Cookie) error {
+	path, err := CookiesPath()
+	if err != nil {
+		return err
+	}
+
+	// Write to a private temp file first so a failed write keeps the old cookies
+	tmp, err := os.CreateTemp(filepath.Dir(path), CookiesFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create cookies file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(CookiesFilePermissions); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := network.WriteNetscapeCookies(tmp, cookies); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cookies: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ClearCookies removes the stored cookies
+func ClearCookies() error {
+	path, err := CookiesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ImportCookiesFile parses a Netscape cookies.txt and stores its cookies
+func ImportCookiesFile(r io.Reader) ([]*http.Cookie, error) {
+	cookies, err := network.ParseNetscapeCookies(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 {
+		return nil, ErrNoCookiesFound
+	}
+	if err := SaveCookies(cookies); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// ImportBrowserCookies reads YouTube and Google cookies from the most recently
+// used profile of a local browser and stores them. Linux only; requires sqlite3.
+func ImportBrowserCookies(ctx context.Context, browser Browser) ([]*http.Cookie, error) {
+	if runtime.GOOS != OSLinux {
+		return nil, ErrBrowserCookiesUnsupported
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var cookies []*http.Cookie
+	switch browser {
+	case BrowserFirefox:
+		db := findCookieDatabase(home, FirefoxProfileRoots, []string{"*/cookies.sqlite"})
+		if db == "" {
+			return nil, ErrBrowserProfileNotFound
+		}
+		cookies, err = readFirefoxCookies(ctx, db)
+	case BrowserChromium:
+		db := findCookieDatabase(home, ChromiumProfileRoots, []string{"*/Cookies", "*/Network/Cookies"})
+		if db == "" {
+			return nil, ErrBrowserProfileNotFound
+		}
+		cookies, err = readChromiumCookies(ctx, db)
+	default:
+		return nil, fmt.Errorf("unsupported browser: %s", browser)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(cookies) == 0 {
+		return nil, ErrNoCookiesFound
+	}
+	if err := SaveCookies(cookies); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// findCookieDatabase returns the most recently modified database matching the patterns
+func findCookieDatabase(home string, roots, patterns []string) string {
+	var newest string
+	var newestTime time.Time
+	for _, root := range roots {
+		for _, pattern := range patterns {
+			matches, _ := filepath.Glob(filepath.Join(home, root, pattern))
+			for _, match := range matches {
+				info, err := os.Stat(match)
+				if err != nil || info.IsDir() {
+					continue
+				}
+				if newest == "" || info.ModTime().After(newestTime) {
+					newest, newestTime = match, info.ModTime()
+				}
+			}
+		}
+	}
+	return newest
+}
+
+// firefoxCookieRow is one row of the moz_cookies table
+type firefoxCookieRow struct {
+	Host     string `json:"host"`
+	Path     string `json:"path"`
+	Secure   int    `json:"isSecure"`
+	HTTPOnly int    `json:"isHttpOnly"`
+	Expiry   int64  `json:"expiry"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+}
+
+// readFirefoxCookies reads YouTube cookies from a Firefox cookies.sqlite
+func readFirefoxCookies(ctx context.Context, dbPath string) ([]*http.Cookie, error) {
+	query := "SELECT host, path, isSecure, isHttpOnly, expiry, name, value FROM moz_cookies WHERE " +
+		domainFilter("host")
+
+	var rows []firefoxCookieRow
+	if err := querySQLite(ctx, dbPath, query, &rows); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cookies := make([]*http.Cookie, 0, len(rows))
+	for _, row := range rows {
+		expiry := row.Expiry
+		if expiry > firefoxMillisThreshold {
+			expiry /= 1000
+		}
+		cookie := &http.Cookie{
+			Domain:   row.Host,
+			Path:     row.Path,
+			Secure:   row.Secure != 0,
+			HttpOnly: row.HTTPOnly != 0,
+			Name:     row.Name,
+			Value:    row.Value,
+		}
+		if expiry > 0 {
+			cookie.Expires = time.Unix(expiry, 0)
+			if cookie.Expires.Before(now) {
+				continue
+			}
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies, nil
+}
+
+// chromiumCookieRow is one row of the Chromium cookies table
+type chromiumCookieRow struct {
+	Host      string `json:"host_key"`
+	Path      string `json:"path"`
+	Secure    int    `json:"is_secure"`
+	HTTPOnly  int    `json:"is_httponly"`
+	Expires   int64  `json:"expires_utc"`
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Encrypted string `json:"encrypted_value"` // hex encoded
+}
+
+// readChromiumCookies reads YouTube cookies from a Chromium Cookies database.
+// Values protected by the system keyring (v11) cannot be decrypted and are skipped.
+func readChromiumCookies(ctx context.Context, dbPath string) ([]*http.Cookie, error) {
+	var meta []struct {
+		Value string `json:"value"`
+	}
+	if err := querySQLite(ctx, dbPath, "SELECT value FROM meta WHERE key = 'version'", &meta); err != nil {
+		return nil, err
+	}
+	version := 0
+	if len(meta) > 0 {
+		version, _ = strconv.Atoi(meta[0].Value)
+	}
+
+	query := "SELECT host_key, path, is_secure, is_httponly, expires_utc, name, value, " +
+		"hex(encrypted_value) AS encrypted_value FROM cookies WHERE " + domainFilter("host_key")
+	var rows []chromiumCookieRow
+	if err := querySQLite(ctx, dbPath, query, &rows); err != nil {
+		return nil, err
+	}
+
+	key := pbkdf2Key()
+	now := time.Now()
+	cookies := make([]*http.Cookie, 0, len(rows))
+	skipped := 0
+	for _, row := range rows {
+		value := row.Value
+		if value == "" && row.Encrypted != "" {
+			encrypted, err := hex.DecodeString(row.Encrypted)
+			if err != nil {
+				skipped++
+				continue
+			}
+			value, err = decryptChromiumValue(key, encrypted, version >= chromiumHashedVersion)
+			if err != nil {
+				skipped++
+				continue
+			}
+		}
+		cookie := &http.Cookie{
+			Domain:   row.Host,
+			Path:     row.Path,
+			Secure:   row.Secure != 0,
+			HttpOnly: row.HTTPOnly != 0,
+			Name:     row.Name,
+			Value:    value,
+		}
+		if row.Expires > 0 {
+			cookie.Expires = time.Unix(row.Expires/1e6-chromiumEpochOffset, 0)
+			if cookie.Expires.Before(now) {
+				continue
+			}
+		}
+		cookies = append(cookies, cookie)
+	}
+	if len(cookies) == 0 && skipped > 0 {
+		return nil, ErrCookiesKeyring
+	}
+	return cookies, nil
+}
+
+// pbkdf2Key derives the key Chromium uses when no keyring is available
+func pbkdf2Key() []byte {
+	key, _ := pbkdf2.Key(sha1.New, chromiumPassword, []byte(chromiumSalt), chromiumIterations, chromiumKeyLength)
+	return key
+}
+
+// decryptChromiumValue decrypts a v10 cookie value (AES-128-CBC, PKCS#7 padding)
+func decryptChromiumValue(key, encrypted []byte, hashedHost bool) (string, error) {
+	if !bytes.HasPrefix(encrypted, []byte(chromiumV10Prefix)) {
+		return "", ErrCookiesKeyring
+	}
+	data := encrypted[len(chromiumV10Prefix):]
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return "", errors.New("invalid encrypted cookie length")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	iv := bytes.Repeat([]byte{' '}, aes.BlockSize)
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(plain) {
+		return "", errors.New("invalid cookie padding")
+	}
+	plain = plain[:len(plain)-padding]
+	if hashedHost {
+		if len(plain) < chromiumHashLength {
+			return "", errors.New("invalid cookie value")
+		}
+		plain = plain[chromiumHashLength:]
+	}
+	return string(plain), nil
+}
+
+// domainFilter builds a WHERE clause matching CookieDomains and their subdomains
+func domainFilter(column string) string {
+	clauses := make([]string, 0, len(CookieDomains)*2)
+	for _, domain := range CookieDomains {
+		clauses = append(clauses,
+			fmt.Sprintf("%s = '%s'", column, domain),
+			fmt.Sprintf("%s LIKE '%%.%s'", column, domain))
+	}
+	return strings.Join(clauses, " OR ")
+}
+
+// querySQLite runs a query against a copy of the database, since browsers keep it locked
+func querySQLite(ctx context.Context, dbPath, query string, result any) error {
+	sqlite, err := exec.LookPath(SQLiteCommand)
+	if err != nil {
+		return fmt.Errorf("%s is required to read browser cookies: %w", SQLiteCommand, err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "yt-downloader-cookies-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	copyPath := filepath.Join(tmpDir, filepath.Base(dbPath))
+	if err := copyFile(dbPath, copyPath); err != nil {
+		return fmt.Errorf("failed to copy cookie database: %w", err)
+	}
+	// Recent writes may still live in the write-ahead log
+	if _, err := os.Stat(dbPath + "-wal"); err == nil {
+		_ = copyFile(dbPath+"-wal", copyPath+"-wal")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, BrowserQueryTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, sqlite, "-json", copyPath, query).Output()
+	if err != nil {
+		return fmt.Errorf("failed to query cookie database: %w", err)
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(output, result)
+}
+
+// copyFile copies src to dst with owner-only permissions
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, CookiesFilePermissions)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,171 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCookieStorage(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	cookies, err := LoadCookies()
+	if err != nil || cookies != nil {
+		t.Fatalf("expected no stored cookies, got %v (%v)", cookies, err)
+	}
+
+	input := ".youtube.com\tTRUE\t/\tTRUE\t0\tSID\tsecret\n"
+	imported, err := ImportCookiesFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ImportCookiesFile: %v", err)
+	}
+	if len(imported) != 1 {
+		t.Fatalf("expected 1 imported cookie, got %d", len(imported))
+	}
+
+	path, err := CookiesPath()
+	if err != nil {
+		t.Fatalf("CookiesPath: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat cookies file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != CookiesFilePermissions {
+		t.Errorf("expected permissions %o, got %o", CookiesFilePermissions, perm)
+	}
+	dirInfo, _ := os.Stat(filepath.Dir(path))
+	if perm := dirInfo.Mode().Perm(); perm != ConfigDirPermissions {
+		t.Errorf("expected config dir permissions %o, got %o", ConfigDirPermissions, perm)
+	}
+
+	loaded, err := LoadCookies()
+	if err != nil || len(loaded) != 1 || loaded[0].Value != "secret" {
+		t.Fatalf("unexpected loaded cookies %v (%v)", loaded, err)
+	}
+
+	if _, err := ImportCookiesFile(strings.NewReader("# empty\n")); err != ErrNoCookiesFound {
+		t.Errorf("expected ErrNoCookiesFound, got %v", err)
+	}
+
+	if err := ClearCookies(); err != nil {
+		t.Fatalf("ClearCookies: %v", err)
+	}
+	if loaded, _ := LoadCookies(); loaded != nil {
+		t.Errorf("expected no cookies after clear, got %v", loaded)
+	}
+	if err := ClearCookies(); err != nil {
+		t.Errorf("clearing twice should succeed: %v", err)
+	}
+}
+
+func TestDecryptChromiumValue(t *testing.T) {
+	key := pbkdf2Key()
+	hostHash := sha256.Sum256([]byte(".youtube.com"))
+
+	tests := []struct {
+		name       string
+		plain      []byte
+		prefix     string
+		hashedHost bool
+		want       string
+		wantErr    bool
+	}{
+		{"v10", []byte("value"), chromiumV10Prefix, false, "value", false},
+		{"v10 hashed host", append(hostHash[:], "value"...), chromiumV10Prefix, true, "value", false},
+		{"block sized", []byte("0123456789abcdef"), chromiumV10Prefix, false, "0123456789abcdef", false},
+		{"keyring", []byte("value"), "v11", false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encrypted := append([]byte(tt.prefix), encryptChromium(t, key, tt.plain)...)
+			got, err := decryptChromiumValue(key, encrypted, tt.hashedHost)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadBrowserCookieDatabases(t *testing.T) {
+	if _, err := exec.LookPath(SQLiteCommand); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+	dir := t.TempDir()
+	future := time.Now().Add(time.Hour)
+
+	firefoxDB := filepath.Join(dir, "cookies.sqlite")
+	createSQLite(t, firefoxDB, fmt.Sprintf(`
+CREATE TABLE moz_cookies (host TEXT, path TEXT, isSecure INTEGER, isHttpOnly INTEGER, expiry INTEGER, name TEXT, value TEXT);
+INSERT INTO moz_cookies VALUES ('.youtube.com', '/', 1, 1, %d, 'SID', 'ff');
+INSERT INTO moz_cookies VALUES ('.youtube.com', '/', 1, 0, %d, 'PREF', 'ms');
+INSERT INTO moz_cookies VALUES ('.youtube.com', '/', 1, 0, 1, 'OLD', 'expired');
+INSERT INTO moz_cookies VALUES ('.example.com', '/', 0, 0, 0, 'OTHER', 'x');`,
+		future.Unix(), future.UnixMilli()))
+
+	cookies, err := readFirefoxCookies(context.Background(), firefoxDB)
+	if err != nil {
+		t.Fatalf("readFirefoxCookies: %v", err)
+	}
+	if len(cookies) != 2 || cookies[0].Value != "ff" || !cookies[0].HttpOnly || cookies[1].Expires.Unix() != future.Unix() {
+		t.Errorf("unexpected firefox cookies: %+v", cookies)
+	}
+
+	encrypted := append([]byte(chromiumV10Prefix), encryptChromium(t, pbkdf2Key(), []byte("chrome"))...)
+	chromiumExpiry := (future.Unix() + chromiumEpochOffset) * 1e6
+	chromiumDB := filepath.Join(dir, "Cookies")
+	createSQLite(t, chromiumDB, fmt.Sprintf(`
+CREATE TABLE meta (key TEXT, value TEXT);
+INSERT INTO meta VALUES ('version', '21');
+CREATE TABLE cookies (host_key TEXT, path TEXT, is_secure INTEGER, is_httponly INTEGER, expires_utc INTEGER, name TEXT, value TEXT, encrypted_value BLOB);
+INSERT INTO cookies VALUES ('.youtube.com', '/', 1, 0, %d, 'SID', '', X'%s');
+INSERT INTO cookies VALUES ('accounts.google.com', '/', 1, 0, 0, 'NID', 'plain', X'');
+INSERT INTO cookies VALUES ('youtube.com.evil', '/', 1, 0, 0, 'BAD', 'x', X'');`,
+		chromiumExpiry, hex.EncodeToString(encrypted)))
+
+	cookies, err = readChromiumCookies(context.Background(), chromiumDB)
+	if err != nil {
+		t.Fatalf("readChromiumCookies: %v", err)
+	}
+	if len(cookies) != 2 || cookies[0].Value != "chrome" || cookies[1].Value != "plain" {
+		t.Errorf("unexpected chromium cookies: %+v", cookies)
+	}
+}
+
+// encryptChromium mirrors Chromium's v10 encryption for test fixtures
+func encryptChromium(t *testing.T, key, plain []byte) []byte {
+	t.Helper()
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	data := append(append([]byte{}, plain...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, bytes.Repeat([]byte{' '}, aes.BlockSize)).CryptBlocks(out, data)
+	return out
+}
+
+// createSQLite creates a database fixture with the sqlite3 command
+func createSQLite(t *testing.T, path, script string) {
+	t.Helper()
+	cmd := exec.Command(SQLiteCommand, path)
+	cmd.Stdin = strings.NewReader(script)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sqlite3: %v: %s", err, output)
+	}
+}
@@ -0,0 +1,46 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrFreeSpaceUnsupported is returned where free disk space cannot be queried
+var ErrFreeSpaceUnsupported = errors.New("free disk space is not available on this system")
+
+// FreeSpace returns the bytes available to the user on the file system that
+// holds path. A path that does not exist yet is looked up by its nearest
+// existing parent directory.
+func FreeSpace(path string) (uint64, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return 0, fmt.Errorf("no existing directory for %s", path)
+		}
+		dir = parent
+	}
+	return freeSpace(dir)
+}
+
+// FormatBytes formats a byte count with a binary unit, e.g. "1.5 GB"
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value, exp := float64(bytes)/unit, 0
+	for value >= unit && exp < len("KMGTPE")-1 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGTPE"[exp])
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package platform
+
+// freeSpace is not implemented on this system
+func freeSpace(dir string) (uint64, error) {
+	return 0, ErrFreeSpaceUnsupported
+}
@@ -0,0 +1,39 @@
+package platform
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestFreeSpace(t *testing.T) {
+	// A missing directory is looked up by its existing parent
+	free, err := FreeSpace(filepath.Join(t.TempDir(), "not", "created"))
+	if errors.Is(err, ErrFreeSpaceUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("FreeSpace: %v", err)
+	}
+	if free == 0 {
+		t.Error("expected free space on the temp directory")
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KB"},
+		{1536 * 1024 * 1024, "1.5 GB"},
+		{5 << 40, "5.0 TB"},
+	}
+	for _, test := range tests {
+		if result := FormatBytes(test.bytes); result != test.expected {
+			t.Errorf("FormatBytes(%d) = %q, expected %q", test.bytes, result, test.expected)
+		}
+	}
+}
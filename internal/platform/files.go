@@ -2,6 +2,7 @@ package platform
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -215,6 +216,43 @@ func CreateDirectoryIfNotExists(dirPath string) error {
 	return nil
 }
 
+// MoveFile moves src to dst. Across file systems the file is copied next to dst
+// under a temporary name first, so dst only ever appears complete.
+func MoveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := dst + ".moving"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(src)
+}
+
 // OpenFileWithDefaultApp opens the file with the default system application
 func OpenFileWithDefaultApp(filePath string) error {
 	// Try to find the file with fallback to similar names
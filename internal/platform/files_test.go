@@ -195,6 +195,31 @@ func TestIsSimilarFileName(t *testing.T) {
 	}
 }
 
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "staging", "video.mp4")
+	dst := filepath.Join(dir, "video.mp4")
+	if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MoveFile(src, dst); err != nil {
+		t.Fatalf("MoveFile: %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "video" {
+		t.Errorf("expected the moved file at %s, got %q, %v", dst, data, err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("expected the source to be gone")
+	}
+	if err := MoveFile(src, dst); err == nil {
+		t.Error("expected an error for a missing source")
+	}
+}
+
 func TestGetDescriptiveScore(t *testing.T) {
 	tests := []struct {
 		filename string
@@ -0,0 +1,36 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Application data locations
+const (
+	AppConfigDirName     = "yt-downloader"
+	QueueFileName        = "queue.json"
+	ConfigDirPermissions = 0700
+)
+
+// AppConfigDir returns the private application config directory, creating it if needed
+func AppConfigDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	dir := filepath.Join(configDir, AppConfigDirName)
+	if err := os.MkdirAll(dir, ConfigDirPermissions); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return dir, nil
+}
+
+// QueuePath returns the location of the saved download queue
+func QueuePath() (string, error) {
+	dir, err := AppConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, QueueFileName), nil
+}
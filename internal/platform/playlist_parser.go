@@ -2,11 +2,17 @@ package platform
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ytget/yt-downloader/internal/engine"
 	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/network"
+	"github.com/ytget/yt-downloader/internal/ytlink"
 )
 
 // Timeout constants
@@ -16,8 +22,7 @@ const (
 
 // URL parameters
 const (
-	PlaylistURLParam       = "list="
-	PlaylistParamSeparator = "&"
+	PlaylistURLParam = "list="
 )
 
 // Default values
@@ -30,20 +35,52 @@ const (
 
 // PlaylistParserService handles parsing of YouTube playlists
 type PlaylistParserService struct {
+	// the settings may change while a parse runs in the background
+	mutex   sync.Mutex
 	timeout time.Duration
+	engine  engine.Engine
+	proxy   network.ProxyConfig
+	cookies []*http.Cookie
 }
 
 // NewPlaylistParserService creates a new playlist parser service
 func NewPlaylistParserService() *PlaylistParserService {
 	return &PlaylistParserService{
 		timeout: DefaultPlaylistParseTimeout,
+		engine:  engine.NewYTDLP(),
+		proxy:   network.ProxyConfig{Mode: network.ProxySystem},
 	}
 }
 
+// SetEngine replaces the engine passed to the library-based parser
+func (p *PlaylistParserService) SetEngine(e engine.Engine) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.engine = e
+}
+
+// SetProxy sets the proxy passed to the library-based parser
+func (p *PlaylistParserService) SetProxy(cfg network.ProxyConfig) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.proxy = cfg
+}
+
+// SetCookies sets the cookies passed to the library-based parser
+func (p *PlaylistParserService) SetCookies(cookies []*http.Cookie) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.cookies = cookies
+}
+
 // ParsePlaylist parses a YouTube playlist URL and returns playlist information
 func (p *PlaylistParserService) ParsePlaylist(ctx context.Context, url string) (*model.Playlist, error) {
+	p.mutex.Lock()
+	timeout, eng, proxy, cookies := p.timeout, p.engine, p.proxy, p.cookies
+	p.mutex.Unlock()
+
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	// Validate URL format
@@ -65,6 +102,9 @@ func (p *PlaylistParserService) ParsePlaylist(ctx context.Context, url string) (
 
 	// Parse playlist using library-based parser
 	y := NewYTDLPParserService()
+	y.SetEngine(eng)
+	y.SetProxy(proxy)
+	y.SetCookies(cookies)
 	libPlaylist, err := y.ParsePlaylist(ctx, url)
 	if err != nil {
 		playlist.Error = err.Error()
@@ -92,41 +132,30 @@ func (p *PlaylistParserService) ParsePlaylist(ctx context.Context, url string) (
 
 // isValidPlaylistURL checks if the URL is a valid YouTube playlist URL
 func (p *PlaylistParserService) isValidPlaylistURL(url string) bool {
-	// Check for playlist parameter in URL
-	return strings.Contains(url, PlaylistURLParam)
+	return hasPlaylistParam(url)
+}
+
+// hasPlaylistParam reports whether url is a YouTube URL with a list parameter.
+// The ID itself is validated when it is extracted, so an empty list= fails there.
+func hasPlaylistParam(url string) bool {
+	_, err := ytlink.Parse(url)
+	return !errors.Is(err, ytlink.ErrNotYouTube) && strings.Contains(url, PlaylistURLParam)
 }
 
 // extractPlaylistID extracts the playlist ID from a YouTube playlist URL
 func (p *PlaylistParserService) extractPlaylistID(url string) (string, error) {
-	// Extract playlist ID from URL
-	// Support various formats:
-	// - https://www.youtube.com/watch?v=VIDEO_ID&list=PLAYLIST_ID&start_radio=1
-	// - https://www.youtube.com/watch?v=VIDEO_ID&list=PLAYLIST_ID
-	// - https://www.youtube.com/playlist?list=PLAYLIST_ID
-
-	// Find list parameter
-	if !strings.Contains(url, PlaylistURLParam) {
-		return "", fmt.Errorf("URL does not contain playlist parameter")
-	}
-
-	// Extract everything after list=
-	parts := strings.Split(url, PlaylistURLParam)
-	if len(parts) < 2 {
-		return "", fmt.Errorf("could not extract playlist ID from URL")
-	}
-
-	playlistID := parts[1]
-
-	// Remove any additional parameters (everything after &)
-	if strings.Contains(playlistID, PlaylistParamSeparator) {
-		playlistID = strings.Split(playlistID, PlaylistParamSeparator)[0]
-	}
-
-	if playlistID == "" {
+	// Supports playlist, watch (video opened from a playlist), youtu.be and embed URLs
+	link, err := ytlink.Parse(url)
+	switch {
+	case err == nil && link.IsPlaylist():
+		return link.PlaylistID, nil
+	case errors.Is(err, ytlink.ErrNotYouTube) && url != "":
+		return "", fmt.Errorf("not a YouTube playlist URL: %w", err)
+	case strings.Contains(url, PlaylistURLParam):
 		return "", fmt.Errorf("empty playlist ID")
+	default:
+		return "", fmt.Errorf("URL does not contain playlist parameter")
 	}
-
-	return playlistID, nil
 }
 
 // extractPlaylistTitle extracts a meaningful title for the playlist
@@ -147,5 +176,7 @@ func (p *PlaylistParserService) extractPlaylistTitle(videos []*model.PlaylistVid
 
 // SetTimeout sets the timeout for playlist parsing
 func (p *PlaylistParserService) SetTimeout(timeout time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 	p.timeout = timeout
 }
@@ -2,10 +2,13 @@ package platform
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
+	"github.com/ytget/yt-downloader/internal/engine"
 	"github.com/ytget/yt-downloader/internal/model"
+	"github.com/ytget/yt-downloader/internal/network"
 )
 
 func TestNewPlaylistParserService(t *testing.T) {
@@ -96,7 +99,7 @@ func TestPlaylistIsValidPlaylistURL(t *testing.T) {
 		{
 			name:     "invalid URL with different domain",
 			url:      "https://example.com/watch?v=VIDEO_ID&list=PLAYLIST_ID",
-			expected: true, // still contains list= parameter
+			expected: false, // list= on a non-YouTube host is not a playlist
 		},
 		{
 			name:     "empty URL",
@@ -371,3 +374,25 @@ func playlistContains(s, substr string) bool {
 		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
 			playlistContains(s[1:], substr))))
 }
+
+func TestPlaylistParserSettingsDuringParse(t *testing.T) {
+	fake := engine.NewFake()
+	fake.AddPlaylist("PLfake", engine.FakePlaylist{Items: []engine.PlaylistItem{{VideoID: "aaaaaaaaaaa", Title: "Episode", Index: 1}}})
+	service := NewPlaylistParserService()
+	service.SetEngine(fake)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			service.SetProxy(network.ProxyConfig{Mode: network.ProxyNone})
+			service.SetCookies([]*http.Cookie{{Name: "SID", Value: "x"}})
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		if _, err := service.ParsePlaylist(context.Background(), "https://www.youtube.com/playlist?list=PLfake"); err != nil {
+			t.Fatalf("ParsePlaylist: %v", err)
+		}
+	}
+	<-done
+}
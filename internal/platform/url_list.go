@@ -0,0 +1,72 @@
+package platform
+
+import (
+	"strings"
+)
+
+// URL list parsing
+const (
+	URLListCommentPrefix = "#"
+	urlSchemeSeparator   = "://"
+)
+
+// ParseURLList extracts URLs from pasted text or the contents of a .txt/.csv file.
+// Values may be separated by newlines, whitespace, commas, semicolons or tabs and
+// may be quoted. Blank lines and lines starting with "#" are ignored, as is a CSV
+// header row without URLs. Lines without any URL are returned as-is so callers can
+// report them as rejected. Repeated values are dropped and counted in duplicates.
+func ParseURLList(text string) (urls []string, duplicates int) {
+	seen := make(map[string]bool)
+	add := func(value string) {
+		if seen[value] {
+			duplicates++
+			return
+		}
+		seen[value] = true
+		urls = append(urls, value)
+	}
+
+	firstRow := true
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "\ufeff"))
+		if line == "" || strings.HasPrefix(line, URLListCommentPrefix) {
+			continue
+		}
+		isFirst := firstRow
+		firstRow = false
+
+		fields := splitURLListLine(line)
+		found := false
+		for _, field := range fields {
+			if strings.Contains(field, urlSchemeSeparator) {
+				add(field)
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+
+		// A leading CSV row such as "url,title" is a header, not a rejected URL
+		if isFirst && len(fields) > 1 && strings.ContainsAny(line, ",;\t") {
+			continue
+		}
+		add(line)
+	}
+	return urls, duplicates
+}
+
+// splitURLListLine splits a line on separators and strips surrounding quotes
+func splitURLListLine(line string) []string {
+	raw := strings.FieldsFunc(line, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\t' || r == ' ' || r == '\r'
+	})
+	fields := make([]string, 0, len(raw))
+	for _, field := range raw {
+		field = strings.Trim(field, `"'`)
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
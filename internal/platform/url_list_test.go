@@ -0,0 +1,67 @@
+package platform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURLList(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		wantURLs       []string
+		wantDuplicates int
+	}{
+		{
+			name:     "multi-line paste",
+			text:     "https://youtu.be/a\r\n\nhttps://youtu.be/b\n",
+			wantURLs: []string{"https://youtu.be/a", "https://youtu.be/b"},
+		},
+		{
+			name:     "single line separated by spaces",
+			text:     "https://youtu.be/a https://youtu.be/b",
+			wantURLs: []string{"https://youtu.be/a", "https://youtu.be/b"},
+		},
+		{
+			name:     "comments are skipped",
+			text:     "# from Anna\nhttps://youtu.be/a",
+			wantURLs: []string{"https://youtu.be/a"},
+		},
+		{
+			name:     "csv with header and quotes",
+			text:     "url,title\n\"https://youtu.be/a\",\"First, video\"\nhttps://youtu.be/b;Second",
+			wantURLs: []string{"https://youtu.be/a", "https://youtu.be/b"},
+		},
+		{
+			name:           "duplicates are counted",
+			text:           "https://youtu.be/a\nhttps://youtu.be/a\nhttps://youtu.be/a",
+			wantURLs:       []string{"https://youtu.be/a"},
+			wantDuplicates: 2,
+		},
+		{
+			name:     "lines without URLs are kept for rejection",
+			text:     "https://youtu.be/a\nnot a link\nyoutube.com/watch?v=x",
+			wantURLs: []string{"https://youtu.be/a", "not a link", "youtube.com/watch?v=x"},
+		},
+		{
+			name:     "byte order mark",
+			text:     "\ufeffhttps://youtu.be/a",
+			wantURLs: []string{"https://youtu.be/a"},
+		},
+		{
+			name: "empty",
+			text: " \n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls, duplicates := ParseURLList(tt.text)
+			if !reflect.DeepEqual(urls, tt.wantURLs) {
+				t.Errorf("urls = %q, want %q", urls, tt.wantURLs)
+			}
+			if duplicates != tt.wantDuplicates {
+				t.Errorf("duplicates = %d, want %d", duplicates, tt.wantDuplicates)
+			}
+		})
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ytget/yt-downloader/internal/engine"
 	"github.com/ytget/yt-downloader/internal/model"
-	"github.com/ytget/ytdlp/v2"
+	"github.com/ytget/yt-downloader/internal/network"
+	"github.com/ytget/yt-downloader/internal/ytlink"
 )
 
 // Timeout constants
@@ -17,12 +21,6 @@ const (
 	DefaultParseTimeout = 60 * time.Second
 )
 
-// URL parameters and separators
-const (
-	PlaylistParam  = "list="
-	ParamSeparator = "&"
-)
-
 // Default values
 const (
 	DefaultDuration     = "Unknown"
@@ -31,7 +29,7 @@ const (
 
 // URL templates
 const (
-	YouTubeVideoURLTemplate = "https://www.youtube.com/watch?v=%s"
+	YouTubeVideoURLTemplate = ytlink.CanonicalVideoURL
 )
 
 // Playlist title constants
@@ -49,21 +47,51 @@ const (
 
 // YTDLPParserService handles parsing of YouTube playlists using library
 type YTDLPParserService struct {
+	// the settings may change while a parse runs in the background
+	mutex   sync.Mutex
 	timeout time.Duration
+	engine  engine.Engine
+	proxy   network.ProxyConfig
+	cookies []*http.Cookie
 }
 
 // NewYTDLPParserService creates a new parser service
 func NewYTDLPParserService() *YTDLPParserService {
 	return &YTDLPParserService{
 		timeout: DefaultParseTimeout,
+		engine:  engine.NewYTDLP(),
+		proxy:   network.ProxyConfig{Mode: network.ProxySystem},
 	}
 }
 
 // SetTimeout sets the timeout for parsing operations
 func (y *YTDLPParserService) SetTimeout(timeout time.Duration) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
 	y.timeout = timeout
 }
 
+// SetEngine replaces the engine used to list playlist items, e.g. with a fake in tests
+func (y *YTDLPParserService) SetEngine(e engine.Engine) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+	y.engine = e
+}
+
+// SetProxy sets the proxy used to fetch playlist items
+func (y *YTDLPParserService) SetProxy(cfg network.ProxyConfig) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+	y.proxy = cfg
+}
+
+// SetCookies sets the cookies sent when fetching playlist items
+func (y *YTDLPParserService) SetCookies(cookies []*http.Cookie) {
+	y.mutex.Lock()
+	defer y.mutex.Unlock()
+	y.cookies = cookies
+}
+
 // ParsePlaylist parses a YouTube playlist and returns video information
 func (y *YTDLPParserService) ParsePlaylist(ctx context.Context, url string) (*model.Playlist, error) {
 	// Validate URL
@@ -77,13 +105,24 @@ func (y *YTDLPParserService) ParsePlaylist(ctx context.Context, url string) (*mo
 		return nil, fmt.Errorf("could not extract playlist ID from URL: %s", url)
 	}
 
+	y.mutex.Lock()
+	timeout, eng, proxy, cookies := y.timeout, y.engine, y.proxy, y.cookies
+	y.mutex.Unlock()
+
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(ctx, y.timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Use library to fetch items
-	d := ytdlp.New()
-	items, err := d.GetPlaylistItemsAll(ctx, playlistID, 0)
+	// Use library to fetch items through the configured proxy and cookies
+	jar, err := network.NewCookieJar(cookies)
+	if err != nil {
+		return nil, fmt.Errorf("cookie configuration: %v", err)
+	}
+	client, err := network.NewHTTPClient(proxy, jar)
+	if err != nil {
+		return nil, fmt.Errorf("proxy configuration: %v", err)
+	}
+	items, err := eng.PlaylistItems(ctx, playlistID, engine.Options{HTTPClient: client})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get playlist items: %v", err)
 	}
@@ -121,22 +160,12 @@ func (y *YTDLPParserService) ParsePlaylist(ctx context.Context, url string) (*mo
 
 // isValidPlaylistURL checks if the URL is a valid YouTube playlist URL
 func (y *YTDLPParserService) isValidPlaylistURL(url string) bool {
-	return strings.Contains(url, PlaylistParam)
+	return hasPlaylistParam(url)
 }
 
 // extractPlaylistID extracts the playlist ID from various URL formats
 func (y *YTDLPParserService) extractPlaylistID(url string) string {
-	if strings.Contains(url, PlaylistParam) {
-		parts := strings.Split(url, PlaylistParam)
-		if len(parts) > 1 {
-			playlistPart := parts[1]
-			if strings.Contains(playlistPart, ParamSeparator) {
-				playlistPart = strings.Split(playlistPart, ParamSeparator)[0]
-			}
-			return playlistPart
-		}
-	}
-	return ""
+	return ytlink.PlaylistID(url)
 }
 
 // formatDuration formats seconds into HH:MM:SS format
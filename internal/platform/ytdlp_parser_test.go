@@ -2,9 +2,11 @@ package platform
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/ytget/yt-downloader/internal/engine"
 	"github.com/ytget/yt-downloader/internal/model"
 )
 
@@ -96,7 +98,7 @@ func TestIsValidPlaylistURL(t *testing.T) {
 		{
 			name:     "invalid URL with different domain",
 			url:      "https://example.com/watch?v=VIDEO_ID&list=PLAYLIST_ID",
-			expected: true, // still contains list= parameter
+			expected: false, // list= on a non-YouTube host is not a playlist
 		},
 		{
 			name:     "empty URL",
@@ -569,3 +571,37 @@ func contains(s, substr string) bool {
 		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
 			contains(s[1:], substr))))
 }
+
+func TestParsePlaylistWithFakeEngine(t *testing.T) {
+	fake := engine.NewFake()
+	fake.AddPlaylist("PLfake", engine.FakePlaylist{Items: []engine.PlaylistItem{
+		{VideoID: "aaaaaaaaaaa", Title: "Show - Episode 1", Index: 1},
+		{VideoID: "bbbbbbbbbbb", Title: "Show - Episode 2", Index: 2},
+	}})
+	fake.AddPlaylist("PLbroken", engine.FakePlaylist{Err: errors.New("playlist unavailable")})
+
+	service := NewYTDLPParserService()
+	service.SetEngine(fake)
+
+	playlist, err := service.ParsePlaylist(context.Background(), "https://www.youtube.com/playlist?list=PLfake")
+	if err != nil {
+		t.Fatalf("ParsePlaylist: %v", err)
+	}
+	if playlist.ID != "PLfake" || playlist.TotalVideos != 2 || playlist.Status != model.PlaylistStatusReady {
+		t.Fatalf("unexpected playlist %+v", playlist)
+	}
+	if video := playlist.Videos[1]; video.URL != "https://www.youtube.com/watch?v=bbbbbbbbbbb" || video.Status != model.VideoStatusPending {
+		t.Errorf("unexpected video %+v", video)
+	}
+
+	if _, err := service.ParsePlaylist(context.Background(), "https://www.youtube.com/playlist?list=PLbroken"); err == nil {
+		t.Error("expected engine error to be returned")
+	}
+
+	wrapper := NewPlaylistParserService()
+	wrapper.SetEngine(fake)
+	playlist, err = wrapper.ParsePlaylist(context.Background(), "https://www.youtube.com/playlist?list=PLfake")
+	if err != nil || playlist.TotalVideos != 2 {
+		t.Errorf("unexpected wrapper result %v (%v)", playlist, err)
+	}
+}
@@ -0,0 +1,7 @@
+package schedule
+
+// Package schedule describes when downloads may start: a daily window of
+// allowed local clock times, e.g. 01:00–07:00 on a link that is metered
+// during business hours, possibly wrapping past midnight. The download
+// service holds tasks outside the window, or before their requested start
+// time, as Scheduled and starts them once the window opens.
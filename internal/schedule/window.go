@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a daily range of local clock times during which downloads may start.
+// An End before Start wraps past midnight; equal bounds allow the whole day.
+type Window struct {
+	Enabled bool
+	Start   time.Duration // offset from local midnight
+	End     time.Duration // offset from local midnight
+}
+
+// Day is the length of the clock range a window bound lies in
+const Day = 24 * time.Hour
+
+// ParseClock parses a "HH:MM" clock time into an offset from midnight
+func ParseClock(value string) (time.Duration, error) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(value), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", value)
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", value)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 || m > 59 || len(minutes) != 2 {
+		return 0, fmt.Errorf("invalid minutes in %q", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// FormatClock formats an offset from midnight as "HH:MM"
+func FormatClock(offset time.Duration) string {
+	offset %= Day
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
+
+// String formats the window as "HH:MM–HH:MM"
+func (w Window) String() string {
+	return FormatClock(w.Start) + "–" + FormatClock(w.End)
+}
+
+// Restricts reports whether the window limits start times at all
+func (w Window) Restricts() bool {
+	return w.Enabled && w.Start%Day != w.End%Day
+}
+
+// Contains reports whether downloads may start at t
+func (w Window) Contains(t time.Time) bool {
+	if !w.Restricts() {
+		return true
+	}
+	offset := t.Sub(midnight(t))
+	if w.Start < w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// NextOpen returns t if the window contains it, otherwise the time it opens next
+func (w Window) NextOpen(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	return NextAt(t, w.Start)
+}
+
+// NextChange returns the next time after t the window opens or closes,
+// or the zero time if it does not restrict start times
+func (w Window) NextChange(t time.Time) time.Time {
+	if !w.Restricts() {
+		return time.Time{}
+	}
+	var next time.Time
+	for day := midnight(t); next.IsZero(); day = midnight(day.AddDate(0, 0, 1)) {
+		for _, bound := range []time.Duration{w.Start, w.End} {
+			if at := day.Add(bound); at.After(t) && (next.IsZero() || at.Before(next)) {
+				next = at
+			}
+		}
+	}
+	return next
+}
+
+// NextAt returns the next time at or after t the local clock shows the offset
+func NextAt(t time.Time, offset time.Duration) time.Time {
+	at := midnight(t).Add(offset)
+	if at.Before(t) {
+		at = midnight(at.AddDate(0, 0, 1)).Add(offset)
+	}
+	return at
+}
+
+// midnight returns the start of t's day in its location
+func midnight(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
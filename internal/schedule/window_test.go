@@ -0,0 +1,83 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+// at returns a local time on a fixed day
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, time.March, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseClock(t *testing.T) {
+	if offset, err := ParseClock(" 07:30 "); err != nil || offset != 7*time.Hour+30*time.Minute {
+		t.Errorf("ParseClock = %v, %v", offset, err)
+	}
+	for _, value := range []string{"", "7", "24:00", "07:60", "07:5", "ab:cd"} {
+		if _, err := ParseClock(value); err == nil {
+			t.Errorf("ParseClock(%q) expected an error", value)
+		}
+	}
+	if clock := FormatClock(time.Hour + 5*time.Minute); clock != "01:05" {
+		t.Errorf("FormatClock = %s", clock)
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	night := Window{Enabled: true, Start: time.Hour, End: 7 * time.Hour}
+	wrapping := Window{Enabled: true, Start: 22 * time.Hour, End: 6 * time.Hour}
+	tests := []struct {
+		window   Window
+		time     time.Time
+		expected bool
+	}{
+		{night, at(1, 1, 0), true},
+		{night, at(1, 6, 59), true},
+		{night, at(1, 7, 0), false},
+		{night, at(1, 12, 0), false},
+		{wrapping, at(1, 23, 0), true},
+		{wrapping, at(1, 3, 0), true},
+		{wrapping, at(1, 12, 0), false},
+		{Window{Start: time.Hour, End: 2 * time.Hour}, at(1, 12, 0), true},
+		{Window{Enabled: true, Start: time.Hour, End: time.Hour}, at(1, 12, 0), true},
+	}
+	for _, test := range tests {
+		if result := test.window.Contains(test.time); result != test.expected {
+			t.Errorf("%v.Contains(%v) = %v, expected %v", test.window, test.time, result, test.expected)
+		}
+	}
+}
+
+func TestWindowNextOpenAndChange(t *testing.T) {
+	night := Window{Enabled: true, Start: time.Hour, End: 7 * time.Hour}
+
+	if open := night.NextOpen(at(1, 12, 0)); !open.Equal(at(2, 1, 0)) {
+		t.Errorf("NextOpen after the window = %v", open)
+	}
+	if open := night.NextOpen(at(1, 0, 30)); !open.Equal(at(1, 1, 0)) {
+		t.Errorf("NextOpen before the window = %v", open)
+	}
+	if now := at(1, 3, 0); !night.NextOpen(now).Equal(now) {
+		t.Error("NextOpen inside the window must return the time itself")
+	}
+
+	if change := night.NextChange(at(1, 3, 0)); !change.Equal(at(1, 7, 0)) {
+		t.Errorf("NextChange inside the window = %v", change)
+	}
+	if change := night.NextChange(at(1, 7, 0)); !change.Equal(at(2, 1, 0)) {
+		t.Errorf("NextChange at closing = %v", change)
+	}
+	if change := (Window{}).NextChange(at(1, 7, 0)); !change.IsZero() {
+		t.Errorf("disabled window must not change, got %v", change)
+	}
+}
+
+func TestNextAt(t *testing.T) {
+	if next := NextAt(at(1, 12, 0), 13*time.Hour); !next.Equal(at(1, 13, 0)) {
+		t.Errorf("NextAt later today = %v", next)
+	}
+	if next := NextAt(at(1, 12, 0), time.Hour); !next.Equal(at(2, 1, 0)) {
+		t.Errorf("NextAt tomorrow = %v", next)
+	}
+}
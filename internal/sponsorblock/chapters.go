@@ -0,0 +1,123 @@
+package sponsorblock
+
+import (
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+// categoryTitles names the chapters that mark segments
+var categoryTitles = map[Category]string{
+	CategorySponsor:       "Sponsor",
+	CategorySelfPromo:     "Self-promotion",
+	CategoryInteraction:   "Interaction reminder",
+	CategoryIntro:         "Intro",
+	CategoryOutro:         "Outro",
+	CategoryPreview:       "Preview",
+	CategoryMusicOffTopic: "Non-music section",
+	CategoryFiller:        "Filler",
+}
+
+// Title returns the chapter title of segments in the category
+func (c Category) Title() string {
+	if title, ok := categoryTitles[c]; ok {
+		return title
+	}
+	return string(c)
+}
+
+// removedBefore returns how much of the merged segments lies before the position
+func removedBefore(segments []Segment, position time.Duration) time.Duration {
+	var removed time.Duration
+	for _, segment := range segments {
+		if segment.Start >= position {
+			break
+		}
+		removed += min(segment.End, position) - segment.Start
+	}
+	return removed
+}
+
+// RemovedChapters shifts chapters to match the file with the merged segments
+// cut out. Chapters that lie entirely inside a segment are dropped.
+func RemovedChapters(chapters []model.Chapter, segments []Segment) []model.Chapter {
+	var result []model.Chapter
+	for _, chapter := range chapters {
+		start := chapter.Start - removedBefore(segments, chapter.Start)
+		end := chapter.End
+		if end > chapter.Start {
+			end -= removedBefore(segments, end)
+			if end <= start {
+				continue
+			}
+		}
+		if last := len(result) - 1; last >= 0 && result[last].End > start {
+			result[last].End = start
+		}
+		result = append(result, model.Chapter{Title: chapter.Title, Start: start, End: end})
+	}
+	return result
+}
+
+// MarkedChapters returns chapters with every merged segment marked as a
+// chapter of its own. The existing chapters are split at segment boundaries;
+// a video without chapters gets plain "Video" chapters between the segments.
+func MarkedChapters(chapters []model.Chapter, segments []Segment, duration time.Duration) []model.Chapter {
+	if len(segments) == 0 {
+		return chapters
+	}
+	if len(chapters) == 0 {
+		chapters = []model.Chapter{{Title: "Video", End: duration}}
+	}
+
+	var result []model.Chapter
+	add := func(title string, start, end time.Duration) {
+		if end != 0 && end <= start {
+			return
+		}
+		result = append(result, model.Chapter{Title: title, Start: start, End: end})
+	}
+	next := 0
+	for _, chapter := range chapters {
+		position := chapter.Start
+		for next < len(segments) && (chapter.End == 0 || segments[next].Start < chapter.End) {
+			segment := segments[next]
+			if segment.End <= position {
+				next++
+				continue
+			}
+			if segment.Start > position {
+				add(chapter.Title, position, segment.Start)
+				position = segment.Start
+			}
+			end := segment.End
+			if chapter.End != 0 && end > chapter.End {
+				// The segment continues into the next chapter
+				add(segment.Category.Title(), position, chapter.End)
+				position = chapter.End
+				break
+			}
+			add(segment.Category.Title(), position, end)
+			position = end
+			next++
+		}
+		if chapter.End == 0 || position < chapter.End {
+			add(chapter.Title, position, chapter.End)
+		}
+	}
+	return mergeAdjacent(result)
+}
+
+// mergeAdjacent joins neighbouring chapters with the same title, which
+// appear when a segment spans a chapter boundary
+func mergeAdjacent(chapters []model.Chapter) []model.Chapter {
+	var result []model.Chapter
+	for _, chapter := range chapters {
+		if last := len(result) - 1; last >= 0 && result[last].Title == chapter.Title && result[last].End == chapter.Start {
+			result[last].End = chapter.End
+			continue
+		}
+		result = append(result, chapter)
+	}
+	return result
+}
@@ -0,0 +1,8 @@
+package sponsorblock
+
+// Package sponsorblock queries a SponsorBlock-compatible API for the
+// community-submitted sponsor, intro, outro and similar segments of a YouTube
+// video, and maps them onto chapters: either shifting existing chapters once
+// the segments are cut out, or marking the segments as chapters of their own.
+// The base URL is configurable so a self-hosted mirror or a local mock server
+// can stand in for the public instance.
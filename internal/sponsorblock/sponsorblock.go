@@ -0,0 +1,185 @@
+package sponsorblock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Mode selects what happens to the segments of a downloaded video
+type Mode string
+
+const (
+	ModeOff      Mode = "off"      // segments are not queried
+	ModeRemove   Mode = "remove"   // segments are cut out of the file
+	ModeChapters Mode = "chapters" // segments are marked as chapters
+)
+
+// Modes lists the modes in the order shown in settings
+var Modes = []Mode{ModeOff, ModeRemove, ModeChapters}
+
+// Category is a SponsorBlock segment category
+type Category string
+
+const (
+	CategorySponsor       Category = "sponsor"
+	CategorySelfPromo     Category = "selfpromo"
+	CategoryInteraction   Category = "interaction"
+	CategoryIntro         Category = "intro"
+	CategoryOutro         Category = "outro"
+	CategoryPreview       Category = "preview"
+	CategoryMusicOffTopic Category = "music_offtopic"
+	CategoryFiller        Category = "filler"
+)
+
+// Categories lists the supported categories in the order shown in settings
+var Categories = []Category{
+	CategorySponsor, CategorySelfPromo, CategoryInteraction, CategoryIntro,
+	CategoryOutro, CategoryPreview, CategoryMusicOffTopic, CategoryFiller,
+}
+
+// DefaultCategories are queried until the user picks others
+var DefaultCategories = []Category{CategorySponsor, CategoryIntro, CategoryOutro}
+
+// API constants
+const (
+	// DefaultBaseURL is the public SponsorBlock instance
+	DefaultBaseURL = "https://sponsor.ajay.app"
+	// SkipSegmentsPath is the endpoint listing the segments of a video
+	SkipSegmentsPath = "/api/skipSegments"
+	// ActionSkip marks segments meant to be skipped; mute and full-video labels are ignored
+	ActionSkip = "skip"
+	// MaxResponseSize limits the segment list read from the server
+	MaxResponseSize = 1 << 20
+)
+
+// Config describes the SponsorBlock post-process
+type Config struct {
+	Mode       Mode
+	Categories []Category
+	BaseURL    string // empty uses DefaultBaseURL
+}
+
+// Enabled reports whether downloads should query segments
+func (c Config) Enabled() bool {
+	return (c.Mode == ModeRemove || c.Mode == ModeChapters) && len(c.Categories) > 0
+}
+
+// IsValid reports whether the category is supported
+func (c Category) IsValid() bool {
+	return slices.Contains(Categories, c)
+}
+
+// Segment is a part of a video in one category
+type Segment struct {
+	Category Category
+	Start    time.Duration
+	End      time.Duration
+}
+
+// Client queries a SponsorBlock-compatible server
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client; an empty base URL uses the public instance and a nil client the default one
+func NewClient(baseURL string, client *http.Client) *Client {
+	if strings.TrimSpace(baseURL) == "" {
+		baseURL = DefaultBaseURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Client{BaseURL: strings.TrimRight(strings.TrimSpace(baseURL), "/"), HTTPClient: client}
+}
+
+// skipSegment is one entry of the skipSegments response
+type skipSegment struct {
+	Category   Category   `json:"category"`
+	ActionType string     `json:"actionType"`
+	Segment    [2]float64 `json:"segment"`
+}
+
+// Segments returns the skippable segments of a video in the given categories,
+// sorted by start. A video without submissions has no segments.
+func (c *Client) Segments(ctx context.Context, videoID string, categories []Category) ([]Segment, error) {
+	if len(categories) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(categories)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{"videoID": {videoID}, "categories": {string(encoded)}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+SkipSegmentsPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// The API answers 404 when nothing was submitted for the video
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("sponsorblock: HTTP %d", resp.StatusCode)
+	}
+
+	var entries []skipSegment
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MaxResponseSize)).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("sponsorblock: %w", err)
+	}
+	segments := make([]Segment, 0, len(entries))
+	for _, entry := range entries {
+		if (entry.ActionType != "" && entry.ActionType != ActionSkip) || entry.Segment[1] <= entry.Segment[0] {
+			continue
+		}
+		segments = append(segments, Segment{
+			Category: entry.Category,
+			Start:    seconds(entry.Segment[0]),
+			End:      seconds(entry.Segment[1]),
+		})
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].Start < segments[j].Start })
+	return segments, nil
+}
+
+// seconds converts fractional seconds to a duration rounded to milliseconds
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second)).Round(time.Millisecond)
+}
+
+// Merge joins overlapping or touching segments of sorted input; a merged
+// segment keeps the category of its first part
+func Merge(segments []Segment) []Segment {
+	var merged []Segment
+	for _, segment := range segments {
+		if last := len(merged) - 1; last >= 0 && segment.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, segment.End)
+			continue
+		}
+		merged = append(merged, segment)
+	}
+	return merged
+}
+
+// Total returns the combined length of merged segments
+func Total(segments []Segment) time.Duration {
+	var total time.Duration
+	for _, segment := range segments {
+		total += segment.End - segment.Start
+	}
+	return total
+}
@@ -0,0 +1,140 @@
+package sponsorblock
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ytget/yt-downloader/internal/model"
+)
+
+func TestClientSegments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != SkipSegmentsPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("videoID") != "abc123DEF45" {
+			http.NotFound(w, r)
+			return
+		}
+		if categories := r.URL.Query().Get("categories"); categories != `["sponsor","intro"]` {
+			t.Errorf("unexpected categories %s", categories)
+		}
+		w.Write([]byte(`[
+			{"category":"sponsor","actionType":"skip","segment":[60.5,90.25]},
+			{"category":"intro","actionType":"skip","segment":[0,12]},
+			{"category":"sponsor","actionType":"mute","segment":[200,210]}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", nil)
+	segments, err := client.Segments(context.Background(), "abc123DEF45", []Category{CategorySponsor, CategoryIntro})
+	if err != nil {
+		t.Fatalf("Segments failed: %v", err)
+	}
+	expected := []Segment{
+		{Category: CategoryIntro, Start: 0, End: 12 * time.Second},
+		{Category: CategorySponsor, Start: 60500 * time.Millisecond, End: 90250 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(segments, expected) {
+		t.Errorf("unexpected segments %+v", segments)
+	}
+
+	// Videos without submissions answer 404
+	segments, err = client.Segments(context.Background(), "unknown0000", []Category{CategorySponsor})
+	if err != nil || segments != nil {
+		t.Errorf("expected no segments, got %+v, %v", segments, err)
+	}
+}
+
+func TestClientSegmentsServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	if _, err := NewClient(server.URL, nil).Segments(context.Background(), "abc", DefaultCategories); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestMergeAndTotal(t *testing.T) {
+	merged := Merge([]Segment{
+		{Category: CategoryIntro, Start: 0, End: 10 * time.Second},
+		{Category: CategorySponsor, Start: 5 * time.Second, End: 20 * time.Second},
+		{Category: CategoryOutro, Start: 50 * time.Second, End: 60 * time.Second},
+	})
+	expected := []Segment{
+		{Category: CategoryIntro, Start: 0, End: 20 * time.Second},
+		{Category: CategoryOutro, Start: 50 * time.Second, End: 60 * time.Second},
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("unexpected merge %+v", merged)
+	}
+	if total := Total(merged); total != 30*time.Second {
+		t.Errorf("unexpected total %v", total)
+	}
+}
+
+func TestRemovedChapters(t *testing.T) {
+	chapters := []model.Chapter{
+		{Title: "Intro", Start: 0, End: 30 * time.Second},
+		{Title: "Talk", Start: 30 * time.Second, End: 5 * time.Minute},
+		{Title: "Outro", Start: 5 * time.Minute, End: 6 * time.Minute},
+	}
+	segments := []Segment{
+		{Category: CategoryIntro, Start: 0, End: 30 * time.Second},
+		{Category: CategorySponsor, Start: 2 * time.Minute, End: 3 * time.Minute},
+	}
+	expected := []model.Chapter{
+		{Title: "Talk", Start: 0, End: 3*time.Minute + 30*time.Second},
+		{Title: "Outro", Start: 3*time.Minute + 30*time.Second, End: 4*time.Minute + 30*time.Second},
+	}
+	if result := RemovedChapters(chapters, segments); !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected chapters:\n%+v\nwant\n%+v", result, expected)
+	}
+}
+
+func TestMarkedChapters(t *testing.T) {
+	chapters := []model.Chapter{
+		{Title: "Talk", Start: 0, End: 3 * time.Minute},
+		{Title: "Q&A", Start: 3 * time.Minute, End: 5 * time.Minute},
+	}
+	segments := []Segment{
+		{Category: CategorySponsor, Start: time.Minute, End: 90 * time.Second},
+		{Category: CategoryOutro, Start: 170 * time.Second, End: 190 * time.Second},
+	}
+	expected := []model.Chapter{
+		{Title: "Talk", Start: 0, End: time.Minute},
+		{Title: "Sponsor", Start: time.Minute, End: 90 * time.Second},
+		{Title: "Talk", Start: 90 * time.Second, End: 170 * time.Second},
+		{Title: "Outro", Start: 170 * time.Second, End: 190 * time.Second},
+		{Title: "Q&A", Start: 190 * time.Second, End: 5 * time.Minute},
+	}
+	if result := MarkedChapters(chapters, segments, 5*time.Minute); !reflect.DeepEqual(result, expected) {
+		t.Errorf("unexpected chapters:\n%+v\nwant\n%+v", result, expected)
+	}
+
+	// Videos without chapters get plain chapters around the segments
+	result := MarkedChapters(nil, segments[:1], 2*time.Minute)
+	if len(result) != 3 || result[0].Title != "Video" || result[1].Title != "Sponsor" || result[2].End != 2*time.Minute {
+		t.Errorf("unexpected chapters %+v", result)
+	}
+}
+
+func TestConfigEnabled(t *testing.T) {
+	if (Config{Mode: ModeOff, Categories: DefaultCategories}).Enabled() {
+		t.Error("off mode must be disabled")
+	}
+	if (Config{Mode: ModeRemove}).Enabled() {
+		t.Error("no categories must be disabled")
+	}
+	if !(Config{Mode: ModeChapters, Categories: DefaultCategories}).Enabled() {
+		t.Error("chapters mode must be enabled")
+	}
+}
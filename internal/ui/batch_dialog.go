@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Batch dialog constants
+const (
+	BatchDialogWidth   = 600
+	BatchDialogHeight  = 420
+	BatchEntryMinLines = 12
+	// MaxRejectedShown limits the rejected lines listed in the summary dialog
+	MaxRejectedShown = 10
+)
+
+// BatchImportExtensions are the file types accepted by the batch import
+var BatchImportExtensions = []string{".txt", ".csv"}
+
+// ShowBatchDialog shows a dialog for pasting many URLs or importing them from a
+// .txt/.csv file. onSubmit receives the collected text when the user confirms.
+func ShowBatchDialog(window fyne.Window, localization *Localization, initialText string, onSubmit func(text string)) {
+	urlsEntry := widget.NewMultiLineEntry()
+	urlsEntry.SetPlaceHolder(localization.GetText(KeyBatchHint))
+	urlsEntry.SetMinRowsVisible(BatchEntryMinLines)
+	urlsEntry.SetText(initialText)
+
+	importBtn := widget.NewButton(localization.GetText(KeyImportFile), func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", localization.GetText(KeyImportFailed), err), window)
+				return
+			}
+			// Append to what is already pasted, one block per import
+			text := strings.TrimRight(urlsEntry.Text, "\n")
+			if text != "" {
+				text += "\n"
+			}
+			urlsEntry.SetText(text + string(data))
+		}, window)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter(BatchImportExtensions))
+		fileDialog.Show()
+	})
+
+	content := container.NewBorder(nil, container.NewHBox(importBtn), nil, nil, urlsEntry)
+
+	dlg := dialog.NewCustomConfirm(localization.GetText(KeyAddURLs), localization.GetText(KeyDownload), localization.GetText(KeyCancel), content, func(confirmed bool) {
+		if confirmed && onSubmit != nil {
+			onSubmit(urlsEntry.Text)
+		}
+	}, window)
+	dlg.Resize(fyne.NewSize(BatchDialogWidth, BatchDialogHeight))
+	dlg.Show()
+}
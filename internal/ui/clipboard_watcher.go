@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// Clipboard watcher constants
+const (
+	ClipboardPollInterval = time.Second
+)
+
+// ClipboardWatcher polls the clipboard on a timer and reports text that changed
+// since the previous poll. Callbacks run on the UI thread.
+type ClipboardWatcher struct {
+	clipboard fyne.Clipboard
+	onChange  func(text string)
+
+	mutex sync.Mutex
+	stop  chan struct{}
+	last  string // accessed on the UI thread only
+}
+
+// NewClipboardWatcher creates a stopped watcher for the given clipboard
+func NewClipboardWatcher(clipboard fyne.Clipboard, onChange func(text string)) *ClipboardWatcher {
+	return &ClipboardWatcher{
+		clipboard: clipboard,
+		onChange:  onChange,
+	}
+}
+
+// Start begins polling; calling it on a running watcher does nothing
+func (w *ClipboardWatcher) Start() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.stop != nil {
+		return
+	}
+	w.stop = make(chan struct{})
+	go w.run(w.stop)
+}
+
+// Stop ends polling
+func (w *ClipboardWatcher) Stop() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
+
+// run polls the clipboard until stop is closed
+func (w *ClipboardWatcher) run(stop chan struct{}) {
+	ticker := time.NewTicker(ClipboardPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			// The clipboard may only be read on the UI thread
+			fyne.Do(w.poll)
+		}
+	}
+}
+
+// poll reads the clipboard and reports new content
+func (w *ClipboardWatcher) poll() {
+	text := w.clipboard.Content()
+	if text == "" || text == w.last {
+		return
+	}
+	w.last = text
+	if w.onChange != nil {
+		w.onChange(text)
+	}
+}
@@ -29,6 +29,8 @@ const (
 	MiddleDotSeparator  = " · "
 	DashPlaceholder     = "—"
 	ProgressLabelFormat = "%d%%"
+	// StartAtLayout formats the clock time a scheduled task starts at
+	StartAtLayout = "15:04"
 )
 
 // Layout sizing (TaskRow / lists)
@@ -75,11 +77,6 @@ const (
 	UIUpdateDebounce = 100 * time.Millisecond
 )
 
-// URLs / parsing
-const (
-	PlaylistQueryParam = "list="
-)
-
 // Delays
 const (
 	AutoStartDelay = 500 * time.Millisecond
@@ -5,7 +5,10 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/driver/mobile"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
 )
 
 // GestureType represents different types of gestures
@@ -216,3 +219,43 @@ func (ptr *PullToRefreshWidget) TouchCancel(event *mobile.TouchEvent) {
 		ptr.gestureHandler.TouchCancel(event)
 	}
 }
+
+// DragHandle is a grip that reports the vertical distance it was dragged.
+// Only the grip is draggable, so lists around it still scroll normally.
+type DragHandle struct {
+	widget.BaseWidget
+
+	onDragEnd func(dy float32)
+	dy        float32
+}
+
+// NewDragHandle creates a grip that calls onDragEnd when a drag ends
+func NewDragHandle(onDragEnd func(dy float32)) *DragHandle {
+	handle := &DragHandle{onDragEnd: onDragEnd}
+	handle.ExtendBaseWidget(handle)
+	return handle
+}
+
+// CreateRenderer shows the grip icon
+func (dh *DragHandle) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(widget.NewIcon(theme.MenuIcon()))
+}
+
+// Dragged accumulates the vertical movement of the drag
+func (dh *DragHandle) Dragged(e *fyne.DragEvent) {
+	dh.dy += e.Dragged.DY
+}
+
+// DragEnd reports the total vertical movement
+func (dh *DragHandle) DragEnd() {
+	dy := dh.dy
+	dh.dy = 0
+	if dh.onDragEnd != nil {
+		dh.onDragEnd(dy)
+	}
+}
+
+// Cursor shows a pointer cursor on desktop
+func (dh *DragHandle) Cursor() desktop.Cursor {
+	return desktop.PointerCursor
+}
@@ -11,41 +11,154 @@ type Localization struct {
 // Text keys for localization
 const (
 	// Actions
-	KeyAppTitle          = "app_title"
-	KeyDownload          = "download"
-	KeyOpen              = "open"
-	KeyCompress          = "compress"
-	KeySettings          = "settings"
-	KeyFile              = "file"
-	KeyLanguage          = "language"
-	KeyDownloadDirectory = "download_directory"
-	KeyMaxParallel       = "max_parallel"
-	KeyQualityPreset     = "quality_preset"
-	KeyFilenameTemplate  = "filename_template"
-	KeySave              = "save"
-	KeyCancel            = "cancel"
-	KeyBrowse            = "browse"
-	KeyEnterURL          = "enter_url"
-	KeySettingsSaved     = "settings_saved"
-	KeyDownloadStarted   = "download_started"
-	KeyDownloadCompleted = "download_completed"
-	KeyErrorStartingTask = "error_starting_task"
-	KeyErrorOpeningFile  = "error_opening_file"
-	KeyErrorCopyingPath  = "error_copying_path"
-	KeyErrorRemovingTask = "error_removing_task"
-	KeyInvalidURL        = "invalid_url"
-	KeyPleaseEnterURL    = "please_enter_url"
-	KeyAlreadyInQueue    = "already_in_queue"
-	KeyTaskAdded         = "task_added"
-	KeyPause             = "pause"
-	KeyContinue          = "continue"
-	KeyPlay              = "play"
+	KeyAppTitle            = "app_title"
+	KeyDownload            = "download"
+	KeyOpen                = "open"
+	KeyCompress            = "compress"
+	KeySettings            = "settings"
+	KeyFile                = "file"
+	KeyLanguage            = "language"
+	KeyDownloadDirectory   = "download_directory"
+	KeyMaxParallel         = "max_parallel"
+	KeyDownloadSegments    = "download_segments"
+	KeyDiskReserve         = "disk_reserve"
+	KeyDiskLow             = "disk_low"
+	KeyCollisionPolicy     = "collision_policy"
+	KeyCollisionVerify     = "collision_verify"
+	KeyCollisionRename     = "collision_rename"
+	KeyCollisionSkip       = "collision_skip"
+	KeyCollisionOverwrite  = "collision_overwrite"
+	KeyVerifyMode          = "verify_mode"
+	KeyVerifyOff           = "verify_off"
+	KeyVerifyMark          = "verify_mark"
+	KeyVerifyRetry         = "verify_retry"
+	KeyTempDir             = "temp_dir"
+	KeyTempDirHint         = "temp_dir_hint"
+	KeyPartialsTitle       = "partials_title"
+	KeyPartialsFound       = "partials_found"
+	KeyResumeDownload      = "resume_download"
+	KeyDeletePartial       = "delete_partial"
+	KeyKeepPartial         = "keep_partial"
+	KeyPartialFailed       = "partial_failed"
+	KeyRemoveDownload      = "remove_download"
+	KeyDeletePartialPrompt = "delete_partial_prompt"
+	KeyClose               = "close"
+	KeyQualityPreset       = "quality_preset"
+	KeyFilenameTemplate    = "filename_template"
+	KeySave                = "save"
+	KeyCancel              = "cancel"
+	KeyBrowse              = "browse"
+	KeyEnterURL            = "enter_url"
+	KeySettingsSaved       = "settings_saved"
+	KeyDownloadStarted     = "download_started"
+	KeyDownloadCompleted   = "download_completed"
+	KeyErrorStartingTask   = "error_starting_task"
+	KeyErrorOpeningFile    = "error_opening_file"
+	KeyErrorCopyingPath    = "error_copying_path"
+	KeyErrorRemovingTask   = "error_removing_task"
+	KeyInvalidURL          = "invalid_url"
+	KeyPleaseEnterURL      = "please_enter_url"
+	KeyAlreadyInQueue      = "already_in_queue"
+	KeyTaskAdded           = "task_added"
+	KeyPause               = "pause"
+	KeyContinue            = "continue"
+	KeyStopRecording       = "stop_recording"
+	KeyNoChapters          = "no_chapters"
+	KeySplitStarted        = "split_started"
+	KeySplitDone           = "split_done"
+	KeySplitFailed         = "split_failed"
+	KeyPlay                = "play"
 
 	// Notification panel
 	KeyParsingStarted = "parsing_started"
 	KeyParsingFailed  = "parsing_failed"
 	KeyPlaylistParsed = "playlist_parsed"
 
+	// Playlist review
+	KeySelectAll          = "select_all"
+	KeySelectNone         = "select_none"
+	KeyApply              = "apply"
+	KeyStartDownload      = "start_download"
+	KeyRangePlaceholder   = "range_placeholder"
+	KeyTitleFilter        = "title_filter"
+	KeyMinDuration        = "min_duration"
+	KeyMaxDuration        = "max_duration"
+	KeySelectedCount      = "selected_count"
+	KeyNothingSelected    = "nothing_selected"
+	KeyInvalidRange       = "invalid_range"
+	KeyInvalidDuration    = "invalid_duration"
+	KeyPlaylistReadyToRun = "playlist_ready_to_run"
+	KeyPlaylistSubfolder  = "playlist_subfolder"
+	KeyPlaylistWriteM3U   = "playlist_write_m3u"
+	KeyPlaylistPaused     = "playlist_paused"
+
+	// Control API
+	KeyAPIEnabled     = "api_enabled"
+	KeyAPIPort        = "api_port"
+	KeyAPIToken       = "api_token"
+	KeyRegenerate     = "regenerate"
+	KeyCopy           = "copy"
+	KeyAPIStartFailed = "api_start_failed"
+
+	// Batch input
+	KeyAddURLs       = "add_urls"
+	KeyBatchHint     = "batch_hint"
+	KeyImportFile    = "import_file"
+	KeyImportFailed  = "import_failed"
+	KeyBatchEmpty    = "batch_empty"
+	KeyBatchSummary  = "batch_summary"
+	KeyBatchRejected = "batch_rejected"
+
+	// Clipboard watcher
+	KeyClipboardWatch    = "clipboard_watch"
+	KeyClipboardDetected = "clipboard_detected"
+	KeyDismiss           = "dismiss"
+
+	// Proxy
+	KeyProxy         = "proxy"
+	KeyProxyNone     = "proxy_none"
+	KeyProxySystem   = "proxy_system"
+	KeyProxyHost     = "proxy_host"
+	KeyProxyPort     = "proxy_port"
+	KeyProxyUsername = "proxy_username"
+	KeyProxyPassword = "proxy_password"
+	KeyProxyRemember = "proxy_remember_password"
+
+	// Cookies
+	KeyCookies             = "cookies"
+	KeyCookiesNone         = "cookies_none"
+	KeyCookiesCount        = "cookies_count"
+	KeyImportCookies       = "import_cookies"
+	KeyCookiesFromFirefox  = "cookies_from_firefox"
+	KeyCookiesFromChromium = "cookies_from_chromium"
+	KeyClearCookies        = "clear_cookies"
+	KeyCookiesFailed       = "cookies_failed"
+
+	// SponsorBlock
+	KeySponsorBlock           = "sponsorblock"
+	KeySponsorBlockOff        = "sponsorblock_off"
+	KeySponsorBlockRemove     = "sponsorblock_remove"
+	KeySponsorBlockChapters   = "sponsorblock_chapters"
+	KeySponsorBlockCategories = "sponsorblock_categories"
+	KeySponsorBlockURL        = "sponsorblock_url"
+	KeySegmentsRemoved        = "segments_removed"
+
+	// Scheduler
+	KeyScheduleTask       = "schedule_task"
+	KeyStartAt            = "start_at"
+	KeyStartAtPlaceholder = "start_at_placeholder"
+	KeyScheduleFailed     = "schedule_failed"
+	KeyDownloadWindow     = "download_window"
+
+	// Queue
+	KeyDownloadNext   = "download_next"
+	KeyMoveToTop      = "move_to_top"
+	KeyMoveToBottom   = "move_to_bottom"
+	KeyPriorityHigh   = "priority_high"
+	KeyPriorityNormal = "priority_normal"
+	KeyPriorityLow    = "priority_low"
+	KeyQueueFailed    = "queue_failed"
+
 	// Tooltips
 	KeyTooltipStartPause = "tooltip_start_pause"
 	KeyTooltipReveal     = "tooltip_reveal"
@@ -114,36 +227,149 @@ func (l *Localization) GetAvailableLanguages() map[string]string {
 func (l *Localization) initializeTexts() {
 	// English texts
 	l.texts["en"] = map[string]string{
-		KeyAppTitle:          "YT Downloader",
-		KeyDownload:          "Download",
-		KeyOpen:              "Open",
-		KeyCompress:          "Compress",
-		KeyFile:              "File",
-		KeyLanguage:          "Language",
-		KeyDownloadDirectory: "Download Directory",
-		KeyMaxParallel:       "Max Parallel Downloads",
-		KeyQualityPreset:     "Quality Preset",
-		KeyFilenameTemplate:  "Filename Template",
-		KeySave:              "Save",
-		KeyCancel:            "Cancel",
-		KeyEnterURL:          "Enter YouTube URL (https://youtube.com/watch?v=...)",
-		KeySettingsSaved:     "Settings saved successfully!",
-		KeyDownloadStarted:   "Download started",
-		KeyDownloadCompleted: "Download completed",
-		KeyErrorStartingTask: "Error starting task",
-		KeyErrorOpeningFile:  "Error opening file",
-		KeyErrorCopyingPath:  "Error copying path",
-		KeyErrorRemovingTask: "Error removing task",
-		KeyInvalidURL:        "Invalid URL",
-		KeyPleaseEnterURL:    "Please enter a URL",
-		KeyAlreadyInQueue:    "Already in queue",
-		KeyTaskAdded:         "Task added to queue",
-		KeyPause:             "Pause",
-		KeyContinue:          "Continue",
-		KeyPlay:              "Play",
-		KeyParsingStarted:    "Starting playlist parsing in background...",
-		KeyParsingFailed:     "Failed to parse playlist",
-		KeyPlaylistParsed:    "Playlist parsed",
+		KeyAppTitle:            "YT Downloader",
+		KeyDownload:            "Download",
+		KeyOpen:                "Open",
+		KeyCompress:            "Compress",
+		KeyFile:                "File",
+		KeyLanguage:            "Language",
+		KeyDownloadDirectory:   "Download Directory",
+		KeyMaxParallel:         "Max Parallel Downloads",
+		KeyDownloadSegments:    "Connections per Download (direct links)",
+		KeyDiskReserve:         "Keep Free Disk Space (MB, 0 = off)",
+		KeyDiskLow:             "Downloads paused: low disk space",
+		KeyCollisionPolicy:     "If the File Exists",
+		KeyCollisionVerify:     "Keep if it matches, otherwise rename",
+		KeyCollisionRename:     "Save under a new name",
+		KeyCollisionSkip:       "Skip the download",
+		KeyCollisionOverwrite:  "Overwrite",
+		KeyVerifyMode:          "Verify downloads",
+		KeyVerifyOff:           "Don't check",
+		KeyVerifyMark:          "Check with ffprobe and mark failures",
+		KeyVerifyRetry:         "Check with ffprobe and download again",
+		KeyTempDir:             "Folder for Unfinished Downloads",
+		KeyTempDirHint:         "Empty: next to the finished files",
+		KeyPartialsTitle:       "Unfinished Downloads",
+		KeyPartialsFound:       "These unfinished downloads have no task. Resume them or delete their data:",
+		KeyResumeDownload:      "Resume",
+		KeyDeletePartial:       "Delete data",
+		KeyKeepPartial:         "Keep",
+		KeyPartialFailed:       "Could not process the unfinished download",
+		KeyRemoveDownload:      "Remove Download",
+		KeyDeletePartialPrompt: "Also delete the data downloaded so far?",
+		KeyClose:               "Close",
+		KeyQualityPreset:       "Quality Preset",
+		KeyFilenameTemplate:    "Filename Template",
+		KeySave:                "Save",
+		KeyCancel:              "Cancel",
+		KeyEnterURL:            "Enter YouTube URL (https://youtube.com/watch?v=...)",
+		KeySettingsSaved:       "Settings saved successfully!",
+		KeyDownloadStarted:     "Download started",
+		KeyDownloadCompleted:   "Download completed",
+		KeyErrorStartingTask:   "Error starting task",
+		KeyErrorOpeningFile:    "Error opening file",
+		KeyErrorCopyingPath:    "Error copying path",
+		KeyErrorRemovingTask:   "Error removing task",
+		KeyInvalidURL:          "Invalid URL",
+		KeyPleaseEnterURL:      "Please enter a URL",
+		KeyAlreadyInQueue:      "Already in queue",
+		KeyTaskAdded:           "Task added to queue",
+		KeyPause:               "Pause",
+		KeyContinue:            "Continue",
+		KeyStopRecording:       "Stop Recording",
+		KeyNoChapters:          "This video has no chapters",
+		KeySplitStarted:        "Splitting by chapters...",
+		KeySplitDone:           "Chapters saved to",
+		KeySplitFailed:         "Splitting by chapters failed",
+		KeyPlay:                "Play",
+		KeyParsingStarted:      "Starting playlist parsing in background...",
+		KeyParsingFailed:       "Failed to parse playlist",
+		KeyPlaylistParsed:      "Playlist parsed",
+
+		// Playlist review
+		KeySelectAll:          "Select all",
+		KeySelectNone:         "Select none",
+		KeyApply:              "Apply",
+		KeyStartDownload:      "Start download",
+		KeyRangePlaceholder:   "Range, e.g. 10-25",
+		KeyTitleFilter:        "Title regex",
+		KeyMinDuration:        "Min duration (mm:ss)",
+		KeyMaxDuration:        "Max duration (mm:ss)",
+		KeySelectedCount:      "%d of %d selected",
+		KeyNothingSelected:    "No videos selected",
+		KeyInvalidRange:       "Invalid range",
+		KeyInvalidDuration:    "Invalid duration",
+		KeyPlaylistReadyToRun: "Choose videos and press Start download",
+		KeyPlaylistSubfolder:  "Own folder with numbered files",
+		KeyPlaylistWriteM3U:   "Create M3U playlist file",
+		KeyPlaylistPaused:     "Paused",
+
+		// Control API
+		KeyAPIEnabled:     "Enable local control API (localhost)",
+		KeyAPIPort:        "API port",
+		KeyAPIToken:       "API token",
+		KeyRegenerate:     "Regenerate",
+		KeyCopy:           "Copy",
+		KeyAPIStartFailed: "Failed to start control API",
+
+		// Batch input
+		KeyAddURLs:       "Add URLs...",
+		KeyBatchHint:     "Paste links, one per line",
+		KeyImportFile:    "Import .txt/.csv...",
+		KeyImportFailed:  "Failed to read file",
+		KeyBatchEmpty:    "No links found",
+		KeyBatchSummary:  "Queued: %d, playlists: %d, duplicates: %d, rejected: %d",
+		KeyBatchRejected: "Rejected lines",
+
+		// Clipboard watcher
+		KeyClipboardWatch:    "Offer to download copied YouTube links",
+		KeyClipboardDetected: "Copied link",
+		KeyDismiss:           "Dismiss",
+
+		// Proxy
+		KeyProxy:         "Proxy",
+		KeyProxyNone:     "Direct connection",
+		KeyProxySystem:   "System settings (environment)",
+		KeyProxyHost:     "Host",
+		KeyProxyPort:     "Port",
+		KeyProxyUsername: "Username (optional)",
+		KeyProxyPassword: "Password",
+		KeyProxyRemember: "Remember the password (stored unencrypted)",
+
+		// Cookies
+		KeyCookies:             "Cookies (age-restricted and members-only videos)",
+		KeyCookiesNone:         "No cookies imported",
+		KeyCookiesCount:        "%d cookies imported",
+		KeyImportCookies:       "Import cookies.txt...",
+		KeyCookiesFromFirefox:  "From Firefox",
+		KeyCookiesFromChromium: "From Chromium",
+		KeyClearCookies:        "Clear",
+		KeyCookiesFailed:       "Cookie import failed",
+
+		// SponsorBlock
+		KeySponsorBlock:           "SponsorBlock (sponsor, intro and outro segments)",
+		KeySponsorBlockOff:        "Off",
+		KeySponsorBlockRemove:     "Cut segments out",
+		KeySponsorBlockChapters:   "Mark segments as chapters",
+		KeySponsorBlockCategories: "Categories",
+		KeySponsorBlockURL:        "Server URL (default: sponsor.ajay.app)",
+		KeySegmentsRemoved:        "✂ −%s",
+
+		// Scheduler
+		KeyScheduleTask:       "Schedule download",
+		KeyStartAt:            "Start at",
+		KeyStartAtPlaceholder: "HH:MM, empty to start now",
+		KeyScheduleFailed:     "Scheduling failed",
+		KeyDownloadWindow:     "Only start downloads between (HH:MM)",
+
+		// Queue
+		KeyDownloadNext:   "Download next",
+		KeyMoveToTop:      "Move to top",
+		KeyMoveToBottom:   "Move to bottom",
+		KeyPriorityHigh:   "High priority",
+		KeyPriorityNormal: "Normal priority",
+		KeyPriorityLow:    "Low priority",
+		KeyQueueFailed:    "Could not change the queue",
 
 		// Tooltips
 		KeyTooltipStartPause: "Start / Pause",
@@ -155,37 +381,150 @@ func (l *Localization) initializeTexts() {
 
 	// Russian texts
 	l.texts["ru"] = map[string]string{
-		KeyAppTitle:          "YT Загрузчик",
-		KeyDownload:          "Скачать",
-		KeyOpen:              "Открыть",
-		KeyCompress:          "Сжать",
-		KeySettings:          "Настройки",
-		KeyFile:              "Файл",
-		KeyLanguage:          "Язык",
-		KeyDownloadDirectory: "Папка загрузки",
-		KeyMaxParallel:       "Макс. параллельных",
-		KeyQualityPreset:     "Предустановка качества",
-		KeyFilenameTemplate:  "Шаблон имени файла",
-		KeySave:              "Сохранить",
-		KeyCancel:            "Отмена",
-		KeyEnterURL:          "Введите URL YouTube (https://youtube.com/watch?v=...)",
-		KeySettingsSaved:     "Настройки успешно сохранены!",
-		KeyDownloadStarted:   "Загрузка начата",
-		KeyDownloadCompleted: "Загрузка завершена",
-		KeyErrorStartingTask: "Ошибка запуска задачи",
-		KeyErrorOpeningFile:  "Ошибка открытия файла",
-		KeyErrorCopyingPath:  "Ошибка копирования пути",
-		KeyErrorRemovingTask: "Ошибка удаления задачи",
-		KeyInvalidURL:        "Неверный URL",
-		KeyPleaseEnterURL:    "Пожалуйста, введите URL",
-		KeyAlreadyInQueue:    "Уже в очереди",
-		KeyTaskAdded:         "Задача добавлена в очередь",
-		KeyPause:             "Пауза",
-		KeyContinue:          "Продолжить",
-		KeyPlay:              "Воспроизвести",
-		KeyParsingStarted:    "Запуск парсинга плейлиста в фоне...",
-		KeyParsingFailed:     "Не удалось распарсить плейлист",
-		KeyPlaylistParsed:    "Плейлист распарсен",
+		KeyAppTitle:            "YT Загрузчик",
+		KeyDownload:            "Скачать",
+		KeyOpen:                "Открыть",
+		KeyCompress:            "Сжать",
+		KeySettings:            "Настройки",
+		KeyFile:                "Файл",
+		KeyLanguage:            "Язык",
+		KeyDownloadDirectory:   "Папка загрузки",
+		KeyMaxParallel:         "Макс. параллельных",
+		KeyDownloadSegments:    "Соединений на загрузку (прямые ссылки)",
+		KeyDiskReserve:         "Оставлять свободного места (МБ, 0 = выкл.)",
+		KeyDiskLow:             "Загрузки приостановлены: мало места на диске",
+		KeyCollisionPolicy:     "Если файл уже есть",
+		KeyCollisionVerify:     "Оставить, если совпадает, иначе переименовать",
+		KeyCollisionRename:     "Сохранить под новым именем",
+		KeyCollisionSkip:       "Пропустить загрузку",
+		KeyCollisionOverwrite:  "Перезаписать",
+		KeyVerifyMode:          "Проверка загрузок",
+		KeyVerifyOff:           "Не проверять",
+		KeyVerifyMark:          "Проверять через ffprobe и отмечать ошибки",
+		KeyVerifyRetry:         "Проверять через ffprobe и скачивать заново",
+		KeyTempDir:             "Папка для незавершённых загрузок",
+		KeyTempDirHint:         "Пусто: рядом с готовыми файлами",
+		KeyPartialsTitle:       "Незавершённые загрузки",
+		KeyPartialsFound:       "У этих незавершённых загрузок нет задачи. Продолжите их или удалите данные:",
+		KeyResumeDownload:      "Продолжить",
+		KeyDeletePartial:       "Удалить данные",
+		KeyKeepPartial:         "Оставить",
+		KeyPartialFailed:       "Не удалось обработать незавершённую загрузку",
+		KeyRemoveDownload:      "Удаление загрузки",
+		KeyDeletePartialPrompt: "Удалить и уже скачанные данные?",
+		KeyClose:               "Закрыть",
+		KeyQualityPreset:       "Предустановка качества",
+		KeyFilenameTemplate:    "Шаблон имени файла",
+		KeySave:                "Сохранить",
+		KeyCancel:              "Отмена",
+		KeyEnterURL:            "Введите URL YouTube (https://youtube.com/watch?v=...)",
+		KeySettingsSaved:       "Настройки успешно сохранены!",
+		KeyDownloadStarted:     "Загрузка начата",
+		KeyDownloadCompleted:   "Загрузка завершена",
+		KeyErrorStartingTask:   "Ошибка запуска задачи",
+		KeyErrorOpeningFile:    "Ошибка открытия файла",
+		KeyErrorCopyingPath:    "Ошибка копирования пути",
+		KeyErrorRemovingTask:   "Ошибка удаления задачи",
+		KeyInvalidURL:          "Неверный URL",
+		KeyPleaseEnterURL:      "Пожалуйста, введите URL",
+		KeyAlreadyInQueue:      "Уже в очереди",
+		KeyTaskAdded:           "Задача добавлена в очередь",
+		KeyPause:               "Пауза",
+		KeyContinue:            "Продолжить",
+		KeyStopRecording:       "Остановить запись",
+		KeyNoChapters:          "У этого видео нет глав",
+		KeySplitStarted:        "Разделение по главам...",
+		KeySplitDone:           "Главы сохранены в",
+		KeySplitFailed:         "Не удалось разделить по главам",
+		KeyPlay:                "Воспроизвести",
+		KeyParsingStarted:      "Запуск парсинга плейлиста в фоне...",
+		KeyParsingFailed:       "Не удалось распарсить плейлист",
+		KeyPlaylistParsed:      "Плейлист распарсен",
+
+		// Playlist review
+		KeySelectAll:          "Выбрать все",
+		KeySelectNone:         "Снять выбор",
+		KeyApply:              "Применить",
+		KeyStartDownload:      "Начать загрузку",
+		KeyRangePlaceholder:   "Диапазон, напр. 10-25",
+		KeyTitleFilter:        "Регулярное выражение для названия",
+		KeyMinDuration:        "Мин. длительность (мм:сс)",
+		KeyMaxDuration:        "Макс. длительность (мм:сс)",
+		KeySelectedCount:      "Выбрано %d из %d",
+		KeyNothingSelected:    "Не выбрано ни одного видео",
+		KeyInvalidRange:       "Неверный диапазон",
+		KeyInvalidDuration:    "Неверная длительность",
+		KeyPlaylistReadyToRun: "Выберите видео и нажмите «Начать загрузку»",
+		KeyPlaylistSubfolder:  "Отдельная папка с нумерацией файлов",
+		KeyPlaylistWriteM3U:   "Создать файл плейлиста M3U",
+		KeyPlaylistPaused:     "Приостановлено",
+
+		// Control API
+		KeyAPIEnabled:     "Включить локальный API управления (localhost)",
+		KeyAPIPort:        "Порт API",
+		KeyAPIToken:       "Токен API",
+		KeyRegenerate:     "Сгенерировать заново",
+		KeyCopy:           "Копировать",
+		KeyAPIStartFailed: "Не удалось запустить API управления",
+
+		// Batch input
+		KeyAddURLs:       "Добавить ссылки...",
+		KeyBatchHint:     "Вставьте ссылки, по одной в строке",
+		KeyImportFile:    "Импорт .txt/.csv...",
+		KeyImportFailed:  "Не удалось прочитать файл",
+		KeyBatchEmpty:    "Ссылки не найдены",
+		KeyBatchSummary:  "В очереди: %d, плейлистов: %d, повторов: %d, отклонено: %d",
+		KeyBatchRejected: "Отклонённые строки",
+
+		// Clipboard watcher
+		KeyClipboardWatch:    "Предлагать скачать скопированные ссылки YouTube",
+		KeyClipboardDetected: "Скопирована ссылка",
+		KeyDismiss:           "Скрыть",
+
+		// Proxy
+		KeyProxy:         "Прокси",
+		KeyProxyNone:     "Прямое подключение",
+		KeyProxySystem:   "Системные настройки (окружение)",
+		KeyProxyHost:     "Хост",
+		KeyProxyPort:     "Порт",
+		KeyProxyUsername: "Пользователь (необязательно)",
+		KeyProxyPassword: "Пароль",
+		KeyProxyRemember: "Запомнить пароль (хранится в открытом виде)",
+
+		// Cookies
+		KeyCookies:             "Cookies (видео 18+ и для спонсоров)",
+		KeyCookiesNone:         "Cookies не импортированы",
+		KeyCookiesCount:        "Импортировано cookies: %d",
+		KeyImportCookies:       "Импорт cookies.txt...",
+		KeyCookiesFromFirefox:  "Из Firefox",
+		KeyCookiesFromChromium: "Из Chromium",
+		KeyClearCookies:        "Очистить",
+		KeyCookiesFailed:       "Не удалось импортировать cookies",
+
+		// SponsorBlock
+		KeySponsorBlock:           "SponsorBlock (реклама, заставки и концовки)",
+		KeySponsorBlockOff:        "Выключено",
+		KeySponsorBlockRemove:     "Вырезать фрагменты",
+		KeySponsorBlockChapters:   "Отмечать фрагменты главами",
+		KeySponsorBlockCategories: "Категории",
+		KeySponsorBlockURL:        "Адрес сервера (по умолчанию sponsor.ajay.app)",
+		KeySegmentsRemoved:        "✂ −%s",
+
+		// Scheduler
+		KeyScheduleTask:       "Запланировать загрузку",
+		KeyStartAt:            "Начать в",
+		KeyStartAtPlaceholder: "ЧЧ:ММ, пусто — начать сразу",
+		KeyScheduleFailed:     "Не удалось запланировать",
+		KeyDownloadWindow:     "Начинать загрузки только с … до (ЧЧ:ММ)",
+
+		// Queue
+		KeyDownloadNext:   "Скачать следующим",
+		KeyMoveToTop:      "В начало очереди",
+		KeyMoveToBottom:   "В конец очереди",
+		KeyPriorityHigh:   "Высокий приоритет",
+		KeyPriorityNormal: "Обычный приоритет",
+		KeyPriorityLow:    "Низкий приоритет",
+		KeyQueueFailed:    "Не удалось изменить очередь",
 
 		// Tooltips
 		KeyTooltipStartPause: "Старт / Пауза",
@@ -197,37 +536,150 @@ func (l *Localization) initializeTexts() {
 
 	// Portuguese texts
 	l.texts["pt"] = map[string]string{
-		KeyAppTitle:          "YT Downloader",
-		KeyDownload:          "Baixar",
-		KeyOpen:              "Abrir",
-		KeyCompress:          "Comprimir",
-		KeySettings:          "Configurações",
-		KeyFile:              "Arquivo",
-		KeyLanguage:          "Idioma",
-		KeyDownloadDirectory: "Diretório de Download",
-		KeyMaxParallel:       "Max Downloads Paralelos",
-		KeyQualityPreset:     "Predefinição de Qualidade",
-		KeyFilenameTemplate:  "Modelo de Nome de Arquivo",
-		KeySave:              "Salvar",
-		KeyCancel:            "Cancelar",
-		KeyEnterURL:          "Digite URL do YouTube (https://youtube.com/watch?v=...)",
-		KeySettingsSaved:     "Configurações salvas com sucesso!",
-		KeyDownloadStarted:   "Download iniciado",
-		KeyDownloadCompleted: "Download concluído",
-		KeyErrorStartingTask: "Erro ao iniciar tarefa",
-		KeyErrorOpeningFile:  "Erro ao abrir arquivo",
-		KeyErrorCopyingPath:  "Erro ao copiar caminho",
-		KeyErrorRemovingTask: "Erro ao remover tarefa",
-		KeyInvalidURL:        "URL inválida",
-		KeyPleaseEnterURL:    "Por favor, digite uma URL",
-		KeyAlreadyInQueue:    "Já na fila",
-		KeyTaskAdded:         "Tarefa adicionada à fila",
-		KeyPause:             "Pausar",
-		KeyContinue:          "Continuar",
-		KeyPlay:              "Reproduzir",
-		KeyParsingStarted:    "Iniciando análise da playlist em segundo plano...",
-		KeyParsingFailed:     "Falha ao analisar a playlist",
-		KeyPlaylistParsed:    "Playlist analisada",
+		KeyAppTitle:            "YT Downloader",
+		KeyDownload:            "Baixar",
+		KeyOpen:                "Abrir",
+		KeyCompress:            "Comprimir",
+		KeySettings:            "Configurações",
+		KeyFile:                "Arquivo",
+		KeyLanguage:            "Idioma",
+		KeyDownloadDirectory:   "Diretório de Download",
+		KeyMaxParallel:         "Max Downloads Paralelos",
+		KeyDownloadSegments:    "Conexões por Download (links diretos)",
+		KeyDiskReserve:         "Manter Espaço Livre (MB, 0 = desligado)",
+		KeyDiskLow:             "Downloads pausados: pouco espaço em disco",
+		KeyCollisionPolicy:     "Se o Arquivo Existir",
+		KeyCollisionVerify:     "Manter se corresponder, senão renomear",
+		KeyCollisionRename:     "Salvar com novo nome",
+		KeyCollisionSkip:       "Pular o download",
+		KeyCollisionOverwrite:  "Sobrescrever",
+		KeyVerifyMode:          "Verificar downloads",
+		KeyVerifyOff:           "Não verificar",
+		KeyVerifyMark:          "Verificar com ffprobe e marcar falhas",
+		KeyVerifyRetry:         "Verificar com ffprobe e baixar novamente",
+		KeyTempDir:             "Pasta para Downloads Incompletos",
+		KeyTempDirHint:         "Vazio: junto aos arquivos concluídos",
+		KeyPartialsTitle:       "Downloads Incompletos",
+		KeyPartialsFound:       "Estes downloads incompletos não têm tarefa. Retome-os ou apague os dados:",
+		KeyResumeDownload:      "Retomar",
+		KeyDeletePartial:       "Apagar dados",
+		KeyKeepPartial:         "Manter",
+		KeyPartialFailed:       "Não foi possível processar o download incompleto",
+		KeyRemoveDownload:      "Remover Download",
+		KeyDeletePartialPrompt: "Apagar também os dados já baixados?",
+		KeyClose:               "Fechar",
+		KeyQualityPreset:       "Predefinição de Qualidade",
+		KeyFilenameTemplate:    "Modelo de Nome de Arquivo",
+		KeySave:                "Salvar",
+		KeyCancel:              "Cancelar",
+		KeyEnterURL:            "Digite URL do YouTube (https://youtube.com/watch?v=...)",
+		KeySettingsSaved:       "Configurações salvas com sucesso!",
+		KeyDownloadStarted:     "Download iniciado",
+		KeyDownloadCompleted:   "Download concluído",
+		KeyErrorStartingTask:   "Erro ao iniciar tarefa",
+		KeyErrorOpeningFile:    "Erro ao abrir arquivo",
+		KeyErrorCopyingPath:    "Erro ao copiar caminho",
+		KeyErrorRemovingTask:   "Erro ao remover tarefa",
+		KeyInvalidURL:          "URL inválida",
+		KeyPleaseEnterURL:      "Por favor, digite uma URL",
+		KeyAlreadyInQueue:      "Já na fila",
+		KeyTaskAdded:           "Tarefa adicionada à fila",
+		KeyPause:               "Pausar",
+		KeyContinue:            "Continuar",
+		KeyStopRecording:       "Parar Gravação",
+		KeyNoChapters:          "Este vídeo não tem capítulos",
+		KeySplitStarted:        "Dividindo por capítulos...",
+		KeySplitDone:           "Capítulos salvos em",
+		KeySplitFailed:         "Falha ao dividir por capítulos",
+		KeyPlay:                "Reproduzir",
+		KeyParsingStarted:      "Iniciando análise da playlist em segundo plano...",
+		KeyParsingFailed:       "Falha ao analisar a playlist",
+		KeyPlaylistParsed:      "Playlist analisada",
+
+		// Playlist review
+		KeySelectAll:          "Selecionar tudo",
+		KeySelectNone:         "Limpar seleção",
+		KeyApply:              "Aplicar",
+		KeyStartDownload:      "Iniciar download",
+		KeyRangePlaceholder:   "Intervalo, ex. 10-25",
+		KeyTitleFilter:        "Regex do título",
+		KeyMinDuration:        "Duração mín. (mm:ss)",
+		KeyMaxDuration:        "Duração máx. (mm:ss)",
+		KeySelectedCount:      "%d de %d selecionados",
+		KeyNothingSelected:    "Nenhum vídeo selecionado",
+		KeyInvalidRange:       "Intervalo inválido",
+		KeyInvalidDuration:    "Duração inválida",
+		KeyPlaylistReadyToRun: "Escolha os vídeos e pressione Iniciar download",
+		KeyPlaylistSubfolder:  "Pasta própria com arquivos numerados",
+		KeyPlaylistWriteM3U:   "Criar arquivo de playlist M3U",
+		KeyPlaylistPaused:     "Pausado",
+
+		// Control API
+		KeyAPIEnabled:     "Ativar API de controle local (localhost)",
+		KeyAPIPort:        "Porta da API",
+		KeyAPIToken:       "Token da API",
+		KeyRegenerate:     "Gerar novamente",
+		KeyCopy:           "Copiar",
+		KeyAPIStartFailed: "Falha ao iniciar a API de controle",
+
+		// Batch input
+		KeyAddURLs:       "Adicionar links...",
+		KeyBatchHint:     "Cole os links, um por linha",
+		KeyImportFile:    "Importar .txt/.csv...",
+		KeyImportFailed:  "Falha ao ler o arquivo",
+		KeyBatchEmpty:    "Nenhum link encontrado",
+		KeyBatchSummary:  "Na fila: %d, playlists: %d, duplicados: %d, rejeitados: %d",
+		KeyBatchRejected: "Linhas rejeitadas",
+
+		// Clipboard watcher
+		KeyClipboardWatch:    "Oferecer download de links do YouTube copiados",
+		KeyClipboardDetected: "Link copiado",
+		KeyDismiss:           "Ignorar",
+
+		// Proxy
+		KeyProxy:         "Proxy",
+		KeyProxyNone:     "Conexão direta",
+		KeyProxySystem:   "Configurações do sistema (ambiente)",
+		KeyProxyHost:     "Host",
+		KeyProxyPort:     "Porta",
+		KeyProxyUsername: "Usuário (opcional)",
+		KeyProxyPassword: "Senha",
+		KeyProxyRemember: "Lembrar a senha (armazenada sem criptografia)",
+
+		// Cookies
+		KeyCookies:             "Cookies (vídeos com restrição de idade e para membros)",
+		KeyCookiesNone:         "Nenhum cookie importado",
+		KeyCookiesCount:        "%d cookies importados",
+		KeyImportCookies:       "Importar cookies.txt...",
+		KeyCookiesFromFirefox:  "Do Firefox",
+		KeyCookiesFromChromium: "Do Chromium",
+		KeyClearCookies:        "Limpar",
+		KeyCookiesFailed:       "Falha ao importar cookies",
+
+		// SponsorBlock
+		KeySponsorBlock:           "SponsorBlock (patrocínios, aberturas e encerramentos)",
+		KeySponsorBlockOff:        "Desativado",
+		KeySponsorBlockRemove:     "Cortar segmentos",
+		KeySponsorBlockChapters:   "Marcar segmentos como capítulos",
+		KeySponsorBlockCategories: "Categorias",
+		KeySponsorBlockURL:        "URL do servidor (padrão: sponsor.ajay.app)",
+		KeySegmentsRemoved:        "✂ −%s",
+
+		// Scheduler
+		KeyScheduleTask:       "Agendar download",
+		KeyStartAt:            "Iniciar às",
+		KeyStartAtPlaceholder: "HH:MM, vazio para iniciar agora",
+		KeyScheduleFailed:     "Falha ao agendar",
+		KeyDownloadWindow:     "Iniciar downloads somente entre (HH:MM)",
+
+		// Queue
+		KeyDownloadNext:   "Baixar a seguir",
+		KeyMoveToTop:      "Mover para o início",
+		KeyMoveToBottom:   "Mover para o fim",
+		KeyPriorityHigh:   "Prioridade alta",
+		KeyPriorityNormal: "Prioridade normal",
+		KeyPriorityLow:    "Prioridade baixa",
+		KeyQueueFailed:    "Não foi possível alterar a fila",
 
 		// Tooltips
 		KeyTooltipStartPause: "Iniciar / Pausar",
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/ytget/yt-downloader/internal/download"
+	"github.com/ytget/yt-downloader/internal/platform"
+)
+
+// Partials dialog constants
+const (
+	PartialsDialogWidth  = 600
+	PartialsDialogHeight = 360
+)
+
+// ShowPartialsDialog lists unfinished downloads that no task owns, each with a
+// button to resume it and one to delete it. A row is disabled once handled;
+// partial files without a known source can only be deleted.
+func ShowPartialsDialog(window fyne.Window, localization *Localization, partials []download.PartialFile, onResume, onDelete func(path string) error) {
+	rows := container.NewVBox(widget.NewLabel(localization.GetText(KeyPartialsFound)))
+	for _, partial := range partials {
+		name := partial.Title
+		if name == "" {
+			name = filepath.Base(partial.OutputPath)
+		}
+		label := widget.NewLabel(fmt.Sprintf("%s (%s)", name, platform.FormatBytes(partial.Size)))
+		label.Truncation = fyne.TextTruncateEllipsis
+
+		var resumeBtn, deleteBtn *widget.Button
+		handle := func(action func(path string) error) {
+			if err := action(partial.Path); err != nil {
+				dialog.ShowError(fmt.Errorf("%s: %w", localization.GetText(KeyPartialFailed), err), window)
+				return
+			}
+			resumeBtn.Disable()
+			deleteBtn.Disable()
+			label.Importance = widget.LowImportance
+			label.Refresh()
+		}
+		resumeBtn = widget.NewButton(localization.GetText(KeyResumeDownload), func() { handle(onResume) })
+		deleteBtn = widget.NewButton(localization.GetText(KeyDeletePartial), func() { handle(onDelete) })
+		if partial.URL == "" {
+			resumeBtn.Disable()
+		}
+		rows.Add(container.NewBorder(nil, nil, nil, container.NewHBox(resumeBtn, deleteBtn), label))
+	}
+
+	dlg := dialog.NewCustom(localization.GetText(KeyPartialsTitle), localization.GetText(KeyClose), container.NewVScroll(rows), window)
+	dlg.Resize(fyne.NewSize(PartialsDialogWidth, PartialsDialogHeight))
+	dlg.Show()
+}
@@ -1,7 +1,12 @@
 package ui
 
 import (
+	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -24,8 +29,27 @@ type PlaylistGroup struct {
 	container *fyne.Container
 	list      *widget.List
 
+	// Review panel shown while a parsed playlist waits for the user to start it
+	reviewPanel      *fyne.Container
+	selectionLabel   *widget.Label
+	rangeEntry       *widget.Entry
+	titleFilterEntry *widget.Entry
+	minDurationEntry *widget.Entry
+	maxDurationEntry *widget.Entry
+	subfolderCheck   *widget.Check
+	m3uCheck         *widget.Check
+	startAtEntry     *widget.Entry // optional "HH:MM" start time of the reviewed playlist
+
+	// Control panel shown while the selected playlist is downloading or paused
+	controlPanel   *fyne.Container
+	controlLabel   *widget.Label
+	pauseResumeBtn *widget.Button
+	cancelBtn      *widget.Button
+
 	// Callbacks
 	onDownloadPlaylist func(*model.Playlist)
+	onPausePlaylist    func(*model.Playlist)
+	onResumePlaylist   func(*model.Playlist)
 	onCancelPlaylist   func(*model.Playlist)
 
 	// TaskRow callbacks
@@ -34,6 +58,10 @@ type PlaylistGroup struct {
 	onOpen       func(filePath string)
 	onCopyPath   func(filePath string)
 	onRemove     func(taskID string)
+	onSplit      func(taskID string)
+	onSchedule   func(taskID string)
+	onQueue      func(taskID string, action QueueAction)
+	onMove       func(taskID, targetID string, up bool)
 }
 
 // NewPlaylistGroup creates a new playlist group UI component
@@ -69,9 +97,15 @@ func (pg *PlaylistGroup) createUI() {
 	// Wrap videos list in scroll container for long playlists
 	scrollContainer := container.NewScroll(pg.list)
 
-	// Main container - videos list with scroll only
+	pg.createReviewPanel()
+	pg.createControlPanel()
+
+	// Review and control panels share the top area; at most one is visible
+	topPanels := container.NewVBox(pg.reviewPanel, pg.controlPanel)
+
+	// Main container - playlist panels on top, videos list with scroll below
 	pg.container = container.NewBorder(
-		nil,             // no top header
+		topPanels,       // top - selection or pause/cancel controls
 		nil,             // no bottom buttons
 		nil,             // left
 		nil,             // right
@@ -79,6 +113,256 @@ func (pg *PlaylistGroup) createUI() {
 	)
 }
 
+// createReviewPanel creates the selection controls for a playlist in Ready state
+func (pg *PlaylistGroup) createReviewPanel() {
+	pg.selectionLabel = widget.NewLabel("")
+	pg.selectionLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	selectAllBtn := widget.NewButton(pg.localization.GetText(KeySelectAll), func() {
+		pg.applySelection(func(p *model.Playlist) error {
+			p.SelectAll(true)
+			return nil
+		})
+	})
+	selectNoneBtn := widget.NewButton(pg.localization.GetText(KeySelectNone), func() {
+		pg.applySelection(func(p *model.Playlist) error {
+			p.SelectAll(false)
+			return nil
+		})
+	})
+
+	pg.rangeEntry = widget.NewEntry()
+	pg.rangeEntry.SetPlaceHolder(pg.localization.GetText(KeyRangePlaceholder))
+	rangeBtn := widget.NewButton(pg.localization.GetText(KeyApply), pg.onApplyRange)
+
+	pg.titleFilterEntry = widget.NewEntry()
+	pg.titleFilterEntry.SetPlaceHolder(pg.localization.GetText(KeyTitleFilter))
+	titleBtn := widget.NewButton(pg.localization.GetText(KeyApply), func() {
+		pattern := strings.TrimSpace(pg.titleFilterEntry.Text)
+		if pattern == "" {
+			return
+		}
+		pg.applySelection(func(p *model.Playlist) error {
+			return p.FilterByTitle(pattern)
+		})
+	})
+
+	pg.minDurationEntry = widget.NewEntry()
+	pg.minDurationEntry.SetPlaceHolder(pg.localization.GetText(KeyMinDuration))
+	pg.maxDurationEntry = widget.NewEntry()
+	pg.maxDurationEntry.SetPlaceHolder(pg.localization.GetText(KeyMaxDuration))
+	durationBtn := widget.NewButton(pg.localization.GetText(KeyApply), pg.onApplyDuration)
+
+	// Output layout for this playlist; defaults come from settings
+	pg.subfolderCheck = widget.NewCheck(pg.localization.GetText(KeyPlaylistSubfolder), func(checked bool) {
+		if pg.isReviewing() {
+			pg.selectedPlaylist.UseSubfolder = checked
+		}
+	})
+	pg.m3uCheck = widget.NewCheck(pg.localization.GetText(KeyPlaylistWriteM3U), func(checked bool) {
+		if pg.isReviewing() {
+			pg.selectedPlaylist.WriteM3U = checked
+		}
+	})
+
+	// Optional start time for the whole playlist
+	pg.startAtEntry = widget.NewEntry()
+	pg.startAtEntry.SetPlaceHolder(pg.localization.GetText(KeyStartAt) + ": " + pg.localization.GetText(KeyStartAtPlaceholder))
+	pg.startAtEntry.Validator = validateStartAt
+
+	startBtn := widget.NewButton(pg.localization.GetText(KeyStartDownload), pg.onStartReviewedPlaylist)
+	startBtn.Importance = widget.HighImportance
+
+	pg.reviewPanel = container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(selectAllBtn, selectNoneBtn, startBtn), pg.selectionLabel),
+		container.NewGridWithColumns(3,
+			container.NewBorder(nil, nil, nil, rangeBtn, pg.rangeEntry),
+			container.NewBorder(nil, nil, nil, titleBtn, pg.titleFilterEntry),
+			container.NewBorder(nil, nil, nil, durationBtn, container.NewGridWithColumns(2, pg.minDurationEntry, pg.maxDurationEntry)),
+		),
+		container.NewBorder(nil, nil, container.NewHBox(pg.subfolderCheck, pg.m3uCheck), nil, pg.startAtEntry),
+		widget.NewSeparator(),
+	)
+	pg.reviewPanel.Hide()
+}
+
+// createControlPanel creates pause/resume and cancel controls for a running playlist
+func (pg *PlaylistGroup) createControlPanel() {
+	pg.controlLabel = widget.NewLabel("")
+	pg.controlLabel.TextStyle = fyne.TextStyle{Bold: true}
+
+	pg.pauseResumeBtn = widget.NewButton(pg.localization.GetText(KeyPause), pg.onPauseResumePlaylist)
+	pg.cancelBtn = widget.NewButton(pg.localization.GetText(KeyCancel), pg.onCancelSelectedPlaylist)
+	pg.cancelBtn.Importance = widget.DangerImportance
+
+	pg.controlPanel = container.NewVBox(
+		container.NewBorder(nil, nil, nil, container.NewHBox(pg.pauseResumeBtn, pg.cancelBtn), pg.controlLabel),
+		widget.NewSeparator(),
+	)
+	pg.controlPanel.Hide()
+}
+
+// onPauseResumePlaylist pauses a downloading playlist or resumes a paused one
+func (pg *PlaylistGroup) onPauseResumePlaylist() {
+	playlist := pg.selectedPlaylist
+	if playlist == nil {
+		return
+	}
+	switch playlist.Status {
+	case model.PlaylistStatusDownloading:
+		if pg.onPausePlaylist != nil {
+			pg.onPausePlaylist(playlist)
+		}
+	case model.PlaylistStatusPaused:
+		if pg.onResumePlaylist != nil {
+			pg.onResumePlaylist(playlist)
+		}
+	}
+	pg.refreshVideosDisplay()
+}
+
+// onCancelSelectedPlaylist cancels the selected playlist and all of its downloads
+func (pg *PlaylistGroup) onCancelSelectedPlaylist() {
+	playlist := pg.selectedPlaylist
+	if playlist == nil || !playlist.IsActive() {
+		return
+	}
+	if pg.onCancelPlaylist != nil {
+		pg.onCancelPlaylist(playlist)
+	}
+	pg.refreshVideosDisplay()
+}
+
+// updateControlPanel shows the control panel for active playlists and updates its labels
+func (pg *PlaylistGroup) updateControlPanel() {
+	playlist := pg.selectedPlaylist
+	if playlist == nil || !playlist.IsActive() {
+		pg.controlPanel.Hide()
+		return
+	}
+
+	summary := fmt.Sprintf("%s · %d/%d", playlist.Title, len(playlist.GetCompletedVideos()), playlist.TotalVideos)
+	if playlist.Status == model.PlaylistStatusPaused {
+		summary += " · " + pg.localization.GetText(KeyPlaylistPaused)
+		pg.pauseResumeBtn.SetText(pg.localization.GetText(KeyContinue))
+	} else {
+		pg.pauseResumeBtn.SetText(pg.localization.GetText(KeyPause))
+	}
+	pg.controlLabel.SetText(summary)
+	pg.controlPanel.Show()
+}
+
+// isReviewing reports whether the selected playlist is waiting for the user to start it
+func (pg *PlaylistGroup) isReviewing() bool {
+	return pg.selectedPlaylist != nil && pg.selectedPlaylist.Status == model.PlaylistStatusReady
+}
+
+// applySelection runs a selection change on the reviewed playlist and refreshes the view
+func (pg *PlaylistGroup) applySelection(change func(*model.Playlist) error) {
+	if !pg.isReviewing() {
+		return
+	}
+	if err := change(pg.selectedPlaylist); err != nil {
+		widget.ShowPopUp(widget.NewLabel(err.Error()), pg.window.Canvas())
+		return
+	}
+	pg.refreshVideosDisplay()
+}
+
+// onApplyRange selects only videos within the entered range ("10-25" or a single "7")
+func (pg *PlaylistGroup) onApplyRange() {
+	from, to, err := parseIndexRange(pg.rangeEntry.Text)
+	if err != nil {
+		widget.ShowPopUp(widget.NewLabel(pg.localization.GetText(KeyInvalidRange)+": "+err.Error()), pg.window.Canvas())
+		return
+	}
+	pg.applySelection(func(p *model.Playlist) error {
+		return p.SelectRange(from, to)
+	})
+}
+
+// onApplyDuration deselects videos outside the entered duration bounds
+func (pg *PlaylistGroup) onApplyDuration() {
+	minDuration, okMin := parseOptionalDuration(pg.minDurationEntry.Text)
+	maxDuration, okMax := parseOptionalDuration(pg.maxDurationEntry.Text)
+	if !okMin || !okMax {
+		widget.ShowPopUp(widget.NewLabel(pg.localization.GetText(KeyInvalidDuration)), pg.window.Canvas())
+		return
+	}
+	pg.applySelection(func(p *model.Playlist) error {
+		p.FilterByDuration(minDuration, maxDuration)
+		return nil
+	})
+}
+
+// onStartReviewedPlaylist hands the reviewed playlist over to the download callback
+func (pg *PlaylistGroup) onStartReviewedPlaylist() {
+	if !pg.isReviewing() {
+		return
+	}
+	playlist := pg.selectedPlaylist
+	if playlist.GetSelectedCount() == 0 {
+		widget.ShowPopUp(widget.NewLabel(pg.localization.GetText(KeyNothingSelected)), pg.window.Canvas())
+		return
+	}
+	startAt, err := parseStartAt(pg.startAtEntry.Text, time.Now())
+	if err != nil {
+		widget.ShowPopUp(widget.NewLabel(pg.localization.GetText(KeyScheduleFailed)+": "+err.Error()), pg.window.Canvas())
+		return
+	}
+	playlist.StartAt = startAt
+	if pg.onDownloadPlaylist == nil {
+		log.Printf("Download requested for playlist %s but no callback is set", playlist.ID)
+		return
+	}
+	pg.onDownloadPlaylist(playlist)
+
+	// Playlist left Ready state: hide review controls and checkboxes
+	pg.refreshVideosDisplay()
+}
+
+// updateReviewPanel shows or hides the review panel and updates the selection summary
+func (pg *PlaylistGroup) updateReviewPanel() {
+	if !pg.isReviewing() {
+		pg.reviewPanel.Hide()
+		return
+	}
+	pg.selectionLabel.SetText(fmt.Sprintf("%s · "+pg.localization.GetText(KeySelectedCount),
+		pg.selectedPlaylist.Title, pg.selectedPlaylist.GetSelectedCount(), pg.selectedPlaylist.TotalVideos))
+	pg.subfolderCheck.SetChecked(pg.selectedPlaylist.UseSubfolder)
+	pg.m3uCheck.SetChecked(pg.selectedPlaylist.WriteM3U)
+	pg.reviewPanel.Show()
+}
+
+// parseIndexRange parses "10-25" or "7" into a 1-based inclusive range
+func parseIndexRange(text string) (int, int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, 0, fmt.Errorf("empty range")
+	}
+	fromText, toText, found := strings.Cut(text, "-")
+	from, err := strconv.Atoi(strings.TrimSpace(fromText))
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return from, from, nil
+	}
+	to, err := strconv.Atoi(strings.TrimSpace(toText))
+	if err != nil {
+		return 0, 0, err
+	}
+	return from, to, nil
+}
+
+// parseOptionalDuration parses a duration bound; an empty value means "no bound"
+func parseOptionalDuration(text string) (time.Duration, bool) {
+	if strings.TrimSpace(text) == "" {
+		return 0, true
+	}
+	return model.ParseDuration(text)
+}
+
 // createVideoRow creates a template video row widget
 func (pg *PlaylistGroup) createVideoRow() fyne.CanvasObject {
 	// Create a minimal task for the template - this will be replaced with real data
@@ -133,9 +417,69 @@ func (pg *PlaylistGroup) createVideoRow() fyne.CanvasObject {
 				log.Printf("Remove requested for playlist video: %s", taskID)
 			}
 		},
+		func(taskID string) {
+			// Handle split by chapters for playlist videos
+			if pg.onSplit != nil {
+				pg.onSplit(taskID)
+			} else {
+				log.Printf("Split requested for playlist video: %s", taskID)
+			}
+		},
+		func(taskID string) {
+			// Handle start time for playlist videos
+			if pg.onSchedule != nil {
+				pg.onSchedule(taskID)
+			} else {
+				log.Printf("Schedule requested for playlist video: %s", taskID)
+			}
+		},
+		func(taskID string, action QueueAction) {
+			// Handle queue changes for playlist videos
+			if pg.onQueue != nil {
+				pg.onQueue(taskID, action)
+			} else {
+				log.Printf("Queue change requested for playlist video: %s", taskID)
+			}
+		},
+		func(taskID string, rows int) {
+			// Only individual videos are reordered; playlist videos keep the playlist order
+			targetID, ok := dragTarget(pg.individualVideos, taskID, rows)
+			if !ok {
+				return
+			}
+			if pg.onMove != nil {
+				pg.onMove(taskID, targetID, rows < 0)
+			} else {
+				log.Printf("Move requested for video: %s", taskID)
+			}
+		},
 	)
 
-	return taskRow
+	return newVideoRow(taskRow)
+}
+
+// videoRow pairs a TaskRow with the selection checkbox used while reviewing a playlist
+type videoRow struct {
+	widget.BaseWidget
+
+	check   *widget.Check
+	taskRow *TaskRow
+}
+
+// newVideoRow wraps a TaskRow with a hidden selection checkbox
+func newVideoRow(taskRow *TaskRow) *videoRow {
+	row := &videoRow{
+		check:   widget.NewCheck("", nil),
+		taskRow: taskRow,
+	}
+	row.check.Hide()
+	row.ExtendBaseWidget(row)
+	return row
+}
+
+// CreateRenderer places the checkbox to the left of the task row
+func (r *videoRow) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewBorder(nil, nil, r.check, nil, r.taskRow))
 }
 
 // convertVideoStatusToTaskStatus converts VideoStatus to TaskStatus
@@ -166,11 +510,12 @@ func (pg *PlaylistGroup) updateVideoRow(id widget.ListItemID, obj fyne.CanvasObj
 	}
 
 	videoItem := pg.allVideos[id]
-	taskRow, ok := obj.(*TaskRow)
+	row, ok := obj.(*videoRow)
 	if !ok {
-		log.Printf("Warning: expected TaskRow but got %T", obj)
+		log.Printf("Warning: expected videoRow but got %T", obj)
 		return
 	}
+	taskRow := row.taskRow
 
 	log.Printf("Updating video row %d with item: %T, ID: %v", id, videoItem, videoItem)
 
@@ -195,9 +540,28 @@ func (pg *PlaylistGroup) updateVideoRow(id widget.ListItemID, obj fyne.CanvasObj
 		log.Printf("Updating TaskRow for PlaylistVideo %s: Status=%s, OutputPath=%s, FileSize=%d",
 			video.ID, video.Status, video.OutputPath, video.FileSize)
 
+		reviewing := pg.isReviewing()
+		videoID := video.ID
+
 		// Update in UI thread to avoid Fyne call thread errors
 		fyne.Do(func() {
+			taskRow.SetReorderable(false)
 			taskRow.UpdateTask(task)
+
+			// Rebind the checkbox: list rows are recycled between items
+			row.check.OnChanged = nil
+			if reviewing {
+				row.check.SetChecked(video.IsSelected())
+				row.check.OnChanged = func(selected bool) {
+					pg.applySelection(func(p *model.Playlist) error {
+						p.SetVideoSelected(videoID, selected)
+						return nil
+					})
+				}
+				row.check.Show()
+			} else {
+				row.check.Hide()
+			}
 		})
 	} else if task, ok := videoItem.(*model.DownloadTask); ok {
 		// Individual video task - update directly in UI thread
@@ -205,7 +569,10 @@ func (pg *PlaylistGroup) updateVideoRow(id widget.ListItemID, obj fyne.CanvasObj
 			task.ID, task.Status, task.OutputPath, task.FileSize)
 
 		fyne.Do(func() {
+			taskRow.SetReorderable(true)
 			taskRow.UpdateTask(task)
+			row.check.OnChanged = nil
+			row.check.Hide()
 		})
 	} else {
 		log.Printf("Warning: unknown video item type: %T", videoItem)
@@ -217,12 +584,18 @@ func (pg *PlaylistGroup) Container() *fyne.Container {
 	return pg.container
 }
 
-// SetCallbacks sets the callback functions for playlist actions (kept for compatibility)
+// SetCallbacks sets the callback functions for playlist actions.
+// onDownloadPlaylist is invoked when the user starts a reviewed playlist;
+// the others are driven by the control panel of a running playlist.
 func (pg *PlaylistGroup) SetCallbacks(
 	onDownloadPlaylist func(*model.Playlist),
+	onPausePlaylist func(*model.Playlist),
+	onResumePlaylist func(*model.Playlist),
 	onCancelPlaylist func(*model.Playlist),
 ) {
 	pg.onDownloadPlaylist = onDownloadPlaylist
+	pg.onPausePlaylist = onPausePlaylist
+	pg.onResumePlaylist = onResumePlaylist
 	pg.onCancelPlaylist = onCancelPlaylist
 }
 
@@ -233,12 +606,20 @@ func (pg *PlaylistGroup) SetTaskRowCallbacks(
 	onOpen func(filePath string),
 	onCopyPath func(filePath string),
 	onRemove func(taskID string),
+	onSplit func(taskID string),
+	onSchedule func(taskID string),
+	onQueue func(taskID string, action QueueAction),
+	onMove func(taskID, targetID string, up bool),
 ) {
 	pg.onStartPause = onStartPause
 	pg.onReveal = onReveal
 	pg.onOpen = onOpen
 	pg.onCopyPath = onCopyPath
 	pg.onRemove = onRemove
+	pg.onSplit = onSplit
+	pg.onSchedule = onSchedule
+	pg.onQueue = onQueue
+	pg.onMove = onMove
 }
 
 // UpdatePlaylistProgress updates the progress display for a playlist
@@ -303,7 +684,10 @@ func (pg *PlaylistGroup) refreshVideosDisplay() {
 		}
 	}
 
-	// Add individual videos if available
+	// Add individual videos if available, in queue order
+	sort.SliceStable(pg.individualVideos, func(i, j int) bool {
+		return pg.individualVideos[i].QueueSeq < pg.individualVideos[j].QueueSeq
+	})
 	log.Printf("Adding %d individual videos to display", len(pg.individualVideos))
 	for _, task := range pg.individualVideos {
 		log.Printf("Adding individual video: ID=%s, Status=%s, OutputPath=%s", task.ID, task.Status, task.OutputPath)
@@ -312,281 +696,41 @@ func (pg *PlaylistGroup) refreshVideosDisplay() {
 
 	log.Printf("Total videos in display: %d", len(pg.allVideos))
 
+	pg.updateReviewPanel()
+	pg.updateControlPanel()
+
 	// Refresh the list to update UI
 	pg.list.Refresh()
 }
 
-// UpdateVideoStatus updates the status of a specific video
-func (pg *PlaylistGroup) UpdateVideoStatus(videoID string, status interface{}) {
-	updated := false
-
-	// Update playlist videos
-	if pg.selectedPlaylist != nil {
-		for _, video := range pg.selectedPlaylist.Videos {
-			if video.ID == videoID {
-				if newStatus, ok := status.(model.VideoStatus); ok {
-					if video.Status != newStatus {
-						video.Status = newStatus
-						updated = true
-					}
-				}
-				break
-			}
-		}
-	}
-
-	// Update individual videos
-	for _, task := range pg.individualVideos {
-		if task.ID == videoID {
-			if newStatus, ok := status.(model.TaskStatus); ok {
-				if task.Status != newStatus {
-					task.Status = newStatus
-					updated = true
-				}
-			}
-			break
-		}
-	}
-
-	// Only refresh UI if something actually changed
-	if updated {
-		fyne.Do(func() {
-			pg.list.Refresh()
-		})
-	}
-}
-
-// UpdateVideoProgress updates the progress of a specific video
-func (pg *PlaylistGroup) UpdateVideoProgress(videoID string, progress float64) {
-	log.Printf("PlaylistGroup UpdateVideoProgress: id=%s progress=%.2f", videoID, progress)
-	updated := false
-
-	// Update playlist videos
-	if pg.selectedPlaylist != nil {
-		for _, video := range pg.selectedPlaylist.Videos {
-			if video.ID == videoID && video.Progress != progress {
-				video.Progress = progress
-				// Keep UI responsive by refreshing when value changes
-				updated = true
-				log.Printf("PlaylistGroup playlist video updated: id=%s progress=%.2f", videoID, progress)
-				break
-			}
-		}
-	}
-
-	// Update individual videos
-	for _, task := range pg.individualVideos {
-		if task.ID == videoID {
-			// Even если значение уже совпадает (тот же указатель), нам нужна перерисовка строки
-			task.Progress = progress
-			task.Percent = int(progress * 100)
-			updated = true
-			log.Printf("PlaylistGroup individual task updated: id=%s progress=%.2f percent=%d", videoID, progress, task.Percent)
-			break
-		}
-	}
-
-	// Only refresh UI if something actually changed
-	if updated {
-		fyne.Do(func() {
-			log.Printf("PlaylistGroup list refreshed after progress update")
-			pg.list.Refresh()
-		})
-	}
-}
-
-// UpdateVideoSpeed updates the speed and ETA for a specific video by internal ID
-func (pg *PlaylistGroup) UpdateVideoSpeed(videoID string, speed string, etaSec int) {
-	updated := false
-
-	// Update playlist videos
-	if pg.selectedPlaylist != nil {
-		for _, video := range pg.selectedPlaylist.Videos {
-			if video.ID == videoID {
-				if video.Speed != speed || video.ETASec != etaSec {
-					video.Speed = speed
-					video.ETASec = etaSec
-					updated = true
-				}
-				break
-			}
-		}
-	}
-
-	// Update individual videos
-	for _, task := range pg.individualVideos {
-		if task.ID == videoID {
-			if task.Speed != speed || task.ETASec != etaSec {
-				task.Speed = speed
-				task.ETASec = etaSec
-				updated = true
-			}
-			break
-		}
-	}
-
-	if updated {
-		fyne.Do(func() { pg.list.Refresh() })
-	}
-}
-
-// UpdateVideoSpeedByURL updates speed and ETA using video URL mapping
-func (pg *PlaylistGroup) UpdateVideoSpeedByURL(videoURL string, speed string, etaSec int) {
-	updated := false
-
-	if pg.selectedPlaylist != nil {
-		for _, video := range pg.selectedPlaylist.Videos {
-			if video.URL == videoURL {
-				if video.Speed != speed || video.ETASec != etaSec {
-					video.Speed = speed
-					video.ETASec = etaSec
-					updated = true
-				}
-				break
-			}
+// UpdateIndividualVideo replaces an individual video with a fresh task snapshot
+func (pg *PlaylistGroup) UpdateIndividualVideo(task *model.DownloadTask) {
+	for i, existing := range pg.individualVideos {
+		if existing.ID == task.ID {
+			pg.individualVideos[i] = task
+			fyne.Do(func() {
+				pg.refreshVideosDisplay()
+			})
+			return
 		}
 	}
-
-	if updated {
-		fyne.Do(func() { pg.list.Refresh() })
-	}
 }
 
-// UpdateVideoOutputPath updates the output path of a specific video
-func (pg *PlaylistGroup) UpdateVideoOutputPath(videoID string, outputPath string, fileSize int64) {
-	updated := false
-
-	// Update playlist videos
-	if pg.selectedPlaylist != nil {
-		for _, video := range pg.selectedPlaylist.Videos {
-			if video.ID == videoID {
-				if video.OutputPath != outputPath || video.FileSize != fileSize {
-					video.OutputPath = outputPath
-					video.FileSize = fileSize
-					updated = true
-				}
-				break
-			}
-		}
-	}
-
-	// Update individual videos
-	for _, task := range pg.individualVideos {
-		if task.ID == videoID {
-			if task.OutputPath != outputPath || task.FileSize != fileSize {
-				task.OutputPath = outputPath
-				task.FileSize = fileSize
-				updated = true
-			}
+// UpdatePlaylist replaces a playlist with a fresh snapshot from the download service
+func (pg *PlaylistGroup) UpdatePlaylist(snapshot *model.Playlist) {
+	found := false
+	for i, playlist := range pg.playlists {
+		if playlist.ID == snapshot.ID {
+			pg.playlists[i] = snapshot
+			found = true
 			break
 		}
 	}
-
-	// Only refresh UI if something actually changed
-	if updated {
-		fyne.Do(func() {
-			// Refresh the list to update UI
-			pg.list.Refresh()
-
-			// Also refresh the specific video row to update buttons
-			pg.refreshVideosDisplay()
-		})
-	}
-}
-
-// UpdateVideoProgressByURL updates progress of a playlist video identified by its URL
-func (pg *PlaylistGroup) UpdateVideoProgressByURL(videoURL string, progress float64) {
-	updated := false
-
-	if pg.selectedPlaylist != nil {
-		for _, video := range pg.selectedPlaylist.Videos {
-			if video.URL == videoURL && video.Progress != progress {
-				video.Progress = progress
-				updated = true
-				break
-			}
-		}
-	}
-
-	if updated {
-		fyne.Do(func() { pg.list.Refresh() })
-	}
-}
-
-// UpdateVideoStatusByURL updates status of a playlist video identified by its URL
-func (pg *PlaylistGroup) UpdateVideoStatusByURL(videoURL string, status interface{}) {
-	updated := false
-
-	if pg.selectedPlaylist != nil {
-		for _, video := range pg.selectedPlaylist.Videos {
-			if video.URL == videoURL {
-				if newStatus, ok := status.(model.VideoStatus); ok {
-					if video.Status != newStatus {
-						video.Status = newStatus
-						updated = true
-					}
-				} else if newTaskStatus, ok := status.(model.TaskStatus); ok {
-					switch newTaskStatus {
-					case model.TaskStatusDownloading, model.TaskStatusStarting:
-						if video.Status != model.VideoStatusDownloading {
-							video.Status = model.VideoStatusDownloading
-							updated = true
-						}
-					case model.TaskStatusPaused:
-						if video.Status != model.VideoStatusPaused {
-							video.Status = model.VideoStatusPaused
-							updated = true
-						}
-					case model.TaskStatusCompleted:
-						if video.Status != model.VideoStatusCompleted {
-							video.Status = model.VideoStatusCompleted
-							updated = true
-						}
-					case model.TaskStatusError:
-						if video.Status != model.VideoStatusError {
-							video.Status = model.VideoStatusError
-							updated = true
-						}
-					case model.TaskStatusStopped:
-						if video.Status != model.VideoStatusSkipped {
-							video.Status = model.VideoStatusSkipped
-							updated = true
-						}
-					default:
-						// keep as is
-					}
-				}
-				break
-			}
-		}
-	}
-
-	if updated {
-		fyne.Do(func() { pg.list.Refresh() })
-	}
-}
-
-// UpdateVideoOutputPathByURL updates output path of a playlist video identified by its URL
-func (pg *PlaylistGroup) UpdateVideoOutputPathByURL(videoURL string, outputPath string, fileSize int64) {
-	updated := false
-
-	if pg.selectedPlaylist != nil {
-		for _, video := range pg.selectedPlaylist.Videos {
-			if video.URL == videoURL {
-				if video.OutputPath != outputPath || video.FileSize != fileSize {
-					video.OutputPath = outputPath
-					video.FileSize = fileSize
-					updated = true
-				}
-				break
-			}
-		}
+	if !found {
+		return
 	}
-
-	if updated {
-		fyne.Do(func() {
-			pg.list.Refresh()
-			pg.refreshVideosDisplay()
-		})
+	if pg.selectedPlaylist != nil && pg.selectedPlaylist.ID == snapshot.ID {
+		pg.selectedPlaylist = snapshot
+		pg.refreshVideosDisplay()
 	}
 }
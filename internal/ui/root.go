@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,19 +15,22 @@ import (
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/ytget/yt-downloader/internal/api"
 	"github.com/ytget/yt-downloader/internal/compress"
 	"github.com/ytget/yt-downloader/internal/config"
 	"github.com/ytget/yt-downloader/internal/download"
 	"github.com/ytget/yt-downloader/internal/model"
 	"github.com/ytget/yt-downloader/internal/platform"
+	"github.com/ytget/yt-downloader/internal/schedule"
+	"github.com/ytget/yt-downloader/internal/ytlink"
 )
 
 // UI constants
 const (
-	RootPlaylistQueryParam = "list="
-	RootUIUpdateDebounce   = 100 * time.Millisecond
+	RootUIUpdateDebounce = 100 * time.Millisecond
 )
 
 // Toast notification constants
@@ -100,6 +105,12 @@ type RootUI struct {
 	notificationLabel     *widget.Label
 	notificationSpinner   *widget.ProgressBarInfinite
 
+	// Clipboard watcher prompt shown in the notification panel
+	clipboardWatcher     *ClipboardWatcher
+	clipboardURL         string
+	clipboardDownloadBtn *widget.Button
+	clipboardDismissBtn  *widget.Button
+
 	// Mobile UI enhancements
 	mobileUI *MobileUI
 
@@ -111,6 +122,9 @@ type RootUI struct {
 
 	// Dialog for no app found scenario
 	noAppDialog *widget.PopUp
+
+	// Optional local control API, nil while disabled
+	apiServer *api.Server
 }
 
 // NewRootUI creates and initializes the main UI
@@ -144,13 +158,76 @@ func NewRootUI(window fyne.Window, app fyne.App, downloadSvc download.Downloader
 	version := time.Now().Format("2006-01-02 15:04:05")
 	window.SetTitle(fmt.Sprintf("%s v%s", localization.GetText(KeyAppTitle), version))
 
-	// Set up callback for download updates
-	ui.downloadSvc.SetUpdateCallback(ui.onTaskUpdate)
+	if ui.compressSvc != nil {
+		ui.compressSvc.SetUpdateCallback(ui.onCompressionUpdate)
+	}
+
+	// Subscribe to download events; tasks and playlists arrive as snapshots.
+	// Unsubscribing on close ends consumeDownloadEvents.
+	events, unsubscribe := ui.downloadSvc.Subscribe()
+	window.SetOnClosed(unsubscribe)
+	go ui.consumeDownloadEvents(events)
 
 	ui.setupUI()
+	ui.applyAPISettings()
+	ui.applyClipboardSettings()
+	if cookies, err := platform.LoadCookies(); err != nil {
+		log.Printf("Failed to load cookies: %v", err)
+	} else {
+		ui.applyCookies(cookies)
+	}
+	ui.restoreQueue()
+	ui.checkOrphanedPartials()
 	return ui
 }
 
+// checkOrphanedPartials offers to resume or delete unfinished downloads left on
+// disk without a task, e.g. by removed tasks or a crash
+func (ui *RootUI) checkOrphanedPartials() {
+	partials := ui.downloadSvc.OrphanedPartials()
+	if len(partials) == 0 {
+		return
+	}
+	log.Printf("Found %d orphaned partial downloads", len(partials))
+	ShowPartialsDialog(ui.window, ui.localization, partials, ui.resumePartial, ui.downloadSvc.DeletePartial)
+}
+
+// resumePartial queues the download an orphaned partial file belongs to
+func (ui *RootUI) resumePartial(path string) error {
+	task, err := ui.downloadSvc.ResumePartial(path)
+	if err != nil {
+		return err
+	}
+	_ = ui.tasks.Append(task)
+	ui.playlistGroup.AddIndividualVideo(task)
+	ui.updateFilteredTasks()
+	ui.taskList.Refresh()
+	return nil
+}
+
+// restoreQueue queues the downloads left unfinished by the previous session.
+// Settings are applied first so restored tasks use the configured directory.
+func (ui *RootUI) restoreQueue() {
+	path, err := platform.QueuePath()
+	if err != nil {
+		log.Printf("Failed to locate download queue: %v", err)
+		return
+	}
+	ui.readAndApplySettings()
+	restored, err := ui.downloadSvc.LoadQueue(path)
+	if err != nil {
+		log.Printf("Failed to restore download queue: %v", err)
+	}
+	for _, task := range restored {
+		_ = ui.tasks.Append(task)
+		ui.playlistGroup.AddIndividualVideo(task)
+	}
+	if len(restored) > 0 {
+		ui.updateFilteredTasks()
+		ui.taskList.Refresh()
+	}
+}
+
 // setupUI creates and arranges all UI components
 func (ui *RootUI) setupUI() {
 	// Create menu
@@ -158,7 +235,7 @@ func (ui *RootUI) setupUI() {
 
 	// Create URL entry with mobile optimizations
 	ui.urlEntry = ui.mobileUI.CreateMobileEntry(ui.localization.GetText(KeyEnterURL))
-	ui.urlEntry.Validator = ui.validateURL
+	ui.urlEntry.Validator = ui.validateURLInput
 	// Trigger download when user presses Enter in the URL field
 	ui.urlEntry.OnSubmitted = func(string) {
 		ui.onDownloadClick()
@@ -228,7 +305,13 @@ func (ui *RootUI) setupUI() {
 	ui.notificationLabel.Alignment = fyne.TextAlignLeading
 	ui.notificationSpinner = widget.NewProgressBarInfinite()
 	ui.notificationSpinner.Hide()
-	ui.notificationContainer = container.NewHBox(ui.notificationSpinner, container.NewPadded(ui.notificationLabel))
+	ui.clipboardDownloadBtn = widget.NewButton(ui.localization.GetText(KeyDownload), ui.onClipboardDownload)
+	ui.clipboardDownloadBtn.Importance = widget.HighImportance
+	ui.clipboardDownloadBtn.Hide()
+	ui.clipboardDismissBtn = widget.NewButton(ui.localization.GetText(KeyDismiss), ui.onClipboardDismiss)
+	ui.clipboardDismissBtn.Hide()
+	ui.notificationContainer = container.NewHBox(ui.notificationSpinner, container.NewPadded(ui.notificationLabel),
+		ui.clipboardDownloadBtn, ui.clipboardDismissBtn)
 	ui.notificationContainer.Hide()
 
 	// Combine URL row and notification panel at the top
@@ -269,6 +352,8 @@ func (ui *RootUI) setupUI() {
 	// Set playlist callbacks
 	ui.playlistGroup.SetCallbacks(
 		ui.onPlaylistDownload,
+		ui.onPlaylistPause,
+		ui.onPlaylistResume,
 		ui.onPlaylistCancel,
 	)
 
@@ -279,6 +364,10 @@ func (ui *RootUI) setupUI() {
 		ui.onOpenFile,
 		ui.onCopyPath,
 		ui.onRemoveTask,
+		ui.onSplitChapters,
+		ui.onScheduleTask,
+		ui.onQueueAction,
+		ui.moveTaskTo,
 	)
 
 	// Create main layout with simple list for mobile
@@ -320,6 +409,11 @@ func (ui *RootUI) createMenu() {
 	// Settings menu item
 	settingsItem := fyne.NewMenuItem(ui.localization.GetText(KeySettings), ui.onShowSettings)
 
+	// Batch input menu item
+	addURLsItem := fyne.NewMenuItem(ui.localization.GetText(KeyAddURLs), func() {
+		ui.onShowBatchDialog("")
+	})
+
 	// Language submenu
 	languageMenu := fyne.NewMenu(ui.localization.GetText(KeyLanguage))
 
@@ -340,7 +434,7 @@ func (ui *RootUI) createMenu() {
 
 	// Create main menu
 	mainMenu := fyne.NewMainMenu(
-		fyne.NewMenu(ui.localization.GetText(KeyFile), settingsItem),
+		fyne.NewMenu(ui.localization.GetText(KeyFile), addURLsItem, settingsItem),
 		languageMenu,
 	)
 
@@ -401,11 +495,19 @@ func (ui *RootUI) validateURL(input string) error {
 	return nil
 }
 
+// validateURLInput validates the URL entry, which may hold several pasted links
+func (ui *RootUI) validateURLInput(input string) error {
+	urls, _ := platform.ParseURLList(input)
+	for _, u := range urls {
+		if err := ui.validateURL(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // onDownloadClick handles the download button click
 func (ui *RootUI) onDownloadClick() {
-	// Read settings before processing download
-	ui.readAndApplySettings()
-
 	urlText := strings.TrimSpace(ui.urlEntry.Text)
 	if urlText == "" {
 		// Also reflect in notification panel
@@ -414,6 +516,14 @@ func (ui *RootUI) onDownloadClick() {
 		return
 	}
 
+	// Several pasted links are queued as a batch
+	if urls, duplicates := platform.ParseURLList(urlText); len(urls)+duplicates > 1 {
+		ui.urlEntry.SetText("")
+		ui.enqueueBatch(urlText)
+		return
+	}
+
+	urlText = sanitizeURL(urlText)
 	if err := ui.validateURL(urlText); err != nil {
 		// Also reflect in notification panel
 		ui.showNotification(ui.localization.GetText(KeyInvalidURL)+": "+err.Error(), false)
@@ -421,33 +531,76 @@ func (ui *RootUI) onDownloadClick() {
 		return
 	}
 
-	// Clean URL from any special characters that might cause display issues
-	cleanURL := strings.ReplaceAll(urlText, "\n", "")
-	cleanURL = strings.ReplaceAll(cleanURL, "\r", "")
-	cleanURL = strings.ReplaceAll(cleanURL, "\t", " ")
-	cleanURL = strings.TrimSpace(cleanURL)
+	ui.readAndApplySettings()
+	isPlaylist, err := ui.queueURL(urlText)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			widget.ShowPopUp(widget.NewLabel(ui.localization.GetText(KeyAlreadyInQueue)), ui.window.Canvas())
+		} else {
+			widget.ShowPopUp(widget.NewLabel("Error: "+err.Error()), ui.window.Canvas())
+		}
+		return
+	}
+	if isPlaylist {
+		// The entry is cleared once the playlist is parsed
+		return
+	}
+
+	ui.urlEntry.SetText("")
+
+	widget.ShowPopUp(widget.NewLabel(ui.localization.GetText(KeyDownloadStarted)), ui.window.Canvas())
+}
+
+// enqueueURL queues a video URL or starts parsing a playlist URL and reports which one it was.
+// It is used by the download button and by external sources such as the control API,
+// and must run on the UI thread.
+func (ui *RootUI) enqueueURL(rawURL string) (bool, error) {
+	cleanURL := sanitizeURL(rawURL)
+	if err := ui.validateURL(cleanURL); err != nil {
+		return false, err
+	}
+
+	// Read settings before processing download
+	ui.readAndApplySettings()
+	return ui.queueURL(cleanURL)
+}
 
+// sanitizeURL removes special characters that might cause display issues
+func sanitizeURL(rawURL string) string {
+	clean := strings.ReplaceAll(rawURL, "\n", "")
+	clean = strings.ReplaceAll(clean, "\r", "")
+	clean = strings.ReplaceAll(clean, "\t", " ")
+	return strings.TrimSpace(clean)
+}
+
+// queueURL is enqueueURL for a cleaned and validated URL with the settings
+// already applied, so that a batch reads them once
+func (ui *RootUI) queueURL(cleanURL string) (bool, error) {
 	log.Printf("Processing URL: %s", cleanURL)
 
 	// Check if this is a playlist URL
 	if ui.isPlaylistURL(cleanURL) {
 		log.Printf("Detected playlist URL, processing as playlist")
 		ui.handlePlaylistURL(cleanURL)
-		return
+		return true, nil
 	}
 
-	// Regular video download
-	log.Printf("Adding download task for video URL: %s", cleanURL)
+	// Regular video download, routed to the extractor of its site. YouTube links are
+	// stored in canonical form so that shorts, youtu.be and mobile links to the same
+	// video are recognised as one
+	site, err := ui.downloadSvc.SiteForURL(cleanURL)
+	if err != nil {
+		return false, err
+	}
+	if link, err := ytlink.Parse(cleanURL); site == download.SiteYouTube && err == nil && link.IsVideo() {
+		cleanURL = link.CanonicalURL()
+	}
+	log.Printf("Adding %s download task for URL: %s", site, cleanURL)
 
 	// Add task to download service
 	task, err := ui.downloadSvc.AddTask(cleanURL)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			widget.ShowPopUp(widget.NewLabel(ui.localization.GetText(KeyAlreadyInQueue)), ui.window.Canvas())
-		} else {
-			widget.ShowPopUp(widget.NewLabel("Error: "+err.Error()), ui.window.Canvas())
-		}
-		return
+		return false, err
 	}
 
 	log.Printf("Task added successfully: ID=%s, Status=%s, OutputPath=%s",
@@ -466,9 +619,72 @@ func (ui *RootUI) onDownloadClick() {
 	// Single refresh of the entire UI to ensure proper display
 	ui.window.Canvas().Refresh(ui.window.Content())
 
-	ui.urlEntry.SetText("")
+	return false, nil
+}
 
-	widget.ShowPopUp(widget.NewLabel(ui.localization.GetText(KeyDownloadStarted)), ui.window.Canvas())
+// onShowBatchDialog opens the dialog for pasting or importing many URLs
+func (ui *RootUI) onShowBatchDialog(initialText string) {
+	ShowBatchDialog(ui.window, ui.localization, initialText, ui.enqueueBatch)
+}
+
+// enqueueBatch queues every URL found in text, skipping duplicates, and shows a
+// summary of accepted and rejected lines. It must run on the UI thread.
+func (ui *RootUI) enqueueBatch(text string) {
+	urls, duplicates := platform.ParseURLList(text)
+	if len(urls) == 0 {
+		ui.showNotification(ui.localization.GetText(KeyBatchEmpty), false)
+		return
+	}
+
+	ui.readAndApplySettings()
+	var queued, playlists int
+	var rejected []string
+	for _, u := range urls {
+		if err := ui.validateURL(u); err != nil {
+			rejected = append(rejected, fmt.Sprintf("%s: %v", u, err))
+			continue
+		}
+		isPlaylist, err := ui.queueURL(u)
+		switch {
+		case err != nil && strings.Contains(err.Error(), "already exists"):
+			duplicates++
+		case err != nil:
+			rejected = append(rejected, fmt.Sprintf("%s: %v", u, err))
+		case isPlaylist:
+			playlists++
+		default:
+			queued++
+		}
+	}
+	log.Printf("Batch processed: queued=%d, playlists=%d, duplicates=%d, rejected=%d",
+		queued, playlists, duplicates, len(rejected))
+
+	summary := fmt.Sprintf(ui.localization.GetText(KeyBatchSummary), queued, playlists, duplicates, len(rejected))
+	ui.showNotification(summary, false)
+
+	// Playlist parsing replaces the notification, so the summary is also shown in a dialog
+	message := summary
+	if len(rejected) > 0 {
+		shown := rejected
+		if len(shown) > MaxRejectedShown {
+			shown = shown[:MaxRejectedShown]
+		}
+		message += "\n\n" + ui.localization.GetText(KeyBatchRejected) + ":\n" + strings.Join(shown, "\n")
+		if len(rejected) > len(shown) {
+			message += fmt.Sprintf("\n... (+%d)", len(rejected)-len(shown))
+		}
+	}
+	dialog.ShowInformation(ui.localization.GetText(KeyAddURLs), message, ui.window)
+}
+
+// EnqueueExternalURL queues a URL received from outside the window, e.g. the control API
+// or a second launch of the app. It is safe to call from any goroutine.
+func (ui *RootUI) EnqueueExternalURL(rawURL string) error {
+	var err error
+	fyne.DoAndWait(func() {
+		_, err = ui.enqueueURL(rawURL)
+	})
+	return err
 }
 
 // showNotification displays a message in the notification panel under the URL input.
@@ -478,6 +694,7 @@ func (ui *RootUI) showNotification(message string, spinning bool) {
 		return
 	}
 	fyne.Do(func() {
+		ui.hideClipboardPrompt()
 		ui.notificationLabel.SetText(message)
 		if spinning {
 			ui.notificationSpinner.Show()
@@ -497,6 +714,7 @@ func (ui *RootUI) hideNotification() {
 		return
 	}
 	fyne.Do(func() {
+		ui.hideClipboardPrompt()
 		ui.notificationSpinner.Hide()
 		ui.notificationContainer.Hide()
 	})
@@ -505,9 +723,132 @@ func (ui *RootUI) hideNotification() {
 // onShowSettings shows the settings dialog
 func (ui *RootUI) onShowSettings() {
 	ShowSettingsDialog(ui.window, ui.settings, ui.localization, func() {
-		// Settings changed callback - could restart download service if needed
+		// Settings changed callback - restart the control API with new port or token
+		ui.applyAPISettings()
+		ui.applyClipboardSettings()
 		widget.ShowPopUp(widget.NewLabel("Settings saved"), ui.window.Canvas())
-	})
+	}, ui.applyCookies)
+}
+
+// applyAPISettings starts, restarts or stops the local control API according to settings
+func (ui *RootUI) applyAPISettings() {
+	if ui.apiServer != nil {
+		if err := ui.apiServer.Stop(); err != nil {
+			log.Printf("Failed to stop API server: %v", err)
+		}
+		ui.apiServer = nil
+	}
+
+	if !ui.settings.GetAPIEnabled() {
+		return
+	}
+
+	server := api.NewServer(ui.downloadSvc, ui.settings.GetAPIToken())
+	server.SetURLHandler(ui.EnqueueExternalURL)
+	if err := server.Start(ui.settings.GetAPIPort()); err != nil {
+		log.Printf("Failed to start API server: %v", err)
+		ui.showNotification(ui.localization.GetText(KeyAPIStartFailed)+": "+err.Error(), false)
+		return
+	}
+	ui.apiServer = server
+}
+
+// applyClipboardSettings starts or stops the clipboard watcher according to settings
+func (ui *RootUI) applyClipboardSettings() {
+	if !ui.settings.GetClipboardWatch() {
+		if ui.clipboardWatcher != nil {
+			ui.clipboardWatcher.Stop()
+			ui.clipboardWatcher = nil
+		}
+		ui.hideClipboardPrompt()
+		return
+	}
+	if ui.clipboardWatcher == nil {
+		ui.clipboardWatcher = NewClipboardWatcher(fyne.CurrentApp().Clipboard(), ui.onClipboardChanged)
+		ui.clipboardWatcher.Start()
+	}
+}
+
+// onClipboardChanged offers a copied YouTube video or playlist link for download
+func (ui *RootUI) onClipboardChanged(text string) {
+	urls, _ := platform.ParseURLList(text)
+	if len(urls) != 1 {
+		return
+	}
+	candidate := urls[0]
+	if ui.validateURL(candidate) != nil {
+		return
+	}
+	if link, err := ytlink.Parse(candidate); err != nil || (!link.IsVideo() && !link.IsPlaylist()) {
+		return
+	}
+	if ui.settings.IsClipboardURLDismissed(candidate) || ui.isURLQueued(candidate) {
+		return
+	}
+
+	log.Printf("Offering copied URL: %s", candidate)
+	ui.clipboardURL = candidate
+	ui.notificationLabel.SetText(fmt.Sprintf("%s: %s", ui.localization.GetText(KeyClipboardDetected), candidate))
+	ui.notificationSpinner.Hide()
+	ui.clipboardDownloadBtn.SetText(ui.localization.GetText(KeyDownload))
+	ui.clipboardDismissBtn.SetText(ui.localization.GetText(KeyDismiss))
+	ui.clipboardDownloadBtn.Show()
+	ui.clipboardDismissBtn.Show()
+	ui.notificationContainer.Show()
+	ui.notificationContainer.Refresh()
+}
+
+// isURLQueued reports whether a task for the URL already exists
+func (ui *RootUI) isURLQueued(rawURL string) bool {
+	if videoID := ytlink.VideoID(rawURL); videoID != "" {
+		if _, ok := ui.downloadSvc.GetTaskByVideoID(videoID); ok {
+			return true
+		}
+	}
+	for _, task := range ui.downloadSvc.GetAllTasks() {
+		if task.URL == rawURL {
+			return true
+		}
+	}
+	return false
+}
+
+// onClipboardDownload queues the offered URL
+func (ui *RootUI) onClipboardDownload() {
+	rawURL := ui.clipboardURL
+	ui.hideClipboardPrompt()
+	ui.notificationContainer.Hide()
+	if rawURL == "" {
+		return
+	}
+
+	isPlaylist, err := ui.enqueueURL(rawURL)
+	switch {
+	case err != nil:
+		ui.showNotification(ui.localization.GetText(KeyInvalidURL)+": "+err.Error(), false)
+	case !isPlaylist:
+		ui.showNotification(ui.localization.GetText(KeyDownloadStarted), false)
+	}
+}
+
+// onClipboardDismiss hides the prompt and stops offering the URL
+func (ui *RootUI) onClipboardDismiss() {
+	if ui.clipboardURL != "" {
+		ui.settings.AddDismissedClipboardURL(ui.clipboardURL)
+	}
+	ui.hideClipboardPrompt()
+	ui.notificationContainer.Hide()
+}
+
+// hideClipboardPrompt removes the prompt buttons; must run on the UI thread
+func (ui *RootUI) hideClipboardPrompt() {
+	ui.clipboardURL = ""
+	if ui.clipboardDownloadBtn != nil {
+		ui.clipboardDownloadBtn.Hide()
+	}
+	if ui.clipboardDismissBtn != nil {
+		ui.clipboardDismissBtn.Hide()
+	}
 }
 
 // createTaskItem creates a new task item widget
@@ -529,6 +870,10 @@ func (ui *RootUI) createTaskItem() fyne.CanvasObject {
 		ui.onOpenFile,
 		ui.onCopyPath,
 		ui.onRemoveTask,
+		ui.onSplitChapters,
+		ui.onScheduleTask,
+		ui.onQueueAction,
+		ui.onDragTask,
 	)
 
 	return taskRow
@@ -558,6 +903,10 @@ func (ui *RootUI) updateFilteredTaskItem(id widget.ListItemID, item fyne.CanvasO
 			ui.onOpenFile,
 			ui.onCopyPath,
 			ui.onRemoveTask,
+			ui.onSplitChapters,
+			ui.onScheduleTask,
+			ui.onQueueAction,
+			ui.onDragTask,
 		)
 
 		// Update the task data
@@ -608,6 +957,11 @@ func (ui *RootUI) updateFilteredTasks() {
 			ui.filteredTasks = append(ui.filteredTasks, task)
 		}
 	}
+
+	// Show tasks in the order they start
+	sort.SliceStable(ui.filteredTasks, func(i, j int) bool {
+		return ui.filteredTasks[i].QueueSeq < ui.filteredTasks[j].QueueSeq
+	})
 }
 
 // shouldShowTask returns whether a task should be shown based on current filter
@@ -616,13 +970,14 @@ func (ui *RootUI) shouldShowTask(task *model.DownloadTask) bool {
 	case FilterAll:
 		return true
 	case FilterDownloading:
-		return task.Status == model.TaskStatusDownloading || task.Status == model.TaskStatusStarting
+		return task.Status == model.TaskStatusDownloading || task.Status == model.TaskStatusStarting ||
+			task.Status == model.TaskStatusWaiting || task.Status == model.TaskStatusRecording
 	case FilterPending:
-		return task.Status == model.TaskStatusPending
+		return task.Status.IsQueued()
 	case FilterCompleted:
 		return task.Status == model.TaskStatusCompleted
 	case FilterErrors:
-		return task.Status == model.TaskStatusError
+		return task.Status.IsFailed()
 	default:
 		return true
 	}
@@ -683,7 +1038,7 @@ func (ui *RootUI) onStartPauseTask(taskID string) {
 	log.Printf("Task %s status: %s, OutputPath: %s", taskID, task.Status, task.OutputPath)
 
 	switch task.Status {
-	case model.TaskStatusPending, model.TaskStatusError, model.TaskStatusStopped:
+	case model.TaskStatusPending, model.TaskStatusError, model.TaskStatusVerifyFailed, model.TaskStatusStopped:
 		// Start/Restart the task
 		log.Printf("Starting task %s", taskID)
 		err := ui.downloadSvc.RestartTask(taskID)
@@ -699,7 +1054,14 @@ func (ui *RootUI) onStartPauseTask(taskID string) {
 			log.Printf("Error resuming task %s: %v", taskID, err)
 			widget.ShowPopUp(widget.NewLabel("Error resuming task: "+err.Error()), ui.window.Canvas())
 		}
-	case model.TaskStatusDownloading, model.TaskStatusStarting:
+	case model.TaskStatusRecording:
+		// Stop the recording; the service keeps what was recorded
+		log.Printf("Stopping recording of task %s", taskID)
+		if err := ui.downloadSvc.StopTask(taskID); err != nil {
+			log.Printf("Error stopping recording of task %s: %v", taskID, err)
+			widget.ShowPopUp(widget.NewLabel("Error stopping recording: "+err.Error()), ui.window.Canvas())
+		}
+	case model.TaskStatusDownloading, model.TaskStatusStarting, model.TaskStatusWaiting, model.TaskStatusScheduled:
 		// Pause the task
 		log.Printf("Pausing task %s", taskID)
 		err := ui.downloadSvc.PauseTask(taskID)
@@ -874,11 +1236,27 @@ func (ui *RootUI) onCopyPath(filePath string) {
 	widget.ShowPopUp(widget.NewLabel("Path copied to clipboard"), ui.window.Canvas())
 }
 
-// onRemoveTask handles removing a task from the list
+// onRemoveTask handles removing a task from the list. For an unfinished
+// download it asks whether the partial data should be deleted too.
 func (ui *RootUI) onRemoveTask(taskID string) {
 	log.Printf("onRemoveTask called for task %s", taskID)
 
-	err := ui.downloadSvc.RemoveTask(taskID)
+	task, ok := ui.downloadSvc.GetTask(taskID)
+	if !ok || task.OutputPath == "" || task.Status == model.TaskStatusCompleted {
+		ui.removeTask(taskID, false)
+		return
+	}
+	message := widget.NewLabel(ui.localization.GetText(KeyDeletePartialPrompt))
+	message.Wrapping = fyne.TextWrapWord
+	dialog.ShowCustomConfirm(ui.localization.GetText(KeyRemoveDownload), ui.localization.GetText(KeyDeletePartial),
+		ui.localization.GetText(KeyKeepPartial), message, func(deletePartial bool) {
+			ui.removeTask(taskID, deletePartial)
+		}, ui.window)
+}
+
+// removeTask removes a task from the download service and the list
+func (ui *RootUI) removeTask(taskID string, deletePartial bool) {
+	err := ui.downloadSvc.RemoveTask(taskID, deletePartial)
 	if err != nil {
 		log.Printf("Error removing task %s: %v", taskID, err)
 		widget.ShowPopUp(widget.NewLabel("Error removing task: "+err.Error()), ui.window.Canvas())
@@ -915,6 +1293,178 @@ func (ui *RootUI) onRemoveTask(taskID string) {
 	}
 }
 
+// onSplitChapters cuts a downloaded video into one file per chapter
+func (ui *RootUI) onSplitChapters(taskID string) {
+	log.Printf("onSplitChapters called for task %s", taskID)
+
+	task, ok := ui.downloadSvc.GetTask(taskID)
+	if !ok || task.OutputPath == "" || len(task.Chapters) == 0 {
+		widget.ShowPopUp(widget.NewLabel(ui.localization.GetText(KeyNoChapters)), ui.window.Canvas())
+		return
+	}
+	if ui.compressSvc == nil {
+		log.Printf("Compression service is not available")
+		return
+	}
+
+	if _, err := ui.compressSvc.SplitByChapters(task.OutputPath, task.Chapters); err != nil {
+		log.Printf("Error splitting task %s by chapters: %v", taskID, err)
+		ui.showNotification(ui.localization.GetText(KeySplitFailed)+": "+err.Error(), false)
+		return
+	}
+	ui.showNotification(fmt.Sprintf("%s (%d)", ui.localization.GetText(KeySplitStarted), len(task.Chapters)), true)
+}
+
+// onScheduleTask asks for the clock time a task should start at; an empty time
+// starts it as soon as a download slot is free
+func (ui *RootUI) onScheduleTask(taskID string) {
+	log.Printf("onScheduleTask called for task %s", taskID)
+
+	task, ok := ui.downloadSvc.GetTask(taskID)
+	if !ok {
+		// Fallback to lookup by YouTube video ID for playlist rows
+		if t2, ok := ui.downloadSvc.GetTaskByVideoID(taskID); ok {
+			task = t2
+		} else {
+			log.Printf("Task %s not found", taskID)
+			return
+		}
+	}
+
+	startEntry := widget.NewEntry()
+	startEntry.SetPlaceHolder(ui.localization.GetText(KeyStartAtPlaceholder))
+	if !task.StartAt.IsZero() {
+		startEntry.SetText(task.StartAt.Format(StartAtLayout))
+	}
+	startEntry.Validator = validateStartAt
+
+	dialog.ShowForm(ui.localization.GetText(KeyScheduleTask), ui.localization.GetText(KeySave), ui.localization.GetText(KeyCancel),
+		[]*widget.FormItem{widget.NewFormItem(ui.localization.GetText(KeyStartAt), startEntry)},
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			startAt, _ := parseStartAt(startEntry.Text, time.Now())
+			if err := ui.downloadSvc.ScheduleTask(task.ID, startAt); err != nil {
+				log.Printf("Error scheduling task %s: %v", task.ID, err)
+				ui.showNotification(ui.localization.GetText(KeyScheduleFailed)+": "+err.Error(), false)
+			}
+		}, ui.window)
+}
+
+// onQueueAction moves a queued task or changes its priority
+func (ui *RootUI) onQueueAction(taskID string, action QueueAction) {
+	log.Printf("onQueueAction called for task %s: %d", taskID, action)
+
+	task, ok := ui.downloadSvc.GetTask(taskID)
+	if !ok {
+		// Fallback to lookup by YouTube video ID for playlist rows
+		if t2, ok := ui.downloadSvc.GetTaskByVideoID(taskID); ok {
+			task = t2
+		} else {
+			log.Printf("Task %s not found", taskID)
+			return
+		}
+	}
+
+	var err error
+	switch action {
+	case QueueDownloadNext:
+		err = ui.downloadSvc.DownloadNext(task.ID)
+	case QueueMoveToTop:
+		err = ui.downloadSvc.MoveTask(task.ID, 0)
+	case QueueMoveToBottom:
+		err = ui.downloadSvc.MoveTask(task.ID, -1)
+	case QueuePriorityHigh:
+		err = ui.downloadSvc.SetTaskPriority(task.ID, model.PriorityHigh)
+	case QueuePriorityNormal:
+		err = ui.downloadSvc.SetTaskPriority(task.ID, model.PriorityNormal)
+	case QueuePriorityLow:
+		err = ui.downloadSvc.SetTaskPriority(task.ID, model.PriorityLow)
+	}
+	if err != nil {
+		log.Printf("Error changing queue for task %s: %v", task.ID, err)
+		ui.showNotification(ui.localization.GetText(KeyQueueFailed)+": "+err.Error(), false)
+	}
+}
+
+// onDragTask moves a task dragged over the given number of rows in the task list
+func (ui *RootUI) onDragTask(taskID string, rows int) {
+	if targetID, ok := dragTarget(ui.filteredTasks, taskID, rows); ok {
+		ui.moveTaskTo(taskID, targetID, rows < 0)
+	}
+}
+
+// moveTaskTo moves a task to the queue position of the target task. A target
+// that is running or finished sends it to the top or bottom of the queue.
+func (ui *RootUI) moveTaskTo(taskID, targetID string, up bool) {
+	index := -1
+	if up {
+		index = 0
+	}
+	for i, queued := range ui.downloadSvc.GetQueue() {
+		if queued.ID == targetID {
+			index = i
+			break
+		}
+	}
+	if err := ui.downloadSvc.MoveTask(taskID, index); err != nil {
+		log.Printf("Error moving task %s: %v", taskID, err)
+		ui.showNotification(ui.localization.GetText(KeyQueueFailed)+": "+err.Error(), false)
+	}
+}
+
+// dragTarget returns the task a row lands on when dragged over the given number
+// of rows, or false if the row stays in place
+func dragTarget(order []*model.DownloadTask, taskID string, rows int) (string, bool) {
+	for i, task := range order {
+		if task.ID != taskID {
+			continue
+		}
+		target := i + rows
+		if target < 0 {
+			target = 0
+		}
+		if target > len(order)-1 {
+			target = len(order) - 1
+		}
+		if target == i {
+			return "", false
+		}
+		return order[target].ID, true
+	}
+	return "", false
+}
+
+// parseStartAt converts an "HH:MM" entry into the next time the clock shows it;
+// an empty entry yields the zero time
+func parseStartAt(text string, now time.Time) (time.Time, error) {
+	if strings.TrimSpace(text) == "" {
+		return time.Time{}, nil
+	}
+	offset, err := schedule.ParseClock(text)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.NextAt(now, offset), nil
+}
+
+// validateStartAt validates a start time entry
+func validateStartAt(text string) error {
+	_, err := parseStartAt(text, time.Now())
+	return err
+}
+
+// onCompressionUpdate reports finished split-by-chapter tasks in the notification panel
+func (ui *RootUI) onCompressionUpdate(task *model.CompressionTask) {
+	switch task.Status {
+	case model.TaskStatusCompleted:
+		ui.showNotification(ui.localization.GetText(KeySplitDone)+": "+task.OutputPath, false)
+	case model.TaskStatusError:
+		ui.showNotification(ui.localization.GetText(KeySplitFailed)+": "+task.LastError, false)
+	}
+}
+
 // debouncedUIUpdate prevents excessive UI updates by limiting frequency
 func (ui *RootUI) debouncedUIUpdate() {
 	ui.uiUpdateMutex.Lock()
@@ -928,6 +1478,26 @@ func (ui *RootUI) debouncedUIUpdate() {
 	ui.lastUIUpdate = now
 }
 
+// consumeDownloadEvents dispatches download service events to the UI
+func (ui *RootUI) consumeDownloadEvents(events <-chan download.Event) {
+	for event := range events {
+		switch event.Type {
+		case download.EventPlaylistUpdated:
+			playlist := event.Playlist
+			fyne.Do(func() {
+				ui.playlistGroup.UpdatePlaylist(playlist)
+			})
+		case download.EventDiskLow:
+			task := event.Task
+			ui.showNotification(ui.localization.GetText(KeyDiskLow)+": "+task.LastError, false)
+			ui.onTaskUpdate(&task)
+		default:
+			task := event.Task
+			ui.onTaskUpdate(&task)
+		}
+	}
+}
+
 // onTaskUpdate handles task updates from the download service
 func (ui *RootUI) onTaskUpdate(task *model.DownloadTask) {
 	// Clean task data to prevent display issues
@@ -1001,29 +1571,9 @@ func (ui *RootUI) onTaskUpdate(task *model.DownloadTask) {
 		}
 	})
 
-	// Update PlaylistGroup if this task is displayed there
-	// Only update if values actually changed to prevent excessive UI updates
-	log.Printf("Updating playlist group: id=%s progress=%.2f status=%s", task.ID, task.Progress, task.Status)
-	ui.playlistGroup.UpdateVideoProgress(task.ID, task.Progress)
-	ui.playlistGroup.UpdateVideoStatus(task.ID, task.Status)
-	// Propagate runtime telemetry to playlist rows so speed/ETA are visible
-	ui.playlistGroup.UpdateVideoSpeed(task.ID, task.Speed, task.ETASec)
-	// Additionally try to match playlist items by URL, since playlist videos use
-	// YouTube video IDs while download tasks have generated IDs.
-	if task.URL != "" {
-		ui.playlistGroup.UpdateVideoProgressByURL(task.URL, task.Progress)
-		ui.playlistGroup.UpdateVideoStatusByURL(task.URL, task.Status)
-		ui.playlistGroup.UpdateVideoSpeedByURL(task.URL, task.Speed, task.ETASec)
-	}
-
-	// Only update OutputPath if it's not empty and different from current
-	if task.OutputPath != "" {
-		log.Printf("Updating playlist output path: id=%s path=%s size=%d", task.ID, task.OutputPath, task.FileSize)
-		ui.playlistGroup.UpdateVideoOutputPath(task.ID, task.OutputPath, task.FileSize)
-		if task.URL != "" {
-			ui.playlistGroup.UpdateVideoOutputPathByURL(task.URL, task.OutputPath, task.FileSize)
-		}
-	}
+	// Update PlaylistGroup if this task is displayed there as an individual video.
+	// Playlist videos are refreshed from playlist events instead.
+	ui.playlistGroup.UpdateIndividualVideo(task)
 
 	// Use debounced UI update to prevent excessive refreshes
 	log.Printf("Debounced UI update")
@@ -1158,6 +1708,34 @@ func (ui *RootUI) onPlaylistDownload(playlist *model.Playlist) {
 	log.Printf("Started downloading playlist: %s with %d videos", playlist.Title, playlist.TotalVideos)
 }
 
+// onPlaylistPause handles playlist pause requests
+func (ui *RootUI) onPlaylistPause(playlist *model.Playlist) {
+	if playlist == nil {
+		return
+	}
+
+	if err := ui.downloadSvc.PausePlaylist(playlist.ID); err != nil {
+		log.Printf("Failed to pause playlist: %v", err)
+		return
+	}
+
+	log.Printf("Paused playlist download: %s", playlist.Title)
+}
+
+// onPlaylistResume handles playlist resume requests
+func (ui *RootUI) onPlaylistResume(playlist *model.Playlist) {
+	if playlist == nil {
+		return
+	}
+
+	if err := ui.downloadSvc.ResumePlaylist(playlist.ID); err != nil {
+		log.Printf("Failed to resume playlist: %v", err)
+		return
+	}
+
+	log.Printf("Resumed playlist download: %s", playlist.Title)
+}
+
 // onPlaylistCancel handles playlist cancellation requests
 func (ui *RootUI) onPlaylistCancel(playlist *model.Playlist) {
 	if playlist == nil {
@@ -1174,9 +1752,9 @@ func (ui *RootUI) onPlaylistCancel(playlist *model.Playlist) {
 	log.Printf("Cancelled playlist download: %s", playlist.Title)
 }
 
-// isPlaylistURL checks if the URL is a playlist URL
+// isPlaylistURL checks if the URL is a YouTube playlist URL, including a video opened from a playlist
 func (ui *RootUI) isPlaylistURL(url string) bool {
-	return strings.Contains(url, RootPlaylistQueryParam)
+	return ytlink.PlaylistID(url) != ""
 }
 
 // handlePlaylistURL handles playlist URL processing
@@ -1208,6 +1786,10 @@ func (ui *RootUI) handlePlaylistURL(url string) {
 			log.Printf("Playlist parsed successfully: %s with %d videos", playlist.Title, playlist.TotalVideos)
 			log.Printf("Playlist videos: %+v", playlist.Videos)
 
+			// Apply default output layout; the user may change it while reviewing
+			playlist.UseSubfolder = ui.settings.GetPlaylistSubfolder()
+			playlist.WriteM3U = ui.settings.GetPlaylistWriteM3U()
+
 			// Add playlist to playlist group
 			log.Printf("Adding playlist to UI...")
 			ui.playlistGroup.AddPlaylist(playlist)
@@ -1215,36 +1797,34 @@ func (ui *RootUI) handlePlaylistURL(url string) {
 			// Clear URL entry
 			ui.urlEntry.SetText("")
 
-			// Show success message in notification panel
-			ui.showNotification(fmt.Sprintf("%s: %s (%d)", ui.localization.GetText(KeyPlaylistParsed), playlist.Title, playlist.TotalVideos), false)
-
 			log.Printf("Playlist added to UI successfully")
 
-			// Auto-start downloading the playlist
-			log.Printf("Auto-starting playlist download...")
-			go func() {
-				// Small delay to ensure UI is updated
-				time.Sleep(RootPlaylistParseDelay)
-
-				// Start downloading the playlist
-				err := ui.downloadSvc.AddPlaylist(playlist)
-				if err != nil {
-					log.Printf("Failed to add playlist to download service: %v", err)
-					return
-				}
-
-				err = ui.downloadSvc.DownloadPlaylist(playlist)
-				if err != nil {
-					log.Printf("Failed to start playlist download: %v", err)
-					return
-				}
+			// On mobile the playlist view is not shown, so there is nothing to review
+			if ui.mobileUI.IsMobileDevice() {
+				ui.showNotification(fmt.Sprintf("%s: %s (%d)", ui.localization.GetText(KeyPlaylistParsed), playlist.Title, playlist.TotalVideos), false)
+				log.Printf("Auto-starting playlist download...")
+				go func() {
+					// Small delay to ensure UI is updated
+					time.Sleep(RootPlaylistParseDelay)
+					ui.onPlaylistDownload(playlist)
+				}()
+				return
+			}
 
-				log.Printf("Auto-started downloading playlist: %s with %d videos", playlist.Title, playlist.TotalVideos)
-			}()
+			// Leave the playlist in Ready state so the user can choose videos first
+			ui.showNotification(fmt.Sprintf("%s: %s (%d). %s", ui.localization.GetText(KeyPlaylistParsed),
+				playlist.Title, playlist.TotalVideos, ui.localization.GetText(KeyPlaylistReadyToRun)), false)
 		})
 	}()
 }
 
+// applyCookies sends imported cookies with downloads and playlist parsing
+func (ui *RootUI) applyCookies(cookies []*http.Cookie) {
+	ui.downloadSvc.SetCookies(cookies)
+	ui.parserService.SetCookies(cookies)
+	log.Printf("Cookies applied: %d", len(cookies))
+}
+
 // readAndApplySettings reads current settings and applies them to download service
 func (ui *RootUI) readAndApplySettings() {
 	// Update localization language
@@ -1260,7 +1840,31 @@ func (ui *RootUI) readAndApplySettings() {
 
 	// Update download service settings
 	ui.downloadSvc.SetMaxParallelDownloads(ui.settings.GetMaxParallelDownloads())
+	ui.downloadSvc.SetDownloadSegments(ui.settings.GetDownloadSegments())
 	ui.downloadSvc.SetDownloadDirectory(downloadsDir)
+	ui.downloadSvc.SetTempDir(ui.settings.GetTempDirectory())
+
+	// Update proxy for downloads and playlist parsing
+	proxy := ui.settings.GetProxy()
+	ui.downloadSvc.SetProxy(proxy)
+	ui.parserService.SetProxy(proxy)
+
+	// Update the free disk space downloads must leave
+	ui.downloadSvc.SetDiskReserve(int64(ui.settings.GetDiskReserveMB()) << 20)
+
+	// Update what happens when an output file already exists
+	collision := ui.settings.GetCollisionPolicy()
+	ui.downloadSvc.SetCollisionPolicy(collision)
+	ui.downloadSvc.SetVerifyMode(ui.settings.GetVerifyMode())
+	if ui.compressSvc != nil {
+		ui.compressSvc.SetCollisionPolicy(collision)
+	}
+
+	// Update the window in which queued downloads may start
+	ui.downloadSvc.SetDownloadWindow(ui.settings.GetDownloadWindow())
+
+	// Update SponsorBlock post-processing
+	ui.downloadSvc.SetSponsorBlock(ui.settings.GetSponsorBlock())
 
 	// Update quality preset
 	switch ui.settings.GetQualityPreset() {
@@ -1274,8 +1878,8 @@ func (ui *RootUI) readAndApplySettings() {
 		ui.downloadSvc.SetQualityPreset("best")
 	}
 
-	log.Printf("Settings applied: dir=%s, maxParallel=%d, quality=%s",
-		downloadsDir, ui.settings.GetMaxParallelDownloads(), ui.settings.GetQualityPreset())
+	log.Printf("Settings applied: dir=%s, maxParallel=%d, segments=%d, quality=%s, proxy=%s",
+		downloadsDir, ui.settings.GetMaxParallelDownloads(), ui.settings.GetDownloadSegments(), ui.settings.GetQualityPreset(), proxy)
 }
 
 // createMobileIconPanel creates a panel with quick access icons for mobile
@@ -1,25 +1,39 @@
 package ui
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
 	"strconv"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
-
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/ytget/yt-downloader/internal/config"
+	"github.com/ytget/yt-downloader/internal/download"
+	"github.com/ytget/yt-downloader/internal/network"
+	"github.com/ytget/yt-downloader/internal/platform"
+	"github.com/ytget/yt-downloader/internal/schedule"
+	"github.com/ytget/yt-downloader/internal/sponsorblock"
 )
 
 // Dialog size constants
 const (
 	SettingsDialogWidth  = 500
-	SettingsDialogHeight = 400
+	SettingsDialogHeight = 560
 )
 
-// ShowSettingsDialog shows the application settings dialog
-func ShowSettingsDialog(window fyne.Window, settings *config.Settings, localization *Localization, onSettingsChanged func()) {
+// CookiesImportExtensions are the file types accepted by the cookie import
+var CookiesImportExtensions = []string{".txt"}
+
+// ShowSettingsDialog shows the application settings dialog.
+// Cookies are stored as soon as they are imported or cleared and reported through onCookiesChanged.
+func ShowSettingsDialog(window fyne.Window, settings *config.Settings, localization *Localization, onSettingsChanged func(), onCookiesChanged func(cookies []*http.Cookie)) {
 	// Download directory selection
 	downloadDirLabel := widget.NewLabel(localization.GetText(KeyDownloadDirectory) + ":")
 	downloadDirEntry := widget.NewEntry()
@@ -35,6 +49,20 @@ func ShowSettingsDialog(window fyne.Window, settings *config.Settings, localizat
 
 	downloadDirContainer := container.NewBorder(nil, nil, nil, browseBtn, downloadDirEntry)
 
+	// Optional directory for unfinished downloads, e.g. on a faster disk
+	tempDirLabel := widget.NewLabel(localization.GetText(KeyTempDir) + ":")
+	tempDirEntry := widget.NewEntry()
+	tempDirEntry.SetPlaceHolder(localization.GetText(KeyTempDirHint))
+	tempDirEntry.SetText(settings.GetTempDirectory())
+	tempBrowseBtn := widget.NewButton(localization.GetText(KeyBrowse), func() {
+		dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+			if err == nil && folder != nil {
+				tempDirEntry.SetText(folder.Path())
+			}
+		}, window)
+	})
+	tempDirContainer := container.NewBorder(nil, nil, nil, tempBrowseBtn, tempDirEntry)
+
 	// Quality preset selection
 	qualityLabel := widget.NewLabel(localization.GetText(KeyQualityPreset) + ":")
 	qualitySelect := widget.NewSelect([]string{"Best", "Medium", "Audio Only"}, nil)
@@ -60,28 +88,340 @@ func ShowSettingsDialog(window fyne.Window, settings *config.Settings, localizat
 		return nil
 	}
 
+	// Connections per file download; bounded together with the parallel limit
+	segmentsLabel := widget.NewLabel(localization.GetText(KeyDownloadSegments) + ":")
+	segmentsEntry := widget.NewEntry()
+	segmentsEntry.SetText(strconv.Itoa(settings.GetDownloadSegments()))
+	segmentsEntry.Validator = func(s string) error {
+		if _, err := strconv.Atoi(s); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// Free disk space kept by downloads; 0 disables the checks
+	diskReserveLabel := widget.NewLabel(localization.GetText(KeyDiskReserve) + ":")
+	diskReserveEntry := widget.NewEntry()
+	diskReserveEntry.SetText(strconv.Itoa(settings.GetDiskReserveMB()))
+	diskReserveEntry.Validator = func(s string) error {
+		if _, err := strconv.Atoi(s); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// What happens when a download or compression would overwrite a file
+	collisionLabel := widget.NewLabel(localization.GetText(KeyCollisionPolicy) + ":")
+	collisionNames := map[platform.CollisionPolicy]string{
+		platform.CollisionVerify:    localization.GetText(KeyCollisionVerify),
+		platform.CollisionRename:    localization.GetText(KeyCollisionRename),
+		platform.CollisionSkip:      localization.GetText(KeyCollisionSkip),
+		platform.CollisionOverwrite: localization.GetText(KeyCollisionOverwrite),
+	}
+	collisionOptions := make([]string, 0, len(platform.CollisionPolicies))
+	collisionPolicies := make(map[string]platform.CollisionPolicy, len(platform.CollisionPolicies))
+	for _, policy := range platform.CollisionPolicies {
+		collisionOptions = append(collisionOptions, collisionNames[policy])
+		collisionPolicies[collisionNames[policy]] = policy
+	}
+	collisionSelect := widget.NewSelect(collisionOptions, nil)
+	collisionSelect.SetSelected(collisionNames[settings.GetCollisionPolicy()])
+
+	// Whether finished downloads are checked with ffprobe
+	verifyLabel := widget.NewLabel(localization.GetText(KeyVerifyMode) + ":")
+	verifyNames := map[download.VerifyMode]string{
+		download.VerifyOff:   localization.GetText(KeyVerifyOff),
+		download.VerifyMark:  localization.GetText(KeyVerifyMark),
+		download.VerifyRetry: localization.GetText(KeyVerifyRetry),
+	}
+	verifyOptions := make([]string, 0, len(download.VerifyModes))
+	verifyModes := make(map[string]download.VerifyMode, len(download.VerifyModes))
+	for _, mode := range download.VerifyModes {
+		verifyOptions = append(verifyOptions, verifyNames[mode])
+		verifyModes[verifyNames[mode]] = mode
+	}
+	verifySelect := widget.NewSelect(verifyOptions, nil)
+	verifySelect.SetSelected(verifyNames[settings.GetVerifyMode()])
+
 	// Auto reveal setting
 	autoRevealCheck := widget.NewCheck("Auto-reveal completed downloads", nil)
 	autoRevealCheck.SetChecked(settings.GetAutoRevealOnComplete())
 
+	// Clipboard watcher
+	clipboardWatchCheck := widget.NewCheck(localization.GetText(KeyClipboardWatch), nil)
+	clipboardWatchCheck.SetChecked(settings.GetClipboardWatch())
+
+	// Playlist output layout defaults
+	playlistSubfolderCheck := widget.NewCheck(localization.GetText(KeyPlaylistSubfolder), nil)
+	playlistSubfolderCheck.SetChecked(settings.GetPlaylistSubfolder())
+	playlistM3UCheck := widget.NewCheck(localization.GetText(KeyPlaylistWriteM3U), nil)
+	playlistM3UCheck.SetChecked(settings.GetPlaylistWriteM3U())
+
+	// Proxy
+	proxyCfg := settings.GetProxy()
+	proxyLabel := widget.NewLabel(localization.GetText(KeyProxy) + ":")
+	proxyModeNames := map[network.ProxyMode]string{
+		network.ProxyNone:   localization.GetText(KeyProxyNone),
+		network.ProxySystem: localization.GetText(KeyProxySystem),
+		network.ProxyHTTP:   "HTTP",
+		network.ProxyHTTPS:  "HTTPS",
+		network.ProxySOCKS5: "SOCKS5",
+	}
+	proxyModeOptions := make([]string, 0, len(network.ProxyModes))
+	proxyModes := make(map[string]network.ProxyMode, len(network.ProxyModes))
+	for _, mode := range network.ProxyModes {
+		proxyModeOptions = append(proxyModeOptions, proxyModeNames[mode])
+		proxyModes[proxyModeNames[mode]] = mode
+	}
+	proxyHostEntry := widget.NewEntry()
+	proxyHostEntry.SetPlaceHolder(localization.GetText(KeyProxyHost))
+	proxyHostEntry.SetText(proxyCfg.Host)
+	proxyPortEntry := widget.NewEntry()
+	proxyPortEntry.SetPlaceHolder(localization.GetText(KeyProxyPort))
+	if proxyCfg.Port > 0 {
+		proxyPortEntry.SetText(strconv.Itoa(proxyCfg.Port))
+	}
+	proxyUserEntry := widget.NewEntry()
+	proxyUserEntry.SetPlaceHolder(localization.GetText(KeyProxyUsername))
+	proxyUserEntry.SetText(proxyCfg.Username)
+	proxyPasswordEntry := widget.NewPasswordEntry()
+	proxyPasswordEntry.SetPlaceHolder(localization.GetText(KeyProxyPassword))
+	proxyPasswordEntry.SetText(proxyCfg.Password)
+	proxyRememberCheck := widget.NewCheck(localization.GetText(KeyProxyRemember), nil)
+	proxyRememberCheck.SetChecked(settings.GetProxyRememberPassword())
+	proxyServerFields := []fyne.Disableable{proxyHostEntry, proxyPortEntry, proxyUserEntry, proxyPasswordEntry, proxyRememberCheck}
+	proxyModeSelect := widget.NewSelect(proxyModeOptions, func(selected string) {
+		// Server fields only apply to explicit proxies
+		for _, field := range proxyServerFields {
+			if proxyModes[selected].NeedsServer() {
+				field.Enable()
+			} else {
+				field.Disable()
+			}
+		}
+	})
+	proxyModeSelect.SetSelected(proxyModeNames[proxyCfg.Mode])
+	proxyServerContainer := container.NewGridWithColumns(2, proxyHostEntry, proxyPortEntry)
+	proxyAuthContainer := container.NewGridWithColumns(2, proxyUserEntry, proxyPasswordEntry)
+
+	// Daily window in which queued downloads may start
+	downloadWindow := settings.GetDownloadWindow()
+	windowStartEntry := widget.NewEntry()
+	windowStartEntry.SetPlaceHolder(config.DefaultWindowStart)
+	windowStartEntry.SetText(schedule.FormatClock(downloadWindow.Start))
+	windowEndEntry := widget.NewEntry()
+	windowEndEntry.SetPlaceHolder(config.DefaultWindowEnd)
+	windowEndEntry.SetText(schedule.FormatClock(downloadWindow.End))
+	for _, entry := range []*widget.Entry{windowStartEntry, windowEndEntry} {
+		entry.Validator = func(s string) error {
+			_, err := schedule.ParseClock(s)
+			return err
+		}
+	}
+	windowCheck := widget.NewCheck(localization.GetText(KeyDownloadWindow), func(checked bool) {
+		if checked {
+			windowStartEntry.Enable()
+			windowEndEntry.Enable()
+		} else {
+			windowStartEntry.Disable()
+			windowEndEntry.Disable()
+		}
+	})
+	windowCheck.SetChecked(downloadWindow.Enabled)
+	if !downloadWindow.Enabled {
+		windowStartEntry.Disable()
+		windowEndEntry.Disable()
+	}
+	windowContainer := container.NewGridWithColumns(2, windowStartEntry, windowEndEntry)
+
+	// SponsorBlock segments of YouTube downloads
+	sponsorCfg := settings.GetSponsorBlock()
+	sponsorLabel := widget.NewLabel(localization.GetText(KeySponsorBlock) + ":")
+	sponsorModeNames := map[sponsorblock.Mode]string{
+		sponsorblock.ModeOff:      localization.GetText(KeySponsorBlockOff),
+		sponsorblock.ModeRemove:   localization.GetText(KeySponsorBlockRemove),
+		sponsorblock.ModeChapters: localization.GetText(KeySponsorBlockChapters),
+	}
+	sponsorModeOptions := make([]string, 0, len(sponsorblock.Modes))
+	sponsorModes := make(map[string]sponsorblock.Mode, len(sponsorblock.Modes))
+	for _, mode := range sponsorblock.Modes {
+		sponsorModeOptions = append(sponsorModeOptions, sponsorModeNames[mode])
+		sponsorModes[sponsorModeNames[mode]] = mode
+	}
+	sponsorCategoryOptions := make([]string, 0, len(sponsorblock.Categories))
+	sponsorCategories := make(map[string]sponsorblock.Category, len(sponsorblock.Categories))
+	for _, category := range sponsorblock.Categories {
+		sponsorCategoryOptions = append(sponsorCategoryOptions, category.Title())
+		sponsorCategories[category.Title()] = category
+	}
+	sponsorCategoryCheck := widget.NewCheckGroup(sponsorCategoryOptions, nil)
+	sponsorCategoryCheck.Horizontal = true
+	for _, category := range sponsorCfg.Categories {
+		sponsorCategoryCheck.Selected = append(sponsorCategoryCheck.Selected, category.Title())
+	}
+	sponsorURLEntry := widget.NewEntry()
+	sponsorURLEntry.SetPlaceHolder(localization.GetText(KeySponsorBlockURL))
+	sponsorURLEntry.SetText(sponsorCfg.BaseURL)
+	sponsorModeSelect := widget.NewSelect(sponsorModeOptions, func(selected string) {
+		if sponsorModes[selected] == sponsorblock.ModeOff {
+			sponsorCategoryCheck.Disable()
+			sponsorURLEntry.Disable()
+		} else {
+			sponsorCategoryCheck.Enable()
+			sponsorURLEntry.Enable()
+		}
+	})
+	sponsorModeSelect.SetSelected(sponsorModeNames[sponsorCfg.Mode])
+	sponsorCategoriesLabel := widget.NewLabel(localization.GetText(KeySponsorBlockCategories) + ":")
+
+	// Cookies for age-restricted and members-only videos
+	cookiesLabel := widget.NewLabel(localization.GetText(KeyCookies) + ":")
+	cookiesStatus := widget.NewLabel("")
+	setCookies := func(cookies []*http.Cookie) {
+		if len(cookies) == 0 {
+			cookiesStatus.SetText(localization.GetText(KeyCookiesNone))
+		} else {
+			cookiesStatus.SetText(fmt.Sprintf(localization.GetText(KeyCookiesCount), len(cookies)))
+		}
+	}
+	if stored, err := platform.LoadCookies(); err != nil {
+		log.Printf("Failed to load cookies: %v", err)
+		setCookies(nil)
+	} else {
+		setCookies(stored)
+	}
+	onImported := func(cookies []*http.Cookie, err error) {
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("%s: %w", localization.GetText(KeyCookiesFailed), err), window)
+			return
+		}
+		setCookies(cookies)
+		if onCookiesChanged != nil {
+			onCookiesChanged(cookies)
+		}
+	}
+	importCookiesBtn := widget.NewButton(localization.GetText(KeyImportCookies), func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			defer reader.Close()
+			onImported(platform.ImportCookiesFile(reader))
+		}, window)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter(CookiesImportExtensions))
+		fileDialog.Show()
+	})
+	importBrowserCookies := func(browser platform.Browser) {
+		// Reading the browser database shells out to sqlite3, keep it off the UI thread
+		go func() {
+			cookies, err := platform.ImportBrowserCookies(context.Background(), browser)
+			fyne.Do(func() { onImported(cookies, err) })
+		}()
+	}
+	firefoxCookiesBtn := widget.NewButton(localization.GetText(KeyCookiesFromFirefox), func() {
+		importBrowserCookies(platform.BrowserFirefox)
+	})
+	chromiumCookiesBtn := widget.NewButton(localization.GetText(KeyCookiesFromChromium), func() {
+		importBrowserCookies(platform.BrowserChromium)
+	})
+	clearCookiesBtn := widget.NewButton(localization.GetText(KeyClearCookies), func() {
+		if err := platform.ClearCookies(); err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		onImported(nil, nil)
+	})
+	cookiesButtons := container.NewHBox(importCookiesBtn)
+	if runtime.GOOS == platform.OSLinux {
+		cookiesButtons.Add(firefoxCookiesBtn)
+		cookiesButtons.Add(chromiumCookiesBtn)
+	}
+	cookiesButtons.Add(clearCookiesBtn)
+
+	// Local control API
+	apiEnabledCheck := widget.NewCheck(localization.GetText(KeyAPIEnabled), nil)
+	apiEnabledCheck.SetChecked(settings.GetAPIEnabled())
+	apiPortLabel := widget.NewLabel(localization.GetText(KeyAPIPort) + ":")
+	apiPortEntry := widget.NewEntry()
+	apiPortEntry.SetText(strconv.Itoa(settings.GetAPIPort()))
+	apiPortEntry.Validator = func(s string) error {
+		port, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		if port < config.MinAPIPort || port > config.MaxAPIPort {
+			return fmt.Errorf("port must be between %d and %d", config.MinAPIPort, config.MaxAPIPort)
+		}
+		return nil
+	}
+	apiTokenLabel := widget.NewLabel(localization.GetText(KeyAPIToken) + ":")
+	apiTokenEntry := widget.NewEntry()
+	apiTokenEntry.SetText(settings.GetAPIToken())
+	apiTokenEntry.Disable()
+	regenerateBtn := widget.NewButton(localization.GetText(KeyRegenerate), func() {
+		apiTokenEntry.SetText(settings.RegenerateAPIToken())
+	})
+	copyTokenBtn := widget.NewButton(localization.GetText(KeyCopy), func() {
+		fyne.CurrentApp().Clipboard().SetContent(apiTokenEntry.Text)
+	})
+	apiTokenContainer := container.NewBorder(nil, nil, nil, container.NewHBox(copyTokenBtn, regenerateBtn), apiTokenEntry)
+
 	// Create form
 	form := container.NewVBox(
 		downloadDirLabel,
 		downloadDirContainer,
+		tempDirLabel,
+		tempDirContainer,
 		widget.NewSeparator(),
 		qualityLabel,
 		qualitySelect,
 		widget.NewSeparator(),
 		parallelLabel,
 		parallelEntry,
+		segmentsLabel,
+		segmentsEntry,
+		diskReserveLabel,
+		diskReserveEntry,
+		collisionLabel,
+		collisionSelect,
+		verifyLabel,
+		verifySelect,
+		windowCheck,
+		windowContainer,
 		widget.NewSeparator(),
 		autoRevealCheck,
+		clipboardWatchCheck,
+		playlistSubfolderCheck,
+		playlistM3UCheck,
+		widget.NewSeparator(),
+		proxyLabel,
+		proxyModeSelect,
+		proxyServerContainer,
+		proxyAuthContainer,
+		proxyRememberCheck,
+		widget.NewSeparator(),
+		sponsorLabel,
+		sponsorModeSelect,
+		sponsorCategoriesLabel,
+		sponsorCategoryCheck,
+		sponsorURLEntry,
+		widget.NewSeparator(),
+		cookiesLabel,
+		cookiesStatus,
+		cookiesButtons,
+		widget.NewSeparator(),
+		apiEnabledCheck,
+		apiPortLabel,
+		apiPortEntry,
+		apiTokenLabel,
+		apiTokenContainer,
 	)
 
 	// Save function
 	saveSettings := func() {
 		// Save download directory
 		settings.SetDownloadDirectory(downloadDirEntry.Text)
+		settings.SetTempDirectory(tempDirEntry.Text)
 
 		// Save quality preset
 		switch qualitySelect.Selected {
@@ -97,9 +437,65 @@ func ShowSettingsDialog(window fyne.Window, settings *config.Settings, localizat
 		if parallel, err := strconv.Atoi(parallelEntry.Text); err == nil && parallel > 0 {
 			settings.SetMaxParallelDownloads(parallel)
 		}
+		if segments, err := strconv.Atoi(segmentsEntry.Text); err == nil && segments > 0 {
+			settings.SetDownloadSegments(segments)
+		}
+		if reserve, err := strconv.Atoi(diskReserveEntry.Text); err == nil && reserve >= 0 {
+			settings.SetDiskReserveMB(reserve)
+		}
+		settings.SetCollisionPolicy(collisionPolicies[collisionSelect.Selected])
+		settings.SetVerifyMode(verifyModes[verifySelect.Selected])
+
+		// Save download window; an invalid time keeps the previous one
+		newWindow := settings.GetDownloadWindow()
+		newWindow.Enabled = windowCheck.Checked
+		if start, err := schedule.ParseClock(windowStartEntry.Text); err == nil {
+			newWindow.Start = start
+		}
+		if end, err := schedule.ParseClock(windowEndEntry.Text); err == nil {
+			newWindow.End = end
+		}
+		settings.SetDownloadWindow(newWindow)
 
 		// Save auto reveal setting
 		settings.SetAutoRevealOnComplete(autoRevealCheck.Checked)
+		settings.SetClipboardWatch(clipboardWatchCheck.Checked)
+
+		// Save playlist output defaults
+		settings.SetPlaylistSubfolder(playlistSubfolderCheck.Checked)
+		settings.SetPlaylistWriteM3U(playlistM3UCheck.Checked)
+
+		// Save proxy; an incomplete server configuration keeps the previous one
+		settings.SetProxyRememberPassword(proxyRememberCheck.Checked)
+		proxyPort, _ := strconv.Atoi(proxyPortEntry.Text)
+		newProxy := network.ProxyConfig{
+			Mode:     proxyModes[proxyModeSelect.Selected],
+			Host:     proxyHostEntry.Text,
+			Port:     proxyPort,
+			Username: proxyUserEntry.Text,
+			Password: proxyPasswordEntry.Text,
+		}
+		if err := newProxy.Validate(); err == nil {
+			settings.SetProxy(newProxy)
+		} else {
+			log.Printf("Proxy settings not saved: %v", err)
+		}
+
+		// Save SponsorBlock post-processing
+		newSponsor := sponsorblock.Config{
+			Mode:    sponsorModes[sponsorModeSelect.Selected],
+			BaseURL: sponsorURLEntry.Text,
+		}
+		for _, title := range sponsorCategoryCheck.Selected {
+			newSponsor.Categories = append(newSponsor.Categories, sponsorCategories[title])
+		}
+		settings.SetSponsorBlock(newSponsor)
+
+		// Save control API settings; the token is saved when regenerated
+		settings.SetAPIEnabled(apiEnabledCheck.Checked)
+		if port, err := strconv.Atoi(apiPortEntry.Text); err == nil {
+			settings.SetAPIPort(port)
+		}
 
 		// Notify parent about changes
 		if onSettingsChanged != nil {
@@ -108,7 +504,7 @@ func ShowSettingsDialog(window fyne.Window, settings *config.Settings, localizat
 	}
 
 	// Show dialog
-	dlg := dialog.NewCustomConfirm(localization.GetText(KeySettings), localization.GetText(KeySave), localization.GetText(KeyCancel), form, func(confirmed bool) {
+	dlg := dialog.NewCustomConfirm(localization.GetText(KeySettings), localization.GetText(KeySave), localization.GetText(KeyCancel), container.NewVScroll(form), func(confirmed bool) {
 		if confirmed {
 			saveSettings()
 		}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"image/color"
 	"log"
+	"math"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -33,6 +35,24 @@ const (
 	TaskRowDialogHeight = 400
 )
 
+// QueueAction is a queue change requested from a task row
+type QueueAction int
+
+const (
+	QueueDownloadNext QueueAction = iota
+	QueueMoveToTop
+	QueueMoveToBottom
+	QueuePriorityHigh
+	QueuePriorityNormal
+	QueuePriorityLow
+)
+
+// Priority markers appended to the status of queued tasks
+const (
+	PriorityHighIcon = "↑"
+	PriorityLowIcon  = "↓"
+)
+
 // formatFileSize is intentionally unused in current compact UI; keep for future use.
 //
 //lint:ignore U1000 keep for future size labels in task rows
@@ -67,6 +87,11 @@ type TaskRow struct {
 	openBtn       *widget.Button // reveal in file manager
 	playBtn       *widget.Button // open file with default app (player)
 	copyBtn       *widget.Button
+	chaptersBtn   *widget.Button // split the file by chapters, shown when the video has them
+	scheduleBtn   *widget.Button // set the start time of a queued or stopped task
+	queueBtn      *widget.Button // reorder or reprioritize a queued task
+	dragHandle    *DragHandle    // drag a queued task to another queue position
+	fixedOrder    bool           // hides the drag handle, e.g. for playlist videos
 
 	// Mobile-specific button
 	mobilePlayBtn *widget.Button // single large play button for mobile
@@ -77,6 +102,10 @@ type TaskRow struct {
 	onOpen       func(filePath string)
 	onCopyPath   func(filePath string)
 	onRemove     func(taskID string)
+	onSplit      func(taskID string)
+	onSchedule   func(taskID string)
+	onQueue      func(taskID string, action QueueAction)
+	onDrag       func(taskID string, rows int)
 }
 
 // NewTaskRow creates a new task row widget
@@ -109,6 +138,10 @@ func (tr *TaskRow) SetCallbacks(
 	onOpen func(filePath string),
 	onCopyPath func(filePath string),
 	onRemove func(taskID string),
+	onSplit func(taskID string),
+	onSchedule func(taskID string),
+	onQueue func(taskID string, action QueueAction),
+	onDrag func(taskID string, rows int),
 ) {
 	// Log callback status for debugging
 	if onStartPause == nil {
@@ -126,12 +159,31 @@ func (tr *TaskRow) SetCallbacks(
 	if onRemove == nil {
 		log.Printf("Warning: onRemove callback is nil for task %s", tr.task.ID)
 	}
+	if onSplit == nil {
+		log.Printf("Warning: onSplit callback is nil for task %s", tr.task.ID)
+	}
+	if onSchedule == nil {
+		log.Printf("Warning: onSchedule callback is nil for task %s", tr.task.ID)
+	}
+	if onQueue == nil {
+		log.Printf("Warning: onQueue callback is nil for task %s", tr.task.ID)
+	}
+	// A nil onDrag disables drag-to-reorder, e.g. for rows inside a playlist
 
 	tr.onStartPause = onStartPause
 	tr.onReveal = onReveal
 	tr.onOpen = onOpen
 	tr.onCopyPath = onCopyPath
 	tr.onRemove = onRemove
+	tr.onSplit = onSplit
+	tr.onSchedule = onSchedule
+	tr.onQueue = onQueue
+	tr.onDrag = onDrag
+}
+
+// SetReorderable sets whether the row can be dragged to another queue position
+func (tr *TaskRow) SetReorderable(reorderable bool) {
+	tr.fixedOrder = !reorderable
 }
 
 // UpdateTask updates the row with new task data
@@ -265,6 +317,31 @@ func (tr *TaskRow) createUI() {
 	})
 	tr.copyBtn.Importance = widget.MediumImportance
 
+	tr.chaptersBtn = tr.mobileUI.CreateMobileButton("chapters", func() {
+		currentTask := tr.task
+		if tr.onSplit != nil {
+			tr.onSplit(currentTask.ID)
+		} else {
+			log.Printf("onSplit callback is nil for task %s", currentTask.ID)
+		}
+	})
+	tr.chaptersBtn.Importance = widget.MediumImportance
+
+	tr.scheduleBtn = tr.mobileUI.CreateMobileButton("⏲", func() {
+		currentTask := tr.task
+		if tr.onSchedule != nil {
+			tr.onSchedule(currentTask.ID)
+		} else {
+			log.Printf("onSchedule callback is nil for task %s", currentTask.ID)
+		}
+	})
+	tr.scheduleBtn.Importance = widget.MediumImportance
+
+	tr.queueBtn = tr.mobileUI.CreateMobileButton("⇅", tr.showQueueMenu)
+	tr.queueBtn.Importance = widget.MediumImportance
+
+	tr.dragHandle = NewDragHandle(tr.onDragEnd)
+
 	// Create mobile-specific play button
 	tr.mobilePlayBtn = tr.mobileUI.CreateMobileButton(IconMusic+" "+tr.localization.GetText(KeyPlay), func() {
 		currentTask := tr.task
@@ -348,7 +425,7 @@ func (tr *TaskRow) updateFromTask() {
 
 	// Update status label color and text
 	switch tr.task.Status {
-	case model.TaskStatusError:
+	case model.TaskStatusError, model.TaskStatusVerifyFailed:
 		tr.statusLabel.Importance = widget.DangerImportance
 		tr.statusLabel.SetText(IconError + " " + tr.task.Status.String())
 	case model.TaskStatusCompleted:
@@ -366,10 +443,27 @@ func (tr *TaskRow) updateFromTask() {
 	case model.TaskStatusStopped:
 		tr.statusLabel.Importance = widget.MediumImportance
 		tr.statusLabel.SetText("⏹ " + tr.task.Status.String())
+	case model.TaskStatusWaiting:
+		tr.statusLabel.Importance = widget.MediumImportance
+		tr.statusLabel.SetText("⏰ " + tr.task.Status.String())
+	case model.TaskStatusScheduled:
+		tr.statusLabel.Importance = widget.MediumImportance
+		tr.statusLabel.SetText("🕒 " + tr.task.Status.String())
+	case model.TaskStatusRecording:
+		tr.statusLabel.Importance = widget.DangerImportance
+		tr.statusLabel.SetText("● " + tr.task.Status.String())
 	default:
 		tr.statusLabel.Importance = widget.MediumImportance
 		tr.statusLabel.SetText(tr.task.Status.String())
 	}
+	if tr.isQueued() {
+		switch {
+		case tr.task.Priority > model.PriorityNormal:
+			tr.statusLabel.SetText(tr.statusLabel.Text + " " + PriorityHighIcon)
+		case tr.task.Priority < model.PriorityNormal:
+			tr.statusLabel.SetText(tr.statusLabel.Text + " " + PriorityLowIcon)
+		}
+	}
 
 	// Update progress with icon (robust fallback logic)
 	effectivePercent := tr.task.Percent
@@ -392,8 +486,18 @@ func (tr *TaskRow) updateFromTask() {
 	if effectivePercent > MaxProgressPercent {
 		effectivePercent = MaxProgressPercent
 	}
+	// Live broadcasts have no known size: show the countdown or the recorded duration.
+	// Finished downloads show how much SponsorBlock cut out of them.
 	if tr.task.Status == model.TaskStatusCompleted {
-		tr.progressLabel.SetText("")
+		if tr.task.RemovedDuration > 0 {
+			tr.progressLabel.SetText(fmt.Sprintf(tr.localization.GetText(KeySegmentsRemoved), tr.task.GetRemovedString()))
+		} else {
+			tr.progressLabel.SetText("")
+		}
+	} else if tr.task.Status == model.TaskStatusWaiting || tr.task.Status == model.TaskStatusScheduled {
+		tr.progressLabel.SetText(tr.task.GetCountdownString(time.Now()))
+	} else if tr.task.Status == model.TaskStatusRecording {
+		tr.progressLabel.SetText(tr.task.GetRecordedString())
 	} else {
 		tr.progressLabel.SetText(fmt.Sprintf("%d%%", effectivePercent))
 	}
@@ -416,7 +520,7 @@ func (tr *TaskRow) updateFromTask() {
 		}
 	} else if tr.task.Status == model.TaskStatusCompleted {
 		speedEtaText = ""
-	} else if tr.task.Status == model.TaskStatusError {
+	} else if tr.task.Status.IsFailed() {
 		speedEtaText = "Error"
 	}
 	tr.speedEtaLabel.SetText(speedEtaText)
@@ -429,6 +533,54 @@ func (tr *TaskRow) updateFromTask() {
 
 // showMoreMenu removed: actions are separate buttons now
 
+// isQueued reports whether the task waits in the download queue and can be reordered
+func (tr *TaskRow) isQueued() bool {
+	return tr.task.Status.IsQueued() || tr.task.Status == model.TaskStatusPaused
+}
+
+// showQueueMenu shows the reorder and priority actions below the queue button
+func (tr *TaskRow) showQueueMenu() {
+	currentTask := tr.task
+	if tr.onQueue == nil {
+		log.Printf("onQueue callback is nil for task %s", currentTask.ID)
+		return
+	}
+	item := func(key string, action QueueAction) *fyne.MenuItem {
+		return fyne.NewMenuItem(tr.localization.GetText(key), func() {
+			tr.onQueue(currentTask.ID, action)
+		})
+	}
+	priorityItem := func(key string, action QueueAction, priority model.TaskPriority) *fyne.MenuItem {
+		menuItem := item(key, action)
+		menuItem.Checked = currentTask.Priority == priority
+		return menuItem
+	}
+
+	menu := fyne.NewMenu("",
+		item(KeyDownloadNext, QueueDownloadNext),
+		item(KeyMoveToTop, QueueMoveToTop),
+		item(KeyMoveToBottom, QueueMoveToBottom),
+		fyne.NewMenuItemSeparator(),
+		priorityItem(KeyPriorityHigh, QueuePriorityHigh, model.PriorityHigh),
+		priorityItem(KeyPriorityNormal, QueuePriorityNormal, model.PriorityNormal),
+		priorityItem(KeyPriorityLow, QueuePriorityLow, model.PriorityLow),
+	)
+	driver := fyne.CurrentApp().Driver()
+	position := driver.AbsolutePositionForObject(tr.queueBtn).AddXY(0, tr.queueBtn.Size().Height)
+	widget.ShowPopUpMenuAtPosition(menu, driver.CanvasForObject(tr.queueBtn), position)
+}
+
+// onDragEnd moves the task by the number of rows its handle was dragged over
+func (tr *TaskRow) onDragEnd(dy float32) {
+	height := tr.Size().Height
+	if tr.onDrag == nil || !tr.isQueued() || height <= 0 {
+		return
+	}
+	if rows := int(math.Round(float64(dy / height))); rows != 0 {
+		tr.onDrag(tr.task.ID, rows)
+	}
+}
+
 // updateButtons updates button states based on task status
 func (tr *TaskRow) updateButtons() {
 	if tr.task == nil {
@@ -441,14 +593,18 @@ func (tr *TaskRow) updateButtons() {
 
 	// Start/Pause button visibility, enabled state, and text by status
 	switch tr.task.Status {
-	case model.TaskStatusPending:
+	case model.TaskStatusPending, model.TaskStatusScheduled:
 		tr.startPauseBtn.Show()
 		tr.startPauseBtn.Enable()
 		tr.startPauseBtn.SetText(tr.localization.GetText(KeyPause))
-	case model.TaskStatusStarting, model.TaskStatusDownloading:
+	case model.TaskStatusStarting, model.TaskStatusDownloading, model.TaskStatusWaiting:
 		tr.startPauseBtn.Show()
 		tr.startPauseBtn.Enable()
 		tr.startPauseBtn.SetText(tr.localization.GetText(KeyPause))
+	case model.TaskStatusRecording:
+		tr.startPauseBtn.Show()
+		tr.startPauseBtn.Enable()
+		tr.startPauseBtn.SetText(tr.localization.GetText(KeyStopRecording))
 	case model.TaskStatusPaused:
 		tr.startPauseBtn.Show()
 		tr.startPauseBtn.Enable()
@@ -457,7 +613,7 @@ func (tr *TaskRow) updateButtons() {
 		tr.startPauseBtn.Show()
 		tr.startPauseBtn.Enable()
 		tr.startPauseBtn.SetText(tr.localization.GetText(KeyPause))
-	case model.TaskStatusError:
+	case model.TaskStatusError, model.TaskStatusVerifyFailed:
 		tr.startPauseBtn.Show()
 		tr.startPauseBtn.Enable()
 		tr.startPauseBtn.SetText(tr.localization.GetText(KeyPause))
@@ -504,6 +660,37 @@ func (tr *TaskRow) updateButtons() {
 		tr.copyBtn.Disable()
 	}
 
+	// Chapters button only for finished videos that have chapters
+	if tr.task.Status == model.TaskStatusCompleted && len(tr.task.Chapters) > 0 {
+		tr.chaptersBtn.Show()
+		tr.chaptersBtn.Enable()
+	} else {
+		tr.chaptersBtn.Hide()
+	}
+
+	// Schedule button for tasks that have not started or were stopped
+	switch tr.task.Status {
+	case model.TaskStatusPending, model.TaskStatusScheduled, model.TaskStatusPaused,
+		model.TaskStatusStopped, model.TaskStatusError, model.TaskStatusVerifyFailed:
+		tr.scheduleBtn.Show()
+		tr.scheduleBtn.Enable()
+	default:
+		tr.scheduleBtn.Hide()
+	}
+
+	// Queue button and drag handle for tasks waiting in the queue
+	if tr.isQueued() {
+		tr.queueBtn.Show()
+		tr.queueBtn.Enable()
+	} else {
+		tr.queueBtn.Hide()
+	}
+	if tr.isQueued() && tr.onDrag != nil && !tr.fixedOrder {
+		tr.dragHandle.Show()
+	} else {
+		tr.dragHandle.Hide()
+	}
+
 	// Mobile-specific button visibility
 	if tr.mobileUI.IsMobileDevice() {
 		// On mobile, hide the play button completely - show only file path
@@ -514,6 +701,10 @@ func (tr *TaskRow) updateButtons() {
 		tr.openBtn.Hide()
 		tr.playBtn.Hide()
 		tr.copyBtn.Hide()
+		tr.chaptersBtn.Hide()
+		tr.scheduleBtn.Hide()
+		tr.queueBtn.Hide()
+		tr.dragHandle.Hide()
 	} else {
 		// On desktop, hide mobile button and show regular buttons
 		tr.mobilePlayBtn.Hide()
@@ -564,7 +755,11 @@ func (r *taskRowRenderer) Refresh() {
 		r.createLayout()
 	}
 
-	if r.taskRow.task != nil {
+	// Live and scheduled tasks show a countdown or recorded duration set by updateFromTask
+	// instead of a percent, and finished tasks the duration SponsorBlock cut out
+	if task := r.taskRow.task; task != nil && task.Status != model.TaskStatusWaiting && task.Status != model.TaskStatusRecording &&
+		task.Status != model.TaskStatusScheduled &&
+		(task.Status != model.TaskStatusCompleted || task.RemovedDuration == 0) {
 		// Also update textual progress label to reflect current percent
 		effectivePercent := r.taskRow.task.Percent
 		if r.taskRow.task.Status == model.TaskStatusCompleted {
@@ -617,8 +812,8 @@ func (r *taskRowRenderer) createLayout() {
 		return
 	}
 
-	// Left side: file title (more prominent)
-	leftSide := tr.titleLabel
+	// Left side: drag handle for queued tasks, then the file title (more prominent)
+	leftSide := container.NewBorder(nil, nil, tr.dragHandle, nil, tr.titleLabel)
 
 	// Right side: vertical compact info aligned to the right with fixed widths
 	// Helper to fix width using a transparent rectangle underneath
@@ -648,6 +843,9 @@ func (r *taskRowRenderer) createLayout() {
 		r.taskRow.openBtn,       // open (reveal)
 		r.taskRow.playBtn,       // play (open with default app)
 		r.taskRow.copyBtn,       // path (copy)
+		r.taskRow.chaptersBtn,   // split by chapters
+		r.taskRow.scheduleBtn,   // set start time
+		r.taskRow.queueBtn,      // reorder and priority
 	)
 
 	// Ensure buttons are properly sized and clickable
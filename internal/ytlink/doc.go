@@ -0,0 +1,6 @@
+package ytlink
+
+// Package ytlink classifies and canonicalises YouTube URLs: watch, shorts,
+// embed, live, youtu.be, m./music. and youtube-nocookie video links, playlists,
+// channels and @handles. It is the single place where video and playlist IDs
+// are extracted from URLs.
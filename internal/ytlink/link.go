@@ -0,0 +1,227 @@
+package ytlink
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Kind classifies a YouTube link
+type Kind string
+
+const (
+	KindVideo    Kind = "video"
+	KindShorts   Kind = "shorts"
+	KindLive     Kind = "live"
+	KindPlaylist Kind = "playlist"
+	KindChannel  Kind = "channel"
+	KindHandle   Kind = "handle"
+)
+
+// Canonical URL templates
+const (
+	CanonicalVideoURL    = "https://www.youtube.com/watch?v=%s"
+	CanonicalPlaylistURL = "https://www.youtube.com/playlist?list=%s"
+	CanonicalChannelURL  = "https://www.youtube.com/channel/%s"
+	CanonicalHandleURL   = "https://www.youtube.com/@%s"
+)
+
+// Query parameters
+const (
+	VideoParam    = "v"
+	PlaylistParam = "list"
+)
+
+// Errors returned by Parse
+var (
+	ErrNotYouTube  = errors.New("not a YouTube URL")
+	ErrUnsupported = errors.New("unsupported YouTube URL")
+)
+
+var (
+	videoIDPattern    = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	playlistIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	channelIDPattern  = regexp.MustCompile(`^UC[A-Za-z0-9_-]{22}$`)
+	handlePattern     = regexp.MustCompile(`^[A-Za-z0-9._-]{3,30}$`)
+)
+
+// youTubeHosts lists the hosts serving youtube.com style paths
+var youTubeHosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"music.youtube.com":        true,
+	"gaming.youtube.com":       true,
+	"youtube-nocookie.com":     true,
+	"www.youtube-nocookie.com": true,
+}
+
+// shortHost serves youtu.be/ID links
+const shortHost = "youtu.be"
+
+// Link is a parsed YouTube URL. IDs that do not apply to the kind are empty;
+// a video opened from a playlist keeps both VideoID and PlaylistID.
+type Link struct {
+	Kind       Kind
+	VideoID    string
+	PlaylistID string
+	ChannelID  string
+	Handle     string // without the leading "@"
+}
+
+// Parse classifies a YouTube URL. A missing scheme is assumed to be https.
+func Parse(raw string) (Link, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Link{}, ErrNotYouTube
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Link{}, fmt.Errorf("%w: %v", ErrNotYouTube, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return Link{}, ErrNotYouTube
+	}
+
+	host := strings.ToLower(u.Hostname())
+	query := u.Query()
+	playlistID := query.Get(PlaylistParam)
+	if !IsValidPlaylistID(playlistID) {
+		playlistID = ""
+	}
+	segments := pathSegments(u.Path)
+
+	switch {
+	case host == shortHost || host == "www."+shortHost:
+		if len(segments) > 0 && IsValidVideoID(segments[0]) {
+			return Link{Kind: KindVideo, VideoID: segments[0], PlaylistID: playlistID}, nil
+		}
+		return playlistOnly(playlistID)
+	case youTubeHosts[host]:
+		return parseYouTubePath(segments, query, playlistID)
+	default:
+		return Link{}, ErrNotYouTube
+	}
+}
+
+// parseYouTubePath classifies the path of a youtube.com style URL
+func parseYouTubePath(segments []string, query url.Values, playlistID string) (Link, error) {
+	if len(segments) == 0 {
+		return playlistOnly(playlistID)
+	}
+
+	first := segments[0]
+	second := ""
+	if len(segments) > 1 {
+		second = segments[1]
+	}
+
+	switch {
+	case first == "watch":
+		if videoID := query.Get(VideoParam); IsValidVideoID(videoID) {
+			return Link{Kind: KindVideo, VideoID: videoID, PlaylistID: playlistID}, nil
+		}
+		return playlistOnly(playlistID)
+	case first == "playlist":
+		return playlistOnly(playlistID)
+	case first == "shorts" && IsValidVideoID(second):
+		return Link{Kind: KindShorts, VideoID: second, PlaylistID: playlistID}, nil
+	case first == "live" && IsValidVideoID(second):
+		return Link{Kind: KindLive, VideoID: second, PlaylistID: playlistID}, nil
+	case first == "embed" && second == "videoseries":
+		return playlistOnly(playlistID)
+	case (first == "embed" || first == "v" || first == "e") && IsValidVideoID(second):
+		return Link{Kind: KindVideo, VideoID: second, PlaylistID: playlistID}, nil
+	case first == "channel" && channelIDPattern.MatchString(second):
+		return Link{Kind: KindChannel, ChannelID: second}, nil
+	case strings.HasPrefix(first, "@") && handlePattern.MatchString(first[1:]):
+		return Link{Kind: KindHandle, Handle: first[1:]}, nil
+	}
+	return Link{}, ErrUnsupported
+}
+
+// playlistOnly returns a playlist link or ErrUnsupported when there is no playlist ID
+func playlistOnly(playlistID string) (Link, error) {
+	if playlistID == "" {
+		return Link{}, ErrUnsupported
+	}
+	return Link{Kind: KindPlaylist, PlaylistID: playlistID}, nil
+}
+
+// pathSegments splits a URL path into non-empty segments
+func pathSegments(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+// IsVideo reports whether the link points to a single video (watch, shorts, live, embed)
+func (l Link) IsVideo() bool {
+	return l.VideoID != ""
+}
+
+// IsPlaylist reports whether the link carries a playlist, including a video opened from one
+func (l Link) IsPlaylist() bool {
+	return l.PlaylistID != ""
+}
+
+// CanonicalURL returns the canonical www.youtube.com URL of the link.
+// Videos map to watch URLs without playlist or tracking parameters.
+func (l Link) CanonicalURL() string {
+	switch {
+	case l.VideoID != "":
+		return fmt.Sprintf(CanonicalVideoURL, l.VideoID)
+	case l.PlaylistID != "":
+		return fmt.Sprintf(CanonicalPlaylistURL, l.PlaylistID)
+	case l.ChannelID != "":
+		return fmt.Sprintf(CanonicalChannelURL, l.ChannelID)
+	case l.Handle != "":
+		return fmt.Sprintf(CanonicalHandleURL, l.Handle)
+	}
+	return ""
+}
+
+// CanonicalPlaylistURL returns the playlist URL of the link, or "" if it has no playlist
+func (l Link) CanonicalPlaylistURL() string {
+	if l.PlaylistID == "" {
+		return ""
+	}
+	return fmt.Sprintf(CanonicalPlaylistURL, l.PlaylistID)
+}
+
+// VideoID returns the video ID of a YouTube video URL, or "" if there is none
+func VideoID(raw string) string {
+	link, err := Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return link.VideoID
+}
+
+// PlaylistID returns the playlist ID of a YouTube URL, or "" if there is none
+func PlaylistID(raw string) string {
+	link, err := Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return link.PlaylistID
+}
+
+// IsValidVideoID reports whether id has the shape of a YouTube video ID
+func IsValidVideoID(id string) bool {
+	return videoIDPattern.MatchString(id)
+}
+
+// IsValidPlaylistID reports whether id has the shape of a YouTube playlist ID
+func IsValidPlaylistID(id string) bool {
+	return playlistIDPattern.MatchString(id)
+}
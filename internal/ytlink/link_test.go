@@ -0,0 +1,89 @@
+package ytlink
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const id = "dQw4w9WgXcQ"
+	const list = "PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf"
+	const channel = "UC38IQsAvIsxxjztdMZQtwHA"
+
+	tests := []struct {
+		name      string
+		url       string
+		want      Link
+		canonical string
+		wantErr   error
+	}{
+		{"watch", "https://www.youtube.com/watch?v=" + id, Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"watch with tracking", "https://www.youtube.com/watch?feature=share&v=" + id + "&t=42s#x", Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"watch without scheme", "youtube.com/watch?v=" + id, Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"mobile", "https://m.youtube.com/watch?v=" + id, Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"music", "https://music.youtube.com/watch?v=" + id + "&si=abc", Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"short link", "https://youtu.be/" + id + "?si=abc", Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"shorts", "https://www.youtube.com/shorts/" + id, Link{Kind: KindShorts, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"live", "https://www.youtube.com/live/" + id + "?feature=share", Link{Kind: KindLive, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"embed", "https://www.youtube.com/embed/" + id, Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"nocookie embed", "https://www.youtube-nocookie.com/embed/" + id + "?rel=0", Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"uppercase host", "HTTPS://WWW.YOUTUBE.COM/watch?v=" + id, Link{Kind: KindVideo, VideoID: id}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"video in playlist", "https://www.youtube.com/watch?v=" + id + "&list=" + list + "&index=3", Link{Kind: KindVideo, VideoID: id, PlaylistID: list}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"short link in playlist", "https://youtu.be/" + id + "?list=" + list, Link{Kind: KindVideo, VideoID: id, PlaylistID: list}, "https://www.youtube.com/watch?v=" + id, nil},
+		{"playlist", "https://www.youtube.com/playlist?list=" + list, Link{Kind: KindPlaylist, PlaylistID: list}, "https://www.youtube.com/playlist?list=" + list, nil},
+		{"music playlist", "https://music.youtube.com/playlist?list=" + list, Link{Kind: KindPlaylist, PlaylistID: list}, "https://www.youtube.com/playlist?list=" + list, nil},
+		{"embedded playlist", "https://www.youtube.com/embed/videoseries?list=" + list, Link{Kind: KindPlaylist, PlaylistID: list}, "https://www.youtube.com/playlist?list=" + list, nil},
+		{"watch with placeholder video", "https://www.youtube.com/watch?v=VIDEO_ID&list=PLAYLIST_ID", Link{Kind: KindPlaylist, PlaylistID: "PLAYLIST_ID"}, "https://www.youtube.com/playlist?list=PLAYLIST_ID", nil},
+		{"channel", "https://www.youtube.com/channel/" + channel + "/videos", Link{Kind: KindChannel, ChannelID: channel}, "https://www.youtube.com/channel/" + channel, nil},
+		{"handle", "https://www.youtube.com/@GoogleDevelopers/videos", Link{Kind: KindHandle, Handle: "GoogleDevelopers"}, "https://www.youtube.com/@GoogleDevelopers", nil},
+		{"short video ID", "https://www.youtube.com/watch?v=abc", Link{}, "", ErrUnsupported},
+		{"empty playlist", "https://www.youtube.com/watch?v=abc&list=", Link{}, "", ErrUnsupported},
+		{"results page", "https://www.youtube.com/results?search_query=go", Link{}, "", ErrUnsupported},
+		{"other host", "https://example.com/watch?v=" + id + "&list=" + list, Link{}, "", ErrNotYouTube},
+		{"lookalike host", "https://youtube.com.evil.test/watch?v=" + id, Link{}, "", ErrNotYouTube},
+		{"other scheme", "ftp://www.youtube.com/watch?v=" + id, Link{}, "", ErrNotYouTube},
+		{"empty", "", Link{}, "", ErrNotYouTube},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, err := Parse(tt.url)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want %v", tt.url, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.url, err)
+			}
+			if link != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.url, link, tt.want)
+			}
+			if got := link.CanonicalURL(); got != tt.canonical {
+				t.Errorf("CanonicalURL() = %q, want %q", got, tt.canonical)
+			}
+		})
+	}
+}
+
+func TestIDHelpers(t *testing.T) {
+	url := "https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PL123"
+	if got := VideoID(url); got != "dQw4w9WgXcQ" {
+		t.Errorf("VideoID = %q", got)
+	}
+	if got := PlaylistID(url); got != "PL123" {
+		t.Errorf("PlaylistID = %q", got)
+	}
+	if got := VideoID("https://vimeo.com/123456"); got != "" {
+		t.Errorf("VideoID of non-YouTube URL = %q, want empty", got)
+	}
+
+	link, _ := Parse(url)
+	if !link.IsVideo() || !link.IsPlaylist() {
+		t.Errorf("expected video opened from a playlist, got %+v", link)
+	}
+	if got := link.CanonicalPlaylistURL(); got != "https://www.youtube.com/playlist?list=PL123" {
+		t.Errorf("CanonicalPlaylistURL = %q", got)
+	}
+}
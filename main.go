@@ -1,13 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 
 	"github.com/ytget/yt-downloader/internal/compress"
 	"github.com/ytget/yt-downloader/internal/download"
+	"github.com/ytget/yt-downloader/internal/instance"
 	"github.com/ytget/yt-downloader/internal/platform"
 	"github.com/ytget/yt-downloader/internal/ui"
 )
@@ -28,6 +31,13 @@ func main() {
 	// Log version information
 	fmt.Printf("YT Downloader v%s starting...\n", version)
 
+	// Hand URL arguments (including ytdl:// links) to an already running instance
+	startupURLs := instance.URLArgs(os.Args[1:])
+	lock := acquireInstanceLock(startupURLs)
+	if lock != nil {
+		defer lock.Close()
+	}
+
 	// Initialize logo resource
 	if err := ui.InitLogoResource(); err != nil {
 		fmt.Printf("Warning: failed to initialize logo: %v\n", err)
@@ -60,8 +70,59 @@ func main() {
 	compressSvc := compress.NewService()
 
 	// Create and setup UI
-	ui.NewRootUI(myWindow, myApp, downloadSvc, compressSvc)
+	rootUI := ui.NewRootUI(myWindow, myApp, downloadSvc, compressSvc)
+
+	// Accept URLs forwarded by later launches and queue the ones passed to this one
+	if lock != nil {
+		lock.Serve(func(url string) error {
+			err := rootUI.EnqueueExternalURL(url)
+			fyne.Do(myWindow.RequestFocus)
+			return err
+		})
+	}
+	if len(startupURLs) > 0 {
+		go func() {
+			for _, url := range startupURLs {
+				if err := rootUI.EnqueueExternalURL(url); err != nil {
+					fmt.Printf("failed to queue %s: %v\n", url, err)
+				}
+			}
+		}()
+	}
 
 	// Show and run
 	myWindow.ShowAndRun()
+
+	// Keep unfinished downloads and their order for the next launch
+	if err := downloadSvc.SaveQueue(); err != nil {
+		fmt.Printf("failed to save download queue: %v\n", err)
+	}
+}
+
+// acquireInstanceLock makes this process the primary instance. When another
+// instance is running, the URLs are forwarded to it and the process exits.
+// It returns nil if the lock is unavailable; the app then runs standalone.
+func acquireInstanceLock(urls []string) *instance.Lock {
+	socketPath, err := instance.DefaultSocketPath()
+	if err != nil {
+		fmt.Printf("Warning: single-instance lock disabled: %v\n", err)
+		return nil
+	}
+
+	lock, err := instance.Acquire(socketPath)
+	if err == nil {
+		return lock
+	}
+	if !errors.Is(err, instance.ErrAlreadyRunning) {
+		fmt.Printf("Warning: single-instance lock disabled: %v\n", err)
+		return nil
+	}
+
+	if err := instance.Forward(socketPath, urls); err != nil {
+		fmt.Printf("failed to forward to running instance: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("YT Downloader is already running; forwarded to the existing window")
+	os.Exit(0)
+	return nil
 }